@@ -14,6 +14,7 @@ import (
 	"github.com/checkmarx/ast-cli/internal/wrappers"
 	"github.com/checkmarx/ast-cli/internal/wrappers/bitbucketserver"
 	"github.com/checkmarx/ast-cli/internal/wrappers/configuration"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
 	"github.com/spf13/viper"
 )
 
@@ -77,6 +78,8 @@ func main() {
 	featureFlagsWrapper := wrappers.NewFeatureFlagsHTTPWrapper(featureFlagsPath)
 	policyWrapper := wrappers.NewHTTPPolicyWrapper(policyEvaluationPath)
 	sastMetadataWrapper := wrappers.NewSastIncrementalHTTPWrapper(sastMetadataPath)
+	npmRegistryWrapper := wrappers.NewNpmRegistryHTTPWrapper()
+	scaDBWrapper := wrappers.NewScaDBHTTPWrapper()
 
 	astCli := commands.NewAstCLI(
 		scansWrapper,
@@ -106,6 +109,9 @@ func main() {
 		featureFlagsWrapper,
 		policyWrapper,
 		sastMetadataWrapper,
+		npmRegistryWrapper,
+		scaDBWrapper,
+		container.NewProvider(),
 	)
 	exitListener()
 	err = astCli.Execute()
@@ -146,6 +152,11 @@ func bindProxy() {
 	if err != nil {
 		exitIfError(err)
 	}
+	err = viper.BindEnv(params.NoProxyKey, params.CxNoProxyEnv, params.NoProxyEnv, params.NoProxyLowerCaseEnv)
+	if err != nil {
+		exitIfError(err)
+	}
+	viper.SetDefault(params.NoProxyKey, "")
 }
 
 func exitListener() {
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -37,15 +39,28 @@ const (
 	lowLabel                  = "low"
 	infoLabel                 = "info"
 	sonarTypeLabel            = "_sonar"
+	sonarV10TypeLabel         = "_sonar_v10"
+	sonarSecurityQuality      = "SECURITY"
+	sonarImpactLow            = "LOW"
+	sonarImpactMedium         = "MEDIUM"
+	sonarImpactHigh           = "HIGH"
+	sonarTrustworthyAttribute = "TRUSTWORTHY"
 	glSastTypeLobel           = ".gl-sast-report"
 	directoryPermission       = 0700
 	infoSonar                 = "INFO"
 	lowSonar                  = "MINOR"
 	mediumSonar               = "MAJOR"
 	highSonar                 = "CRITICAL"
+	noneSarif                 = "none"
 	infoLowSarif              = "note"
 	mediumSarif               = "warning"
 	highSarif                 = "error"
+	criticalCx                = "CRITICAL"
+	sarifAutomationCategory   = "checkmarx-one"
+	sarifFingerprintKey       = "similarityHash/v1"
+	sarifBaselineNew          = "new"
+	sarifBaselineUnchanged    = "unchanged"
+	sarifBaselineAbsent       = "absent"
 	vulnerabilitySonar        = "VULNERABILITY"
 	infoCx                    = "INFO"
 	lowCx                     = "LOW"
@@ -96,6 +111,9 @@ var summaryFormats = []string{
 	printer.FormatSummaryMarkdown,
 	printer.FormatSbom,
 	printer.FormatGL,
+	printer.FormatGLDependency,
+	printer.FormatGLContainer,
+	printer.FormatHTML,
 }
 
 var filterResultsListFlagUsage = fmt.Sprintf(
@@ -132,9 +150,32 @@ var sonarSeverities = map[string]string{
 	highCx:   highSonar,
 }
 
+var sonarImpactSeverities = map[string]string{
+	infoCx:     sonarImpactLow,
+	lowCx:      sonarImpactLow,
+	mediumCx:   sonarImpactMedium,
+	highCx:     sonarImpactHigh,
+	criticalCx: sonarImpactHigh,
+}
+
+var sonarImpactRank = map[string]int{
+	sonarImpactLow:    1,
+	sonarImpactMedium: 2,
+	sonarImpactHigh:   3,
+}
+
+// sonarCleanCodeAttributes is the attribute of the code broken by the findings of each engine: SAST findings are
+// incomplete handling of input, SCA findings untrustworthy dependencies and KICS findings unconventional configuration
+var sonarCleanCodeAttributes = map[string]string{
+	commonParams.SastType: "COMPLETE",
+	commonParams.ScaType:  sonarTrustworthyAttribute,
+	commonParams.KicsType: "CONVENTIONAL",
+}
+
 func NewResultsCommand(
 	resultsWrapper wrappers.ResultsWrapper,
 	scanWrapper wrappers.ScansWrapper,
+	projectsWrapper wrappers.ProjectsWrapper,
 	resultsSbomWrapper wrappers.ResultsSbomWrapper,
 	resultsPdfReportsWrapper wrappers.ResultsPdfWrapper,
 	codeBashingWrapper wrappers.CodeBashingWrapper,
@@ -156,8 +197,13 @@ func NewResultsCommand(
 	showResultCmd := resultShowSubCommand(resultsWrapper, scanWrapper, resultsSbomWrapper, resultsPdfReportsWrapper, risksOverviewWrapper, policyWrapper)
 	codeBashingCmd := resultCodeBashing(codeBashingWrapper)
 	bflResultCmd := resultBflSubCommand(bflWrapper)
+	scaPathsCmd := resultScaPathsSubCommand(resultsWrapper, scanWrapper)
+	importResultsCmd := resultImportSubCommand()
+	complianceCmd := resultComplianceSubCommand(resultsWrapper, scanWrapper)
+	portfolioCmd := resultPortfolioSubCommand(resultsWrapper, scanWrapper, projectsWrapper, risksOverviewWrapper, policyWrapper)
+	trendsCmd := resultTrendsSubCommand(resultsWrapper, scanWrapper, projectsWrapper)
 	resultCmd.AddCommand(
-		showResultCmd, bflResultCmd, codeBashingCmd,
+		showResultCmd, bflResultCmd, codeBashingCmd, scaPathsCmd, importResultsCmd, complianceCmd, portfolioCmd, trendsCmd,
 	)
 	return resultCmd
 }
@@ -193,10 +239,18 @@ func resultShowSubCommand(
 		printer.FormatPDF,
 		printer.FormatSummaryMarkdown,
 		printer.FormatGL,
+		printer.FormatGLDependency,
+		printer.FormatGLContainer,
+		printer.FormatSonarV10,
+		printer.FormatHTML,
+		printer.FormatCSV,
+		printer.FormatXLSX,
 	)
 	resultShowCmd.PersistentFlags().String(commonParams.ReportFormatPdfToEmailFlag, "", pdfToEmailFlagDescription)
 	resultShowCmd.PersistentFlags().String(commonParams.ReportSbomFormatFlag, defaultSbomOption, sbomReportFlagDescription)
 	resultShowCmd.PersistentFlags().String(commonParams.ReportFormatPdfOptionsFlag, defaultPdfOptionsDataSections, pdfOptionsFlagDescription)
+	resultShowCmd.PersistentFlags().String(commonParams.ReportFormatPdfModeFlag, pdfModeServer, pdfModeFlagDescription)
+	resultShowCmd.PersistentFlags().String(commonParams.ReportColumnsFlag, "", spreadsheetColumnsFlagDescription+strings.Join(spreadsheetColumnNames(), ","))
 	resultShowCmd.PersistentFlags().String(commonParams.TargetFlag, "cx_result", "Output file")
 	resultShowCmd.PersistentFlags().String(commonParams.TargetPathFlag, ".", "Output Path")
 	resultShowCmd.PersistentFlags().StringSlice(commonParams.FilterFlag, []string{}, filterResultsListFlagUsage)
@@ -220,6 +274,8 @@ func resultShowSubCommand(
 	resultShowCmd.PersistentFlags().Bool(commonParams.IgnorePolicyFlag, false, "Do not evaluate policies")
 	resultShowCmd.PersistentFlags().Bool(commonParams.SastRedundancyFlag, false,
 		"Populate SAST results 'data.redundancy' with values '"+fixLabel+"' (to fix) or '"+redundantLabel+"' (no need to fix)")
+	resultShowCmd.PersistentFlags().String(commonParams.BaseScanIDFlag, "",
+		"ID of the scan to compare with, SARIF results get a baselineState of new, unchanged or absent")
 	return resultShowCmd
 }
 
@@ -584,7 +640,9 @@ func runGetResultCommand(
 		format, _ := cmd.Flags().GetString(commonParams.TargetFormatFlag)
 		formatPdfToEmail, _ := cmd.Flags().GetString(commonParams.ReportFormatPdfToEmailFlag)
 		formatPdfOptions, _ := cmd.Flags().GetString(commonParams.ReportFormatPdfOptionsFlag)
+		formatPdfMode, _ := cmd.Flags().GetString(commonParams.ReportFormatPdfModeFlag)
 		formatSbomOptions, _ := cmd.Flags().GetString(commonParams.ReportSbomFormatFlag)
+		reportColumns, _ := cmd.Flags().GetString(commonParams.ReportColumnsFlag)
 		useSCALocalFlow, _ := cmd.Flags().GetBool(commonParams.ReportSbomFormatLocalFlowFlag)
 		retrySBOM, _ := cmd.Flags().GetInt(commonParams.RetrySBOMFlag)
 		sastRedundancy, _ := cmd.Flags().GetBool(commonParams.SastRedundancyFlag)
@@ -625,6 +683,11 @@ func runGetResultCommand(
 			params[commonParams.SastRedundancyFlag] = ""
 		}
 
+		baseResults, err := readBaseResults(cmd, resultsWrapper, scanWrapper, format)
+		if err != nil {
+			return err
+		}
+
 		return CreateScanReport(
 			resultsWrapper,
 			risksOverviewWrapper,
@@ -637,11 +700,42 @@ func runGetResultCommand(
 			format,
 			formatPdfToEmail,
 			formatPdfOptions,
+			formatPdfMode,
 			formatSbomOptions,
+			reportColumns,
 			targetFile,
 			targetPath,
-			params)
+			params,
+			baseResults)
+	}
+}
+
+// readBaseResults reads the results of --base-scan-id when a SARIF report is requested
+func readBaseResults(
+	cmd *cobra.Command,
+	resultsWrapper wrappers.ResultsWrapper,
+	scanWrapper wrappers.ScansWrapper,
+	format string,
+) (*wrappers.ScanResultsCollection, error) {
+	baseScanID, _ := cmd.Flags().GetString(commonParams.BaseScanIDFlag)
+	if baseScanID == "" || !verifyFormatsByReportList(strings.Split(format, ","), printer.FormatSarif) {
+		return nil, nil
+	}
+	baseScan, errorModel, err := scanWrapper.GetByID(baseScanID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", failedGettingScan)
+	}
+	if errorModel != nil {
+		return nil, errors.Errorf("%s: CODE: %d, %s", failedGettingScan, errorModel.Code, errorModel.Message)
+	}
+	baseResults, err := ReadResults(resultsWrapper, baseScan, make(map[string]string))
+	if err != nil {
+		return nil, err
 	}
+	if baseResults == nil {
+		return &wrappers.ScanResultsCollection{ScanID: baseScanID}, nil
+	}
+	return baseResults, nil
 }
 
 func runGetCodeBashingCommand(
@@ -696,10 +790,13 @@ func CreateScanReport(
 	reportTypes,
 	formatPdfToEmail,
 	formatPdfOptions,
+	formatPdfMode,
 	formatSbomOptions,
+	reportColumns,
 	targetFile,
 	targetPath string,
 	params map[string]string,
+	baseResults *wrappers.ScanResultsCollection,
 ) error {
 	reportList := strings.Split(reportTypes, ",")
 	results := &wrappers.ScanResultsCollection{}
@@ -729,8 +826,8 @@ func CreateScanReport(
 		}
 	}
 	for _, reportType := range reportList {
-		err = createReport(reportType, formatPdfToEmail, formatPdfOptions, formatSbomOptions, targetFile,
-			targetPath, results, summary, resultsSbomWrapper, resultsPdfReportsWrapper, useSCALocalFlow, retrySBOM)
+		err = createReport(reportType, formatPdfToEmail, formatPdfOptions, formatPdfMode, formatSbomOptions, reportColumns, targetFile,
+			targetPath, results, baseResults, summary, resultsSbomWrapper, resultsPdfReportsWrapper, useSCALocalFlow, retrySBOM)
 		if err != nil {
 			return err
 		}
@@ -827,10 +924,13 @@ func isValidScanStatus(status, format string) bool {
 func createReport(format,
 	formatPdfToEmail,
 	formatPdfOptions,
+	formatPdfMode,
 	formatSbomOptions,
+	reportColumns,
 	targetFile,
 	targetPath string,
 	results *wrappers.ScanResultsCollection,
+	baseResults *wrappers.ScanResultsCollection,
 	summary *wrappers.ResultSummary,
 	resultsSbomWrapper wrappers.ResultsSbomWrapper,
 	resultsPdfReportsWrapper wrappers.ResultsPdfWrapper,
@@ -838,12 +938,16 @@ func createReport(format,
 	retrySBOM int) error {
 	if printer.IsFormat(format, printer.FormatSarif) && isValidScanStatus(summary.Status, printer.FormatSarif) {
 		sarifRpt := createTargetName(targetFile, targetPath, printer.FormatSarif)
-		return exportSarifResults(sarifRpt, results)
+		return exportSarifResults(sarifRpt, results, baseResults)
 	}
 	if printer.IsFormat(format, printer.FormatSonar) && isValidScanStatus(summary.Status, printer.FormatSonar) {
 		sonarRpt := createTargetName(fmt.Sprintf("%s%s", targetFile, sonarTypeLabel), targetPath, printer.FormatJSON)
 		return exportSonarResults(sonarRpt, results)
 	}
+	if printer.IsFormat(format, printer.FormatSonarV10) && isValidScanStatus(summary.Status, printer.FormatSonarV10) {
+		sonarRpt := createTargetName(fmt.Sprintf("%s%s", targetFile, sonarV10TypeLabel), targetPath, printer.FormatJSON)
+		return exportSonarV10Results(sonarRpt, results)
+	}
 	if printer.IsFormat(format, printer.FormatJSON) && isValidScanStatus(summary.Status, printer.FormatJSON) {
 		jsonRpt := createTargetName(targetFile, targetPath, printer.FormatJSON)
 		return exportJSONResults(jsonRpt, results)
@@ -852,6 +956,26 @@ func createReport(format,
 		jsonRpt := createTargetName(fmt.Sprintf("%s%s", targetFile, glSastTypeLobel), targetPath, printer.FormatJSON)
 		return exportGlSastResults(jsonRpt, results, summary)
 	}
+	if printer.IsFormat(format, printer.FormatGLDependency) {
+		jsonRpt := createTargetName(fmt.Sprintf("%s%s", targetFile, glDependencyTypeLabel), targetPath, printer.FormatJSON)
+		return exportGlDependencyResults(jsonRpt, results, summary, wrappers.GlDependencyScanningType)
+	}
+	if printer.IsFormat(format, printer.FormatGLContainer) {
+		jsonRpt := createTargetName(fmt.Sprintf("%s%s", targetFile, glContainerTypeLabel), targetPath, printer.FormatJSON)
+		return exportGlDependencyResults(jsonRpt, results, summary, wrappers.GlContainerScanningType)
+	}
+	if printer.IsFormat(format, printer.FormatHTML) && isValidScanStatus(summary.Status, printer.FormatHTML) {
+		htmlRpt := createTargetName(fmt.Sprintf("%s%s", targetFile, resultsHTMLTypeLabel), targetPath, printer.FormatHTML)
+		return writeHTMLResults(htmlRpt, results, summary)
+	}
+	if printer.IsFormat(format, printer.FormatCSV) && isValidScanStatus(summary.Status, printer.FormatCSV) {
+		csvRpt := createTargetName(targetFile, targetPath, printer.FormatCSV)
+		return exportCSVResults(csvRpt, results, summary, reportColumns)
+	}
+	if printer.IsFormat(format, printer.FormatXLSX) && isValidScanStatus(summary.Status, printer.FormatXLSX) {
+		xlsxRpt := createTargetName(targetFile, targetPath, printer.FormatXLSX)
+		return exportXLSXResults(xlsxRpt, results, summary, reportColumns)
+	}
 	if printer.IsFormat(format, printer.FormatSummaryConsole) {
 		return writeConsoleSummary(summary)
 	}
@@ -867,7 +991,7 @@ func createReport(format,
 	}
 	if printer.IsFormat(format, printer.FormatPDF) && isValidScanStatus(summary.Status, printer.FormatPDF) {
 		summaryRpt := createTargetName(targetFile, targetPath, printer.FormatPDF)
-		return exportPdfResults(resultsPdfReportsWrapper, summary, summaryRpt, formatPdfToEmail, formatPdfOptions)
+		return exportPdfResults(resultsPdfReportsWrapper, summary, results, summaryRpt, formatPdfToEmail, formatPdfOptions, formatPdfMode)
 	}
 	if printer.IsFormat(format, printer.FormatSummaryMarkdown) {
 		summaryRpt := createTargetName(targetFile, targetPath, "md")
@@ -978,11 +1102,11 @@ func enrichScaResults(
 	return resultsModel, nil
 }
 
-func exportSarifResults(targetFile string, results *wrappers.ScanResultsCollection) error {
+func exportSarifResults(targetFile string, results, baseResults *wrappers.ScanResultsCollection) error {
 	var err error
 	var resultsJSON []byte
 	log.Println("Creating SARIF Report: ", targetFile)
-	var sarifResults = convertCxResultsToSarif(results)
+	var sarifResults = convertCxResultsToSarif(results, baseResults)
 	resultsJSON, err = json.Marshal(sarifResults)
 	if err != nil {
 		return errors.Wrapf(err, "%s: failed to serialize results response ", failedGettingAll)
@@ -1057,6 +1181,20 @@ func exportSonarResults(targetFile string, results *wrappers.ScanResultsCollecti
 	_ = f.Close()
 	return nil
 }
+func exportSonarV10Results(targetFile string, results *wrappers.ScanResultsCollection) error {
+	log.Println("Creating SONAR v10 Report: ", targetFile)
+	resultsJSON, err := json.Marshal(convertCxResultsToSonarV10(results))
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to serialize results response ", failedGettingAll)
+	}
+	f, err := os.Create(targetFile)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to create target file  ", failedGettingAll)
+	}
+	_, _ = fmt.Fprintln(f, string(resultsJSON))
+	_ = f.Close()
+	return nil
+}
 func exportJSONResults(targetFile string, results *wrappers.ScanResultsCollection) error {
 	var err error
 	var resultsJSON []byte
@@ -1156,13 +1294,40 @@ func exportSbomResults(sbomWrapper wrappers.ResultsSbomWrapper,
 	}
 	return nil
 }
-func exportPdfResults(pdfWrapper wrappers.ResultsPdfWrapper, summary *wrappers.ResultSummary, summaryRpt, formatPdfToEmail, pdfOptions string) error {
-	pdfReportsPayload := &wrappers.PdfReportsPayload{}
-	pollingResp := &wrappers.PdfPollingResponse{}
+func exportPdfResults(pdfWrapper wrappers.ResultsPdfWrapper, summary *wrappers.ResultSummary, results *wrappers.ScanResultsCollection,
+	summaryRpt, formatPdfToEmail, pdfOptions, pdfMode string) error {
 	pdfOptionsSections, pdfOptionsEngines, err := parsePDFOptions(pdfOptions, summary.EnginesEnabled)
 	if err != nil {
 		return err
 	}
+	exportLocal := func() error {
+		if len(formatPdfToEmail) > 0 {
+			log.Println("Ignoring --" + commonParams.ReportFormatPdfToEmailFlag + ", the local PDF report is only saved to the file system")
+		}
+		log.Println("Creating PDF Report: ", summaryRpt)
+		return exportLocalPdfResults(summary, results, summaryRpt, pdfOptionsSections, pdfOptionsEngines)
+	}
+	switch strings.ToLower(strings.TrimSpace(pdfMode)) {
+	case pdfModeLocal:
+		return exportLocal()
+	case pdfModeServer, "":
+		return exportServerPdfResults(pdfWrapper, summary, summaryRpt, formatPdfToEmail, pdfOptionsSections, pdfOptionsEngines)
+	case pdfModeAuto:
+		err = exportServerPdfResults(pdfWrapper, summary, summaryRpt, formatPdfToEmail, pdfOptionsSections, pdfOptionsEngines)
+		if err == nil {
+			return nil
+		}
+		log.Printf("%s, creating the PDF report locally", err)
+		return exportLocal()
+	default:
+		return errors.Errorf("invalid PDF report mode \"%s\", available options: %s", pdfMode, strings.Join(pdfModes, ","))
+	}
+}
+
+func exportServerPdfResults(pdfWrapper wrappers.ResultsPdfWrapper, summary *wrappers.ResultSummary, summaryRpt, formatPdfToEmail string,
+	pdfOptionsSections, pdfOptionsEngines []string) error {
+	pdfReportsPayload := &wrappers.PdfReportsPayload{}
+	pollingResp := &wrappers.PdfPollingResponse{}
 	pdfReportsPayload.ReportName = reportNameScanReport
 	pdfReportsPayload.ReportType = "cli"
 	pdfReportsPayload.FileFormat = printer.FormatPDF
@@ -1259,15 +1424,53 @@ func parsePDFOptions(pdfOptions string, enabledEngines []string) (pdfOptionsSect
 	return pdfOptionsSections, pdfOptionsEngines, nil
 }
 
-func convertCxResultsToSarif(results *wrappers.ScanResultsCollection) *wrappers.SarifResultsCollection {
+func convertCxResultsToSarif(results, baseResults *wrappers.ScanResultsCollection) *wrappers.SarifResultsCollection {
 	var sarif = new(wrappers.SarifResultsCollection)
 	sarif.Schema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
 	sarif.Version = "2.1.0"
-	sarif.Runs = []wrappers.SarifRun{}
-	sarif.Runs = append(sarif.Runs, createSarifRun(results))
+	sarif.Runs = createSarifRuns(results, baseResults)
 	return sarif
 }
 
+// createSarifRuns creates a run for each engine. With base results, each result gets a baselineState and the
+// base results missing from the scan are added as absent.
+func createSarifRuns(results, baseResults *wrappers.ScanResultsCollection) []wrappers.SarifRun {
+	engines, resultsByEngine := groupResultsByEngine(results)
+	baseEngines, baseResultsByEngine := groupResultsByEngine(baseResults)
+	for _, engine := range baseEngines {
+		if _, ok := resultsByEngine[engine]; !ok {
+			engines = append(engines, engine)
+		}
+	}
+	scanID := ""
+	if results != nil {
+		scanID = results.ScanID
+	}
+	runs := []wrappers.SarifRun{}
+	for _, engine := range engines {
+		runs = append(runs, createSarifRun(engine, scanID, resultsByEngine[engine], baseResultsByEngine[engine], baseResults != nil))
+	}
+	if len(runs) == 0 {
+		runs = append(runs, createSarifRun("", scanID, nil, nil, false))
+	}
+	return runs
+}
+
+func groupResultsByEngine(results *wrappers.ScanResultsCollection) (engines []string, resultsByEngine map[string][]*wrappers.ScanResult) {
+	resultsByEngine = make(map[string][]*wrappers.ScanResult)
+	if results == nil {
+		return engines, resultsByEngine
+	}
+	for _, result := range results.Results {
+		engine := strings.TrimSpace(result.Type)
+		if _, ok := resultsByEngine[engine]; !ok {
+			engines = append(engines, engine)
+		}
+		resultsByEngine[engine] = append(resultsByEngine[engine], result)
+	}
+	return engines, resultsByEngine
+}
+
 func convertCxResultToGlVulnerability(results *wrappers.ScanResultsCollection, glSast *wrappers.GlSastResultsCollection, summaryBaseURI string) {
 	for _, result := range results.Results {
 		if strings.TrimSpace(result.Type) == commonParams.SastType {
@@ -1337,27 +1540,57 @@ func convertCxResultsToSonar(results *wrappers.ScanResultsCollection) *wrappers.
 	return sonar
 }
 
-func createSarifRun(results *wrappers.ScanResultsCollection) wrappers.SarifRun {
+func createSarifRun(engine, scanID string, results, baseResults []*wrappers.ScanResult, hasBaseline bool) wrappers.SarifRun {
 	var sarifRun wrappers.SarifRun
 	sarifRun.Tool.Driver.Name = wrappers.SarifName
 	sarifRun.Tool.Driver.Version = wrappers.SarifVersion
 	sarifRun.Tool.Driver.InformationURI = wrappers.SarifInformationURI
-	sarifRun.Tool.Driver.Rules, sarifRun.Results = parseResults(results)
+	if engine != "" {
+		sarifRun.AutomationDetails = &wrappers.SarifAutomationDetails{
+			ID: fmt.Sprintf("%s/%s/%s", sarifAutomationCategory, engine, scanID),
+		}
+	}
+	sarifRun.Tool.Driver.Rules, sarifRun.Results = parseResults(results, baseResults, hasBaseline)
 	return sarifRun
 }
 
-func parseResults(results *wrappers.ScanResultsCollection) ([]wrappers.SarifDriverRule, []wrappers.SarifScanResult) {
+func parseResults(results, baseResults []*wrappers.ScanResult, hasBaseline bool) ([]wrappers.SarifDriverRule, []wrappers.SarifScanResult) {
 	var sarifRules = make([]wrappers.SarifDriverRule, 0)
 	var sarifResults = make([]wrappers.SarifScanResult, 0)
-	if results != nil {
-		ruleIds := map[interface{}]bool{}
-		for _, result := range results.Results {
+	ruleIds := map[interface{}]bool{}
+	baseFingerprints := map[string]bool{}
+	for _, result := range baseResults {
+		for _, sarifResult := range findResult(result) {
+			baseFingerprints[sarifResult.Fingerprints[sarifFingerprintKey]] = true
+		}
+	}
+	fingerprints := map[string]bool{}
+	for _, result := range results {
+		if rule := findRule(ruleIds, result); rule != nil {
+			sarifRules = append(sarifRules, *rule)
+		}
+		for _, sarifResult := range findResult(result) {
+			fingerprint := sarifResult.Fingerprints[sarifFingerprintKey]
+			fingerprints[fingerprint] = true
+			if hasBaseline {
+				sarifResult.BaselineState = sarifBaselineNew
+				if baseFingerprints[fingerprint] {
+					sarifResult.BaselineState = sarifBaselineUnchanged
+				}
+			}
+			sarifResults = append(sarifResults, sarifResult)
+		}
+	}
+	for _, result := range baseResults {
+		for _, sarifResult := range findResult(result) {
+			if fingerprints[sarifResult.Fingerprints[sarifFingerprintKey]] {
+				continue
+			}
 			if rule := findRule(ruleIds, result); rule != nil {
 				sarifRules = append(sarifRules, *rule)
 			}
-			if sarifResult := findResult(result); sarifResult != nil {
-				sarifResults = append(sarifResults, sarifResult...)
-			}
+			sarifResult.BaselineState = sarifBaselineAbsent
+			sarifResults = append(sarifResults, sarifResult)
 		}
 	}
 	return sarifRules, sarifResults
@@ -1368,26 +1601,91 @@ func parseResultsSonar(results *wrappers.ScanResultsCollection) []wrappers.Sonar
 
 	if results != nil {
 		for _, result := range results.Results {
-			var auxIssue = initSonarIssue(result)
-
-			engineType := strings.TrimSpace(result.Type)
-
-			if engineType == commonParams.SastType {
-				auxIssue.PrimaryLocation = parseSonarPrimaryLocation(result)
-				auxIssue.SecondaryLocations = parseSonarSecondaryLocations(result)
-				sonarIssues = append(sonarIssues, auxIssue)
-			} else if engineType == commonParams.KicsType {
-				auxIssue.PrimaryLocation = parseLocationKics(result)
-				sonarIssues = append(sonarIssues, auxIssue)
-			} else if engineType == commonParams.ScaType {
-				sonarIssuesByLocation := parseScaSonarLocations(result)
-				sonarIssues = append(sonarIssues, sonarIssuesByLocation...)
-			}
+			sonarIssues = append(sonarIssues, parseResultSonar(result)...)
 		}
 	}
 	return sonarIssues
 }
 
+func parseResultSonar(result *wrappers.ScanResult) []wrappers.SonarIssues {
+	var auxIssue = initSonarIssue(result)
+
+	engineType := strings.TrimSpace(result.Type)
+
+	if engineType == commonParams.SastType {
+		auxIssue.PrimaryLocation = parseSonarPrimaryLocation(result)
+		auxIssue.SecondaryLocations = parseSonarSecondaryLocations(result)
+		return []wrappers.SonarIssues{auxIssue}
+	} else if engineType == commonParams.KicsType {
+		auxIssue.PrimaryLocation = parseLocationKics(result)
+		return []wrappers.SonarIssues{auxIssue}
+	} else if engineType == commonParams.ScaType {
+		return parseScaSonarLocations(result)
+	}
+	return nil
+}
+
+// convertCxResultsToSonarV10 groups the issues by rule, the issues of SAST and KICS results refer to their query and
+// the issues of SCA results to their vulnerability
+func convertCxResultsToSonarV10(results *wrappers.ScanResultsCollection) *wrappers.ScanResultsSonarV10 {
+	var sonar = &wrappers.ScanResultsSonarV10{
+		Rules:  []wrappers.SonarRule{},
+		Issues: []wrappers.SonarIssues{},
+	}
+	if results == nil {
+		return sonar
+	}
+	ruleIndexes := map[string]int{}
+	for _, result := range results.Results {
+		issues := parseResultSonar(result)
+		if len(issues) == 0 {
+			continue
+		}
+		rule := initSonarRule(result)
+		key := rule.EngineID + "/" + rule.ID
+		if index, ok := ruleIndexes[key]; !ok {
+			ruleIndexes[key] = len(sonar.Rules)
+			sonar.Rules = append(sonar.Rules, rule)
+		} else if sonarImpactRank[rule.Impacts[0].Severity] > sonarImpactRank[sonar.Rules[index].Impacts[0].Severity] {
+			// triaged results of a query can have different severities, the rule keeps the highest
+			sonar.Rules[index].Impacts = rule.Impacts
+		}
+		for i := range issues {
+			issues[i].RuleID = rule.ID
+			issues[i].EngineID = ""
+			issues[i].Severity = ""
+			issues[i].Type = ""
+			sonar.Issues = append(sonar.Issues, issues[i])
+		}
+	}
+	return sonar
+}
+
+func initSonarRule(result *wrappers.ScanResult) wrappers.SonarRule {
+	_, name, _ := findRuleID(result)
+	rule := wrappers.SonarRule{
+		ID:                 result.ID,
+		Name:               name,
+		Description:        result.Description,
+		EngineID:           result.Type,
+		CleanCodeAttribute: sonarCleanCodeAttributes[result.Type],
+		Impacts: []wrappers.SonarImpact{{
+			SoftwareQuality: sonarSecurityQuality,
+			Severity:        sonarImpactSeverities[strings.ToUpper(result.Severity)],
+		}},
+	}
+	if result.ScanResultData.QueryID != nil {
+		rule.ID = fmt.Sprint(result.ScanResultData.QueryID)
+	}
+	if rule.CleanCodeAttribute == "" {
+		rule.CleanCodeAttribute = sonarTrustworthyAttribute
+	}
+	if rule.Impacts[0].Severity == "" {
+		rule.Impacts[0].Severity = sonarImpactLow
+	}
+	return rule
+}
+
 func initSonarIssue(result *wrappers.ScanResult) wrappers.SonarIssues {
 	var sonarIssue wrappers.SonarIssues
 	sonarIssue.Severity = sonarSeverities[result.Severity]
@@ -1555,12 +1853,16 @@ func findProperties(result *wrappers.ScanResult) wrappers.SarifProperties {
 
 func findSarifLevel(result *wrappers.ScanResult) string {
 	level := map[string]string{
-		infoCx:   infoLowSarif,
-		lowCx:    infoLowSarif,
-		mediumCx: mediumSarif,
-		highCx:   highSarif,
+		infoCx:     infoLowSarif,
+		lowCx:      infoLowSarif,
+		mediumCx:   mediumSarif,
+		highCx:     highSarif,
+		criticalCx: highSarif,
 	}
-	return level[result.Severity]
+	if sarifLevel, ok := level[strings.ToUpper(result.Severity)]; ok {
+		return sarifLevel
+	}
+	return noneSarif
 }
 
 func initSarifResult(result *wrappers.ScanResult) wrappers.SarifScanResult {
@@ -1568,6 +1870,7 @@ func initSarifResult(result *wrappers.ScanResult) wrappers.SarifScanResult {
 	scanResult.RuleID, _, scanResult.Message.Text = findRuleID(result)
 	scanResult.Level = findSarifLevel(result)
 	scanResult.Locations = []wrappers.SarifLocation{}
+	scanResult.Properties = &wrappers.SarifResultProperties{SimilarityID: findSarifSimilarityID(result), State: result.State}
 
 	return scanResult
 }
@@ -1583,12 +1886,35 @@ func findResult(result *wrappers.ScanResult) []wrappers.SarifScanResult {
 		scanResults = parseSarifResultsSca(result, scanResults)
 	}
 
+	for i := range scanResults {
+		addSarifFingerprints(result, &scanResults[i])
+	}
 	if len(scanResults) > 0 {
 		return scanResults
 	}
 	return nil
 }
 
+func findSarifSimilarityID(result *wrappers.ScanResult) string {
+	if result.SimilarityID == "" {
+		return result.ID
+	}
+	return result.SimilarityID
+}
+
+// addSarifFingerprints adds a fingerprint that is stable across scans, based on the similarity ID of the result.
+// SCA results are reported once per package file, so the file is part of their fingerprint. It is not a
+// primaryLocationLineHash, which consumers such as GitHub code scanning compute from the source lines themselves.
+func addSarifFingerprints(result *wrappers.ScanResult, sarifResult *wrappers.SarifScanResult) {
+	parts := []string{strings.TrimSpace(result.Type), sarifResult.RuleID, findSarifSimilarityID(result)}
+	if result.Type == commonParams.ScaType && len(sarifResult.Locations) > 0 {
+		parts = append(parts, sarifResult.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	}
+	hash := sha256.Sum256([]byte(strings.Join(parts, "/")))
+	fingerprint := hex.EncodeToString(hash[:])
+	sarifResult.Fingerprints = map[string]string{sarifFingerprintKey: fingerprint}
+}
+
 func parseSarifResultsSca(result *wrappers.ScanResult, scanResults []wrappers.SarifScanResult) []wrappers.SarifScanResult {
 	if result == nil || result.ScanResultData.ScaPackageCollection == nil || result.ScanResultData.ScaPackageCollection.Locations == nil {
 		return scanResults
@@ -1619,10 +1945,13 @@ func parseSarifResultKics(result *wrappers.ScanResult, scanResults []wrappers.Sa
 		"",
 		1,
 	)
-	scanLocation.PhysicalLocation.Region = &wrappers.SarifRegion{}
-	scanLocation.PhysicalLocation.Region.StartLine = result.ScanResultData.Line
-	scanLocation.PhysicalLocation.Region.StartColumn = 1
-	scanLocation.PhysicalLocation.Region.EndColumn = 2
+	// A region needs a start line, without one the location is the whole file
+	if result.ScanResultData.Line > 0 {
+		scanLocation.PhysicalLocation.Region = &wrappers.SarifRegion{}
+		scanLocation.PhysicalLocation.Region.StartLine = result.ScanResultData.Line
+		scanLocation.PhysicalLocation.Region.StartColumn = 1
+		scanLocation.PhysicalLocation.Region.EndColumn = 2
+	}
 	scanResult.Locations = append(scanResult.Locations, scanLocation)
 
 	scanResults = append(scanResults, scanResult)
@@ -1634,6 +1963,7 @@ func parseSarifResultSast(result *wrappers.ScanResult, scanResults []wrappers.Sa
 		return scanResults
 	}
 	var scanResult = initSarifResult(result)
+	var threadFlow = wrappers.SarifThreadFlow{Locations: []wrappers.SarifThreadFlowLocation{}}
 
 	for _, node := range result.ScanResultData.Nodes {
 		var scanLocation wrappers.SarifLocation
@@ -1649,9 +1979,19 @@ func parseSarifResultSast(result *wrappers.ScanResult, scanResults []wrappers.Sa
 			scanLocation.PhysicalLocation.Region.StartColumn = column
 			scanLocation.PhysicalLocation.Region.EndColumn = column + length
 
-			scanResult.Locations = append(scanResult.Locations, scanLocation)
+			// The first node is the primary location, the whole data flow goes to the code flow
+			if len(scanResult.Locations) == 0 {
+				scanResult.Locations = append(scanResult.Locations, scanLocation)
+			}
+			if node.Name != "" {
+				scanLocation.Message = &wrappers.SarifMessage{Text: node.Name}
+			}
+			threadFlow.Locations = append(threadFlow.Locations, wrappers.SarifThreadFlowLocation{Location: scanLocation})
 		}
 	}
+	if len(threadFlow.Locations) > 0 {
+		scanResult.CodeFlows = []wrappers.SarifCodeFlow{{ThreadFlows: []wrappers.SarifThreadFlow{threadFlow}}}
+	}
 
 	scanResults = append(scanResults, scanResult)
 	return scanResults
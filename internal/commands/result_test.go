@@ -3,13 +3,16 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
 	"github.com/checkmarx/ast-cli/internal/params"
 	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"gotest.tools/assert"
 )
 
@@ -55,6 +58,66 @@ func TestRunGetResultsByScanIdSonarFormat(t *testing.T) {
 	os.Remove(fmt.Sprintf("%s.%s", fileName, printer.FormatSonar))
 }
 
+func TestRunGetResultsByScanIdSonarV10Format(t *testing.T) {
+	execCmdNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "sonar-v10")
+	sonarFile := fmt.Sprintf("%s%s.%s", fileName, sonarV10TypeLabel, printer.FormatJSON)
+	defer os.Remove(sonarFile)
+	sonarJSON, err := os.ReadFile(sonarFile)
+	assert.NilError(t, err)
+	var sonar wrappers.ScanResultsSonarV10
+	assert.NilError(t, json.Unmarshal(sonarJSON, &sonar))
+	assert.Assert(t, len(sonar.Rules) > 0)
+	assert.Assert(t, !strings.Contains(string(sonarJSON), `"severity":"HIGH","type"`))
+}
+
+func TestConvertCxResultsToSonarV10(t *testing.T) {
+	results := importTestResults()
+	sastResult := *results.Results[0]
+	sastResult.ID = "sast-id-2"
+	sastResult.Severity = criticalCx
+	results.Results = append(results.Results, &sastResult)
+	results.Results[0].Severity = mediumCx
+
+	sonar := convertCxResultsToSonarV10(results)
+	assert.DeepEqual(t, sonar.Rules, []wrappers.SonarRule{
+		{
+			ID:                 "5157925289005576664",
+			Name:               "SQL Injection",
+			Description:        "SQL injection",
+			EngineID:           params.SastType,
+			CleanCodeAttribute: "COMPLETE",
+			Impacts:            []wrappers.SonarImpact{{SoftwareQuality: "SECURITY", Severity: "HIGH"}},
+		},
+		{
+			ID:                 "CVE-2021-23337",
+			Name:               "Cve202123337",
+			Description:        "Command injection in lodash",
+			EngineID:           params.ScaType,
+			CleanCodeAttribute: "TRUSTWORTHY",
+			Impacts:            []wrappers.SonarImpact{{SoftwareQuality: "SECURITY", Severity: "MEDIUM"}},
+		},
+		{
+			ID:                 "f6cc7a1b",
+			Name:               "S3 Bucket Public",
+			Description:        "Bucket is public",
+			EngineID:           params.KicsType,
+			CleanCodeAttribute: "CONVENTIONAL",
+			Impacts:            []wrappers.SonarImpact{{SoftwareQuality: "SECURITY", Severity: "LOW"}},
+		},
+	})
+	// the SCA result has an issue per location
+	assert.Equal(t, len(sonar.Issues), 5)
+	assert.Equal(t, sonar.Issues[0].RuleID, "5157925289005576664")
+	assert.Equal(t, sonar.Issues[4].RuleID, "5157925289005576664")
+	assert.Equal(t, sonar.Issues[1].PrimaryLocation.FilePath, "package.json")
+	assert.Equal(t, sonar.Issues[2].PrimaryLocation.FilePath, "package-lock.json")
+	for _, issue := range sonar.Issues {
+		assert.Equal(t, issue.Severity, "")
+		assert.Equal(t, issue.Type, "")
+		assert.Equal(t, issue.EngineID, "")
+	}
+}
+
 func TestRunGetResultsByScanIdJsonFormat(t *testing.T) {
 	execCmdNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "json")
 
@@ -332,3 +395,154 @@ func TestRunGetResultsByScanIdGLFormat(t *testing.T) {
 	// Run test for gl-sast report type
 	os.Remove(fmt.Sprintf("%s.%s", fileName, printer.FormatGL))
 }
+
+func TestRunGetScaPathsByScanIdAndPackage(t *testing.T) {
+	execCmdNilAssertion(t, "results", "sca-paths", "--scan-id", "MOCK", "--package", "mock")
+	execCmdNilAssertion(t, "results", "sca-paths", "--scan-id", "MOCK", "--package", "mock", "--format", "json")
+	execCmdNilAssertion(t, "results", "sca-paths", "--scan-id", "MOCK", "--package", "mock", "--format", "dot")
+}
+
+func TestRunGetScaPathsWithMissingFlags(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "sca-paths", "--package", "mock")
+	assert.Equal(t, err.Error(), "Failed getting SCA dependency paths: Please provide a scan ID")
+
+	err = execCmdNotNilAssertion(t, "results", "sca-paths", "--scan-id", "MOCK")
+	assert.Equal(t, err.Error(), "Failed getting SCA dependency paths: Please provide a package")
+}
+
+func TestRunGetScaPathsWithUnknownPackage(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "sca-paths", "--scan-id", "MOCK", "--package", "unknown")
+	assert.Equal(t, err.Error(), "Failed getting SCA dependency paths: package unknown was not found in the SCA results of scan MOCK")
+}
+
+func TestRunGetScaPathsWithWrongFormat(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "sca-paths", "--scan-id", "MOCK", "--package", "mock", "--format", "list")
+	assert.Equal(t, err.Error(), "Failed getting SCA dependency paths: invalid format list, use one of tree, json, dot")
+}
+
+func TestScaPathsTreeAndDot(t *testing.T) {
+	lodash := wrappers.DependencyPath{ID: "Npm-lodash-4.17.15", Name: "lodash", Version: "4.17.15"}
+	express := wrappers.DependencyPath{ID: "Npm-express-4.17.1", Name: "express", Version: "4.17.1"}
+	bodyParser := wrappers.DependencyPath{ID: "Npm-body-parser-1.19.0", Name: "body-parser", Version: "1.19.0"}
+	mocha := wrappers.DependencyPath{ID: "Npm-mocha-8.0.0", Name: "mocha", Version: "8.0.0", IsDevelopment: true}
+	location := "package.json"
+	results := &wrappers.ScanResultsCollection{Results: []*wrappers.ScanResult{{
+		Type:     params.ScaType,
+		ID:       "1",
+		Severity: "HIGH",
+		State:    "TO_VERIFY",
+		ScanResultData: wrappers.ScanResultData{
+			PackageIdentifier: lodash.ID,
+			Nodes:             []*wrappers.ScanResultNode{{FileName: "src/app.js", Line: 12, Method: "merge"}},
+			ScaPackageCollection: &wrappers.ScaPackageCollection{
+				ID:               lodash.ID,
+				Locations:        []*string{&location},
+				TypeOfDependency: indirectDependencyType,
+				DependencyPathArray: [][]wrappers.DependencyPath{
+					{express, bodyParser, lodash},
+					{express, lodash},
+					{mocha, lodash},
+				},
+			},
+		},
+		VulnerabilityDetails: wrappers.VulnerabilityDetails{CveName: "CVE-2021-23337"},
+	}}}
+
+	views := toScaPackagePathsViews(results, "lodash", true)
+	assert.Equal(t, len(views), 1)
+	assert.Equal(t, views[0].Scope, scaRuntimeScope)
+	assert.Equal(t, views[0].DependencyPaths[2].Scope, scaDevelopmentScope)
+
+	tree := &strings.Builder{}
+	assert.NilError(t, printScaPaths(tree, views, printer.FormatTree))
+	assert.Assert(t, strings.Contains(tree.String(), `Dependency paths:
+|-- express@4.17.1
+|   |-- body-parser@1.19.0
+|   |   `+"`"+`-- lodash@4.17.15 (vulnerable)
+|   `+"`"+`-- lodash@4.17.15 (vulnerable)
+`+"`"+`-- mocha@8.0.0 [development]
+    `+"`"+`-- lodash@4.17.15 (vulnerable)
+`), tree.String())
+	assert.Assert(t, strings.Contains(tree.String(), "CVE-2021-23337:\n        src/app.js:12 merge"), tree.String())
+
+	dot := &strings.Builder{}
+	assert.NilError(t, printScaPaths(dot, views, printer.FormatDot))
+	assert.Assert(t, strings.Contains(dot.String(), `"Npm-express-4.17.1" -> "Npm-lodash-4.17.15";`), dot.String())
+	assert.Assert(t, strings.Contains(dot.String(), `"Npm-mocha-8.0.0" [label="mocha@8.0.0", style=dashed];`), dot.String())
+	assert.Assert(t, strings.Contains(dot.String(), `-> "Npm-lodash-4.17.15" [style=dotted, label="CVE-2021-23337"];`), dot.String())
+
+	views = toScaPackagePathsViews(results, lodash.ID, false)
+	assert.Equal(t, len(views[0].Vulnerabilities[0].ExploitablePath), 0)
+}
+
+func validateSarifSchema(t *testing.T, sarifJSON []byte) {
+	schemaFile, err := os.Open("data/sarif-schema-2.1.0.json")
+	assert.NilError(t, err)
+	defer schemaFile.Close()
+	compiler := jsonschema.NewCompiler()
+	assert.NilError(t, compiler.AddResource("sarif-schema-2.1.0.json", schemaFile))
+	schema, err := compiler.Compile("sarif-schema-2.1.0.json")
+	assert.NilError(t, err)
+	var document interface{}
+	assert.NilError(t, json.Unmarshal(sarifJSON, &document))
+	assert.NilError(t, schema.Validate(document))
+}
+
+func TestRunGetResultsByScanIdSarifFormatWithBaseScan(t *testing.T) {
+	execCmdNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "sarif", "--base-scan-id", "MOCK-BASE")
+	sarifFile := fmt.Sprintf("%s.%s", fileName, printer.FormatSarif)
+	defer os.Remove(sarifFile)
+	sarifJSON, err := os.ReadFile(sarifFile)
+	assert.NilError(t, err)
+	validateSarifSchema(t, sarifJSON)
+
+	var sarif wrappers.SarifResultsCollection
+	assert.NilError(t, json.Unmarshal(sarifJSON, &sarif))
+	assert.Equal(t, len(sarif.Runs), 3)
+	assert.Equal(t, sarif.Runs[0].AutomationDetails.ID, "checkmarx-one/sast/MOCK")
+	assert.Equal(t, sarif.Runs[1].AutomationDetails.ID, "checkmarx-one/sca/MOCK")
+	assert.Equal(t, sarif.Runs[2].AutomationDetails.ID, "checkmarx-one/kics/MOCK")
+	sastResult := sarif.Runs[0].Results[0]
+	assert.Equal(t, len(sastResult.Locations), 1)
+	assert.Equal(t, len(sastResult.CodeFlows[0].ThreadFlows[0].Locations), 2)
+	assert.Equal(t, sastResult.BaselineState, "unchanged")
+	assert.Assert(t, sastResult.Fingerprints["similarityHash/v1"] != "")
+	assert.Assert(t, sastResult.PartialFingerprints == nil, "the similarity hash is not a primaryLocationLineHash")
+}
+
+func TestConvertCxResultsToSarifBaselineState(t *testing.T) {
+	newResult := func(similarityID string) *wrappers.ScanResult {
+		return &wrappers.ScanResult{
+			Type:         params.SastType,
+			Severity:     "HIGH",
+			SimilarityID: similarityID,
+			ScanResultData: wrappers.ScanResultData{
+				QueryID:   1,
+				QueryName: "SQL_Injection",
+				Nodes: []*wrappers.ScanResultNode{
+					{FileName: "/src/app.js", Line: 3, Column: 1, Length: 5, Name: "input"},
+					{FileName: "/src/db.js", Line: 9, Column: 2, Length: 4, Name: "query"},
+				},
+			},
+		}
+	}
+	results := &wrappers.ScanResultsCollection{ScanID: "scan", Results: []*wrappers.ScanResult{newResult("1"), newResult("2")}}
+	baseResults := &wrappers.ScanResultsCollection{ScanID: "base", Results: []*wrappers.ScanResult{newResult("2"), newResult("3")}}
+
+	sarif := convertCxResultsToSarif(results, baseResults)
+	sarifJSON, err := json.Marshal(sarif)
+	assert.NilError(t, err)
+	validateSarifSchema(t, sarifJSON)
+
+	assert.Equal(t, len(sarif.Runs), 1)
+	states := []string{}
+	for _, result := range sarif.Runs[0].Results {
+		states = append(states, result.BaselineState)
+	}
+	assert.DeepEqual(t, states, []string{"new", "unchanged", "absent"})
+	assert.Equal(t, sarif.Runs[0].Results[0].CodeFlows[0].ThreadFlows[0].Locations[1].Location.Message.Text, "query")
+
+	sarif = convertCxResultsToSarif(results, nil)
+	assert.Equal(t, sarif.Runs[0].Results[0].BaselineState, "")
+	assert.Equal(t, len(sarif.Runs[0].Results), 2)
+}
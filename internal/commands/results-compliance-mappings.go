@@ -0,0 +1,115 @@
+package commands
+
+import commonParams "github.com/checkmarx/ast-cli/internal/params"
+
+const (
+	owaspTop10Framework = "OWASP Top 10 2021"
+	owaspASVSFramework  = "OWASP ASVS 4.0"
+	pciDSSFramework     = "PCI DSS 4.0"
+	cweTop25Framework   = "CWE Top 25 2023"
+)
+
+// defaultComplianceMapping maps the CWE of the findings, and the engines for the findings without one, to the
+// categories of each framework. --compliance-mapping extends it with the same structure.
+var defaultComplianceMapping = complianceMapping{
+	Frameworks: []complianceMappingFramework{
+		{
+			Name: owaspTop10Framework,
+			Categories: []complianceMappingCategory{
+				{Name: "A01:2021-Broken Access Control", CWEs: complianceCWEs(22, 23, 35, 59, 200, 201, 219, 264, 275, 276, 284, 285,
+					352, 359, 377, 402, 425, 441, 497, 538, 540, 548, 552, 566, 601, 639, 651, 668, 706, 862, 863, 913, 922, 1275)},
+				{Name: "A02:2021-Cryptographic Failures", CWEs: complianceCWEs(259, 261, 296, 310, 319, 321, 322, 323, 324, 325, 326,
+					327, 328, 329, 330, 331, 335, 336, 337, 338, 340, 347, 523, 720, 757, 759, 760, 780, 818, 916)},
+				{Name: "A03:2021-Injection", CWEs: complianceCWEs(20, 74, 75, 77, 78, 79, 80, 83, 87, 88, 89, 90, 91, 93, 94, 95, 96,
+					97, 98, 99, 113, 116, 138, 184, 470, 471, 564, 610, 643, 644, 652, 917)},
+				{Name: "A04:2021-Insecure Design", CWEs: complianceCWEs(73, 183, 209, 213, 235, 256, 257, 266, 269, 280, 311, 312, 313,
+					316, 419, 430, 434, 444, 451, 472, 501, 522, 525, 539, 579, 598, 602, 642, 646, 650, 653, 656, 657, 799, 807,
+					840, 841, 927, 1021, 1173)},
+				{Name: "A05:2021-Security Misconfiguration", CWEs: complianceCWEs(2, 11, 13, 15, 16, 260, 315, 520, 526, 537, 541, 547,
+					611, 614, 756, 776, 942, 1004, 1032, 1174), Engines: []string{commonParams.KicsType}},
+				{Name: "A06:2021-Vulnerable and Outdated Components", CWEs: complianceCWEs(937, 1035, 1104),
+					Engines: []string{commonParams.ScaType}},
+				{Name: "A07:2021-Identification and Authentication Failures", CWEs: complianceCWEs(255, 259, 287, 288, 290, 294, 295,
+					297, 300, 302, 304, 306, 307, 346, 384, 521, 613, 620, 640, 798, 940, 1216)},
+				{Name: "A08:2021-Software and Data Integrity Failures", CWEs: complianceCWEs(345, 353, 426, 494, 502, 565, 784, 829,
+					830, 915, 1321)},
+				{Name: "A09:2021-Security Logging and Monitoring Failures", CWEs: complianceCWEs(117, 223, 532, 778)},
+				{Name: "A10:2021-Server-Side Request Forgery", CWEs: complianceCWEs(918)},
+			},
+		},
+		{
+			Name: owaspASVSFramework,
+			Categories: []complianceMappingCategory{
+				{Name: "V2 Authentication", CWEs: complianceCWEs(259, 287, 304, 306, 307, 521, 620, 640, 798)},
+				{Name: "V3 Session Management", CWEs: complianceCWEs(384, 539, 613, 614, 1004)},
+				{Name: "V4 Access Control", CWEs: complianceCWEs(276, 284, 285, 352, 548, 639, 732, 862, 863)},
+				{Name: "V5 Validation, Sanitization and Encoding", CWEs: complianceCWEs(20, 74, 77, 78, 79, 89, 90, 91, 94, 95, 113,
+					116, 119, 120, 125, 134, 190, 470, 502, 601, 611, 643, 776, 787, 915, 918, 1321)},
+				{Name: "V6 Stored Cryptography", CWEs: complianceCWEs(310, 311, 312, 321, 326, 327, 328, 330, 338, 759, 760, 916)},
+				{Name: "V7 Error Handling and Logging", CWEs: complianceCWEs(117, 209, 223, 532, 778)},
+				{Name: "V8 Data Protection", CWEs: complianceCWEs(200, 212, 359, 524, 525, 538, 598, 922)},
+				{Name: "V9 Communication", CWEs: complianceCWEs(295, 297, 319, 523, 757)},
+				{Name: "V10 Malicious Code", CWEs: complianceCWEs(494, 506, 507, 511, 829)},
+				{Name: "V11 Business Logic", CWEs: complianceCWEs(362, 367, 799, 841)},
+				{Name: "V12 Files and Resources", CWEs: complianceCWEs(22, 73, 98, 434, 552)},
+				{Name: "V14 Configuration", CWEs: complianceCWEs(16, 346, 937, 942, 1021, 1035, 1104),
+					Engines: []string{commonParams.ScaType, commonParams.KicsType}},
+			},
+		},
+		{
+			Name: pciDSSFramework,
+			Categories: []complianceMappingCategory{
+				{Name: "2.2 System components are configured and managed securely", Engines: []string{commonParams.KicsType}},
+				{Name: "4.2 Strong cryptography protects data during transmission", CWEs: complianceCWEs(295, 297, 319, 523, 757)},
+				{Name: "6.2.4 Injection attacks", CWEs: complianceCWEs(74, 77, 78, 79, 89, 90, 91, 94, 95, 611, 643, 917)},
+				{Name: "6.2.4 Attacks on data and data structures", CWEs: complianceCWEs(20, 119, 120, 125, 190, 416, 476, 502, 787,
+					1321)},
+				{Name: "6.2.4 Attacks on cryptography usage", CWEs: complianceCWEs(310, 321, 326, 327, 328, 330, 338, 759, 760, 916)},
+				{Name: "6.2.4 Attacks on business logic", CWEs: complianceCWEs(352, 362, 434, 601, 840, 841, 918)},
+				{Name: "6.2.4 Attacks on access control mechanisms", CWEs: complianceCWEs(22, 269, 276, 284, 285, 639, 862, 863)},
+				{Name: "6.3.3 Known vulnerabilities in system components", CWEs: complianceCWEs(937, 1035, 1104),
+					Engines: []string{commonParams.ScaType}},
+				{Name: "8.3 Strong authentication", CWEs: complianceCWEs(259, 287, 306, 307, 521, 522, 798)},
+				{Name: "10.2 Audit logs", CWEs: complianceCWEs(117, 223, 532, 778)},
+			},
+		},
+		{
+			Name: cweTop25Framework,
+			Categories: []complianceMappingCategory{
+				{Name: "#1 CWE-787 Out-of-bounds Write", CWEs: complianceCWEs(787)},
+				{Name: "#2 CWE-79 Cross-site Scripting", CWEs: complianceCWEs(79)},
+				{Name: "#3 CWE-89 SQL Injection", CWEs: complianceCWEs(89)},
+				{Name: "#4 CWE-416 Use After Free", CWEs: complianceCWEs(416)},
+				{Name: "#5 CWE-78 OS Command Injection", CWEs: complianceCWEs(78)},
+				{Name: "#6 CWE-20 Improper Input Validation", CWEs: complianceCWEs(20)},
+				{Name: "#7 CWE-125 Out-of-bounds Read", CWEs: complianceCWEs(125)},
+				{Name: "#8 CWE-22 Path Traversal", CWEs: complianceCWEs(22)},
+				{Name: "#9 CWE-352 Cross-Site Request Forgery", CWEs: complianceCWEs(352)},
+				{Name: "#10 CWE-434 Unrestricted Upload of File with Dangerous Type", CWEs: complianceCWEs(434)},
+				{Name: "#11 CWE-862 Missing Authorization", CWEs: complianceCWEs(862)},
+				{Name: "#12 CWE-476 NULL Pointer Dereference", CWEs: complianceCWEs(476)},
+				{Name: "#13 CWE-287 Improper Authentication", CWEs: complianceCWEs(287)},
+				{Name: "#14 CWE-190 Integer Overflow or Wraparound", CWEs: complianceCWEs(190)},
+				{Name: "#15 CWE-502 Deserialization of Untrusted Data", CWEs: complianceCWEs(502)},
+				{Name: "#16 CWE-77 Command Injection", CWEs: complianceCWEs(77)},
+				{Name: "#17 CWE-119 Improper Restriction of Operations within the Bounds of a Memory Buffer", CWEs: complianceCWEs(119)},
+				{Name: "#18 CWE-798 Use of Hard-coded Credentials", CWEs: complianceCWEs(798)},
+				{Name: "#19 CWE-918 Server-Side Request Forgery", CWEs: complianceCWEs(918)},
+				{Name: "#20 CWE-306 Missing Authentication for Critical Function", CWEs: complianceCWEs(306)},
+				{Name: "#21 CWE-362 Race Condition", CWEs: complianceCWEs(362)},
+				{Name: "#22 CWE-269 Improper Privilege Management", CWEs: complianceCWEs(269)},
+				{Name: "#23 CWE-94 Code Injection", CWEs: complianceCWEs(94)},
+				{Name: "#24 CWE-863 Incorrect Authorization", CWEs: complianceCWEs(863)},
+				{Name: "#25 CWE-276 Incorrect Default Permissions", CWEs: complianceCWEs(276)},
+			},
+		},
+	},
+}
+
+func complianceCWEs(ids ...int) []string {
+	cwes := make([]string, len(ids))
+	for i, id := range ids {
+		cwes[i] = complianceCWE(id)
+	}
+	return cwes
+}
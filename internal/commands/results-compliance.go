@@ -0,0 +1,360 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	htmlTemplate "html/template"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	failedGettingCompliance     = "Failed getting the compliance report"
+	complianceInvalidFormat     = "%s: invalid format %s, use one of %s"
+	complianceInvalidMapping    = "%s: invalid compliance mapping %s"
+	complianceCWEFramework      = "CWE"
+	complianceReportedFramework = "Checkmarx One compliance"
+	complianceCWEPrefix         = "CWE-"
+)
+
+var complianceFormats = []string{printer.FormatJSON, printer.FormatSummaryMarkdown, printer.FormatHTML}
+
+// complianceMapping is the table of frameworks and categories the findings are grouped by
+type complianceMapping struct {
+	Frameworks []complianceMappingFramework `json:"frameworks"`
+}
+
+type complianceMappingFramework struct {
+	Name       string                      `json:"name"`
+	Categories []complianceMappingCategory `json:"categories"`
+}
+
+// complianceMappingCategory holds the findings with one of its CWEs, or of one of its engines
+type complianceMappingCategory struct {
+	Name    string   `json:"name"`
+	CWEs    []string `json:"cwes,omitempty"`
+	Engines []string `json:"engines,omitempty"`
+}
+
+type complianceReport struct {
+	ScanID      string                `json:"scanId"`
+	ProjectName string                `json:"projectName,omitempty"`
+	BranchName  string                `json:"branchName,omitempty"`
+	CreatedAt   string                `json:"createdAt,omitempty"`
+	Severities  []string              `json:"severities"`
+	Frameworks  []complianceFramework `json:"frameworks"`
+	Findings    []complianceFinding   `json:"findings"`
+}
+
+type complianceFramework struct {
+	Name       string               `json:"name"`
+	Total      int                  `json:"total"`
+	Categories []complianceCategory `json:"categories"`
+}
+
+// complianceCategory counts its findings by severity and state, Findings are the numbers of the findings of the report
+type complianceCategory struct {
+	Name       string         `json:"name"`
+	Total      int            `json:"total"`
+	Severities map[string]int `json:"severities"`
+	States     map[string]int `json:"states"`
+	Findings   []int          `json:"findings"`
+}
+
+type complianceFinding struct {
+	Number      int      `json:"number"`
+	ID          string   `json:"id"`
+	Engine      string   `json:"engine"`
+	Severity    string   `json:"severity"`
+	State       string   `json:"state"`
+	Title       string   `json:"title"`
+	CWE         string   `json:"cwe,omitempty"`
+	Compliances []string `json:"compliances,omitempty"`
+	Locations   []string `json:"locations,omitempty"`
+}
+
+func resultComplianceSubCommand(resultsWrapper wrappers.ResultsWrapper, scanWrapper wrappers.ScansWrapper) *cobra.Command {
+	complianceCmd := &cobra.Command{
+		Use:   "compliance",
+		Short: "Group the results of a scan by compliance framework",
+		Long: "The compliance command groups the results of a scan by OWASP Top 10 2021, OWASP ASVS, PCI DSS 4.0, CWE Top 25, " +
+			"the CWE of the results and the compliances reported by Checkmarx One, with the count of results per severity and state.",
+		Example: heredoc.Doc(
+			`
+			$ cx results compliance --scan-id <scan Id>
+			$ cx results compliance --scan-id <scan Id> --format html --compliance-mapping mapping.json > compliance.html
+		`,
+		),
+		RunE: runGetComplianceCommand(resultsWrapper, scanWrapper),
+	}
+	addScanIDFlag(complianceCmd, "ID to report on.")
+	complianceCmd.PersistentFlags().String(commonParams.ComplianceMappingFlag, "",
+		"JSON file with frameworks and categories added to the built-in compliance mapping")
+	complianceCmd.PersistentFlags().StringSlice(commonParams.FilterFlag, []string{}, filterResultsListFlagUsage)
+	addFormatFlag(complianceCmd, printer.FormatSummaryMarkdown, printer.FormatJSON, printer.FormatHTML)
+	return complianceCmd
+}
+
+func runGetComplianceCommand(resultsWrapper wrappers.ResultsWrapper, scanWrapper wrappers.ScansWrapper) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		scanID, _ := cmd.Flags().GetString(commonParams.ScanIDFlag)
+		if scanID == "" {
+			return errors.Errorf("%s: Please provide a scan ID", failedGettingCompliance)
+		}
+		format, _ := cmd.Flags().GetString(commonParams.FormatFlag)
+		if !containsFold(complianceFormats, format) {
+			return errors.Errorf(complianceInvalidFormat, failedGettingCompliance, format, strings.Join(complianceFormats, ", "))
+		}
+		mappingFile, _ := cmd.Flags().GetString(commonParams.ComplianceMappingFlag)
+		mapping, err := loadComplianceMapping(mappingFile)
+		if err != nil {
+			return err
+		}
+		params, err := getFilters(cmd)
+		if err != nil {
+			return errors.Wrapf(err, "%s", failedGettingCompliance)
+		}
+		scan, errorModel, err := scanWrapper.GetByID(scanID)
+		if err != nil {
+			return errors.Wrapf(err, "%s", failedGettingScan)
+		}
+		if errorModel != nil {
+			return errors.Errorf("%s: CODE: %d, %s", failedGettingScan, errorModel.Code, errorModel.Message)
+		}
+		results, err := ReadResults(resultsWrapper, scan, params)
+		if err != nil {
+			return err
+		}
+		report := toComplianceReport(results, mapping)
+		report.ScanID = scan.ID
+		report.ProjectName = scan.ProjectName
+		report.BranchName = scan.Branch
+		report.CreatedAt = scan.CreatedAt.Format(summaryCreatedAtLayout)
+		return printComplianceReport(cmd.OutOrStdout(), report, format)
+	}
+}
+
+// loadComplianceMapping adds the frameworks and categories of mappingFile to the built-in mapping, the CWEs and
+// engines of a category already in the mapping are added to it
+func loadComplianceMapping(mappingFile string) (*complianceMapping, error) {
+	mapping := &complianceMapping{}
+	mergeComplianceMapping(mapping, &defaultComplianceMapping)
+	if mappingFile == "" {
+		return mapping, nil
+	}
+	data, err := os.ReadFile(mappingFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", failedGettingCompliance)
+	}
+	extension := &complianceMapping{}
+	if err = json.Unmarshal(data, extension); err != nil {
+		return nil, errors.Wrapf(err, complianceInvalidMapping, failedGettingCompliance, mappingFile)
+	}
+	for _, framework := range extension.Frameworks {
+		if framework.Name == "" {
+			return nil, errors.Errorf(complianceInvalidMapping+": framework without name", failedGettingCompliance, mappingFile)
+		}
+		for _, category := range framework.Categories {
+			if category.Name == "" {
+				return nil, errors.Errorf(complianceInvalidMapping+": category without name in %s", failedGettingCompliance, mappingFile,
+					framework.Name)
+			}
+		}
+	}
+	mergeComplianceMapping(mapping, extension)
+	return mapping, nil
+}
+
+func mergeComplianceMapping(mapping, extension *complianceMapping) {
+	for _, extensionFramework := range extension.Frameworks {
+		var framework *complianceMappingFramework
+		for i := range mapping.Frameworks {
+			if strings.EqualFold(mapping.Frameworks[i].Name, extensionFramework.Name) {
+				framework = &mapping.Frameworks[i]
+			}
+		}
+		if framework == nil {
+			mapping.Frameworks = append(mapping.Frameworks, complianceMappingFramework{Name: extensionFramework.Name})
+			framework = &mapping.Frameworks[len(mapping.Frameworks)-1]
+		}
+		for _, extensionCategory := range extensionFramework.Categories {
+			var category *complianceMappingCategory
+			for i := range framework.Categories {
+				if strings.EqualFold(framework.Categories[i].Name, extensionCategory.Name) {
+					category = &framework.Categories[i]
+				}
+			}
+			if category == nil {
+				framework.Categories = append(framework.Categories, complianceMappingCategory{Name: extensionCategory.Name})
+				category = &framework.Categories[len(framework.Categories)-1]
+			}
+			for _, cwe := range extensionCategory.CWEs {
+				category.CWEs = append(category.CWEs, normalizeComplianceCWE(cwe))
+			}
+			category.Engines = append(category.Engines, extensionCategory.Engines...)
+		}
+	}
+}
+
+// toComplianceReport groups the results by the categories of the mapping, by their CWE and by the compliances
+// reported with them. Every category of the mapping is listed, the CWE and reported ones only when found.
+func toComplianceReport(results *wrappers.ScanResultsCollection, mapping *complianceMapping) *complianceReport {
+	report := &complianceReport{
+		Severities: resultsHTMLSeverities,
+		Frameworks: []complianceFramework{},
+		Findings:   []complianceFinding{},
+	}
+	if results != nil {
+		for _, result := range results.Results {
+			report.Findings = append(report.Findings, toComplianceFinding(result, len(report.Findings)+1))
+		}
+	}
+	for _, mappingFramework := range mapping.Frameworks {
+		framework := complianceFramework{Name: mappingFramework.Name, Categories: []complianceCategory{}}
+		for _, mappingCategory := range mappingFramework.Categories {
+			category := newComplianceCategory(mappingCategory.Name)
+			for i := range report.Findings {
+				finding := &report.Findings[i]
+				if containsFold(mappingCategory.CWEs, finding.CWE) || (finding.CWE == "" && containsFold(mappingCategory.Engines, finding.Engine)) {
+					category.add(finding)
+				}
+			}
+			framework.add(category)
+		}
+		report.Frameworks = append(report.Frameworks, framework)
+	}
+	report.Frameworks = append(report.Frameworks,
+		groupComplianceFindings(complianceCWEFramework, report.Findings, func(finding *complianceFinding) []string {
+			if finding.CWE == "" {
+				return nil
+			}
+			return []string{finding.CWE}
+		}),
+		groupComplianceFindings(complianceReportedFramework, report.Findings, func(finding *complianceFinding) []string {
+			return finding.Compliances
+		}),
+	)
+	return report
+}
+
+func toComplianceFinding(result *wrappers.ScanResult, number int) complianceFinding {
+	htmlFinding := toResultsHTMLFinding(result)
+	finding := complianceFinding{
+		Number:    number,
+		ID:        result.ID,
+		Engine:    htmlFinding.Engine,
+		Severity:  htmlFinding.Severity,
+		State:     htmlFinding.State,
+		Title:     htmlFinding.Query,
+		Locations: htmlFinding.Locations,
+	}
+	if result.VulnerabilityDetails.CweID != nil {
+		finding.CWE = normalizeComplianceCWE(fmt.Sprint(result.VulnerabilityDetails.CweID))
+	}
+	for _, compliance := range result.VulnerabilityDetails.Compliances {
+		if compliance != nil && *compliance != "" && !contains(finding.Compliances, *compliance) {
+			finding.Compliances = append(finding.Compliances, *compliance)
+		}
+	}
+	return finding
+}
+
+// groupComplianceFindings creates a framework with a category for each key of the findings, sorted by name
+func groupComplianceFindings(name string, findings []complianceFinding, keys func(finding *complianceFinding) []string) complianceFramework {
+	categories := map[string]*complianceCategory{}
+	for i := range findings {
+		for _, key := range keys(&findings[i]) {
+			if categories[key] == nil {
+				categories[key] = newComplianceCategory(key)
+			}
+			categories[key].add(&findings[i])
+		}
+	}
+	names := make([]string, 0, len(categories))
+	for key := range categories {
+		names = append(names, key)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return complianceCategoryLess(names[i], names[j])
+	})
+	framework := complianceFramework{Name: name, Categories: []complianceCategory{}}
+	for _, key := range names {
+		framework.add(categories[key])
+	}
+	return framework
+}
+
+// complianceCategoryLess sorts CWE-79 before CWE-100
+func complianceCategoryLess(a, b string) bool {
+	aID, aErr := strconv.Atoi(strings.TrimPrefix(a, complianceCWEPrefix))
+	bID, bErr := strconv.Atoi(strings.TrimPrefix(b, complianceCWEPrefix))
+	if aErr == nil && bErr == nil {
+		return aID < bID
+	}
+	return a < b
+}
+
+func newComplianceCategory(name string) *complianceCategory {
+	return &complianceCategory{Name: name, Severities: map[string]int{}, States: map[string]int{}, Findings: []int{}}
+}
+
+func (c *complianceCategory) add(finding *complianceFinding) {
+	c.Total++
+	c.Severities[finding.Severity]++
+	if finding.State != "" {
+		c.States[finding.State]++
+	}
+	c.Findings = append(c.Findings, finding.Number)
+}
+
+func (f *complianceFramework) add(category *complianceCategory) {
+	f.Total += category.Total
+	f.Categories = append(f.Categories, *category)
+}
+
+// normalizeComplianceCWE turns the CWE IDs of the results, 79 or CWE-79, into CWE-79
+func normalizeComplianceCWE(cwe string) string {
+	cwe = strings.TrimSpace(cwe)
+	if cwe == "" {
+		return ""
+	}
+	if strings.HasPrefix(strings.ToUpper(cwe), complianceCWEPrefix) {
+		cwe = cwe[len(complianceCWEPrefix):]
+	}
+	return complianceCWEPrefix + cwe
+}
+
+func complianceCWE(id int) string {
+	return complianceCWEPrefix + strconv.Itoa(id)
+}
+
+func printComplianceReport(w io.Writer, report *complianceReport, format string) error {
+	switch {
+	case printer.IsFormat(format, printer.FormatJSON):
+		return printer.Print(w, report, printer.FormatJSON)
+	case printer.IsFormat(format, printer.FormatHTML):
+		complianceTemplate, err := htmlTemplate.New("complianceTemplate").Parse(wrappers.ComplianceHTMLTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to parse the HTML template", failedGettingCompliance)
+		}
+		return complianceTemplate.Execute(w, report)
+	default:
+		complianceTemplate, err := template.New("complianceTemplate").Parse(wrappers.ComplianceMarkdownTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to parse the markdown template", failedGettingCompliance)
+		}
+		return complianceTemplate.Execute(w, report)
+	}
+}
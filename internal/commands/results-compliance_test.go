@@ -0,0 +1,155 @@
+//go:build !integration
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"gotest.tools/assert"
+)
+
+func complianceTestResults() *wrappers.ScanResultsCollection {
+	owasp := "OWASP Top 10 2021"
+	pci := "PCI DSS v4.0"
+	return &wrappers.ScanResultsCollection{Results: []*wrappers.ScanResult{
+		{
+			Type: params.SastType, ID: "1", Severity: "HIGH", State: "TO_VERIFY",
+			ScanResultData:       wrappers.ScanResultData{QueryName: "SQL_Injection"},
+			VulnerabilityDetails: wrappers.VulnerabilityDetails{CweID: float64(89), Compliances: []*string{&owasp, &pci, &owasp}},
+		},
+		{
+			Type: params.SastType, ID: "2", Severity: "MEDIUM", State: "CONFIRMED",
+			ScanResultData:       wrappers.ScanResultData{QueryName: "Reflected_XSS"},
+			VulnerabilityDetails: wrappers.VulnerabilityDetails{CweID: "CWE-79", Compliances: []*string{&owasp}},
+		},
+		{
+			Type: params.ScaType, ID: "CVE-2020-8203", Severity: "HIGH", State: "TO_VERIFY",
+			ScanResultData: wrappers.ScanResultData{PackageIdentifier: "Npm-lodash-4.17.15"},
+		},
+		{
+			Type: params.KicsType, ID: "4", Severity: "LOW", State: "NOT_EXPLOITABLE",
+			ScanResultData: wrappers.ScanResultData{QueryName: "Container Running As Root", Filename: "Dockerfile", Line: 3},
+		},
+	}}
+}
+
+func findComplianceCategory(t *testing.T, report *complianceReport, frameworkName, categoryName string) complianceCategory {
+	for _, framework := range report.Frameworks {
+		if framework.Name != frameworkName {
+			continue
+		}
+		for _, category := range framework.Categories {
+			if category.Name == categoryName {
+				return category
+			}
+		}
+	}
+	t.Fatalf("category %s of %s not found", categoryName, frameworkName)
+	return complianceCategory{}
+}
+
+func TestToComplianceReport(t *testing.T) {
+	mapping, err := loadComplianceMapping("")
+	assert.NilError(t, err)
+	report := toComplianceReport(complianceTestResults(), mapping)
+
+	assert.Equal(t, len(report.Findings), 4)
+	assert.Equal(t, report.Findings[0].CWE, "CWE-89")
+	assert.DeepEqual(t, report.Findings[0].Compliances, []string{"OWASP Top 10 2021", "PCI DSS v4.0"})
+	assert.Equal(t, report.Findings[1].Title, "Reflected XSS")
+
+	injection := findComplianceCategory(t, report, owaspTop10Framework, "A03:2021-Injection")
+	assert.Equal(t, injection.Total, 2)
+	assert.DeepEqual(t, injection.Severities, map[string]int{"HIGH": 1, "MEDIUM": 1})
+	assert.DeepEqual(t, injection.States, map[string]int{"TO_VERIFY": 1, "CONFIRMED": 1})
+	assert.DeepEqual(t, injection.Findings, []int{1, 2})
+	// the findings without CWE are mapped by engine
+	assert.DeepEqual(t, findComplianceCategory(t, report, owaspTop10Framework, "A06:2021-Vulnerable and Outdated Components").Findings, []int{3})
+	assert.DeepEqual(t, findComplianceCategory(t, report, pciDSSFramework, "2.2 System components are configured and managed securely").Findings, []int{4})
+	assert.DeepEqual(t, findComplianceCategory(t, report, cweTop25Framework, "#3 CWE-89 SQL Injection").Findings, []int{1})
+	assert.Equal(t, findComplianceCategory(t, report, owaspTop10Framework, "A10:2021-Server-Side Request Forgery").Total, 0)
+
+	cwe := report.Frameworks[len(report.Frameworks)-2]
+	assert.Equal(t, cwe.Name, complianceCWEFramework)
+	assert.Equal(t, len(cwe.Categories), 2)
+	assert.Equal(t, cwe.Categories[0].Name, "CWE-79")
+	reported := report.Frameworks[len(report.Frameworks)-1]
+	assert.Equal(t, reported.Name, complianceReportedFramework)
+	assert.DeepEqual(t, findComplianceCategory(t, report, complianceReportedFramework, "OWASP Top 10 2021").Findings, []int{1, 2})
+}
+
+func TestLoadComplianceMappingExtension(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "mapping.json")
+	mappingJSON := `{"frameworks": [
+		{"name": "OWASP Top 10 2021", "categories": [{"name": "A03:2021-Injection", "cwes": ["1336"]}]},
+		{"name": "Internal", "categories": [{"name": "Secrets", "cwes": ["CWE-798"]}, {"name": "IaC", "engines": ["kics"]}]}
+	]}`
+	assert.NilError(t, os.WriteFile(mappingFile, []byte(mappingJSON), 0600))
+	mapping, err := loadComplianceMapping(mappingFile)
+	assert.NilError(t, err)
+	assert.Equal(t, len(mapping.Frameworks), len(defaultComplianceMapping.Frameworks)+1)
+	injection := mapping.Frameworks[0].Categories[2]
+	assert.Equal(t, injection.Name, "A03:2021-Injection")
+	assert.Equal(t, injection.CWEs[len(injection.CWEs)-1], "CWE-1336")
+	// the built-in mapping is not changed
+	assert.Assert(t, !contains(defaultComplianceMapping.Frameworks[0].Categories[2].CWEs, "CWE-1336"))
+
+	report := toComplianceReport(complianceTestResults(), mapping)
+	assert.DeepEqual(t, findComplianceCategory(t, report, "Internal", "IaC").Findings, []int{4})
+}
+
+func TestLoadComplianceMappingInvalid(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "mapping.json")
+	assert.NilError(t, os.WriteFile(mappingFile, []byte(`{"frameworks": [{"categories": []}]}`), 0600))
+	_, err := loadComplianceMapping(mappingFile)
+	assert.ErrorContains(t, err, "framework without name")
+
+	assert.NilError(t, os.WriteFile(mappingFile, []byte(`[]`), 0600))
+	_, err = loadComplianceMapping(mappingFile)
+	assert.ErrorContains(t, err, "invalid compliance mapping")
+}
+
+func TestPrintComplianceReport(t *testing.T) {
+	mapping, err := loadComplianceMapping("")
+	assert.NilError(t, err)
+	report := toComplianceReport(complianceTestResults(), mapping)
+	report.ScanID = "MOCK"
+
+	var markdown bytes.Buffer
+	assert.NilError(t, printComplianceReport(&markdown, report, printer.FormatSummaryMarkdown))
+	assert.Assert(t, strings.Contains(markdown.String(), "| A03:2021-Injection | 2 | 0 | 1 | 1 | 0 | 0 | CONFIRMED: 1 TO_VERIFY: 1 | [1](#finding-1), [2](#finding-2) |"))
+	assert.Assert(t, strings.Contains(markdown.String(), "### <a id=\"finding-1\"></a>1. SQL Injection"))
+
+	var html bytes.Buffer
+	assert.NilError(t, printComplianceReport(&html, report, printer.FormatHTML))
+	assert.Assert(t, strings.Contains(html.String(), "<a href=\"#finding-3\">3</a>"))
+	assert.Assert(t, strings.Contains(html.String(), "<tr id=\"finding-3\">"))
+
+	var reportJSON bytes.Buffer
+	assert.NilError(t, printComplianceReport(&reportJSON, report, printer.FormatJSON))
+	parsed := complianceReport{}
+	assert.NilError(t, json.Unmarshal(reportJSON.Bytes(), &parsed))
+	assert.Equal(t, len(parsed.Frameworks), len(report.Frameworks))
+}
+
+func TestRunGetResultsComplianceCommand(t *testing.T) {
+	execCmdNilAssertion(t, "results", "compliance", "--scan-id", "MOCK", "--format", "json")
+}
+
+func TestRunGetResultsComplianceCommandWithoutScanID(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "compliance")
+	assert.ErrorContains(t, err, "Please provide a scan ID")
+}
+
+func TestRunGetResultsComplianceCommandInvalidFormat(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "compliance", "--scan-id", "MOCK", "--format", "pdf")
+	assert.ErrorContains(t, err, "invalid format pdf")
+}
@@ -0,0 +1,463 @@
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/remediation"
+	"github.com/pkg/errors"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+const (
+	glDependencyTypeLabel  = ".gl-dependency-scanning-report"
+	glContainerTypeLabel   = ".gl-container-scanning-report"
+	glSchemaVersion        = "15.0.0"
+	glSchemaURL            = "https://gitlab.com/gitlab-org/gitlab/-/raw/master/lib/gitlab/ci/parsers/security/validators/schemas/15.0.0/"
+	glDependencySchemaFile = "dependency-scanning-report-format.json"
+	glContainerSchemaFile  = "container-scanning-report-format.json"
+	glCveIdentifier        = "cve"
+	glCweIdentifier        = "cwe"
+	glCveURL               = "https://cve.mitre.org/cgi-bin/cvename.cgi?name="
+	glCweURL               = "https://cwe.mitre.org/data/definitions/%s.html"
+	glUnknown              = "unknown"
+	glDiffPrefixFrom       = "a/"
+	glDiffPrefixTo         = "b/"
+	glPackageSeparator     = "-"
+)
+
+// glPackageManagers maps the package managers of the SCA package identifiers to the package managers of GitLab
+var glPackageManagers = map[string]string{
+	"npm":    "npm",
+	"maven":  "maven",
+	"python": "pip",
+	"go":     "go",
+	"php":    "composer",
+	"ruby":   "bundler",
+	"nuget":  "nuget",
+	"ios":    "cocoapods",
+}
+
+// glPackageManagersByFile refines the package manager with the dependency file, e.g. a Maven package declared in
+// build.gradle is a Gradle dependency
+var glPackageManagersByFile = map[string]string{
+	"yarn.lock":        "yarn",
+	"pnpm-lock.yaml":   "pnpm",
+	"build.gradle":     "gradle",
+	"build.gradle.kts": "gradle",
+	"build.sbt":        "sbt",
+	"pipfile":          "pipenv",
+	"pipfile.lock":     "pipenv",
+	"poetry.lock":      "poetry",
+	"setup.py":         "setuptools",
+	"conanfile.txt":    "conan",
+}
+
+// glOperatingSystems are the package managers of SCA packages installed in container images
+var glOperatingSystems = map[string]bool{
+	"alpine": true,
+	"apk":    true,
+	"debian": true,
+	"dpkg":   true,
+	"ubuntu": true,
+	"rpm":    true,
+	"centos": true,
+	"redhat": true,
+	"rhel":   true,
+	"oracle": true,
+	"amazon": true,
+	"photon": true,
+	"wolfi":  true,
+}
+
+// glDependencyReport builds a dependency or container scanning report, the dependencies of each file are numbered
+// with iids referred to by the dependency paths
+type glDependencyReport struct {
+	collection      *wrappers.GlDependencyResultsCollection
+	dependencyFiles []wrappers.GlDependencyFile
+	files           map[string]int
+	iids            map[string]int
+	remediations    map[string]int
+}
+
+func exportGlDependencyResults(targetFile string, results *wrappers.ScanResultsCollection, summary *wrappers.ResultSummary, scanType string) error {
+	log.Println("Creating gl-"+strings.TrimSuffix(scanType, "_scanning")+" Report: ", targetFile)
+	report, err := convertCxResultsToGlDependency(results, summary, scanType)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to add scan to gl %s report", failedListingResults, scanType)
+	}
+	resultsJSON, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to serialize gl %s report ", failedListingResults, scanType)
+	}
+	f, err := os.Create(targetFile)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to create target file  ", failedListingResults)
+	}
+	_, _ = fmt.Fprintln(f, string(resultsJSON))
+	defer f.Close()
+	return nil
+}
+
+// convertCxResultsToGlDependency converts the SCA results to a GitLab dependency scanning report, or to a container
+// scanning report for the packages of the operating system of container images
+func convertCxResultsToGlDependency(results *wrappers.ScanResultsCollection, summary *wrappers.ResultSummary,
+	scanType string) (*wrappers.GlDependencyResultsCollection, error) {
+	createdAt, err := time.Parse(summaryCreatedAtLayout, summary.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	analyzerID, schemaFile := wrappers.GlDependencyAnalyzerID, glDependencySchemaFile
+	if scanType == wrappers.GlContainerScanningType {
+		analyzerID, schemaFile = wrappers.GlContainerAnalyzerID, glContainerSchemaFile
+	}
+	report := &glDependencyReport{
+		collection: &wrappers.GlDependencyResultsCollection{
+			Schema:          glSchemaURL + schemaFile,
+			Version:         glSchemaVersion,
+			Vulnerabilities: []wrappers.GlDependencyVulnerability{},
+			Remediations:    []wrappers.GlRemediation{},
+		},
+		dependencyFiles: []wrappers.GlDependencyFile{},
+		files:           map[string]int{},
+		iids:            map[string]int{},
+		remediations:    map[string]int{},
+	}
+	scan := &report.collection.Scan
+	scan.Analyzer = wrappers.Analyzer{
+		ID:      analyzerID,
+		Name:    wrappers.VendorName,
+		URL:     wrappers.AnalyzerURL,
+		Vendor:  wrappers.Vendor{Name: wrappers.VendorName},
+		Version: commonParams.Version,
+	}
+	scan.Scanner = wrappers.GlScanner{
+		ID:      analyzerID,
+		Name:    wrappers.VendorName,
+		Vendor:  wrappers.Vendor{Name: wrappers.VendorName},
+		Version: commonParams.Version,
+	}
+	scan.Status = commonParams.Success
+	scan.Type = scanType
+	scan.StartTime = createdAt.Format(glTimeFormat)
+	scan.EndTime = createdAt.Format(glTimeFormat)
+
+	if results != nil {
+		for _, result := range results.Results {
+			if strings.TrimSpace(result.Type) == commonParams.ScaType {
+				report.addResult(result, summary.BaseURI, scanType)
+			}
+		}
+	}
+	if scanType == wrappers.GlDependencyScanningType {
+		report.collection.DependencyFiles = &report.dependencyFiles
+	}
+	return report.collection, nil
+}
+
+func (r *glDependencyReport) addResult(result *wrappers.ScanResult, summaryBaseURI, scanType string) {
+	packageManager, name, version := glScaPackage(result)
+	locations := glScaLocations(result)
+	image := glContainerImage(result)
+	if (image != "" || isGlContainerPackage(packageManager, locations)) != (scanType == wrappers.GlContainerScanningType) {
+		return
+	}
+	if scanType == wrappers.GlContainerScanningType {
+		// the Dockerfile a package was found in is not the image it is installed in
+		if image == "" {
+			logger.PrintIfVerbose("Skipping SCA result " + result.ID + " of " + result.ScanResultData.PackageIdentifier + " without container image")
+			return
+		}
+		locations = []string{image}
+	}
+	if len(locations) == 0 {
+		logger.PrintIfVerbose("Skipping SCA result " + result.ID + " of " + result.ScanResultData.PackageIdentifier + " without location")
+		return
+	}
+	for _, location := range locations {
+		vulnerability := wrappers.GlDependencyVulnerability{
+			ID:          fmt.Sprintf("%s:%s:%s", result.ID, result.ScanResultData.PackageIdentifier, location),
+			Name:        fmt.Sprintf("%s in %s %s", result.ID, name, version),
+			Description: result.Description,
+			Severity:    cases.Title(language.English).String(result.Severity),
+			Identifiers: glScaIdentifiers(result, summaryBaseURI),
+			Links:       glScaLinks(result),
+		}
+		dependency := wrappers.GlDependency{Package: wrappers.GlPackage{Name: name}, Version: version}
+		if scanType == wrappers.GlContainerScanningType {
+			vulnerability.Location = wrappers.GlDependencyLocation{
+				Image:           location,
+				OperatingSystem: glOperatingSystem(packageManager),
+				Dependency:      dependency,
+			}
+		} else {
+			vulnerability.Location = wrappers.GlDependencyLocation{
+				File:       location,
+				Dependency: r.addDependency(result, location, packageManager, name, version),
+			}
+		}
+		if recommended := glRecommendedVersion(result); recommended != "" {
+			vulnerability.Solution = fmt.Sprintf("Upgrade %s to version %s", name, recommended)
+			if scanType == wrappers.GlDependencyScanningType {
+				r.addRemediation(result, vulnerability.ID, location, name, recommended)
+			}
+		}
+		r.collection.Vulnerabilities = append(r.collection.Vulnerabilities, vulnerability)
+	}
+}
+
+// addDependency adds the vulnerable package to its dependency file, with the packages of its first dependency path
+// that bring it into the project
+func (r *glDependencyReport) addDependency(result *wrappers.ScanResult, location, packageManager, name, version string) wrappers.GlDependency {
+	scaPackage := result.ScanResultData.ScaPackageCollection
+	direct := scaPackage == nil || scaPackage.IsDirectDependency
+	var ancestors []wrappers.DependencyPath
+	if scaPackage != nil {
+		for _, dependencyPath := range scaPackage.DependencyPathArray {
+			if len(dependencyPath) > 1 {
+				ancestors = dependencyPath[:len(dependencyPath)-1]
+				break
+			}
+		}
+	}
+	var dependencyPath []wrappers.GlDependencyPath
+	for i, ancestor := range ancestors {
+		ancestorDependency := r.fileDependency(location, packageManager, ancestor.Name, ancestor.Version, i == 0, nil)
+		dependencyPath = append(dependencyPath, wrappers.GlDependencyPath{IID: ancestorDependency.IID})
+	}
+	return r.fileDependency(location, packageManager, name, version, direct, dependencyPath)
+}
+
+// fileDependency returns the dependency of a file, adding it on first use
+func (r *glDependencyReport) fileDependency(location, packageManager, name, version string, direct bool,
+	dependencyPath []wrappers.GlDependencyPath) wrappers.GlDependency {
+	index, ok := r.files[location]
+	if !ok {
+		index = len(r.dependencyFiles)
+		r.files[location] = index
+		r.dependencyFiles = append(r.dependencyFiles, wrappers.GlDependencyFile{
+			Path:           location,
+			PackageManager: glPackageManager(packageManager, location),
+			Dependencies:   []wrappers.GlDependency{},
+		})
+	}
+	file := &r.dependencyFiles[index]
+	key := location + "\x00" + name + "\x00" + version
+	if iid, ok := r.iids[key]; ok {
+		for i := range file.Dependencies {
+			if file.Dependencies[i].IID == iid {
+				file.Dependencies[i].Direct = file.Dependencies[i].Direct || direct
+				if len(file.Dependencies[i].DependencyPath) == 0 {
+					file.Dependencies[i].DependencyPath = dependencyPath
+				}
+				return file.Dependencies[i]
+			}
+		}
+	}
+	dependency := wrappers.GlDependency{
+		Package:        wrappers.GlPackage{Name: name},
+		Version:        version,
+		IID:            len(r.iids) + 1,
+		Direct:         direct,
+		DependencyPath: dependencyPath,
+	}
+	r.iids[key] = dependency.IID
+	file.Dependencies = append(file.Dependencies, dependency)
+	return dependency
+}
+
+// addRemediation adds the upgrade of a direct dependency when its dependency file is in the working directory,
+// the remediations hold the diff of the file and every vulnerability it fixes
+func (r *glDependencyReport) addRemediation(result *wrappers.ScanResult, vulnerabilityID, location, name, recommended string) {
+	scaPackage := result.ScanResultData.ScaPackageCollection
+	if scaPackage != nil && !scaPackage.IsDirectDependency {
+		return
+	}
+	key := location + "\x00" + name + "\x00" + recommended
+	if index, ok := r.remediations[key]; ok {
+		if index >= 0 {
+			fixes := &r.collection.Remediations[index].Fixes
+			*fixes = append(*fixes, wrappers.GlRemediationFix{ID: vulnerabilityID})
+		}
+		return
+	}
+	// a failed remediation is not retried for the other vulnerabilities of the package
+	r.remediations[key] = -1
+	diff, err := glRemediationDiff(location, name, recommended)
+	if err != nil {
+		logger.PrintIfVerbose(fmt.Sprintf("No remediation for %s in %s: %v", name, location, err))
+		return
+	}
+	r.collection.Remediations = append(r.collection.Remediations, wrappers.GlRemediation{
+		Fixes:   []wrappers.GlRemediationFix{{ID: vulnerabilityID}},
+		Summary: fmt.Sprintf("Upgrade %s to version %s", name, recommended),
+		Diff:    base64.StdEncoding.EncodeToString([]byte(diff)),
+	})
+	r.remediations[key] = len(r.collection.Remediations) - 1
+}
+
+// glRemediationDiff returns the unified diff that upgrades a package in a dependency file of the working directory
+func glRemediationDiff(location, name, version string) (string, error) {
+	relative := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(location)), "/")
+	content, err := os.ReadFile(filepath.FromSlash(relative))
+	if err != nil {
+		return "", err
+	}
+	parser, err := remediation.NewPackage(relative, string(content), name, version)
+	if err != nil {
+		return "", err
+	}
+	upgraded, err := parser.Parser()
+	if err != nil {
+		return "", err
+	}
+	diff := remediation.UnifiedDiff(glDiffPrefixFrom+relative, glDiffPrefixTo+relative, string(content), upgraded)
+	if diff == "" {
+		return "", errors.Errorf("%s is up to date", relative)
+	}
+	return diff, nil
+}
+
+// glScaPackage returns the package manager, name and version of the package of a result, from its dependency
+// paths when available, otherwise from its identifier Manager-name-version
+func glScaPackage(result *wrappers.ScanResult) (packageManager, name, version string) {
+	identifier := result.ScanResultData.PackageIdentifier
+	packageManager, rest, found := strings.Cut(identifier, glPackageSeparator)
+	if !found {
+		packageManager, rest = "", identifier
+	}
+	if scaPackage := result.ScanResultData.ScaPackageCollection; scaPackage != nil && len(scaPackage.DependencyPathArray) > 0 {
+		if name, version = scaPackageNameAndVersion(scaPackage); name != "" {
+			return packageManager, name, version
+		}
+	}
+	if separator := strings.LastIndex(rest, glPackageSeparator); separator > 0 {
+		return packageManager, rest[:separator], rest[separator+1:]
+	}
+	return packageManager, rest, ""
+}
+
+func glScaLocations(result *wrappers.ScanResult) []string {
+	var locations []string
+	if result.ScanResultData.ScaPackageCollection == nil {
+		return locations
+	}
+	for _, location := range result.ScanResultData.ScaPackageCollection.Locations {
+		if location != nil && strings.TrimSpace(*location) != "" {
+			locations = append(locations, *location)
+		}
+	}
+	return locations
+}
+
+// glContainerImage returns the image name:tag of an SCA container result, or an empty string for other results
+func glContainerImage(result *wrappers.ScanResult) string {
+	imageName := strings.TrimSpace(result.ScanResultData.ImageName)
+	if imageName == "" {
+		return ""
+	}
+	if imageTag := strings.TrimSpace(result.ScanResultData.ImageTag); imageTag != "" {
+		return imageName + ":" + imageTag
+	}
+	return imageName
+}
+
+// isGlContainerPackage reports whether a package belongs to a container image, either a package of its operating
+// system or a package found only in Dockerfiles
+func isGlContainerPackage(packageManager string, locations []string) bool {
+	if glOperatingSystems[strings.ToLower(packageManager)] {
+		return true
+	}
+	if len(locations) == 0 {
+		return false
+	}
+	for _, location := range locations {
+		if !isDockerfile(location) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDockerfile(location string) bool {
+	base := strings.ToLower(path.Base(filepath.ToSlash(location)))
+	return base == "dockerfile" || base == "containerfile" || strings.HasSuffix(base, ".dockerfile") ||
+		strings.HasPrefix(base, "dockerfile.")
+}
+
+func glOperatingSystem(packageManager string) string {
+	if glOperatingSystems[strings.ToLower(packageManager)] {
+		return strings.ToLower(packageManager)
+	}
+	return glUnknown
+}
+
+func glPackageManager(packageManager, location string) string {
+	base := strings.ToLower(path.Base(filepath.ToSlash(location)))
+	if manager, ok := glPackageManagersByFile[base]; ok {
+		return manager
+	}
+	if manager, ok := glPackageManagers[strings.ToLower(packageManager)]; ok {
+		return manager
+	}
+	if packageManager == "" {
+		return glUnknown
+	}
+	return strings.ToLower(packageManager)
+}
+
+func glRecommendedVersion(result *wrappers.ScanResult) string {
+	if result.ScanResultData.RecommendedVersion == nil {
+		return ""
+	}
+	return fmt.Sprint(result.ScanResultData.RecommendedVersion)
+}
+
+func glScaIdentifiers(result *wrappers.ScanResult, summaryBaseURI string) []wrappers.Identifier {
+	var identifiers []wrappers.Identifier
+	cve := result.VulnerabilityDetails.CveName
+	if cve == "" && strings.HasPrefix(strings.ToUpper(result.ID), "CVE-") {
+		cve = result.ID
+	}
+	if cve != "" {
+		identifiers = append(identifiers, wrappers.Identifier{Type: glCveIdentifier, Name: cve, URL: glCveURL + cve, Value: cve})
+	}
+	if cwe := strings.TrimPrefix(fmt.Sprint(result.VulnerabilityDetails.CweID), "CWE-"); result.VulnerabilityDetails.CweID != nil && cwe != "" {
+		identifiers = append(identifiers, wrappers.Identifier{
+			Type:  glCweIdentifier,
+			Name:  "CWE-" + cwe,
+			URL:   fmt.Sprintf(glCweURL, cwe),
+			Value: cwe,
+		})
+	}
+	return append(identifiers, wrappers.Identifier{
+		Type:  glSastCxOneScanIdentifier,
+		Name:  "CxOne Scan",
+		URL:   summaryBaseURI,
+		Value: result.ID,
+	})
+}
+
+func glScaLinks(result *wrappers.ScanResult) []wrappers.GlLink {
+	var links []wrappers.GlLink
+	if scaPackage := result.ScanResultData.ScaPackageCollection; scaPackage != nil && scaPackage.FixLink != "" {
+		links = append(links, wrappers.GlLink{Name: "Checkmarx DevHub", URL: scaPackage.FixLink})
+	}
+	for _, packageData := range result.ScanResultData.PackageData {
+		if packageData != nil && packageData.URL != "" {
+			links = append(links, wrappers.GlLink{Name: packageData.Type, URL: packageData.URL})
+		}
+	}
+	return links
+}
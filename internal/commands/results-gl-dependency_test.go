@@ -0,0 +1,206 @@
+//go:build !integration
+
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gotest.tools/assert"
+)
+
+func glDependencyTestResults() *wrappers.ScanResultsCollection {
+	results := importTestResults()
+	packageFile := "package.json"
+	dockerfile := "deploy/Dockerfile"
+	results.Results = append(results.Results,
+		&wrappers.ScanResult{
+			Type:        params.ScaType,
+			ID:          "CVE-2020-8203",
+			Severity:    highCx,
+			Description: "Prototype pollution in lodash",
+			VulnerabilityDetails: wrappers.VulnerabilityDetails{
+				CveName: "CVE-2020-8203",
+				CweID:   "CWE-1321",
+			},
+			ScanResultData: wrappers.ScanResultData{
+				PackageIdentifier:  "Npm-lodash-4.17.15",
+				RecommendedVersion: "4.17.21",
+				ScaPackageCollection: &wrappers.ScaPackageCollection{
+					ID:                 "Npm-lodash-4.17.15",
+					FixLink:            "https://devhub.checkmarx.com/cve-details/CVE-2020-8203",
+					Locations:          []*string{&packageFile},
+					IsDirectDependency: true,
+				},
+			},
+		},
+		&wrappers.ScanResult{
+			Type:        params.ScaType,
+			ID:          "CVE-2021-3520",
+			Severity:    criticalCx,
+			Description: "Integer overflow in lz4",
+			ScanResultData: wrappers.ScanResultData{
+				PackageIdentifier: "Npm-lz4-0.6.5",
+				ScaPackageCollection: &wrappers.ScaPackageCollection{
+					ID:        "Npm-lz4-0.6.5",
+					Locations: []*string{&packageFile},
+					DependencyPathArray: [][]wrappers.DependencyPath{{
+						{ID: "Npm-compressjs-1.0.3", Name: "compressjs", Version: "1.0.3"},
+						{ID: "Npm-lz4-0.6.5", Name: "lz4", Version: "0.6.5"},
+					}},
+				},
+			},
+		},
+		&wrappers.ScanResult{
+			Type:        params.ScaType,
+			ID:          "CVE-2023-0286",
+			Severity:    highCx,
+			Description: "Type confusion in openssl",
+			ScanResultData: wrappers.ScanResultData{
+				PackageIdentifier: "Debian-openssl-1.1.1n",
+				ImageName:         "registry.example.com/app",
+				ImageTag:          "1.2.0",
+				ScaPackageCollection: &wrappers.ScaPackageCollection{
+					ID:        "Debian-openssl-1.1.1n",
+					Locations: []*string{&dockerfile},
+				},
+			},
+		},
+	)
+	return results
+}
+
+func glDependencyTestSummary() *wrappers.ResultSummary {
+	return &wrappers.ResultSummary{CreatedAt: "2023-05-01, 10:20:30", BaseURI: "https://ast.checkmarx.net/projects/id"}
+}
+
+func validateGlSchema(t *testing.T, schemaName string, report []byte) {
+	schemaFile, err := os.Open(filepath.Join("data", schemaName))
+	assert.NilError(t, err)
+	defer schemaFile.Close()
+	compiler := jsonschema.NewCompiler()
+	assert.NilError(t, compiler.AddResource(schemaName, schemaFile))
+	schema, err := compiler.Compile(schemaName)
+	assert.NilError(t, err)
+	var document interface{}
+	assert.NilError(t, json.Unmarshal(report, &document))
+	assert.NilError(t, schema.Validate(document))
+}
+
+func TestConvertCxResultsToGlDependency(t *testing.T) {
+	report, err := convertCxResultsToGlDependency(glDependencyTestResults(), glDependencyTestSummary(), wrappers.GlDependencyScanningType)
+	assert.NilError(t, err)
+	reportJSON, err := json.Marshal(report)
+	assert.NilError(t, err)
+	validateGlSchema(t, "gl-dependency-scanning-report-format.json", reportJSON)
+
+	assert.Equal(t, report.Scan.Type, "dependency_scanning")
+	assert.Equal(t, report.Scan.StartTime, "2023-05-01T10:20:30")
+	// lodash is in two files for the first vulnerability
+	assert.Equal(t, len(report.Vulnerabilities), 4)
+	assert.Equal(t, report.Vulnerabilities[0].Location.File, "package.json")
+	assert.Equal(t, report.Vulnerabilities[1].Location.File, "package-lock.json")
+	assert.Equal(t, report.Vulnerabilities[2].Severity, "High")
+	assert.Equal(t, report.Vulnerabilities[2].Solution, "Upgrade lodash to version 4.17.21")
+	assert.DeepEqual(t, report.Vulnerabilities[2].Identifiers[:2], []wrappers.Identifier{
+		{Type: "cve", Name: "CVE-2020-8203", URL: "https://cve.mitre.org/cgi-bin/cvename.cgi?name=CVE-2020-8203", Value: "CVE-2020-8203"},
+		{Type: "cwe", Name: "CWE-1321", URL: "https://cwe.mitre.org/data/definitions/1321.html", Value: "1321"},
+	})
+	assert.Equal(t, report.Vulnerabilities[2].Links[0].URL, "https://devhub.checkmarx.com/cve-details/CVE-2020-8203")
+
+	dependencyFiles := *report.DependencyFiles
+	assert.Equal(t, len(dependencyFiles), 2)
+	packageJSON := dependencyFiles[0]
+	assert.Equal(t, packageJSON.Path, "package.json")
+	assert.Equal(t, packageJSON.PackageManager, "npm")
+	// lodash once, then compressjs and lz4 it brings
+	assert.Equal(t, len(packageJSON.Dependencies), 3)
+	lz4 := report.Vulnerabilities[3].Location.Dependency
+	assert.Equal(t, lz4.Package.Name, "lz4")
+	assert.Equal(t, lz4.Direct, false)
+	assert.Equal(t, len(lz4.DependencyPath), 1)
+	compressjs := packageJSON.Dependencies[1]
+	assert.Equal(t, compressjs.Package.Name, "compressjs")
+	assert.Equal(t, compressjs.Direct, true)
+	assert.Equal(t, lz4.DependencyPath[0].IID, compressjs.IID)
+	// the dependency file is not in the working directory
+	assert.Equal(t, len(report.Remediations), 0)
+}
+
+func TestConvertCxResultsToGlContainer(t *testing.T) {
+	results := glDependencyTestResults()
+	dockerfile := "deploy/Dockerfile"
+	results.Results = append(results.Results, &wrappers.ScanResult{
+		Type:        params.ScaType,
+		ID:          "CVE-2022-37434",
+		Severity:    criticalCx,
+		Description: "Heap-based buffer over-read in zlib",
+		ScanResultData: wrappers.ScanResultData{
+			PackageIdentifier: "Alpine-zlib-1.2.12",
+			ScaPackageCollection: &wrappers.ScaPackageCollection{
+				ID:        "Alpine-zlib-1.2.12",
+				Locations: []*string{&dockerfile},
+			},
+		},
+	})
+	report, err := convertCxResultsToGlDependency(results, glDependencyTestSummary(), wrappers.GlContainerScanningType)
+	assert.NilError(t, err)
+	reportJSON, err := json.Marshal(report)
+	assert.NilError(t, err)
+	validateGlSchema(t, "gl-container-scanning-report-format.json", reportJSON)
+	assert.Assert(t, !strings.Contains(string(reportJSON), "dependency_files"))
+
+	assert.Equal(t, report.Scan.Type, "container_scanning")
+	// zlib is skipped, only the Dockerfile it was found in is known and not its image
+	assert.Equal(t, len(report.Vulnerabilities), 1)
+	location := report.Vulnerabilities[0].Location
+	assert.Equal(t, location.Image, "registry.example.com/app:1.2.0")
+	assert.Equal(t, location.OperatingSystem, "debian")
+	assert.Equal(t, location.Dependency.Package.Name, "openssl")
+	assert.Equal(t, location.Dependency.Version, "1.1.1n")
+}
+
+func TestConvertCxResultsToGlDependencyRemediation(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NilError(t, err)
+	dir := t.TempDir()
+	packageJSON := "{\n  \"dependencies\": {\n    \"lodash\": \"4.17.15\"\n  }\n}\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(packageJSON), 0600))
+	assert.NilError(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	report, err := convertCxResultsToGlDependency(glDependencyTestResults(), glDependencyTestSummary(), wrappers.GlDependencyScanningType)
+	assert.NilError(t, err)
+	assert.Equal(t, len(report.Remediations), 1)
+	remediation := report.Remediations[0]
+	assert.Equal(t, remediation.Summary, "Upgrade lodash to version 4.17.21")
+	assert.DeepEqual(t, remediation.Fixes, []wrappers.GlRemediationFix{{ID: report.Vulnerabilities[2].ID}})
+	diff, err := base64.StdEncoding.DecodeString(remediation.Diff)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(string(diff), "--- a/package.json\n+++ b/package.json\n"))
+	assert.Assert(t, strings.Contains(string(diff), "+    \"lodash\": \"4.17.21\""))
+}
+
+func TestRunGetResultsByScanIdGLDependencyFormats(t *testing.T) {
+	execCmdNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "gl-dependency,gl-container")
+	for _, report := range []struct{ label, schema string }{
+		{glDependencyTypeLabel, "gl-dependency-scanning-report-format.json"},
+		{glContainerTypeLabel, "gl-container-scanning-report-format.json"},
+	} {
+		reportFile := fmt.Sprintf("%s%s.json", fileName, report.label)
+		reportJSON, err := os.ReadFile(reportFile)
+		assert.NilError(t, err)
+		validateGlSchema(t, report.schema, reportJSON)
+		_ = os.Remove(reportFile)
+	}
+}
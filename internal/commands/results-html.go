@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+)
+
+const (
+	resultsHTMLTypeLabel  = "_report"
+	resultsHTMLTimeFormat = "2006-01-02 15:04:05 MST"
+)
+
+// resultsHTMLSeverities orders the findings of the report, unknown severities come last
+var resultsHTMLSeverities = []string{criticalCx, highCx, mediumCx, lowCx, infoCx}
+
+type resultsHTMLReport struct {
+	Summary        *wrappers.ResultSummary
+	GeneratedAt    string
+	Findings       []resultsHTMLFinding
+	SeverityCounts []resultsHTMLCount
+	Engines        []string
+	Severities     []string
+	States         []string
+}
+
+type resultsHTMLCount struct {
+	Name  string
+	Count int
+}
+
+// resultsHTMLFinding is a row of the report, File and Query hold the values the findings are filtered by
+type resultsHTMLFinding struct {
+	Engine        string
+	Severity      string
+	State         string
+	Query         string
+	File          string
+	Locations     []string
+	Description   string
+	Nodes         []*wrappers.ScanResultNode
+	Package       *resultsHTMLPackage
+	Value         string
+	ExpectedValue string
+}
+
+type resultsHTMLPackage struct {
+	Name               string
+	Version            string
+	TypeOfDependency   string
+	CVE                string
+	CvssScore          float64
+	CWE                string
+	RecommendedVersion string
+	FixLink            string
+}
+
+func writeHTMLResults(targetFile string, results *wrappers.ScanResultsCollection, summary *wrappers.ResultSummary) error {
+	log.Println("Creating HTML Report: ", targetFile)
+	resultsTemplate, err := template.New("resultsTemplate").Parse(wrappers.ResultsHTMLTemplate)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to parse the HTML report template", failedListingResults)
+	}
+	f, err := os.Create(targetFile)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to create target file  ", failedListingResults)
+	}
+	defer f.Close()
+	return resultsTemplate.Execute(f, toResultsHTMLReport(results, summary, time.Now()))
+}
+
+func toResultsHTMLReport(results *wrappers.ScanResultsCollection, summary *wrappers.ResultSummary, generatedAt time.Time) *resultsHTMLReport {
+	report := &resultsHTMLReport{
+		Summary:     summary,
+		GeneratedAt: generatedAt.Format(resultsHTMLTimeFormat),
+		Findings:    []resultsHTMLFinding{},
+	}
+	if results != nil {
+		for _, result := range results.Results {
+			report.Findings = append(report.Findings, toResultsHTMLFinding(result))
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return resultsHTMLSeverityRank(report.Findings[i].Severity) < resultsHTMLSeverityRank(report.Findings[j].Severity)
+	})
+	counts := map[string]int{}
+	engines := map[string]bool{}
+	states := map[string]bool{}
+	for i := range report.Findings {
+		finding := &report.Findings[i]
+		counts[finding.Severity]++
+		if !engines[finding.Engine] {
+			engines[finding.Engine] = true
+			report.Engines = append(report.Engines, finding.Engine)
+		}
+		if finding.State != "" && !states[finding.State] {
+			states[finding.State] = true
+			report.States = append(report.States, finding.State)
+		}
+	}
+	for i := range report.Findings {
+		severity := report.Findings[i].Severity
+		if !containsFold(report.Severities, severity) {
+			report.Severities = append(report.Severities, severity)
+			report.SeverityCounts = append(report.SeverityCounts, resultsHTMLCount{Name: severity, Count: counts[severity]})
+		}
+	}
+	sort.Strings(report.States)
+	return report
+}
+
+func toResultsHTMLFinding(result *wrappers.ScanResult) resultsHTMLFinding {
+	finding := resultsHTMLFinding{
+		Engine:      strings.TrimSpace(result.Type),
+		Severity:    strings.ToUpper(result.Severity),
+		State:       result.State,
+		Description: result.Description,
+	}
+	data := &result.ScanResultData
+	switch finding.Engine {
+	case commonParams.SastType:
+		finding.Query = strings.ReplaceAll(data.QueryName, "_", " ")
+		finding.Nodes = data.Nodes
+		if len(data.Nodes) > 0 {
+			finding.Locations = []string{fmt.Sprintf("%s:%d", data.Nodes[0].FileName, data.Nodes[0].Line)}
+		}
+	case commonParams.ScaType:
+		finding.Package = toResultsHTMLPackage(result)
+		finding.Query = strings.TrimSpace(fmt.Sprintf("%s %s %s", result.ID, finding.Package.Name, finding.Package.Version))
+		finding.Locations = glScaLocations(result)
+	case commonParams.KicsType:
+		finding.Query = data.QueryName
+		finding.Value = data.Value
+		finding.ExpectedValue = data.ExpectedValue
+		if data.Filename != "" {
+			finding.Locations = []string{fmt.Sprintf("%s:%d", data.Filename, data.Line)}
+		}
+	default:
+		finding.Query = data.QueryName
+	}
+	if finding.Query == "" {
+		finding.Query = result.ID
+	}
+	finding.File = strings.Join(finding.Locations, " ")
+	return finding
+}
+
+func toResultsHTMLPackage(result *wrappers.ScanResult) *resultsHTMLPackage {
+	_, name, version := glScaPackage(result)
+	scaPackage := &resultsHTMLPackage{
+		Name:               name,
+		Version:            version,
+		CVE:                result.VulnerabilityDetails.CveName,
+		CvssScore:          result.VulnerabilityDetails.CvssScore,
+		RecommendedVersion: glRecommendedVersion(result),
+	}
+	if result.VulnerabilityDetails.CweID != nil {
+		scaPackage.CWE = fmt.Sprint(result.VulnerabilityDetails.CweID)
+	}
+	if scaPackage.CVE == "" && strings.HasPrefix(strings.ToUpper(result.ID), "CVE-") {
+		scaPackage.CVE = result.ID
+	}
+	if collection := result.ScanResultData.ScaPackageCollection; collection != nil {
+		scaPackage.TypeOfDependency = collection.TypeOfDependency
+		scaPackage.FixLink = collection.FixLink
+	}
+	if scaPackage.TypeOfDependency == "" {
+		scaPackage.TypeOfDependency = directDependencyType
+		if collection := result.ScanResultData.ScaPackageCollection; collection != nil && !collection.IsDirectDependency {
+			scaPackage.TypeOfDependency = indirectDependencyType
+		}
+	}
+	return scaPackage
+}
+
+func resultsHTMLSeverityRank(severity string) int {
+	for i, known := range resultsHTMLSeverities {
+		if severity == known {
+			return i
+		}
+	}
+	return len(resultsHTMLSeverities)
+}
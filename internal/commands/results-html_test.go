@@ -0,0 +1,81 @@
+//go:build !integration
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"gotest.tools/assert"
+)
+
+func TestToResultsHTMLReport(t *testing.T) {
+	report := toResultsHTMLReport(glDependencyTestResults(), glDependencyTestSummary(), time.Date(2023, 5, 2, 8, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, report.GeneratedAt, "2023-05-02 08:00:00 UTC")
+	assert.Equal(t, len(report.Findings), 6)
+	assert.DeepEqual(t, report.Severities, []string{criticalCx, highCx, mediumCx, lowCx})
+	assert.DeepEqual(t, report.SeverityCounts[1], resultsHTMLCount{Name: highCx, Count: 3})
+	assert.DeepEqual(t, report.Engines, []string{"sca", "sast", "kics"})
+	assert.DeepEqual(t, report.States, []string{"NOT_EXPLOITABLE", "TO_VERIFY"})
+
+	sast := report.Findings[1]
+	assert.Equal(t, sast.Query, "SQL Injection")
+	assert.Equal(t, sast.File, "/src/app.js:3")
+	assert.Equal(t, len(sast.Nodes), 2)
+
+	lodash := report.Findings[2]
+	assert.Equal(t, lodash.Query, "CVE-2020-8203 lodash 4.17.15")
+	assert.DeepEqual(t, *lodash.Package, resultsHTMLPackage{
+		Name:               "lodash",
+		Version:            "4.17.15",
+		TypeOfDependency:   directDependencyType,
+		CVE:                "CVE-2020-8203",
+		CWE:                "CWE-1321",
+		RecommendedVersion: "4.17.21",
+		FixLink:            "https://devhub.checkmarx.com/cve-details/CVE-2020-8203",
+	})
+
+	kics := report.Findings[5]
+	assert.Equal(t, kics.File, "/main.tf:7")
+	assert.Equal(t, kics.ExpectedValue, "private")
+}
+
+func TestWriteHTMLResults(t *testing.T) {
+	results := glDependencyTestResults()
+	results.Results[0].Description = `<script>alert("x")</script>`
+	target := filepath.Join(t.TempDir(), "report.html")
+	assert.NilError(t, writeHTMLResults(target, results, glDependencyTestSummary()))
+	content, err := os.ReadFile(target)
+	assert.NilError(t, err)
+	html := string(content)
+
+	assert.Assert(t, !strings.Contains(html, `<script>alert`))
+	assert.Assert(t, strings.Contains(html, `&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;`))
+	// a single file, nothing is loaded from elsewhere
+	assert.Assert(t, !regexp.MustCompile(`(src|href)="(https?:)?//`).MatchString(strings.ReplaceAll(html,
+		`href="https://devhub.checkmarx.com/cve-details/CVE-2020-8203"`, "")))
+	assert.Assert(t, strings.Contains(html, `data-engine="sast" data-severity="HIGH" data-state="TO_VERIFY" data-file="/src/app.js:3" data-query="SQL Injection"`))
+	assert.Assert(t, strings.Contains(html, "Data flow (2 nodes)"))
+	assert.Assert(t, strings.Contains(html, `/src/db.js:9:2`))
+}
+
+func TestRunGetResultsByScanIdHTMLFormat(t *testing.T) {
+	execCmdNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "html")
+	reportFile := fmt.Sprintf("%s%s.html", fileName, resultsHTMLTypeLabel)
+	defer os.Remove(reportFile)
+	content, err := os.ReadFile(reportFile)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), `<tbody id="findings">`))
+}
+
+func TestToResultsHTMLReportEmpty(t *testing.T) {
+	report := toResultsHTMLReport(&wrappers.ScanResultsCollection{}, glDependencyTestSummary(), time.Now())
+	assert.Equal(t, len(report.Findings), 0)
+}
@@ -0,0 +1,456 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	failedImportingResults       = "Failed importing results"
+	resultsImportInvalidFormat   = "%s: invalid format %s, use one of %s"
+	resultsImportInvalidReport   = "%s: invalid report format %s, use one of %s"
+	resultsImportParseError      = "%s: failed to parse %s report"
+	glSastCxOneScanIdentifier    = "cxOneScan"
+	glSastCriticalSeverity       = "CRITICAL"
+	sonarBlockerSeverity         = "BLOCKER"
+	sarifNoneLevelSeverity       = infoCx
+	resultsImportStatusNew       = "NEW"
+	resultsImportStatusRecurrent = "RECURRENT"
+)
+
+var (
+	resultsImportFormats       = []string{printer.FormatSarif, printer.FormatGL, printer.FormatSonar}
+	resultsImportReportFormats = []string{
+		printer.FormatSummaryConsole,
+		printer.FormatSummary,
+		printer.FormatSummaryJSON,
+		printer.FormatSummaryMarkdown,
+		printer.FormatJSON,
+		printer.FormatSarif,
+		printer.FormatSonar,
+		printer.FormatSonarV10,
+		printer.FormatGL,
+		printer.FormatGLDependency,
+		printer.FormatGLContainer,
+		printer.FormatHTML,
+		printer.FormatCSV,
+		printer.FormatXLSX,
+	}
+	resultsImportEngines = []string{commonParams.SastType, commonParams.ScaType, commonParams.KicsType}
+	// Rule IDs of Checkmarx reports end with the engine, e.g. "12345 (sast)"
+	sarifRuleIDRegexp = regexp.MustCompile(`^(.*) \(([^()]+)\)$`)
+	// Descriptions of KICS results hold the value and the expected value, see findDescriptionText
+	kicsDescriptionRegexp = regexp.MustCompile(`(?s)^(.*) Value: (.*) Excepted value: (.*)$`)
+	sarifLevelSeverities  = map[string]string{
+		highSarif:    highCx,
+		mediumSarif:  mediumCx,
+		infoLowSarif: lowCx,
+		noneSarif:    sarifNoneLevelSeverity,
+	}
+)
+
+func resultImportSubCommand() *cobra.Command {
+	resultImportCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import results of other scanners",
+		Long: "The import command reads a SARIF, GitLab SAST or Sonar report and creates the same reports and threshold " +
+			"checks as the results of a scan.",
+		Example: heredoc.Doc(
+			`
+			$ cx results import --format sarif --file results.sarif --report-format summaryConsole,json
+			$ cx results import --format sonar --file sonar.json --threshold "sast-high=1"
+		`,
+		),
+		RunE: runImportResultsCommand,
+	}
+	resultImportCmd.PersistentFlags().String(commonParams.ResultsImportFileFlag, "", "Report file to import")
+	_ = resultImportCmd.MarkPersistentFlagRequired(commonParams.ResultsImportFileFlag)
+	resultImportCmd.PersistentFlags().String(
+		commonParams.FormatFlag, printer.FormatSarif,
+		fmt.Sprintf("Format of the imported report. Available options: %s", strings.Join(resultsImportFormats, ",")),
+	)
+	addResultFormatFlag(resultImportCmd, printer.FormatSummaryConsole, resultsImportReportFormats[1:]...)
+	resultImportCmd.PersistentFlags().String(commonParams.ReportColumnsFlag, "", spreadsheetColumnsFlagDescription+strings.Join(spreadsheetColumnNames(), ","))
+	resultImportCmd.PersistentFlags().String(commonParams.TargetFlag, "cx_result", "Output file")
+	resultImportCmd.PersistentFlags().String(commonParams.TargetPathFlag, ".", "Output Path")
+	resultImportCmd.PersistentFlags().String(commonParams.Threshold, "", commonParams.ThresholdFlagUsage)
+	return resultImportCmd
+}
+
+func runImportResultsCommand(cmd *cobra.Command, _ []string) error {
+	file, _ := cmd.Flags().GetString(commonParams.ResultsImportFileFlag)
+	format, _ := cmd.Flags().GetString(commonParams.FormatFlag)
+	reportFormats, _ := cmd.Flags().GetString(commonParams.TargetFormatFlag)
+	targetFile, _ := cmd.Flags().GetString(commonParams.TargetFlag)
+	targetPath, _ := cmd.Flags().GetString(commonParams.TargetPathFlag)
+	threshold, _ := cmd.Flags().GetString(commonParams.Threshold)
+	reportColumns, _ := cmd.Flags().GetString(commonParams.ReportColumnsFlag)
+
+	reportList := strings.Split(reportFormats, ",")
+	for _, reportFormat := range reportList {
+		if !containsFold(resultsImportReportFormats, reportFormat) {
+			return errors.Errorf(resultsImportInvalidReport, failedImportingResults, reportFormat, strings.Join(resultsImportReportFormats, ", "))
+		}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return errors.Wrapf(err, "%s", failedImportingResults)
+	}
+	results, err := importResults(data, format)
+	if err != nil {
+		return err
+	}
+
+	summary, err := summaryReport(importedResultsSummary(results), nil, nil, results)
+	if err != nil {
+		return err
+	}
+	err = createDirectory(targetPath)
+	if err != nil {
+		return err
+	}
+	for _, reportFormat := range reportList {
+		err = createReport(reportFormat, "", "", "", "", reportColumns, targetFile, targetPath, results, nil, summary, nil, nil, false, 0)
+		if err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(threshold) == "" {
+		return nil
+	}
+	return checkThreshold(threshold, summaryThresholdMap(results))
+}
+
+// importResults parses a report of the given format into results
+func importResults(data []byte, format string) (*wrappers.ScanResultsCollection, error) {
+	var results *wrappers.ScanResultsCollection
+	var err error
+	switch {
+	case printer.IsFormat(format, printer.FormatSarif):
+		results, err = importSarifResults(data)
+	case printer.IsFormat(format, printer.FormatGL):
+		results, err = importGlSastResults(data)
+	case printer.IsFormat(format, printer.FormatSonar):
+		results, err = importSonarResults(data)
+	default:
+		return nil, errors.Errorf(resultsImportInvalidFormat, failedImportingResults, format, strings.Join(resultsImportFormats, ", "))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, resultsImportParseError, failedImportingResults, format)
+	}
+	results.TotalCount = uint(len(results.Results))
+	return results, nil
+}
+
+// importedResultsSummary is the summary of a completed scan running the engines of the imported results
+func importedResultsSummary(results *wrappers.ScanResultsCollection) *wrappers.ResultSummary {
+	engines, _ := groupResultsByEngine(results)
+	return &wrappers.ResultSummary{
+		ScanID:         results.ScanID,
+		Status:         wrappers.ScanCompleted,
+		CreatedAt:      time.Now().Format(summaryCreatedAtLayout),
+		Tags:           map[string]string{},
+		EnginesEnabled: engines,
+	}
+}
+
+func importedEngine(engine string) string {
+	engine = strings.ToLower(strings.TrimSpace(engine))
+	if containsFold(resultsImportEngines, engine) {
+		return engine
+	}
+	return commonParams.SastType
+}
+
+func importSarifResults(data []byte) (*wrappers.ScanResultsCollection, error) {
+	var sarif wrappers.SarifResultsCollection
+	if err := json.Unmarshal(data, &sarif); err != nil {
+		return nil, err
+	}
+	results := &wrappers.ScanResultsCollection{Results: []*wrappers.ScanResult{}}
+	for i := range sarif.Runs {
+		run := &sarif.Runs[i]
+		runEngine, scanID := sarifRunEngine(run)
+		if results.ScanID == "" {
+			results.ScanID = scanID
+		}
+		rules := make(map[string]wrappers.SarifDriverRule)
+		for _, rule := range run.Tool.Driver.Rules {
+			rules[rule.ID] = rule
+		}
+		scaResults := make(map[string]*wrappers.ScanResult)
+		for j := range run.Results {
+			sarifResult := &run.Results[j]
+			if sarifResult.BaselineState == sarifBaselineAbsent {
+				continue
+			}
+			rule := rules[sarifResult.RuleID]
+			engine := runEngine
+			if engine == "" {
+				engine = sarifRuleEngine(sarifResult.RuleID, &rule)
+			}
+			result := newImportedSarifResult(engine, sarifResult, &rule)
+			if engine != commonParams.ScaType {
+				results.Results = append(results.Results, result)
+				continue
+			}
+			// SCA results are reported once per package file
+			key := sarifResult.RuleID + "/" + result.SimilarityID
+			location := sarifResultURI(sarifResult)
+			if scaResult, ok := scaResults[key]; ok {
+				scaResult.ScanResultData.ScaPackageCollection.Locations = append(scaResult.ScanResultData.ScaPackageCollection.Locations, &location)
+				continue
+			}
+			result.ScanResultData.ScaPackageCollection = &wrappers.ScaPackageCollection{
+				ID:        result.ScanResultData.PackageIdentifier,
+				Locations: []*string{&location},
+			}
+			scaResults[key] = result
+			results.Results = append(results.Results, result)
+		}
+	}
+	return results, nil
+}
+
+// sarifRunEngine reads the engine and the scan ID from the automation details of runs created by the CLI
+func sarifRunEngine(run *wrappers.SarifRun) (engine, scanID string) {
+	if run.AutomationDetails == nil {
+		return "", ""
+	}
+	parts := strings.Split(run.AutomationDetails.ID, "/")
+	if len(parts) != 3 || parts[0] != sarifAutomationCategory {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
+
+func sarifRuleEngine(ruleID string, rule *wrappers.SarifDriverRule) string {
+	if match := sarifRuleIDRegexp.FindStringSubmatch(ruleID); match != nil {
+		return importedEngine(match[2])
+	}
+	if tags := rule.Properties.Tags; len(tags) > 2 && tags[1] == "checkmarx" {
+		return importedEngine(tags[2])
+	}
+	return commonParams.SastType
+}
+
+func newImportedSarifResult(engine string, sarifResult *wrappers.SarifScanResult, rule *wrappers.SarifDriverRule) *wrappers.ScanResult {
+	ruleID := sarifResult.RuleID
+	if match := sarifRuleIDRegexp.FindStringSubmatch(ruleID); match != nil && match[2] == engine {
+		ruleID = match[1]
+	}
+	result := &wrappers.ScanResult{
+		Type:        engine,
+		Severity:    sarifSeverity(sarifResult.Level, rule.Properties.SecuritySeverity),
+		Description: rule.FullDescription.Text,
+	}
+	if result.Description == "" {
+		result.Description = sarifResult.Message.Text
+	}
+	if sarifResult.Properties != nil {
+		result.SimilarityID = sarifResult.Properties.SimilarityID
+		result.State = sarifResult.Properties.State
+	}
+	switch sarifResult.BaselineState {
+	case sarifBaselineNew:
+		result.Status = resultsImportStatusNew
+	case sarifBaselineUnchanged:
+		result.Status = resultsImportStatusRecurrent
+	}
+	name := rule.Name
+	if name == "" {
+		name = ruleID
+	}
+	switch engine {
+	case commonParams.ScaType:
+		result.ID = ruleID
+		result.ScanResultData.PackageIdentifier = strings.TrimSuffix(sarifResult.Message.Text, " ("+ruleID+")")
+	case commonParams.KicsType:
+		result.ScanResultData.QueryID = ruleID
+		result.ScanResultData.QueryName = name
+		if match := kicsDescriptionRegexp.FindStringSubmatch(result.Description); match != nil {
+			result.Description = match[1]
+			result.ScanResultData.Value = match[2]
+			result.ScanResultData.ExpectedValue = match[3]
+		}
+		if len(sarifResult.Locations) > 0 {
+			location := sarifResult.Locations[0].PhysicalLocation
+			result.ScanResultData.Filename = "/" + location.ArtifactLocation.URI
+			if location.Region != nil {
+				result.ScanResultData.Line = location.Region.StartLine
+			}
+		}
+	default:
+		result.ScanResultData.QueryID = ruleID
+		result.ScanResultData.QueryName = strings.ReplaceAll(name, " ", "_")
+		result.ScanResultData.Nodes = sarifResultNodes(sarifResult)
+	}
+	return result
+}
+
+// sarifSeverity prefers the security severity of the rule, LOW and INFO results share the same level
+func sarifSeverity(level, securitySeverity string) string {
+	for severity, score := range securities {
+		if score == securitySeverity {
+			return severity
+		}
+	}
+	if severity, ok := sarifLevelSeverities[level]; ok {
+		return severity
+	}
+	return mediumCx
+}
+
+// sarifResultNodes reads the data flow of the result from its code flow, or from its locations when it has none
+func sarifResultNodes(sarifResult *wrappers.SarifScanResult) []*wrappers.ScanResultNode {
+	locations := sarifResult.Locations
+	if len(sarifResult.CodeFlows) > 0 && len(sarifResult.CodeFlows[0].ThreadFlows) > 0 {
+		locations = []wrappers.SarifLocation{}
+		for _, threadFlowLocation := range sarifResult.CodeFlows[0].ThreadFlows[0].Locations {
+			locations = append(locations, threadFlowLocation.Location)
+		}
+	}
+	nodes := []*wrappers.ScanResultNode{}
+	for _, location := range locations {
+		node := &wrappers.ScanResultNode{FileName: "/" + location.PhysicalLocation.ArtifactLocation.URI}
+		if region := location.PhysicalLocation.Region; region != nil {
+			node.Line = region.StartLine
+			node.Column = region.StartColumn
+			if region.EndColumn > region.StartColumn {
+				node.Length = region.EndColumn - region.StartColumn
+			}
+		}
+		if location.Message != nil {
+			node.Name = location.Message.Text
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func sarifResultURI(sarifResult *wrappers.SarifScanResult) string {
+	if len(sarifResult.Locations) == 0 {
+		return ""
+	}
+	return sarifResult.Locations[0].PhysicalLocation.ArtifactLocation.URI
+}
+
+func importGlSastResults(data []byte) (*wrappers.ScanResultsCollection, error) {
+	var glSast wrappers.GlSastResultsCollection
+	if err := json.Unmarshal(data, &glSast); err != nil {
+		return nil, err
+	}
+	results := &wrappers.ScanResultsCollection{Results: []*wrappers.ScanResult{}}
+	for i := range glSast.Vulnerabilities {
+		vulnerability := &glSast.Vulnerabilities[i]
+		severity := strings.ToUpper(vulnerability.Severity)
+		if severity == glSastCriticalSeverity {
+			severity = highCx
+		} else if _, ok := securities[severity]; !ok {
+			severity = infoCx
+		}
+		result := &wrappers.ScanResult{
+			Type:        importedEngine(strings.TrimPrefix(vulnerability.Category, wrappers.VendorName+"-")),
+			ID:          vulnerability.ID,
+			Severity:    severity,
+			Description: vulnerability.Description,
+			ScanResultData: wrappers.ScanResultData{
+				QueryName: vulnerability.Name,
+				Nodes: []*wrappers.ScanResultNode{{
+					FileName: vulnerability.Location.File,
+					Line:     vulnerability.Location.StartLine,
+				}},
+			},
+		}
+		if vulnerability.Location.EndLine > vulnerability.Location.StartLine {
+			result.ScanResultData.Nodes[0].Length = vulnerability.Location.EndLine - vulnerability.Location.StartLine
+		}
+		for _, identifier := range vulnerability.Identifiers {
+			if identifier.Type == glSastCxOneScanIdentifier {
+				result.ID = identifier.Value
+			}
+		}
+		results.Results = append(results.Results, result)
+	}
+	return results, nil
+}
+
+func importSonarResults(data []byte) (*wrappers.ScanResultsCollection, error) {
+	var sonar wrappers.ScanResultsSonar
+	if err := json.Unmarshal(data, &sonar); err != nil {
+		return nil, err
+	}
+	results := &wrappers.ScanResultsCollection{Results: []*wrappers.ScanResult{}}
+	scaResults := make(map[string]*wrappers.ScanResult)
+	for i := range sonar.Results {
+		issue := &sonar.Results[i]
+		result := &wrappers.ScanResult{
+			Type:     importedEngine(issue.EngineID),
+			ID:       issue.RuleID,
+			Severity: sonarSeverity(issue.Severity),
+		}
+		primaryLocation := issue.PrimaryLocation
+		switch result.Type {
+		case commonParams.ScaType:
+			location := primaryLocation.FilePath
+			key := issue.RuleID + "/" + primaryLocation.Message
+			if scaResult, ok := scaResults[key]; ok {
+				scaResult.ScanResultData.ScaPackageCollection.Locations = append(scaResult.ScanResultData.ScaPackageCollection.Locations, &location)
+				continue
+			}
+			result.ScanResultData.PackageIdentifier = strings.TrimSuffix(primaryLocation.Message, " ("+issue.RuleID+")")
+			result.ScanResultData.ScaPackageCollection = &wrappers.ScaPackageCollection{
+				ID:        result.ScanResultData.PackageIdentifier,
+				Locations: []*string{&location},
+			}
+			scaResults[key] = result
+		case commonParams.KicsType:
+			result.ScanResultData.Filename = "/" + primaryLocation.FilePath
+			result.ScanResultData.Line = primaryLocation.TextRange.StartLine
+			result.ScanResultData.Value = primaryLocation.Message
+		default:
+			result.ScanResultData.QueryName = strings.ReplaceAll(primaryLocation.Message, " ", "_")
+			result.ScanResultData.Nodes = []*wrappers.ScanResultNode{sonarLocationNode(&primaryLocation)}
+			for j := range issue.SecondaryLocations {
+				result.ScanResultData.Nodes = append(result.ScanResultData.Nodes, sonarLocationNode(&issue.SecondaryLocations[j]))
+			}
+		}
+		results.Results = append(results.Results, result)
+	}
+	return results, nil
+}
+
+func sonarSeverity(severity string) string {
+	if strings.EqualFold(severity, sonarBlockerSeverity) {
+		return highCx
+	}
+	for cxSeverity, sonarSeverity := range sonarSeverities {
+		if strings.EqualFold(sonarSeverity, severity) {
+			return cxSeverity
+		}
+	}
+	return infoCx
+}
+
+func sonarLocationNode(location *wrappers.SonarLocation) *wrappers.ScanResultNode {
+	node := &wrappers.ScanResultNode{
+		FileName: "/" + location.FilePath,
+		Line:     location.TextRange.StartLine,
+		Column:   location.TextRange.StartColumn,
+	}
+	if location.TextRange.EndColumn > location.TextRange.StartColumn {
+		node.Length = location.TextRange.EndColumn - location.TextRange.StartColumn
+	}
+	return node
+}
@@ -0,0 +1,157 @@
+//go:build !integration
+
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"gotest.tools/assert"
+)
+
+func importTestResults() *wrappers.ScanResultsCollection {
+	packageFile := "package.json"
+	lockFile := "package-lock.json"
+	return &wrappers.ScanResultsCollection{
+		ScanID: "scan-id",
+		Results: []*wrappers.ScanResult{
+			{
+				Type:         params.SastType,
+				ID:           "sast-id",
+				SimilarityID: "-123",
+				State:        "TO_VERIFY",
+				Severity:     highCx,
+				Description:  "SQL injection",
+				ScanResultData: wrappers.ScanResultData{
+					QueryID:   "5157925289005576664",
+					QueryName: "SQL_Injection",
+					Nodes: []*wrappers.ScanResultNode{
+						{FileName: "/src/app.js", Line: 3, Column: 1, Length: 5, Name: "input"},
+						{FileName: "/src/db.js", Line: 9, Column: 2, Length: 4, Name: "query"},
+					},
+				},
+			},
+			{
+				Type:        params.ScaType,
+				ID:          "CVE-2021-23337",
+				State:       "NOT_EXPLOITABLE",
+				Severity:    mediumCx,
+				Description: "Command injection in lodash",
+				ScanResultData: wrappers.ScanResultData{
+					PackageIdentifier: "Npm-lodash-4.17.15",
+					ScaPackageCollection: &wrappers.ScaPackageCollection{
+						ID:        "Npm-lodash-4.17.15",
+						Locations: []*string{&packageFile, &lockFile},
+					},
+				},
+			},
+			{
+				Type:         params.KicsType,
+				ID:           "kics-id",
+				SimilarityID: "abc",
+				Severity:     lowCx,
+				Description:  "Bucket is public",
+				ScanResultData: wrappers.ScanResultData{
+					QueryID:       "f6cc7a1b",
+					QueryName:     "S3 Bucket Public",
+					Filename:      "/main.tf",
+					Line:          7,
+					Value:         "public-read",
+					ExpectedValue: "private",
+				},
+			},
+		},
+	}
+}
+
+func TestImportSarifRoundTrip(t *testing.T) {
+	sarifJSON, err := json.Marshal(convertCxResultsToSarif(importTestResults(), nil))
+	assert.NilError(t, err)
+
+	imported, err := importResults(sarifJSON, "sarif")
+	assert.NilError(t, err)
+	assert.Equal(t, imported.ScanID, "scan-id")
+	assert.Equal(t, imported.TotalCount, uint(3))
+	assert.Equal(t, len(imported.Results[1].ScanResultData.ScaPackageCollection.Locations), 2)
+	assert.Equal(t, imported.Results[1].State, "NOT_EXPLOITABLE")
+
+	roundTripJSON, err := json.Marshal(convertCxResultsToSarif(imported, nil))
+	assert.NilError(t, err)
+	assert.Equal(t, string(roundTripJSON), string(sarifJSON))
+}
+
+func TestImportSonarRoundTrip(t *testing.T) {
+	sonarJSON, err := json.Marshal(convertCxResultsToSonar(importTestResults()))
+	assert.NilError(t, err)
+
+	imported, err := importResults(sonarJSON, "sonar")
+	assert.NilError(t, err)
+	assert.Equal(t, imported.Results[0].Severity, highCx)
+
+	roundTripJSON, err := json.Marshal(convertCxResultsToSonar(imported))
+	assert.NilError(t, err)
+	assert.Equal(t, string(roundTripJSON), string(sonarJSON))
+}
+
+func TestImportGlSast(t *testing.T) {
+	glSast := &wrappers.GlSastResultsCollection{}
+	convertCxResultToGlVulnerability(importTestResults(), glSast, "")
+	glSastJSON, err := json.Marshal(glSast)
+	assert.NilError(t, err)
+
+	imported, err := importResults(glSastJSON, "gl-sast")
+	assert.NilError(t, err)
+	assert.Equal(t, len(imported.Results), 1)
+	result := imported.Results[0]
+	assert.Equal(t, result.Type, params.SastType)
+	assert.Equal(t, result.ID, "sast-id")
+	assert.Equal(t, result.Severity, highCx)
+	assert.Equal(t, result.ScanResultData.QueryName, "SQL_Injection")
+	assert.Equal(t, result.ScanResultData.Nodes[0].FileName, "/src/app.js")
+	assert.Equal(t, result.ScanResultData.Nodes[0].Line, uint(3))
+}
+
+func TestImportThirdPartySarif(t *testing.T) {
+	sarifJSON := `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "other", "rules": [
+		{"id": "py/sql-injection", "name": "SqlInjection", "fullDescription": {"text": "SQL built from user input"}}]}},
+		"results": [{"ruleId": "py/sql-injection", "level": "warning", "message": {"text": "Injection"},
+		"locations": [{"physicalLocation": {"artifactLocation": {"uri": "app.py"}, "region": {"startLine": 4}}}]}]}]}`
+
+	imported, err := importResults([]byte(sarifJSON), "sarif")
+	assert.NilError(t, err)
+	result := imported.Results[0]
+	assert.Equal(t, result.Type, params.SastType)
+	assert.Equal(t, result.Severity, mediumCx)
+	assert.Equal(t, result.Description, "SQL built from user input")
+	assert.Equal(t, result.ScanResultData.QueryID, "py/sql-injection")
+	assert.Equal(t, result.ScanResultData.Nodes[0].FileName, "/app.py")
+	assert.Equal(t, result.ScanResultData.Nodes[0].Line, uint(4))
+}
+
+func TestRunImportResultsCommand(t *testing.T) {
+	dir := t.TempDir()
+	sarifFile := filepath.Join(dir, "other.sarif")
+	sarifJSON, err := json.Marshal(convertCxResultsToSarif(importTestResults(), nil))
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(sarifFile, sarifJSON, 0600))
+
+	execCmdNilAssertion(t, "results", "import", "--file", sarifFile, "--report-format", "json,summaryHTML", "--output-path", dir)
+	resultsJSON, err := os.ReadFile(filepath.Join(dir, "cx_result.json"))
+	assert.NilError(t, err)
+	var results wrappers.ScanResultsCollection
+	assert.NilError(t, json.Unmarshal(resultsJSON, &results))
+	assert.Equal(t, len(results.Results), 3)
+
+	err = execCmdNotNilAssertion(t, "results", "import", "--file", sarifFile, "--output-path", dir, "--threshold", "sast-high=1")
+	assert.ErrorContains(t, err, "Threshold check finished with status Failed")
+
+	err = execCmdNotNilAssertion(t, "results", "import", "--file", sarifFile, "--format", "xml")
+	assert.Equal(t, err.Error(), "Failed importing results: invalid format xml, use one of sarif, gl-sast, sonar")
+
+	err = execCmdNotNilAssertion(t, "results", "import", "--file", sarifFile, "--report-format", "pdf")
+	assert.ErrorContains(t, err, "Failed importing results: invalid report format pdf")
+}
@@ -0,0 +1,348 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfModeLocal           = "local"
+	pdfModeServer          = "server"
+	pdfModeAuto            = "auto"
+	pdfModeFlagDescription = "Where the PDF report is rendered: server uses the report service, local renders it in the CLI " +
+		"and auto renders it locally when the report service fails. Available options: local,server,auto"
+	pdfPageMargin      = 15.0
+	pdfLineHeight      = 6.0
+	pdfTableLineHeight = 5.5
+	pdfTitleFontSize   = 18.0
+	pdfHeadingFontSize = 13.0
+	pdfTextFontSize    = 10.0
+	pdfTableFontSize   = 8.0
+	pdfFontFamily      = "Helvetica"
+	pdfEllipsis        = "..."
+	pdfScanSummary     = "ScanSummary"
+	pdfExecutive       = "ExecutiveSummary"
+	pdfScanResults     = "ScanResults"
+)
+
+var pdfModes = []string{pdfModeLocal, pdfModeServer, pdfModeAuto}
+
+// pdfEngineTypes maps the engines of parsePDFOptions to the types of the results
+var pdfEngineTypes = map[string]string{
+	"SAST": commonParams.SastType,
+	"SCA":  commonParams.ScaType,
+	"KICS": commonParams.KicsType,
+}
+
+var pdfEngineTitles = map[string]string{
+	"SAST": "SAST",
+	"SCA":  "SCA",
+	"KICS": "IaC Security",
+}
+
+// pdfSeverityColors are the colors of the summary HTML report
+var pdfSeverityColors = map[string][3]int{
+	criticalCx: {164, 0, 30},
+	highCx:     {241, 96, 93},
+	mediumCx:   {249, 174, 77},
+	lowCx:      {189, 189, 189},
+	infoCx:     {140, 138, 146},
+}
+
+// pdfTableColumn is a column of a findings table, the widths of the columns of a table fill the page
+type pdfTableColumn struct {
+	title string
+	width float64
+	value func(result *wrappers.ScanResult) string
+}
+
+// localPdfReport renders the PDF report in the CLI, with the sections and engines of --report-pdf-options
+type localPdfReport struct {
+	pdf       *fpdf.Fpdf
+	translate func(string) string
+	summary   *wrappers.ResultSummary
+	results   *wrappers.ScanResultsCollection
+}
+
+func exportLocalPdfResults(summary *wrappers.ResultSummary, results *wrappers.ScanResultsCollection, targetFile string,
+	sections, engines []string) error {
+	pdf := newLocalPdf()
+	report := &localPdfReport{
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+		summary:   summary,
+		results:   results,
+	}
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfPageMargin + 5)
+		pdf.SetFont(pdfFontFamily, "", pdfTableFontSize)
+		pdf.SetTextColor(149, 147, 155)
+		pdf.CellFormat(0, pdfTableLineHeight, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	report.title()
+	for _, section := range sections {
+		switch section {
+		case pdfScanSummary:
+			report.scanSummary()
+		case pdfExecutive:
+			report.executiveSummary(engines)
+		case pdfScanResults:
+			for _, engine := range engines {
+				report.engineResults(engine)
+			}
+		}
+	}
+	return pdf.OutputFileAndClose(targetFile)
+}
+
+func newLocalPdf() *fpdf.Fpdf {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfPageMargin, pdfPageMargin, pdfPageMargin)
+	pdf.SetAutoPageBreak(true, pdfPageMargin)
+	pdf.SetTitle("Checkmarx One Scan Report", true)
+	pdf.SetCreator("Checkmarx One CLI "+commonParams.Version, true)
+	return pdf
+}
+
+func (r *localPdfReport) title() {
+	r.pdf.SetFont(pdfFontFamily, "B", pdfTitleFontSize)
+	r.pdf.SetTextColor(86, 83, 96)
+	r.pdf.CellFormat(0, 10, "Checkmarx One Scan Report", "", 1, "L", false, 0, "")
+	r.pdf.SetFont(pdfFontFamily, "", pdfTextFontSize)
+	r.pdf.CellFormat(0, pdfLineHeight, r.translate(fmt.Sprintf("%s - %s", r.summary.ProjectName, r.summary.CreatedAt)), "", 1, "L", false, 0, "")
+	r.pdf.Ln(4)
+}
+
+func (r *localPdfReport) heading(text string) {
+	r.pdf.Ln(2)
+	r.pdf.SetFont(pdfFontFamily, "B", pdfHeadingFontSize)
+	r.pdf.SetTextColor(86, 83, 96)
+	r.pdf.CellFormat(0, 8, r.translate(text), "B", 1, "L", false, 0, "")
+	r.pdf.Ln(2)
+	r.pdf.SetFont(pdfFontFamily, "", pdfTextFontSize)
+}
+
+func (r *localPdfReport) field(name, value string) {
+	if value == "" {
+		return
+	}
+	r.pdf.SetFont(pdfFontFamily, "B", pdfTextFontSize)
+	r.pdf.CellFormat(40, pdfLineHeight, r.translate(name), "", 0, "L", false, 0, "")
+	r.pdf.SetFont(pdfFontFamily, "", pdfTextFontSize)
+	r.pdf.MultiCell(0, pdfLineHeight, r.translate(value), "", "L", false)
+}
+
+func (r *localPdfReport) scanSummary() {
+	r.heading("Scan Summary")
+	r.field("Project", r.summary.ProjectName)
+	r.field("Branch", r.summary.BranchName)
+	r.field("Scan ID", r.summary.ScanID)
+	r.field("Created", r.summary.CreatedAt)
+	r.field("Status", r.summary.Status)
+	r.field("Engines", strings.Join(r.summary.EnginesEnabled, ", "))
+	if len(r.summary.Tags) > 0 {
+		tags := make([]string, 0, len(r.summary.Tags))
+		for key, value := range r.summary.Tags {
+			if value != "" {
+				key += ":" + value
+			}
+			tags = append(tags, key)
+		}
+		sort.Strings(tags)
+		r.field("Tags", strings.Join(tags, ", "))
+	}
+	r.field("Details", r.summary.BaseURI)
+}
+
+func (r *localPdfReport) executiveSummary(engines []string) {
+	r.heading("Executive Summary")
+	r.field("Risk", r.summary.RiskMsg)
+	r.field("Total issues", strconv.Itoa(r.summary.TotalIssues))
+	r.pdf.Ln(2)
+
+	severities := []struct {
+		name  string
+		count int
+	}{
+		{highCx, r.summary.HighIssues},
+		{mediumCx, r.summary.MediumIssues},
+		{lowCx, r.summary.LowIssues},
+		{infoCx, r.summary.InfoIssues},
+	}
+	pageWidth, _ := r.pdf.GetPageSize()
+	width := (pageWidth - 2*pdfPageMargin) / float64(len(severities))
+	for _, severity := range severities {
+		color := pdfSeverityColors[severity.name]
+		r.pdf.SetFillColor(color[0], color[1], color[2])
+		r.pdf.SetTextColor(252, 253, 255)
+		r.pdf.SetFont(pdfFontFamily, "B", pdfHeadingFontSize)
+		r.pdf.CellFormat(width, 10, strconv.Itoa(severity.count), "", 0, "C", true, 0, "")
+	}
+	r.pdf.Ln(-1)
+	r.pdf.SetFont(pdfFontFamily, "", pdfTableFontSize)
+	for _, severity := range severities {
+		color := pdfSeverityColors[severity.name]
+		r.pdf.SetFillColor(color[0], color[1], color[2])
+		r.pdf.CellFormat(width, pdfTableLineHeight, severity.name, "", 0, "C", true, 0, "")
+	}
+	r.pdf.Ln(-1)
+	r.pdf.SetTextColor(86, 83, 96)
+	r.pdf.Ln(4)
+
+	rows := [][]string{}
+	for _, engine := range engines {
+		rows = append(rows, []string{pdfEngineTitles[engine], strconv.Itoa(r.engineIssues(engine))})
+	}
+	r.table([]string{"Engine", "Issues"}, []float64{90, 90}, rows)
+	r.policies()
+}
+
+func (r *localPdfReport) engineIssues(engine string) int {
+	switch engine {
+	case "SAST":
+		return r.summary.SastIssues
+	case "SCA":
+		return r.summary.ScaIssues
+	case "KICS":
+		return r.summary.KicsIssues
+	}
+	return 0
+}
+
+func (r *localPdfReport) policies() {
+	policies := r.summary.Policies
+	if policies == nil || policies.Status == "" || strings.EqualFold(policies.Status, policeManagementNoneStatus) {
+		return
+	}
+	r.pdf.Ln(2)
+	r.field("Policy status", policies.Status)
+	r.field("Break build", strconv.FormatBool(policies.BreakBuild))
+	if len(policies.Polices) == 0 {
+		return
+	}
+	rows := make([][]string, 0, len(policies.Polices))
+	for _, policy := range policies.Polices {
+		rows = append(rows, []string{policy.Name, strings.Join(policy.RulesViolated, ", "), strconv.FormatBool(policy.BreakBuild)})
+	}
+	r.pdf.Ln(2)
+	r.table([]string{"Policy", "Violated rules", "Break build"}, []float64{60, 90, 30}, rows)
+}
+
+func (r *localPdfReport) engineResults(engine string) {
+	resultType := pdfEngineTypes[engine]
+	var engineResults []*wrappers.ScanResult
+	if r.results != nil {
+		for _, result := range r.results.Results {
+			if strings.TrimSpace(result.Type) == resultType {
+				engineResults = append(engineResults, result)
+			}
+		}
+	}
+	sort.SliceStable(engineResults, func(i, j int) bool {
+		return resultsHTMLSeverityRank(strings.ToUpper(engineResults[i].Severity)) <
+			resultsHTMLSeverityRank(strings.ToUpper(engineResults[j].Severity))
+	})
+	r.heading(fmt.Sprintf("%s Results (%d)", pdfEngineTitles[engine], len(engineResults)))
+	if len(engineResults) == 0 {
+		r.pdf.CellFormat(0, pdfLineHeight, "No results", "", 1, "L", false, 0, "")
+		return
+	}
+	columns := pdfEngineColumns(resultType)
+	titles := make([]string, len(columns))
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		titles[i], widths[i] = column.title, column.width
+	}
+	rows := make([][]string, 0, len(engineResults))
+	for _, result := range engineResults {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.value(result)
+		}
+		rows = append(rows, row)
+	}
+	r.table(titles, widths, rows)
+}
+
+func pdfEngineColumns(resultType string) []pdfTableColumn {
+	severity := pdfTableColumn{"Severity", 20, func(result *wrappers.ScanResult) string { return result.Severity }}
+	state := pdfTableColumn{"State", 32, func(result *wrappers.ScanResult) string { return result.State }}
+	switch resultType {
+	case commonParams.SastType:
+		return []pdfTableColumn{severity,
+			{"Query", 50, func(result *wrappers.ScanResult) string {
+				return strings.ReplaceAll(result.ScanResultData.QueryName, "_", " ")
+			}},
+			{"Location", 78, func(result *wrappers.ScanResult) string {
+				if len(result.ScanResultData.Nodes) == 0 {
+					return ""
+				}
+				node := result.ScanResultData.Nodes[0]
+				return fmt.Sprintf("%s:%d", node.FileName, node.Line)
+			}},
+			state,
+		}
+	case commonParams.ScaType:
+		return []pdfTableColumn{severity,
+			{"Vulnerability", 36, func(result *wrappers.ScanResult) string { return result.ID }},
+			{"Package", 54, func(result *wrappers.ScanResult) string {
+				_, name, version := glScaPackage(result)
+				return strings.TrimSpace(name + " " + version)
+			}},
+			{"Recommended", 38, glRecommendedVersion},
+			state,
+		}
+	default:
+		return []pdfTableColumn{severity,
+			{"Query", 60, func(result *wrappers.ScanResult) string { return result.ScanResultData.QueryName }},
+			{"Location", 68, func(result *wrappers.ScanResult) string {
+				return fmt.Sprintf("%s:%d", result.ScanResultData.Filename, result.ScanResultData.Line)
+			}},
+			state,
+		}
+	}
+}
+
+// table writes a table with a row per line, the values too long for their column are shortened
+func (r *localPdfReport) table(titles []string, widths []float64, rows [][]string) {
+	header := func() {
+		r.pdf.SetFont(pdfFontFamily, "B", pdfTableFontSize)
+		r.pdf.SetFillColor(236, 236, 239)
+		for i, title := range titles {
+			r.pdf.CellFormat(widths[i], pdfTableLineHeight+1, title, "1", 0, "L", true, 0, "")
+		}
+		r.pdf.Ln(-1)
+		r.pdf.SetFont(pdfFontFamily, "", pdfTableFontSize)
+	}
+	_, pageHeight := r.pdf.GetPageSize()
+	header()
+	for _, row := range rows {
+		if r.pdf.GetY()+pdfTableLineHeight > pageHeight-pdfPageMargin {
+			r.pdf.AddPage()
+			header()
+		}
+		for i, value := range row {
+			r.pdf.CellFormat(widths[i], pdfTableLineHeight, r.fit(r.translate(value), widths[i]), "1", 0, "L", false, 0, "")
+		}
+		r.pdf.Ln(-1)
+	}
+}
+
+// fit shortens a value to the width of a cell, keeping its end since paths are more specific at the end
+func (r *localPdfReport) fit(value string, width float64) string {
+	width -= 2 * r.pdf.GetCellMargin()
+	if r.pdf.GetStringWidth(value) <= width {
+		return value
+	}
+	for len(value) > 0 && r.pdf.GetStringWidth(pdfEllipsis+value) > width {
+		value = value[1:]
+	}
+	return pdfEllipsis + value
+}
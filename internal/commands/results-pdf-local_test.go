@@ -0,0 +1,95 @@
+//go:build !integration
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+// failingPdfWrapper is a report service that is not available
+type failingPdfWrapper struct {
+	mock.ResultsPdfWrapper
+}
+
+func (*failingPdfWrapper) GeneratePdfReport(_ *wrappers.PdfReportsPayload) (*wrappers.PdfReportsResponse, *wrappers.WebError, error) {
+	return nil, nil, errors.New("service unavailable")
+}
+
+func localPdfTestSummary() *wrappers.ResultSummary {
+	summary := glDependencyTestSummary()
+	summary.ProjectName = "project"
+	summary.ScanID = "MOCK"
+	summary.EnginesEnabled = []string{"sast", "sca", "kics"}
+	summary.Policies = &wrappers.PolicyResponseModel{
+		Status: "Completed",
+		Polices: []wrappers.Policy{
+			{Name: "No critical", RulesViolated: []string{"critical-severity"}, BreakBuild: true},
+		},
+	}
+	return summary
+}
+
+func assertPdfFile(t *testing.T, pdfFile string) {
+	content, err := os.ReadFile(pdfFile)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(string(content), "%PDF"))
+}
+
+func TestExportLocalPdfResults(t *testing.T) {
+	pdfFile := filepath.Join(t.TempDir(), "report.pdf")
+	sections, engines, err := parsePDFOptions(defaultPdfOptionsDataSections, []string{"sast", "sca", "kics"})
+	assert.NilError(t, err)
+	err = exportLocalPdfResults(localPdfTestSummary(), glDependencyTestResults(), pdfFile, sections, engines)
+	assert.NilError(t, err)
+	assertPdfFile(t, pdfFile)
+}
+
+func TestExportPdfResultsAutoFallsBackToLocal(t *testing.T) {
+	pdfFile := filepath.Join(t.TempDir(), "report.pdf")
+	err := exportPdfResults(&failingPdfWrapper{}, localPdfTestSummary(), glDependencyTestResults(), pdfFile, "",
+		defaultPdfOptionsDataSections, pdfModeAuto)
+	assert.NilError(t, err)
+	assertPdfFile(t, pdfFile)
+}
+
+func TestExportPdfResultsServerDoesNotFallBack(t *testing.T) {
+	pdfFile := filepath.Join(t.TempDir(), "report.pdf")
+	err := exportPdfResults(&failingPdfWrapper{}, localPdfTestSummary(), glDependencyTestResults(), pdfFile, "",
+		defaultPdfOptionsDataSections, pdfModeServer)
+	assert.ErrorContains(t, err, "service unavailable")
+	_, err = os.Stat(pdfFile)
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestLocalPdfReportFit(t *testing.T) {
+	report := &localPdfReport{pdf: newLocalPdf()}
+	report.pdf.SetFont(pdfFontFamily, "", pdfTableFontSize)
+	assert.Equal(t, report.fit("main.go", 40), "main.go")
+	fitted := report.fit(strings.Repeat("directory/", 20)+"main.go", 40)
+	assert.Assert(t, strings.HasPrefix(fitted, pdfEllipsis))
+	assert.Assert(t, strings.HasSuffix(fitted, "main.go"))
+}
+
+func TestRunGetResultsByScanIdLocalPDFFormat(t *testing.T) {
+	pdfFile := fmt.Sprintf("%s.%s", fileName, printer.FormatPDF)
+	defer os.Remove(pdfFile)
+	execCmdNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "pdf",
+		"--report-pdf-mode", "local", "--report-pdf-options", "Sast,Sca,ScanSummary,ScanResults")
+	assertPdfFile(t, pdfFile)
+}
+
+func TestRunGetResultsByScanIdInvalidPDFMode(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "pdf",
+		"--report-pdf-mode", "invalid")
+	assert.ErrorContains(t, err, "invalid PDF report mode \"invalid\", available options: local,server,auto")
+}
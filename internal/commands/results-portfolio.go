@@ -0,0 +1,414 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	htmlTemplate "html/template"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	failedGettingPortfolio      = "Failed getting the portfolio report"
+	portfolioInvalidFormat      = "%s: invalid format %s, use one of %s"
+	portfolioDefaultParallelism = 4
+	portfolioProjectsPageSize   = 100
+	portfolioCompletedStatus    = "Completed"
+	portfolioLatestScanSort     = "-created_at"
+	portfolioNoCompletedScan    = "No completed scan"
+	portfolioTimeFormat         = "2006-01-02 15:04:05 MST"
+)
+
+var portfolioFormats = []string{printer.FormatJSON, printer.FormatSummaryMarkdown, printer.FormatHTML}
+
+type portfolioReport struct {
+	GeneratedAt string             `json:"generatedAt"`
+	Totals      portfolioTotals    `json:"totals"`
+	Projects    []portfolioProject `json:"projects"`
+}
+
+type portfolioTotals struct {
+	Projects         int `json:"projects"`
+	ScannedProjects  int `json:"scannedProjects"`
+	PolicyViolations int `json:"policyViolations"`
+	TotalIssues      int `json:"totalIssues"`
+	HighIssues       int `json:"highIssues"`
+	MediumIssues     int `json:"mediumIssues"`
+	LowIssues        int `json:"lowIssues"`
+	InfoIssues       int `json:"infoIssues"`
+	SastIssues       int `json:"sastIssues"`
+	ScaIssues        int `json:"scaIssues"`
+	KicsIssues       int `json:"kicsIssues"`
+}
+
+// portfolioProject is the latest completed scan of a project, Rank orders the projects by risk
+type portfolioProject struct {
+	Rank             int      `json:"rank"`
+	ProjectID        string   `json:"projectId"`
+	ProjectName      string   `json:"projectName"`
+	Branch           string   `json:"branch,omitempty"`
+	ScanID           string   `json:"scanId,omitempty"`
+	ScanCreatedAt    string   `json:"scanCreatedAt,omitempty"`
+	ScanURL          string   `json:"scanUrl,omitempty"`
+	RiskMsg          string   `json:"risk"`
+	TotalIssues      int      `json:"totalIssues"`
+	HighIssues       int      `json:"highIssues"`
+	MediumIssues     int      `json:"mediumIssues"`
+	LowIssues        int      `json:"lowIssues"`
+	InfoIssues       int      `json:"infoIssues"`
+	SastIssues       int      `json:"sastIssues"`
+	ScaIssues        int      `json:"scaIssues"`
+	KicsIssues       int      `json:"kicsIssues"`
+	PolicyStatus     string   `json:"policyStatus,omitempty"`
+	BreakBuild       bool     `json:"breakBuild"`
+	ViolatedPolicies []string `json:"violatedPolicies,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// portfolioWrappers are the wrappers each project of the portfolio is read with
+type portfolioWrappers struct {
+	projects      wrappers.ProjectsWrapper
+	scans         wrappers.ScansWrapper
+	results       wrappers.ResultsWrapper
+	risksOverview wrappers.RisksOverviewWrapper
+	policy        wrappers.PolicyWrapper
+}
+
+func resultPortfolioSubCommand(
+	resultsWrapper wrappers.ResultsWrapper,
+	scanWrapper wrappers.ScansWrapper,
+	projectsWrapper wrappers.ProjectsWrapper,
+	risksOverviewWrapper wrappers.RisksOverviewWrapper,
+	policyWrapper wrappers.PolicyWrapper,
+) *cobra.Command {
+	portfolioCmd := &cobra.Command{
+		Use:   "portfolio",
+		Short: "Summarize the latest scans of several projects",
+		Long: "The portfolio command reads the latest completed scan of each project with the given tags or IDs " +
+			"and ranks the projects by risk, with their results and policy status.",
+		Example: heredoc.Doc(
+			`
+			$ cx results portfolio --project-tags team:payments
+			$ cx results portfolio --project-ids <project Id>,<project Id> --branch main --format html > portfolio.html
+		`,
+		),
+		RunE: runGetPortfolioCommand(&portfolioWrappers{
+			projects:      projectsWrapper,
+			scans:         scanWrapper,
+			results:       resultsWrapper,
+			risksOverview: risksOverviewWrapper,
+			policy:        policyWrapper,
+		}),
+	}
+	portfolioCmd.PersistentFlags().String(commonParams.ProjectTagList, "",
+		"Projects with all of these tags, e.g. team:payments,critical")
+	portfolioCmd.PersistentFlags().StringSlice(commonParams.ProjectIDsFlag, []string{}, "IDs of the projects")
+	portfolioCmd.PersistentFlags().String(commonParams.BranchFlag, "",
+		"Branch of the scans, the main branch of each project by default, else its latest scan")
+	portfolioCmd.PersistentFlags().Int(commonParams.ParallelismFlag, portfolioDefaultParallelism,
+		"Number of projects read at the same time")
+	addFormatFlag(portfolioCmd, printer.FormatSummaryMarkdown, printer.FormatJSON, printer.FormatHTML)
+	return portfolioCmd
+}
+
+func runGetPortfolioCommand(portfolioWrappers *portfolioWrappers) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		projectTags, _ := cmd.Flags().GetString(commonParams.ProjectTagList)
+		projectIDs, _ := cmd.Flags().GetStringSlice(commonParams.ProjectIDsFlag)
+		branch, _ := cmd.Flags().GetString(commonParams.BranchFlag)
+		parallelism, _ := cmd.Flags().GetInt(commonParams.ParallelismFlag)
+		format, _ := cmd.Flags().GetString(commonParams.FormatFlag)
+		if strings.TrimSpace(projectTags) == "" && len(projectIDs) == 0 {
+			return errors.Errorf("%s: Please provide --%s or --%s", failedGettingPortfolio, commonParams.ProjectTagList,
+				commonParams.ProjectIDsFlag)
+		}
+		if parallelism < 1 {
+			return errors.Errorf("%s: --%s should be at least 1", failedGettingPortfolio, commonParams.ParallelismFlag)
+		}
+		if !containsFold(portfolioFormats, format) {
+			return errors.Errorf(portfolioInvalidFormat, failedGettingPortfolio, format, strings.Join(portfolioFormats, ", "))
+		}
+		projects, err := findPortfolioProjects(portfolioWrappers.projects, createTagMap(projectTags), projectIDs)
+		if err != nil {
+			return err
+		}
+		report := &portfolioReport{
+			GeneratedAt: time.Now().Format(portfolioTimeFormat),
+			Projects:    readPortfolioProjects(portfolioWrappers, projects, branch, parallelism),
+		}
+		rankPortfolioProjects(report)
+		return printPortfolioReport(cmd.OutOrStdout(), report, format)
+	}
+}
+
+// findPortfolioProjects lists the projects with every tag, and the projects of projectIDs, only their IDs are
+// known until they are read
+func findPortfolioProjects(
+	projectsWrapper wrappers.ProjectsWrapper,
+	tags map[string]string,
+	projectIDs []string,
+) ([]wrappers.ProjectResponseModel, error) {
+	var projects []wrappers.ProjectResponseModel
+	found := map[string]bool{}
+	if len(tags) > 0 {
+		keys := make([]string, 0, len(tags))
+		var values []string
+		for key, value := range tags {
+			keys = append(keys, key)
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+		params := map[string]string{
+			commonParams.TagsKeyQueryParam: strings.Join(keys, ","),
+			commonParams.LimitQueryParam:   strconv.Itoa(portfolioProjectsPageSize),
+		}
+		if len(values) > 0 {
+			params[commonParams.TagsValueQueryParam] = strings.Join(values, ",")
+		}
+		for offset := 0; ; offset += portfolioProjectsPageSize {
+			params[commonParams.OffsetQueryParam] = strconv.Itoa(offset)
+			page, errorModel, err := projectsWrapper.Get(params)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s", failedGettingPortfolio)
+			}
+			if errorModel != nil {
+				return nil, errors.Errorf(ErrorCodeFormat, failedGettingPortfolio, errorModel.Code, errorModel.Message)
+			}
+			for i := range page.Projects {
+				if hasPortfolioTags(page.Projects[i].Tags, tags) && !found[page.Projects[i].ID] {
+					found[page.Projects[i].ID] = true
+					projects = append(projects, page.Projects[i])
+				}
+			}
+			if len(page.Projects) < portfolioProjectsPageSize || offset+len(page.Projects) >= int(page.FilteredTotalCount) {
+				break
+			}
+		}
+	}
+	for _, projectID := range projectIDs {
+		projectID = strings.TrimSpace(projectID)
+		if projectID != "" && !found[projectID] {
+			found[projectID] = true
+			projects = append(projects, wrappers.ProjectResponseModel{ID: projectID})
+		}
+	}
+	return projects, nil
+}
+
+// hasPortfolioTags tells if the project has every tag, a tag without value matches any value
+func hasPortfolioTags(projectTags, tags map[string]string) bool {
+	for key, value := range tags {
+		projectValue, ok := projectTags[key]
+		if !ok || (value != "" && projectValue != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// readPortfolioProjects reads the projects with at most parallelism of them at the same time, keeping their order
+func readPortfolioProjects(
+	portfolioWrappers *portfolioWrappers,
+	projects []wrappers.ProjectResponseModel,
+	branch string,
+	parallelism int,
+) []portfolioProject {
+	portfolioProjects := make([]portfolioProject, len(projects))
+	cacheAccessToken()
+	slots := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i := range projects {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			portfolioProjects[i] = readPortfolioProject(portfolioWrappers, projects[i], branch)
+		}(i)
+	}
+	wg.Wait()
+	return portfolioProjects
+}
+
+// cacheAccessToken fetches the access token once before concurrent requests share it, a failure is left to the
+// requests to report
+func cacheAccessToken() {
+	if _, err := wrappers.GetAccessToken(); err != nil {
+		logger.PrintIfVerbose(fmt.Sprintf("Failed to fetch the API access token: %v", err))
+	}
+}
+
+// readPortfolioProject summarizes the latest completed scan of a project, the errors are kept in the project so
+// one failing project does not fail the report
+func readPortfolioProject(portfolioWrappers *portfolioWrappers, project wrappers.ProjectResponseModel, branch string) portfolioProject {
+	portfolioProject := portfolioProject{ProjectID: project.ID, ProjectName: project.Name, Branch: branch}
+	if project.Name == "" {
+		projectResponse, errorModel, err := portfolioWrappers.projects.GetByID(project.ID)
+		if err == nil && errorModel != nil {
+			err = errors.Errorf(ErrorCodeFormat, failedGettingProj, errorModel.Code, errorModel.Message)
+		}
+		if err != nil {
+			portfolioProject.Error = err.Error()
+			return portfolioProject
+		}
+		project = *projectResponse
+		portfolioProject.ProjectName = project.Name
+	}
+	if portfolioProject.Branch == "" {
+		portfolioProject.Branch = project.MainBranch
+	}
+	logger.PrintIfVerbose(fmt.Sprintf("Reading the latest scan of project %s", project.Name))
+
+	scan, err := latestCompletedScan(portfolioWrappers.scans, project.ID, portfolioProject.Branch)
+	if err != nil {
+		portfolioProject.Error = err.Error()
+		return portfolioProject
+	}
+	if scan == nil {
+		portfolioProject.RiskMsg = portfolioNoCompletedScan
+		return portfolioProject
+	}
+	summary, err := readPortfolioSummary(portfolioWrappers, scan)
+	if err != nil {
+		portfolioProject.Error = err.Error()
+		return portfolioProject
+	}
+	portfolioProject.Branch = scan.Branch
+	portfolioProject.ScanID = scan.ID
+	portfolioProject.ScanCreatedAt = summary.CreatedAt
+	portfolioProject.ScanURL = summary.BaseURI
+	portfolioProject.RiskMsg = summary.RiskMsg
+	portfolioProject.TotalIssues = summary.TotalIssues
+	portfolioProject.HighIssues = summary.HighIssues
+	portfolioProject.MediumIssues = summary.MediumIssues
+	portfolioProject.LowIssues = summary.LowIssues
+	portfolioProject.InfoIssues = summary.InfoIssues
+	portfolioProject.SastIssues = max(summary.SastIssues, 0)
+	portfolioProject.ScaIssues = max(summary.ScaIssues, 0)
+	portfolioProject.KicsIssues = max(summary.KicsIssues, 0)
+	if summary.Policies != nil {
+		portfolioProject.PolicyStatus = summary.Policies.Status
+		portfolioProject.BreakBuild = summary.Policies.BreakBuild
+		for _, policy := range summary.Policies.Polices {
+			portfolioProject.ViolatedPolicies = append(portfolioProject.ViolatedPolicies, policy.Name)
+		}
+	}
+	return portfolioProject
+}
+
+func latestCompletedScan(scansWrapper wrappers.ScansWrapper, projectID, branch string) (*wrappers.ScanResponseModel, error) {
+	params := map[string]string{
+		commonParams.ProjectIDQueryParam: projectID,
+		commonParams.StatusesQueryParam:  portfolioCompletedStatus,
+		commonParams.SortQueryParam:      portfolioLatestScanSort,
+		commonParams.LimitQueryParam:     "1",
+	}
+	if branch != "" {
+		params[commonParams.BranchQueryParam] = branch
+	}
+	scans, errorModel, err := scansWrapper.Get(params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", failedGettingScan)
+	}
+	if errorModel != nil {
+		return nil, errors.Errorf("%s: CODE: %d, %s", failedGettingScan, errorModel.Code, errorModel.Message)
+	}
+	if len(scans.Scans) == 0 {
+		return nil, nil
+	}
+	return &scans.Scans[0], nil
+}
+
+func readPortfolioSummary(portfolioWrappers *portfolioWrappers, scan *wrappers.ScanResponseModel) (*wrappers.ResultSummary, error) {
+	summary, err := convertScanToResultsSummary(scan, portfolioWrappers.results)
+	if err != nil {
+		return nil, err
+	}
+	results, err := ReadResults(portfolioWrappers.results, scan, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
+	policies, webErr, err := portfolioWrappers.policy.EvaluatePolicy(map[string]string{"scanId": scan.ID, "astProjectId": scan.ProjectID})
+	if err != nil || webErr != nil {
+		// the report does without the policy status, as results show does when the policies are ignored
+		logger.PrintIfVerbose(fmt.Sprintf("Failed getting the policy status of scan %s", scan.ID))
+		policies = nil
+	}
+	return summaryReport(summary, policies, portfolioWrappers.risksOverview, results)
+}
+
+// rankPortfolioProjects sorts the projects by high, medium, low and info issues, the projects without a scan come last
+func rankPortfolioProjects(report *portfolioReport) {
+	sort.SliceStable(report.Projects, func(i, j int) bool {
+		a, b := &report.Projects[i], &report.Projects[j]
+		if (a.ScanID == "") != (b.ScanID == "") {
+			return a.ScanID != ""
+		}
+		for _, counts := range [][2]int{
+			{a.HighIssues, b.HighIssues},
+			{a.MediumIssues, b.MediumIssues},
+			{a.LowIssues, b.LowIssues},
+			{a.InfoIssues, b.InfoIssues},
+		} {
+			if counts[0] != counts[1] {
+				return counts[0] > counts[1]
+			}
+		}
+		return strings.ToLower(a.ProjectName) < strings.ToLower(b.ProjectName)
+	})
+	report.Totals = portfolioTotals{Projects: len(report.Projects)}
+	for i := range report.Projects {
+		project := &report.Projects[i]
+		project.Rank = i + 1
+		if project.ScanID == "" {
+			continue
+		}
+		report.Totals.ScannedProjects++
+		if len(project.ViolatedPolicies) > 0 {
+			report.Totals.PolicyViolations++
+		}
+		report.Totals.TotalIssues += project.TotalIssues
+		report.Totals.HighIssues += project.HighIssues
+		report.Totals.MediumIssues += project.MediumIssues
+		report.Totals.LowIssues += project.LowIssues
+		report.Totals.InfoIssues += project.InfoIssues
+		report.Totals.SastIssues += project.SastIssues
+		report.Totals.ScaIssues += project.ScaIssues
+		report.Totals.KicsIssues += project.KicsIssues
+	}
+}
+
+func printPortfolioReport(w io.Writer, report *portfolioReport, format string) error {
+	switch {
+	case printer.IsFormat(format, printer.FormatJSON):
+		return printer.Print(w, report, printer.FormatJSON)
+	case printer.IsFormat(format, printer.FormatHTML):
+		portfolioTemplate, err := htmlTemplate.New("portfolioTemplate").Parse(wrappers.PortfolioHTMLTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to parse the HTML template", failedGettingPortfolio)
+		}
+		return portfolioTemplate.Execute(w, report)
+	default:
+		portfolioTemplate, err := template.New("portfolioTemplate").Parse(wrappers.PortfolioMarkdownTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to parse the markdown template", failedGettingPortfolio)
+		}
+		return portfolioTemplate.Execute(w, report)
+	}
+}
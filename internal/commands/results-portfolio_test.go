@@ -0,0 +1,249 @@
+//go:build !integration
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
+	"github.com/golang-jwt/jwt"
+	"github.com/spf13/viper"
+	"gotest.tools/assert"
+)
+
+// portfolioProjectsWrapper has one project per tag value of team
+type portfolioProjectsWrapper struct {
+	mock.ProjectsMockWrapper
+	params map[string]string
+}
+
+func (p *portfolioProjectsWrapper) Get(filters map[string]string) (*wrappers.ProjectsCollectionResponseModel, *wrappers.ErrorModel, error) {
+	p.params = filters
+	return &wrappers.ProjectsCollectionResponseModel{
+		FilteredTotalCount: 3,
+		Projects: []wrappers.ProjectResponseModel{
+			{ID: "payments-api", Name: "payments-api", MainBranch: "main", Tags: map[string]string{"team": "payments"}},
+			{ID: "payments-web", Name: "payments-web", Tags: map[string]string{"team": "payments"}},
+			{ID: "search", Name: "search", Tags: map[string]string{"team": "search"}},
+		},
+	}, nil, nil
+}
+
+// portfolioScansWrapper returns a scan for every project but payments-web and counts the concurrent calls
+type portfolioScansWrapper struct {
+	mock.ScansMockWrapper
+	lock     sync.Mutex
+	running  int
+	maxCalls int
+	params   []map[string]string
+}
+
+func (s *portfolioScansWrapper) Get(filters map[string]string) (*wrappers.ScansCollectionResponseModel, *wrappers.ErrorModel, error) {
+	s.lock.Lock()
+	s.running++
+	s.maxCalls = max(s.maxCalls, s.running)
+	s.params = append(s.params, filters)
+	s.lock.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	s.lock.Lock()
+	s.running--
+	s.lock.Unlock()
+
+	projectID := filters[params.ProjectIDQueryParam]
+	if projectID == "payments-web" {
+		return &wrappers.ScansCollectionResponseModel{}, nil, nil
+	}
+	return &wrappers.ScansCollectionResponseModel{Scans: []wrappers.ScanResponseModel{{
+		ID:        projectID + "-scan",
+		ProjectID: projectID,
+		Status:    "Completed",
+		Branch:    filters[params.BranchQueryParam],
+		Engines:   []string{params.SastType},
+	}}}, nil, nil
+}
+
+func portfolioTestWrappers() (*portfolioWrappers, *portfolioProjectsWrapper, *portfolioScansWrapper) {
+	projectsWrapper := &portfolioProjectsWrapper{}
+	scansWrapper := &portfolioScansWrapper{}
+	return &portfolioWrappers{
+		projects:      projectsWrapper,
+		scans:         scansWrapper,
+		results:       &mock.ResultsMockWrapper{},
+		risksOverview: &mock.RisksOverviewMockWrapper{},
+		policy:        &mock.PolicyMockWrapper{},
+	}, projectsWrapper, scansWrapper
+}
+
+func TestFindPortfolioProjects(t *testing.T) {
+	_, projectsWrapper, _ := portfolioTestWrappers()
+	projects, err := findPortfolioProjects(projectsWrapper, createTagMap("team:payments"), []string{"payments-api", "other"})
+	assert.NilError(t, err)
+	assert.Equal(t, projectsWrapper.params[params.TagsKeyQueryParam], "team")
+	assert.Equal(t, projectsWrapper.params[params.TagsValueQueryParam], "payments")
+	// search has another team and payments-api is listed once
+	assert.Equal(t, len(projects), 3)
+	assert.Equal(t, projects[0].ID, "payments-api")
+	assert.Equal(t, projects[1].ID, "payments-web")
+	assert.Equal(t, projects[2].ID, "other")
+	assert.Equal(t, projects[2].Name, "")
+}
+
+func TestReadPortfolioProjects(t *testing.T) {
+	portfolioWrappers, projectsWrapper, scansWrapper := portfolioTestWrappers()
+	projects, err := findPortfolioProjects(projectsWrapper, nil, []string{"a", "b", "c", "d", "e", "payments-web"})
+	assert.NilError(t, err)
+	portfolioProjects := readPortfolioProjects(portfolioWrappers, projects, "", 2)
+	assert.Equal(t, scansWrapper.maxCalls, 2)
+	assert.Equal(t, len(portfolioProjects), 6)
+	for _, scanParams := range scansWrapper.params {
+		assert.Equal(t, scanParams[params.StatusesQueryParam], "Completed")
+		assert.Equal(t, scanParams[params.SortQueryParam], "-created_at")
+	}
+
+	// the order of the projects is kept, the project name is read from its ID
+	a := portfolioProjects[0]
+	assert.Equal(t, a.ProjectID, "a")
+	assert.Equal(t, a.ScanID, "a-scan")
+	assert.Equal(t, a.RiskMsg, "High Risk")
+	assert.Equal(t, a.PolicyStatus, "COMPLETED")
+	assert.Assert(t, a.HighIssues > 0)
+	assert.Equal(t, portfolioProjects[5].RiskMsg, portfolioNoCompletedScan)
+	assert.Equal(t, portfolioProjects[5].ScanID, "")
+}
+
+// portfolioServer serves the access tokens and an empty list of scans, counting the token requests
+type portfolioServer struct {
+	lock          sync.Mutex
+	tokenRequests int
+	baseURL       string
+}
+
+func (s *portfolioServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/protocol/openid-connect/token") {
+		s.lock.Lock()
+		s.tokenRequests++
+		s.lock.Unlock()
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"ast-base-url": s.baseURL}).SignedString([]byte("key"))
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": token})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(wrappers.ScansCollectionResponseModel{})
+}
+
+func TestReadPortfolioProjectsSharesAccessToken(t *testing.T) {
+	portfolioServer := &portfolioServer{}
+	server := httptest.NewServer(portfolioServer)
+	defer server.Close()
+	portfolioServer.baseURL = server.URL
+	viper.Set(params.BaseURIKey, server.URL)
+	viper.Set(params.BaseAuthURIKey, server.URL)
+	viper.Set(params.TenantKey, "tenant")
+	viper.Set(params.AccessKeyIDConfigKey, "client")
+	viper.Set(params.AccessKeySecretConfigKey, "secret")
+	viper.Set(params.TokenExpirySecondsKey, 300)
+	defer func() {
+		viper.Set(params.BaseURIKey, "")
+		viper.Set(params.BaseAuthURIKey, "")
+		viper.Set(params.TenantKey, "")
+		viper.Set(params.AccessKeyIDConfigKey, "")
+		viper.Set(params.AccessKeySecretConfigKey, "")
+		viper.Set(params.TokenExpirySecondsKey, 0)
+	}()
+	portfolioWrappers := &portfolioWrappers{scans: wrappers.NewHTTPScansWrapper(viper.GetString(params.ScansPathKey))}
+	var projects []wrappers.ProjectResponseModel
+	for i := 0; i < 20; i++ {
+		projectID := strconv.Itoa(i)
+		projects = append(projects, wrappers.ProjectResponseModel{ID: projectID, Name: projectID})
+	}
+
+	// the token is fetched once before the workers
+	portfolioProjects := readPortfolioProjects(portfolioWrappers, projects, "main", 8)
+	for _, project := range portfolioProjects {
+		assert.Equal(t, project.Error, "")
+		assert.Equal(t, project.RiskMsg, portfolioNoCompletedScan)
+	}
+	assert.Equal(t, portfolioServer.tokenRequests, 1)
+
+	// a token expiring during the report is refreshed by the workers concurrently
+	viper.Set(params.TokenExpirySecondsKey, 0)
+	portfolioProjects = readPortfolioProjects(portfolioWrappers, projects, "main", 8)
+	for _, project := range portfolioProjects {
+		assert.Equal(t, project.Error, "")
+	}
+	assert.Equal(t, portfolioServer.tokenRequests, 1+1+len(projects))
+}
+
+func TestReadPortfolioProjectMainBranch(t *testing.T) {
+	portfolioWrappers, _, scansWrapper := portfolioTestWrappers()
+	project := readPortfolioProject(portfolioWrappers, wrappers.ProjectResponseModel{ID: "payments-api", Name: "payments-api", MainBranch: "main"}, "")
+	assert.Equal(t, project.Branch, "main")
+	assert.Equal(t, scansWrapper.params[0][params.BranchQueryParam], "main")
+
+	project = readPortfolioProject(portfolioWrappers, wrappers.ProjectResponseModel{ID: "payments-api", Name: "payments-api", MainBranch: "main"}, "release")
+	assert.Equal(t, project.Branch, "release")
+}
+
+func TestRankPortfolioProjects(t *testing.T) {
+	report := &portfolioReport{Projects: []portfolioProject{
+		{ProjectName: "no-scan", RiskMsg: portfolioNoCompletedScan},
+		{ProjectName: "medium", ScanID: "1", MediumIssues: 5, TotalIssues: 5, SastIssues: 5},
+		{ProjectName: "high", ScanID: "2", HighIssues: 1, LowIssues: 3, TotalIssues: 4, ScaIssues: 4, ViolatedPolicies: []string{"No high"}},
+		{ProjectName: "Another-medium", ScanID: "3", MediumIssues: 5, TotalIssues: 5, KicsIssues: 5},
+	}}
+	rankPortfolioProjects(report)
+	names := []string{}
+	for _, project := range report.Projects {
+		names = append(names, project.ProjectName)
+	}
+	assert.DeepEqual(t, names, []string{"high", "Another-medium", "medium", "no-scan"})
+	assert.Equal(t, report.Projects[3].Rank, 4)
+	assert.DeepEqual(t, report.Totals, portfolioTotals{
+		Projects: 4, ScannedProjects: 3, PolicyViolations: 1, TotalIssues: 14, HighIssues: 1, MediumIssues: 10, LowIssues: 3,
+		SastIssues: 5, ScaIssues: 4, KicsIssues: 5,
+	})
+}
+
+func TestPrintPortfolioReport(t *testing.T) {
+	report := &portfolioReport{GeneratedAt: "now", Projects: []portfolioProject{
+		{ProjectName: "payments-api", Branch: "main", ScanID: "1", ScanCreatedAt: "2023-05-01, 10:20:30", ScanURL: "https://host/scan",
+			RiskMsg: "High Risk", HighIssues: 1, TotalIssues: 1, PolicyStatus: "COMPLETED", ViolatedPolicies: []string{"No high"}, BreakBuild: true},
+		{ProjectName: "broken", Error: "Failed getting a project"},
+	}}
+	rankPortfolioProjects(report)
+
+	var markdown bytes.Buffer
+	assert.NilError(t, printPortfolioReport(&markdown, report, printer.FormatSummaryMarkdown))
+	assert.Assert(t, strings.Contains(markdown.String(),
+		"| 1 | payments-api | main | High Risk | 1 | 1 | 0 | 0 | 0 | COMPLETED: No high (break build) | [2023-05-01, 10:20:30](https://host/scan) |"))
+	assert.Assert(t, strings.Contains(markdown.String(), "| 2 | broken |  | Error: Failed getting a project |"))
+
+	var html bytes.Buffer
+	assert.NilError(t, printPortfolioReport(&html, report, printer.FormatHTML))
+	assert.Assert(t, strings.Contains(html.String(), `<a href="https://host/scan" rel="noopener noreferrer">2023-05-01, 10:20:30</a>`))
+	assert.Assert(t, strings.Contains(html.String(), `<span class="error">Failed getting a project</span>`))
+}
+
+func TestRunGetResultsPortfolioCommand(t *testing.T) {
+	execCmdNilAssertion(t, "results", "portfolio", "--project-ids", "MOCK", "--format", "json", "--parallelism", "2")
+}
+
+func TestRunGetResultsPortfolioCommandWithoutProjects(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "portfolio")
+	assert.ErrorContains(t, err, "Please provide --project-tags or --project-ids")
+}
+
+func TestRunGetResultsPortfolioCommandInvalidParallelism(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "portfolio", "--project-ids", "MOCK", "--parallelism", "0")
+	assert.ErrorContains(t, err, "--parallelism should be at least 1")
+}
@@ -0,0 +1,385 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/commands/util"
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	failedGettingScaPaths      = "Failed getting SCA dependency paths"
+	scaPathsPackageNotFound    = "%s: package %s was not found in the SCA results of scan %s"
+	scaPathsNoScaEngine        = "%s: scan %s did not run SCA"
+	scaPathsInvalidFormat      = "%s: invalid format %s, use one of %s"
+	scaExploitablePathKey      = "ExploitablePath"
+	scaRuntimeScope            = "runtime"
+	scaDevelopmentScope        = "development"
+	scaPathsTreeBranch         = "|-- "
+	scaPathsTreeLastBranch     = "`-- "
+	scaPathsTreeIndent         = "|   "
+	scaPathsTreeLastIndent     = "    "
+	scaPathsExploitableMissing = "Exploitable path was not enabled for this scan, run the scan with --" +
+		commonParams.ExploitablePathFlag + " true"
+)
+
+var scaPathsFormats = []string{printer.FormatTree, printer.FormatJSON, printer.FormatDot}
+
+// scaPackagePathsView is a vulnerable package with every dependency path that brings it into the project
+type scaPackagePathsView struct {
+	ID                     string                     `json:"id"`
+	Name                   string                     `json:"name"`
+	Version                string                     `json:"version"`
+	TypeOfDependency       string                     `json:"typeOfDependency"`
+	Scope                  string                     `json:"scope"`
+	Locations              []string                   `json:"locations,omitempty"`
+	DependencyPaths        []scaDependencyPathView    `json:"dependencyPaths"`
+	ExploitablePathEnabled bool                       `json:"exploitablePathEnabled"`
+	Vulnerabilities        []scaPathVulnerabilityView `json:"vulnerabilities"`
+}
+
+// scaDependencyPathView is one chain from a direct dependency down to the vulnerable package
+type scaDependencyPathView struct {
+	Scope string                    `json:"scope"`
+	Nodes []wrappers.DependencyPath `json:"nodes"`
+}
+
+type scaPathVulnerabilityView struct {
+	ID              string                     `json:"id"`
+	Severity        string                     `json:"severity"`
+	State           string                     `json:"state"`
+	ExploitablePath []*wrappers.ScanResultNode `json:"exploitablePath,omitempty"`
+}
+
+type scaPathTreeNode struct {
+	dependency wrappers.DependencyPath
+	children   []*scaPathTreeNode
+}
+
+func resultScaPathsSubCommand(resultsWrapper wrappers.ResultsWrapper, scanWrapper wrappers.ScansWrapper) *cobra.Command {
+	scaPathsCmd := &cobra.Command{
+		Use:   "sca-paths",
+		Short: "Show how a vulnerable package is brought into the project",
+		Long: "The sca-paths command shows the dependency paths from the direct dependencies to a vulnerable package " +
+			"and, when the scan used --" + commonParams.ExploitablePathFlag + ", the exploitable path to its vulnerable code.",
+		Example: heredoc.Doc(
+			`
+			$ cx results sca-paths --scan-id <scan Id> --package <package Id or name>
+			$ cx results sca-paths --scan-id <scan Id> --package <package Id or name> --format dot | dot -Tsvg -o paths.svg
+		`,
+		),
+		RunE: runGetScaPathsCommand(resultsWrapper, scanWrapper),
+	}
+	addScanIDFlag(scaPathsCmd, "ID to report on.")
+	scaPathsCmd.PersistentFlags().String(commonParams.ScaPathsPackageFlag, "", "Package ID or name of the vulnerable package")
+	addFormatFlag(scaPathsCmd, printer.FormatTree, printer.FormatJSON, printer.FormatDot)
+	return scaPathsCmd
+}
+
+func runGetScaPathsCommand(resultsWrapper wrappers.ResultsWrapper, scanWrapper wrappers.ScansWrapper) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		scanID, _ := cmd.Flags().GetString(commonParams.ScanIDFlag)
+		if scanID == "" {
+			return errors.Errorf("%s: Please provide a scan ID", failedGettingScaPaths)
+		}
+		packageName, _ := cmd.Flags().GetString(commonParams.ScaPathsPackageFlag)
+		if packageName == "" {
+			return errors.Errorf("%s: Please provide a package", failedGettingScaPaths)
+		}
+		format, _ := cmd.Flags().GetString(commonParams.FormatFlag)
+		if !containsFold(scaPathsFormats, format) {
+			return errors.Errorf(scaPathsInvalidFormat, failedGettingScaPaths, format, strings.Join(scaPathsFormats, ", "))
+		}
+		scan, errorModel, err := scanWrapper.GetByID(scanID)
+		if err != nil {
+			return errors.Wrapf(err, "%s", failedGettingScan)
+		}
+		if errorModel != nil {
+			return errors.Errorf("%s: CODE: %d, %s", failedGettingScan, errorModel.Code, errorModel.Message)
+		}
+		if !util.Contains(scan.Engines, commonParams.ScaType) {
+			return errors.Errorf(scaPathsNoScaEngine, failedGettingScaPaths, scanID)
+		}
+		results, err := ReadResults(resultsWrapper, scan, make(map[string]string))
+		if err != nil {
+			return err
+		}
+		views := toScaPackagePathsViews(results, packageName, isScaExploitablePathEnabled(scan))
+		if len(views) == 0 {
+			return errors.Errorf(scaPathsPackageNotFound, failedGettingScaPaths, packageName, scanID)
+		}
+		return printScaPaths(cmd.OutOrStdout(), views, format)
+	}
+}
+
+// isScaExploitablePathEnabled tells if the scan was created with --sca-exploitable-path true
+func isScaExploitablePathEnabled(scan *wrappers.ScanResponseModel) bool {
+	for _, config := range scan.Metadata.Configs {
+		if config.Type != commonParams.ScaType {
+			continue
+		}
+		if value, ok := config.Value[scaExploitablePathKey].(string); ok && strings.EqualFold(value, trueString) {
+			return true
+		}
+	}
+	return false
+}
+
+// toScaPackagePathsViews groups the SCA results of the packages matching packageName, by package ID or name,
+// together with their dependency paths
+func toScaPackagePathsViews(results *wrappers.ScanResultsCollection, packageName string, exploitablePathEnabled bool) []scaPackagePathsView {
+	views := []scaPackagePathsView{}
+	if results == nil {
+		return views
+	}
+	indexByID := map[string]int{}
+	for _, result := range results.Results {
+		scaPackage := result.ScanResultData.ScaPackageCollection
+		if result.Type != commonParams.ScaType || scaPackage == nil {
+			continue
+		}
+		name, version := scaPackageNameAndVersion(scaPackage)
+		if !strings.EqualFold(scaPackage.ID, packageName) && !strings.EqualFold(name, packageName) {
+			continue
+		}
+		index, ok := indexByID[scaPackage.ID]
+		if !ok {
+			index = len(views)
+			indexByID[scaPackage.ID] = index
+			views = append(views, newScaPackagePathsView(scaPackage, name, version, exploitablePathEnabled))
+		}
+		vulnerability := scaPathVulnerabilityView{
+			ID:       result.VulnerabilityDetails.CveName,
+			Severity: result.Severity,
+			State:    result.State,
+		}
+		if vulnerability.ID == "" {
+			vulnerability.ID = result.ID
+		}
+		if exploitablePathEnabled {
+			vulnerability.ExploitablePath = result.ScanResultData.Nodes
+		}
+		views[index].Vulnerabilities = append(views[index].Vulnerabilities, vulnerability)
+	}
+	return views
+}
+
+func newScaPackagePathsView(scaPackage *wrappers.ScaPackageCollection, name, version string, exploitablePathEnabled bool) scaPackagePathsView {
+	view := scaPackagePathsView{
+		ID:                     scaPackage.ID,
+		Name:                   name,
+		Version:                version,
+		TypeOfDependency:       scaPackage.TypeOfDependency,
+		Scope:                  scaDevelopmentScope,
+		DependencyPaths:        []scaDependencyPathView{},
+		ExploitablePathEnabled: exploitablePathEnabled,
+		Vulnerabilities:        []scaPathVulnerabilityView{},
+	}
+	for _, location := range scaPackage.Locations {
+		if location != nil {
+			view.Locations = append(view.Locations, *location)
+		}
+	}
+	for _, dependencyPath := range scaPackage.DependencyPathArray {
+		if len(dependencyPath) == 0 {
+			continue
+		}
+		path := scaDependencyPathView{Scope: scaRuntimeScope, Nodes: dependencyPath}
+		for _, dependency := range dependencyPath {
+			if dependency.IsDevelopment {
+				path.Scope = scaDevelopmentScope
+			}
+		}
+		if path.Scope == scaRuntimeScope {
+			view.Scope = scaRuntimeScope
+		}
+		view.DependencyPaths = append(view.DependencyPaths, path)
+	}
+	if len(view.DependencyPaths) == 0 {
+		view.Scope = scaRuntimeScope
+	}
+	return view
+}
+
+// scaPackageNameAndVersion reads the name and version of the package from the end of its dependency paths
+func scaPackageNameAndVersion(scaPackage *wrappers.ScaPackageCollection) (name, version string) {
+	for _, dependencyPath := range scaPackage.DependencyPathArray {
+		if len(dependencyPath) > 0 {
+			last := dependencyPath[len(dependencyPath)-1]
+			return last.Name, last.Version
+		}
+	}
+	return scaPackage.ID, ""
+}
+
+func printScaPaths(w io.Writer, views []scaPackagePathsView, format string) error {
+	switch {
+	case printer.IsFormat(format, printer.FormatJSON):
+		return printer.Print(w, views, printer.FormatJSON)
+	case printer.IsFormat(format, printer.FormatDot):
+		writeScaPathsDot(w, views)
+	default:
+		for i := range views {
+			if i > 0 {
+				_, _ = fmt.Fprintln(w)
+			}
+			writeScaPathsTree(w, &views[i])
+		}
+	}
+	return nil
+}
+
+func writeScaPathsTree(w io.Writer, view *scaPackagePathsView) {
+	_, _ = fmt.Fprintf(w, "%s (%s)\n", scaDependencyLabel(view.Name, view.Version), view.ID)
+	_, _ = fmt.Fprintf(w, "Dependency: %s, %s\n", view.TypeOfDependency, view.Scope)
+	if len(view.Locations) > 0 {
+		_, _ = fmt.Fprintf(w, "Locations: %s\n", strings.Join(view.Locations, ", "))
+	}
+	vulnerabilities := make([]string, 0, len(view.Vulnerabilities))
+	for _, vulnerability := range view.Vulnerabilities {
+		vulnerabilities = append(vulnerabilities, fmt.Sprintf("%s (%s, %s)", vulnerability.ID, vulnerability.Severity, vulnerability.State))
+	}
+	_, _ = fmt.Fprintf(w, "Vulnerabilities: %s\n", strings.Join(vulnerabilities, ", "))
+
+	_, _ = fmt.Fprintln(w, "\nDependency paths:")
+	writeScaPathTreeNodes(w, buildScaPathTree(view.DependencyPaths), "", view.ID)
+
+	_, _ = fmt.Fprintln(w, "\nExploitable path:")
+	if !view.ExploitablePathEnabled {
+		_, _ = fmt.Fprintf(w, "%s%s\n", scaPathsTreeLastIndent, scaPathsExploitableMissing)
+		return
+	}
+	for _, vulnerability := range view.Vulnerabilities {
+		if len(vulnerability.ExploitablePath) == 0 {
+			_, _ = fmt.Fprintf(w, "%s%s: no exploitable path found\n", scaPathsTreeLastIndent, vulnerability.ID)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s%s:\n", scaPathsTreeLastIndent, vulnerability.ID)
+		for _, node := range vulnerability.ExploitablePath {
+			_, _ = fmt.Fprintf(w, "%s%s%s\n", scaPathsTreeLastIndent, scaPathsTreeLastIndent, scaExploitableNodeLabel(node, " "))
+		}
+	}
+}
+
+// buildScaPathTree merges the dependency paths into a tree, paths sharing a prefix share its nodes
+func buildScaPathTree(paths []scaDependencyPathView) []*scaPathTreeNode {
+	var roots []*scaPathTreeNode
+	for _, path := range paths {
+		level := &roots
+		for _, dependency := range path.Nodes {
+			var current *scaPathTreeNode
+			for _, child := range *level {
+				if child.dependency.ID == dependency.ID {
+					current = child
+					break
+				}
+			}
+			if current == nil {
+				current = &scaPathTreeNode{dependency: dependency}
+				*level = append(*level, current)
+			}
+			level = &current.children
+		}
+	}
+	return roots
+}
+
+func writeScaPathTreeNodes(w io.Writer, nodes []*scaPathTreeNode, prefix, vulnerableID string) {
+	for i, node := range nodes {
+		branch, indent := scaPathsTreeBranch, scaPathsTreeIndent
+		if i == len(nodes)-1 {
+			branch, indent = scaPathsTreeLastBranch, scaPathsTreeLastIndent
+		}
+		label := scaDependencyLabel(node.dependency.Name, node.dependency.Version)
+		if node.dependency.IsDevelopment {
+			label += " [" + scaDevelopmentScope + "]"
+		}
+		if node.dependency.ID == vulnerableID {
+			label += " (vulnerable)"
+		}
+		_, _ = fmt.Fprintf(w, "%s%s%s\n", prefix, branch, label)
+		writeScaPathTreeNodes(w, node.children, prefix+indent, vulnerableID)
+	}
+}
+
+// writeScaPathsDot writes a Graphviz digraph with an edge for each dependency and the exploitable path
+// of each vulnerability pointing at the vulnerable package
+func writeScaPathsDot(w io.Writer, views []scaPackagePathsView) {
+	_, _ = fmt.Fprintln(w, "digraph \"sca-paths\" {")
+	_, _ = fmt.Fprintln(w, "  rankdir=LR;")
+	_, _ = fmt.Fprintln(w, "  node [shape=box];")
+	seenNodes := map[string]bool{}
+	seenEdges := map[string]bool{}
+	for i := range views {
+		view := &views[i]
+		for _, path := range view.DependencyPaths {
+			for j, dependency := range path.Nodes {
+				if !seenNodes[dependency.ID] {
+					seenNodes[dependency.ID] = true
+					attributes := ""
+					if dependency.ID == view.ID {
+						attributes += ", color=red"
+					}
+					if dependency.IsDevelopment {
+						attributes += ", style=dashed"
+					}
+					_, _ = fmt.Fprintf(w, "  %q [label=%q%s];\n", dependency.ID,
+						scaDependencyLabel(dependency.Name, dependency.Version), attributes)
+				}
+				if j == 0 {
+					continue
+				}
+				edge := fmt.Sprintf("  %q -> %q;", path.Nodes[j-1].ID, dependency.ID)
+				if !seenEdges[edge] {
+					seenEdges[edge] = true
+					_, _ = fmt.Fprintln(w, edge)
+				}
+			}
+		}
+		if !seenNodes[view.ID] {
+			seenNodes[view.ID] = true
+			_, _ = fmt.Fprintf(w, "  %q [label=%q, color=red];\n", view.ID, scaDependencyLabel(view.Name, view.Version))
+		}
+		for _, vulnerability := range view.Vulnerabilities {
+			previous := ""
+			for j, node := range vulnerability.ExploitablePath {
+				current := fmt.Sprintf("%s:%s:%d", view.ID, vulnerability.ID, j)
+				_, _ = fmt.Fprintf(w, "  %q [label=%q, shape=ellipse];\n", current, scaExploitableNodeLabel(node, "\n"))
+				if previous != "" {
+					_, _ = fmt.Fprintf(w, "  %q -> %q;\n", previous, current)
+				}
+				previous = current
+			}
+			if previous != "" {
+				_, _ = fmt.Fprintf(w, "  %q -> %q [style=dotted, label=%q];\n", previous, view.ID, vulnerability.ID)
+			}
+		}
+	}
+	_, _ = fmt.Fprintln(w, "}")
+}
+
+func scaDependencyLabel(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
+
+func scaExploitableNodeLabel(node *wrappers.ScanResultNode, separator string) string {
+	method := node.Method
+	if method == "" {
+		method = node.Name
+	}
+	location := fmt.Sprintf("%s:%d", node.FileName, node.Line)
+	if method == "" {
+		return location
+	}
+	return location + separator + method
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+)
+
+const (
+	spreadsheetColumnsFlagDescription = "Columns of the csv and xlsx reports, in order. Available columns: "
+	spreadsheetInvalidColumn          = "%s: invalid report column %s, available columns: %s"
+	spreadsheetSummarySheet           = "Summary"
+	// spreadsheetFormulaPrefixes start a formula when a CSV file is opened by a spreadsheet
+	spreadsheetFormulaPrefixes = "=+-@\t\r"
+)
+
+// spreadsheetColumn is a column of the csv and xlsx reports, named by --report-columns
+type spreadsheetColumn struct {
+	name    string
+	header  string
+	numeric bool
+	value   func(row *spreadsheetRow) string
+}
+
+// spreadsheetRow is a finding of the csv and xlsx reports
+type spreadsheetRow struct {
+	engine             string
+	severity           string
+	state              string
+	status             string
+	query              string
+	cwe                string
+	file               string
+	line               string
+	packageName        string
+	version            string
+	recommendedVersion string
+	firstFoundAt       string
+	similarityID       string
+	scanURL            string
+}
+
+var spreadsheetColumns = []spreadsheetColumn{
+	{name: "engine", header: "Engine", value: func(row *spreadsheetRow) string { return row.engine }},
+	{name: "severity", header: "Severity", value: func(row *spreadsheetRow) string { return row.severity }},
+	{name: "state", header: "State", value: func(row *spreadsheetRow) string { return row.state }},
+	{name: "status", header: "Status", value: func(row *spreadsheetRow) string { return row.status }},
+	{name: "query", header: "Query/CVE", value: func(row *spreadsheetRow) string { return row.query }},
+	{name: "cwe", header: "CWE", value: func(row *spreadsheetRow) string { return row.cwe }},
+	{name: "file", header: "File", value: func(row *spreadsheetRow) string { return row.file }},
+	{name: "line", header: "Line", numeric: true, value: func(row *spreadsheetRow) string { return row.line }},
+	{name: "package", header: "Package", value: func(row *spreadsheetRow) string { return row.packageName }},
+	{name: "version", header: "Version", value: func(row *spreadsheetRow) string { return row.version }},
+	{name: "recommendedVersion", header: "Recommended Version", value: func(row *spreadsheetRow) string { return row.recommendedVersion }},
+	{name: "firstFoundAt", header: "First Found", value: func(row *spreadsheetRow) string { return row.firstFoundAt }},
+	{name: "similarityId", header: "Similarity ID", value: func(row *spreadsheetRow) string { return row.similarityID }},
+	{name: "scanUrl", header: "Scan URL", value: func(row *spreadsheetRow) string { return row.scanURL }},
+}
+
+// spreadsheetEngines orders the engine sheets of the xlsx report, other engines come after them by name
+var spreadsheetEngines = []string{commonParams.SastType, commonParams.ScaType, commonParams.KicsType}
+
+func spreadsheetColumnNames() []string {
+	names := make([]string, len(spreadsheetColumns))
+	for i, column := range spreadsheetColumns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// parseSpreadsheetColumns reads --report-columns, every column when it is empty
+func parseSpreadsheetColumns(reportColumns string) ([]spreadsheetColumn, error) {
+	if strings.TrimSpace(reportColumns) == "" {
+		return spreadsheetColumns, nil
+	}
+	var columns []spreadsheetColumn
+	for _, name := range strings.Split(reportColumns, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, column := range spreadsheetColumns {
+			if strings.EqualFold(column.name, name) {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf(spreadsheetInvalidColumn, failedListingResults, name, strings.Join(spreadsheetColumnNames(), ","))
+		}
+	}
+	return columns, nil
+}
+
+func toSpreadsheetRows(results *wrappers.ScanResultsCollection, summary *wrappers.ResultSummary) []*spreadsheetRow {
+	rows := []*spreadsheetRow{}
+	if results == nil {
+		return rows
+	}
+	for _, result := range results.Results {
+		rows = append(rows, toSpreadsheetRow(result, summary))
+	}
+	return rows
+}
+
+func toSpreadsheetRow(result *wrappers.ScanResult, summary *wrappers.ResultSummary) *spreadsheetRow {
+	data := &result.ScanResultData
+	row := &spreadsheetRow{
+		engine:       strings.TrimSpace(result.Type),
+		severity:     strings.ToUpper(result.Severity),
+		state:        result.State,
+		status:       result.Status,
+		firstFoundAt: result.FirstFoundAt,
+		similarityID: result.SimilarityID,
+		scanURL:      summary.BaseURI,
+	}
+	if result.VulnerabilityDetails.CweID != nil {
+		row.cwe = normalizeComplianceCWE(fmt.Sprint(result.VulnerabilityDetails.CweID))
+	}
+	switch row.engine {
+	case commonParams.SastType:
+		row.query = strings.ReplaceAll(data.QueryName, "_", " ")
+		if len(data.Nodes) > 0 {
+			row.file = data.Nodes[0].FileName
+			row.line = strconv.FormatUint(uint64(data.Nodes[0].Line), 10)
+		}
+	case commonParams.ScaType:
+		row.query = result.ID
+		_, row.packageName, row.version = glScaPackage(result)
+		row.recommendedVersion = glRecommendedVersion(result)
+		if locations := glScaLocations(result); len(locations) > 0 {
+			row.file = locations[0]
+		}
+	default:
+		row.query = data.QueryName
+		row.file = data.Filename
+		if data.Line > 0 {
+			row.line = strconv.FormatUint(uint64(data.Line), 10)
+		}
+	}
+	if row.query == "" {
+		row.query = result.ID
+	}
+	return row
+}
+
+func exportCSVResults(targetFile string, results *wrappers.ScanResultsCollection, summary *wrappers.ResultSummary, reportColumns string) error {
+	columns, err := parseSpreadsheetColumns(reportColumns)
+	if err != nil {
+		return err
+	}
+	log.Println("Creating CSV Report: ", targetFile)
+	f, err := os.Create(targetFile)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to create target file  ", failedListingResults)
+	}
+	defer f.Close()
+	writer := csv.NewWriter(f)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.header
+	}
+	_ = writer.Write(record)
+	for _, row := range toSpreadsheetRows(results, summary) {
+		for i, column := range columns {
+			record[i] = escapeCSVFormula(column.value(row))
+		}
+		_ = writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeCSVFormula keeps the values starting like a formula, a file named =cmd|... for instance, as text
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(spreadsheetFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// groupSpreadsheetRows groups the rows by engine, with the enabled engines without findings too
+func groupSpreadsheetRows(rows []*spreadsheetRow, enabledEngines []string) (engines []string, rowsByEngine map[string][]*spreadsheetRow) {
+	rowsByEngine = map[string][]*spreadsheetRow{}
+	var others []string
+	for _, row := range rows {
+		if _, ok := rowsByEngine[row.engine]; !ok && !contains(spreadsheetEngines, row.engine) {
+			others = append(others, row.engine)
+		}
+		rowsByEngine[row.engine] = append(rowsByEngine[row.engine], row)
+	}
+	for _, engine := range spreadsheetEngines {
+		if _, ok := rowsByEngine[engine]; ok || contains(enabledEngines, engine) {
+			engines = append(engines, engine)
+		}
+	}
+	sort.Strings(others)
+	return append(engines, others...), rowsByEngine
+}
+
+func exportXLSXResults(targetFile string, results *wrappers.ScanResultsCollection, summary *wrappers.ResultSummary, reportColumns string) error {
+	columns, err := parseSpreadsheetColumns(reportColumns)
+	if err != nil {
+		return err
+	}
+	log.Println("Creating XLSX Report: ", targetFile)
+	rows := toSpreadsheetRows(results, summary)
+	engines, rowsByEngine := groupSpreadsheetRows(rows, summary.EnginesEnabled)
+
+	workbook := &xlsxWorkbook{}
+	workbook.addSheet(spreadsheetSummarySheet, spreadsheetSummaryCells(summary, engines, rowsByEngine), false)
+	header := make([]xlsxCell, len(columns))
+	for i, column := range columns {
+		header[i] = xlsxCell{value: column.header, bold: true}
+	}
+	for _, engine := range engines {
+		cells := [][]xlsxCell{header}
+		for _, row := range rowsByEngine[engine] {
+			line := make([]xlsxCell, len(columns))
+			for i, column := range columns {
+				line[i] = xlsxCell{value: column.value(row), numeric: column.numeric}
+			}
+			cells = append(cells, line)
+		}
+		workbook.addSheet(strings.ToUpper(engine), cells, true)
+	}
+	f, err := os.Create(targetFile)
+	if err != nil {
+		return errors.Wrapf(err, "%s: failed to create target file  ", failedListingResults)
+	}
+	defer f.Close()
+	return workbook.write(f)
+}
+
+// spreadsheetSummaryCells is the summary sheet: the scan and the count of findings of each engine by severity
+func spreadsheetSummaryCells(summary *wrappers.ResultSummary, engines []string, rowsByEngine map[string][]*spreadsheetRow) [][]xlsxCell {
+	cells := [][]xlsxCell{}
+	for _, field := range [][2]string{
+		{"Project", summary.ProjectName},
+		{"Branch", summary.BranchName},
+		{"Scan ID", summary.ScanID},
+		{"Created", summary.CreatedAt},
+		{"Status", summary.Status},
+		{"Scan URL", summary.BaseURI},
+	} {
+		if field[1] != "" {
+			cells = append(cells, []xlsxCell{{value: field[0], bold: true}, {value: field[1]}})
+		}
+	}
+	if len(cells) > 0 {
+		cells = append(cells, []xlsxCell{})
+	}
+	header := []xlsxCell{{value: "Engine", bold: true}}
+	for _, severity := range resultsHTMLSeverities {
+		header = append(header, xlsxCell{value: severity, bold: true})
+	}
+	cells = append(cells, append(header, xlsxCell{value: "Total", bold: true}))
+	totals := make([]int, len(resultsHTMLSeverities)+1)
+	for _, engine := range engines {
+		counts := make([]int, len(resultsHTMLSeverities)+1)
+		for _, row := range rowsByEngine[engine] {
+			if rank := resultsHTMLSeverityRank(row.severity); rank < len(resultsHTMLSeverities) {
+				counts[rank]++
+			}
+			counts[len(resultsHTMLSeverities)]++
+		}
+		line := []xlsxCell{{value: strings.ToUpper(engine)}}
+		for i, count := range counts {
+			totals[i] += count
+			line = append(line, xlsxCell{value: strconv.Itoa(count), numeric: true})
+		}
+		cells = append(cells, line)
+	}
+	line := []xlsxCell{{value: "Total", bold: true}}
+	for _, total := range totals {
+		line = append(line, xlsxCell{value: strconv.Itoa(total), numeric: true, bold: true})
+	}
+	return append(cells, line)
+}
@@ -0,0 +1,152 @@
+//go:build !integration
+
+package commands
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"gotest.tools/assert"
+)
+
+func spreadsheetTestResults() *wrappers.ScanResultsCollection {
+	results := glDependencyTestResults()
+	results.Results = append(results.Results, &wrappers.ScanResult{
+		Type:         params.SastType,
+		ID:           "sast-1",
+		Severity:     "high",
+		State:        "TO_VERIFY",
+		Status:       "NEW",
+		SimilarityID: "-1234",
+		FirstFoundAt: "2023-05-01T10:20:30Z",
+		ScanResultData: wrappers.ScanResultData{
+			QueryName: "SQL_Injection",
+			Nodes:     []*wrappers.ScanResultNode{{FileName: "=cmd|' /C calc'!A0", Line: 12}},
+		},
+		VulnerabilityDetails: wrappers.VulnerabilityDetails{CweID: float64(89)},
+	})
+	return results
+}
+
+func readXLSXParts(t *testing.T, xlsxFile string) map[string]string {
+	archive, err := zip.OpenReader(xlsxFile)
+	assert.NilError(t, err)
+	defer archive.Close()
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		f, err := file.Open()
+		assert.NilError(t, err)
+		content, err := io.ReadAll(f)
+		assert.NilError(t, err)
+		_ = f.Close()
+		parts[file.Name] = string(content)
+	}
+	return parts
+}
+
+func TestParseSpreadsheetColumns(t *testing.T) {
+	columns, err := parseSpreadsheetColumns("")
+	assert.NilError(t, err)
+	assert.Equal(t, len(columns), len(spreadsheetColumns))
+
+	columns, err = parseSpreadsheetColumns("severity, Query,scanUrl")
+	assert.NilError(t, err)
+	assert.Equal(t, len(columns), 3)
+	assert.Equal(t, columns[1].header, "Query/CVE")
+
+	_, err = parseSpreadsheetColumns("severity,invalid")
+	assert.ErrorContains(t, err, "invalid report column invalid, available columns: engine,severity,state")
+}
+
+func TestToSpreadsheetRow(t *testing.T) {
+	summary := glDependencyTestSummary()
+	rows := toSpreadsheetRows(spreadsheetTestResults(), summary)
+	lodash := rows[3]
+	assert.Equal(t, lodash.engine, "sca")
+	assert.Equal(t, lodash.query, "CVE-2020-8203")
+	assert.Equal(t, lodash.cwe, "CWE-1321")
+	assert.Equal(t, lodash.packageName, "lodash")
+	assert.Equal(t, lodash.version, "4.17.15")
+	assert.Equal(t, lodash.recommendedVersion, "4.17.21")
+	assert.Equal(t, lodash.file, "package.json")
+	assert.Equal(t, lodash.scanURL, summary.BaseURI)
+
+	sast := rows[len(rows)-1]
+	assert.Equal(t, sast.severity, "HIGH")
+	assert.Equal(t, sast.query, "SQL Injection")
+	assert.Equal(t, sast.cwe, "CWE-89")
+	assert.Equal(t, sast.line, "12")
+	assert.Equal(t, sast.similarityID, "-1234")
+}
+
+func TestExportCSVResults(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "results.csv")
+	err := exportCSVResults(csvFile, spreadsheetTestResults(), glDependencyTestSummary(), "engine,severity,query,file,line")
+	assert.NilError(t, err)
+	f, err := os.Open(csvFile)
+	assert.NilError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), len(spreadsheetTestResults().Results)+1)
+	assert.DeepEqual(t, records[0], []string{"Engine", "Severity", "Query/CVE", "File", "Line"})
+	// values starting like a formula stay text
+	assert.DeepEqual(t, records[len(records)-1], []string{"sast", "HIGH", "SQL Injection", "'=cmd|' /C calc'!A0", "12"})
+}
+
+func TestExportXLSXResults(t *testing.T) {
+	xlsxFile := filepath.Join(t.TempDir(), "results.xlsx")
+	summary := glDependencyTestSummary()
+	summary.EnginesEnabled = []string{params.SastType, params.ScaType, params.KicsType}
+	err := exportXLSXResults(xlsxFile, spreadsheetTestResults(), summary, "")
+	assert.NilError(t, err)
+
+	parts := readXLSXParts(t, xlsxFile)
+	workbook := parts["xl/workbook.xml"]
+	assert.Assert(t, strings.Contains(workbook, `<sheet name="Summary" sheetId="1" r:id="rId1"/>`))
+	assert.Assert(t, strings.Contains(workbook, `<sheet name="SAST" sheetId="2" r:id="rId2"/>`))
+	assert.Assert(t, strings.Contains(workbook, `<sheet name="SCA" sheetId="3" r:id="rId3"/>`))
+	assert.Assert(t, strings.Contains(workbook, `<sheet name="KICS" sheetId="4" r:id="rId4"/>`))
+	assert.Assert(t, strings.Contains(parts["[Content_Types].xml"], "/xl/worksheets/sheet4.xml"))
+
+	sast := parts["xl/worksheets/sheet2.xml"]
+	assert.Assert(t, strings.Contains(sast, `<t xml:space="preserve">SQL Injection</t>`))
+	assert.Assert(t, strings.Contains(sast, `<v>12</v>`))
+	assert.Assert(t, strings.Contains(sast, fmt.Sprintf(`<autoFilter ref="A1:%s3"/>`, xlsxColumnName(len(spreadsheetColumns)-1))))
+	// the SCA sheet has the header and the four SCA findings
+	assert.Equal(t, strings.Count(parts["xl/worksheets/sheet3.xml"], "<row "), 5)
+	summarySheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Assert(t, strings.Contains(summarySheet, "https://ast.checkmarx.net/projects/id"))
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	assert.Equal(t, xlsxColumnName(0), "A")
+	assert.Equal(t, xlsxColumnName(25), "Z")
+	assert.Equal(t, xlsxColumnName(26), "AA")
+	assert.Equal(t, xlsxColumnName(701), "ZZ")
+	assert.Equal(t, xlsxColumnName(702), "AAA")
+}
+
+func TestRunGetResultsByScanIdSpreadsheetFormats(t *testing.T) {
+	execCmdNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "csv,xlsx", "--report-columns", "engine,severity,query")
+	for _, format := range []string{printer.FormatCSV, printer.FormatXLSX} {
+		reportFile := fmt.Sprintf("%s.%s", fileName, format)
+		_, err := os.Stat(reportFile)
+		assert.NilError(t, err, "Report file should exist for extension "+format)
+		_ = os.Remove(reportFile)
+	}
+}
+
+func TestRunGetResultsByScanIdCSVInvalidColumn(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "show", "--scan-id", "MOCK", "--report-format", "csv", "--report-columns", "invalid")
+	assert.ErrorContains(t, err, "invalid report column invalid")
+}
@@ -0,0 +1,644 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	htmlTemplate "html/template"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	failedGettingTrends      = "Failed getting the results trends"
+	trendsDefaultSince       = "90d"
+	trendsScansPageSize      = 100
+	trendsScansSort          = "+created_at"
+	trendsOldestOpenLimit    = 10
+	trendsNewStatus          = "NEW"
+	trendsCriticalLabel      = "critical"
+	trendsHoursPerDay        = 24
+	trendsChartWidth         = 760
+	trendsChartHeight        = 240
+	trendsChartPadding       = 40
+	trendsChartBarRatio      = 0.7
+	trendsDateFormat         = "2006-01-02"
+	trendsInvalidSinceFormat = "%s: invalid --%s %s, use a number of days (90d), weeks (12w) or hours (36h)"
+)
+
+var (
+	trendsFormats       = []string{printer.FormatJSON, printer.FormatSummaryMarkdown, printer.FormatHTML}
+	trendsSeverityOrder = []string{trendsCriticalLabel, highLabel, mediumLabel, lowLabel, infoLabel}
+)
+
+type trendsReport struct {
+	GeneratedAt string            `json:"generatedAt"`
+	ProjectID   string            `json:"projectId"`
+	ProjectName string            `json:"projectName"`
+	Branch      string            `json:"branch,omitempty"`
+	Since       string            `json:"since"`
+	From        string            `json:"from"`
+	Severities  []string          `json:"severities"`
+	Engines     []string          `json:"engines"`
+	Scans       []trendsScan      `json:"scans"`
+	Remediation trendsRemediation `json:"timeToRemediate"`
+	OldestOpen  []trendsFinding   `json:"oldestOpenFindings"`
+	Chart       *trendsChart      `json:"-"`
+	scanResults []*trendsScanInput
+}
+
+// trendsScan has the findings of a scan, new, fixed and triaged compare them with the findings of the previous scan:
+// fixed findings are gone from the scan, triaged ones are still found but not exploitable or ignored
+type trendsScan struct {
+	ScanID    string       `json:"scanId"`
+	CreatedAt string       `json:"createdAt"`
+	Open      trendsCounts `json:"open"`
+	New       trendsCounts `json:"new"`
+	Fixed     trendsCounts `json:"fixed"`
+	Triaged   trendsCounts `json:"triaged"`
+}
+
+type trendsCounts struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"bySeverity"`
+	ByEngine   map[string]int `json:"byEngine"`
+}
+
+type trendsRemediation struct {
+	trendsRemediationTime
+	BySeverity []trendsSeverityRemediation `json:"bySeverity"`
+}
+
+type trendsSeverityRemediation struct {
+	Severity string `json:"severity"`
+	trendsRemediationTime
+}
+
+type trendsRemediationTime struct {
+	Fixed      int     `json:"fixed"`
+	MeanDays   float64 `json:"meanDays"`
+	MedianDays float64 `json:"medianDays"`
+}
+
+type trendsFinding struct {
+	Engine       string  `json:"engine"`
+	Severity     string  `json:"severity"`
+	Finding      string  `json:"finding"`
+	Location     string  `json:"location,omitempty"`
+	FirstFoundAt string  `json:"firstFoundAt,omitempty"`
+	FirstScanID  string  `json:"firstScanId,omitempty"`
+	AgeDays      float64 `json:"ageDays"`
+}
+
+// trendsChart is the stacked bar chart of the open findings of each scan in the HTML report
+type trendsChart struct {
+	Width     int
+	Height    int
+	Left      int
+	Right     int
+	Top       int
+	Bottom    int
+	MaxOpen   int
+	FirstDate string
+	LastDate  string
+	Bars      []trendsBar
+}
+
+type trendsBar struct {
+	X        float64
+	Width    float64
+	Title    string
+	Segments []trendsBarSegment
+}
+
+type trendsBarSegment struct {
+	Y        float64
+	Height   float64
+	Severity string
+}
+
+type trendsScanInput struct {
+	scan    *wrappers.ScanResponseModel
+	results *wrappers.ScanResultsCollection
+}
+
+func resultTrendsSubCommand(
+	resultsWrapper wrappers.ResultsWrapper,
+	scanWrapper wrappers.ScansWrapper,
+	projectsWrapper wrappers.ProjectsWrapper,
+) *cobra.Command {
+	trendsCmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Show how the results of a project change over its scans",
+		Long: "The trends command reads the completed scans of a project branch and reports, for each scan, the new, fixed, " +
+			"triaged and open results by engine and severity, with the time to remediate and the oldest open results. " +
+			"A scan is only compared for the engines it ran, the results of the other engines stay open.",
+		Example: heredoc.Doc(
+			`
+			$ cx results trends --project-id <project Id> --branch main --since 90d
+			$ cx results trends --project-id <project Id> --since 12w --format html > trends.html
+		`,
+		),
+		RunE: runGetTrendsCommand(resultsWrapper, scanWrapper, projectsWrapper),
+	}
+	trendsCmd.PersistentFlags().String(commonParams.ProjectIDFlag, "", "ID of the project")
+	trendsCmd.PersistentFlags().String(commonParams.BranchFlag, "", "Branch of the scans, the main branch of the project by default")
+	trendsCmd.PersistentFlags().String(commonParams.SinceFlag, trendsDefaultSince,
+		"How far back to read the scans, in days (90d), weeks (12w) or hours (36h)")
+	trendsCmd.PersistentFlags().Int(commonParams.ParallelismFlag, portfolioDefaultParallelism,
+		"Number of scans read at the same time")
+	addFormatFlag(trendsCmd, printer.FormatSummaryMarkdown, printer.FormatJSON, printer.FormatHTML)
+	return trendsCmd
+}
+
+func runGetTrendsCommand(
+	resultsWrapper wrappers.ResultsWrapper,
+	scanWrapper wrappers.ScansWrapper,
+	projectsWrapper wrappers.ProjectsWrapper,
+) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		projectID, _ := cmd.Flags().GetString(commonParams.ProjectIDFlag)
+		branch, _ := cmd.Flags().GetString(commonParams.BranchFlag)
+		since, _ := cmd.Flags().GetString(commonParams.SinceFlag)
+		parallelism, _ := cmd.Flags().GetInt(commonParams.ParallelismFlag)
+		format, _ := cmd.Flags().GetString(commonParams.FormatFlag)
+		if strings.TrimSpace(projectID) == "" {
+			return errors.Errorf("%s: Please provide --%s", failedGettingTrends, commonParams.ProjectIDFlag)
+		}
+		period, err := parseTrendsSince(since)
+		if err != nil {
+			return err
+		}
+		if parallelism < 1 {
+			return errors.Errorf("%s: --%s should be at least 1", failedGettingTrends, commonParams.ParallelismFlag)
+		}
+		if !containsFold(trendsFormats, format) {
+			return errors.Errorf(portfolioInvalidFormat, failedGettingTrends, format, strings.Join(trendsFormats, ", "))
+		}
+
+		project, errorModel, err := projectsWrapper.GetByID(projectID)
+		if err != nil {
+			return errors.Wrapf(err, "%s", failedGettingProj)
+		}
+		if errorModel != nil {
+			return errors.Errorf(ErrorCodeFormat, failedGettingProj, errorModel.Code, errorModel.Message)
+		}
+		if branch == "" {
+			branch = project.MainBranch
+		}
+		now := time.Now()
+		from := now.Add(-period)
+		scans, err := findTrendsScans(scanWrapper, projectID, branch, from)
+		if err != nil {
+			return err
+		}
+		inputs, err := readTrendsScans(resultsWrapper, scans, parallelism)
+		if err != nil {
+			return err
+		}
+		report := &trendsReport{
+			GeneratedAt: now.Format(portfolioTimeFormat),
+			ProjectID:   projectID,
+			ProjectName: project.Name,
+			Branch:      branch,
+			Since:       since,
+			From:        from.Format(portfolioTimeFormat),
+			scanResults: inputs,
+		}
+		computeTrends(report, now)
+		return printTrendsReport(cmd.OutOrStdout(), report, format)
+	}
+}
+
+// parseTrendsSince reads a period of days (90d) or weeks (12w), else a Go duration (36h)
+func parseTrendsSince(since string) (time.Duration, error) {
+	since = strings.ToLower(strings.TrimSpace(since))
+	var period time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(since, "d"), strings.HasSuffix(since, "w"):
+		days := 1
+		if strings.HasSuffix(since, "w") {
+			days = 7
+		}
+		var count int
+		count, err = strconv.Atoi(since[:len(since)-1])
+		period = time.Duration(count*days*trendsHoursPerDay) * time.Hour
+	default:
+		period, err = time.ParseDuration(since)
+	}
+	if err != nil || period <= 0 {
+		return 0, errors.Errorf(trendsInvalidSinceFormat, failedGettingTrends, commonParams.SinceFlag, since)
+	}
+	return period, nil
+}
+
+// findTrendsScans lists the completed scans of the branch created after from, the oldest first
+func findTrendsScans(scansWrapper wrappers.ScansWrapper, projectID, branch string, from time.Time) ([]wrappers.ScanResponseModel, error) {
+	params := map[string]string{
+		commonParams.ProjectIDQueryParam: projectID,
+		commonParams.StatusesQueryParam:  portfolioCompletedStatus,
+		commonParams.SortQueryParam:      trendsScansSort,
+		commonParams.FromDateQueryParam:  from.UTC().Format(time.RFC3339),
+		commonParams.LimitQueryParam:     strconv.Itoa(trendsScansPageSize),
+	}
+	if branch != "" {
+		params[commonParams.BranchQueryParam] = branch
+	}
+	var scans []wrappers.ScanResponseModel
+	for offset := 0; ; offset += trendsScansPageSize {
+		params[commonParams.OffsetQueryParam] = strconv.Itoa(offset)
+		page, errorModel, err := scansWrapper.Get(params)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", failedGettingScan)
+		}
+		if errorModel != nil {
+			return nil, errors.Errorf("%s: CODE: %d, %s", failedGettingScan, errorModel.Code, errorModel.Message)
+		}
+		for i := range page.Scans {
+			if page.Scans[i].CreatedAt.IsZero() || !page.Scans[i].CreatedAt.Before(from) {
+				scans = append(scans, page.Scans[i])
+			}
+		}
+		if len(page.Scans) < trendsScansPageSize || offset+len(page.Scans) >= int(page.FilteredTotalCount) {
+			break
+		}
+	}
+	sort.SliceStable(scans, func(i, j int) bool {
+		return scans[i].CreatedAt.Before(scans[j].CreatedAt)
+	})
+	return scans, nil
+}
+
+// readTrendsScans reads the results of the scans with at most parallelism of them at the same time, the first
+// failing scan fails the report since the next scans are compared with it
+func readTrendsScans(resultsWrapper wrappers.ResultsWrapper, scans []wrappers.ScanResponseModel, parallelism int) ([]*trendsScanInput, error) {
+	inputs := make([]*trendsScanInput, len(scans))
+	errs := make([]error, len(scans))
+	cacheAccessToken()
+	slots := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i := range scans {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			logger.PrintIfVerbose(fmt.Sprintf("Reading the results of scan %s", scans[i].ID))
+			results, err := ReadResults(resultsWrapper, &scans[i], make(map[string]string))
+			inputs[i], errs[i] = &trendsScanInput{scan: &scans[i], results: results}, err
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// computeTrends compares the open results of each scan with the previous one, a result is identified by its engine
+// and similarity ID. Only the engines a scan ran are compared: the results of the other engines are carried forward
+// unchanged. An engine run for the first time, as in the first scan, has for new results the ones with the NEW status.
+func computeTrends(report *trendsReport, now time.Time) {
+	severities := map[string]bool{}
+	engines := map[string]bool{}
+	firstSeen := map[string]time.Time{}
+	remediation := map[string][]time.Duration{}
+	// engines run by the earlier scans, scans that do not list their engines ran all of them
+	ranEngines := map[string]bool{}
+	ranAllEngines := false
+	var previous map[string]*wrappers.ScanResult
+	for _, input := range report.scanResults {
+		open, triaged := trendsScanResults(input.results)
+		carried := map[string]bool{}
+		for key, result := range previous {
+			if open[key] == nil && !trendsScanRanEngine(input.scan, trendsResultEngine(result)) {
+				open[key] = result
+				carried[key] = true
+			}
+		}
+		trendsScan := trendsScan{
+			ScanID:    input.scan.ID,
+			CreatedAt: input.scan.CreatedAt.Format(portfolioTimeFormat),
+			Open:      newTrendsCounts(),
+			New:       newTrendsCounts(),
+			Fixed:     newTrendsCounts(),
+			Triaged:   newTrendsCounts(),
+		}
+		for key, result := range open {
+			if _, ok := firstSeen[key]; !ok {
+				firstSeen[key] = input.scan.CreatedAt
+			}
+			trendsScan.Open.add(result)
+			if carried[key] {
+				continue
+			}
+			isNew := previous[key] == nil
+			if !ranAllEngines && !ranEngines[trendsResultEngine(result)] {
+				isNew = strings.EqualFold(result.Status, trendsNewStatus)
+			}
+			if isNew {
+				trendsScan.New.add(result)
+			}
+		}
+		for key, result := range previous {
+			if open[key] != nil {
+				continue
+			}
+			// a result triaged as not exploitable or ignored is not remediated
+			if triaged[key] != nil {
+				trendsScan.Triaged.add(result)
+				continue
+			}
+			trendsScan.Fixed.add(result)
+			severity := trendsSeverity(result)
+			timeToRemediate := max(input.scan.CreatedAt.Sub(trendsFirstFound(result, firstSeen[key])), 0)
+			remediation[severity] = append(remediation[severity], timeToRemediate)
+		}
+		for _, counts := range []trendsCounts{trendsScan.Open, trendsScan.New, trendsScan.Fixed, trendsScan.Triaged} {
+			for severity := range counts.BySeverity {
+				severities[severity] = true
+			}
+			for engine := range counts.ByEngine {
+				engines[engine] = true
+			}
+		}
+		report.Scans = append(report.Scans, trendsScan)
+		previous = open
+		if len(input.scan.Engines) == 0 {
+			ranAllEngines = true
+		}
+		for _, engine := range input.scan.Engines {
+			ranEngines[trendsEngine(engine)] = true
+		}
+	}
+	report.Severities = sortTrendsSeverities(severities)
+	report.Engines = sortedTrendsKeys(engines)
+	report.Remediation = toTrendsRemediation(remediation, report.Severities)
+	report.OldestOpen = oldestTrendsFindings(previous, firstSeen, now)
+	report.Chart = toTrendsChart(report)
+}
+
+// trendsEngine names the engines of scans and results alike, results of IaC Security have the kics type
+func trendsEngine(engine string) string {
+	return strings.Replace(strings.ToLower(strings.TrimSpace(engine)), commonParams.IacType, commonParams.KicsType, 1)
+}
+
+func trendsResultEngine(result *wrappers.ScanResult) string {
+	return trendsEngine(result.Type)
+}
+
+// trendsScanRanEngine reports whether a scan ran an engine, scans that do not list their engines ran all of them
+func trendsScanRanEngine(scan *wrappers.ScanResponseModel, engine string) bool {
+	if len(scan.Engines) == 0 {
+		return true
+	}
+	for _, scanEngine := range scan.Engines {
+		if trendsEngine(scanEngine) == engine {
+			return true
+		}
+	}
+	return false
+}
+
+// trendsScanResults splits the results of a scan into the open ones and the ones triaged as not exploitable or ignored
+func trendsScanResults(results *wrappers.ScanResultsCollection) (open, triaged map[string]*wrappers.ScanResult) {
+	open = map[string]*wrappers.ScanResult{}
+	triaged = map[string]*wrappers.ScanResult{}
+	if results == nil {
+		return open, triaged
+	}
+	for _, result := range results.Results {
+		if isExploitable(result.State) {
+			open[trendsResultKey(result)] = result
+		} else {
+			triaged[trendsResultKey(result)] = result
+		}
+	}
+	return open, triaged
+}
+
+func trendsResultKey(result *wrappers.ScanResult) string {
+	id := result.SimilarityID
+	if id == "" {
+		id = result.ID
+	}
+	return strings.TrimSpace(result.Type) + "|" + id
+}
+
+func trendsSeverity(result *wrappers.ScanResult) string {
+	return strings.ToLower(result.Severity)
+}
+
+// trendsFirstFound is the first found date of the result, else the first scan of the report it is open in
+func trendsFirstFound(result *wrappers.ScanResult, firstSeen time.Time) time.Time {
+	if firstFoundAt, err := time.Parse(time.RFC3339, result.FirstFoundAt); err == nil {
+		return firstFoundAt
+	}
+	return firstSeen
+}
+
+func newTrendsCounts() trendsCounts {
+	return trendsCounts{BySeverity: map[string]int{}, ByEngine: map[string]int{}}
+}
+
+func (c *trendsCounts) add(result *wrappers.ScanResult) {
+	c.Total++
+	c.BySeverity[trendsSeverity(result)]++
+	c.ByEngine[strings.TrimSpace(result.Type)]++
+}
+
+// sortTrendsSeverities orders the severities from critical to info, other severities come last
+func sortTrendsSeverities(severities map[string]bool) []string {
+	sorted := []string{}
+	for _, severity := range trendsSeverityOrder {
+		if severities[severity] {
+			sorted = append(sorted, severity)
+			delete(severities, severity)
+		}
+	}
+	return append(sorted, sortedTrendsKeys(severities)...)
+}
+
+func sortedTrendsKeys(values map[string]bool) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toTrendsRemediation(remediation map[string][]time.Duration, severities []string) trendsRemediation {
+	var all []time.Duration
+	trendsRemediation := trendsRemediation{BySeverity: []trendsSeverityRemediation{}}
+	for _, severity := range severities {
+		if durations := remediation[severity]; len(durations) > 0 {
+			all = append(all, durations...)
+			trendsRemediation.BySeverity = append(trendsRemediation.BySeverity, trendsSeverityRemediation{
+				Severity:              severity,
+				trendsRemediationTime: toTrendsRemediationTime(durations),
+			})
+		}
+	}
+	trendsRemediation.trendsRemediationTime = toTrendsRemediationTime(all)
+	return trendsRemediation
+}
+
+func toTrendsRemediationTime(durations []time.Duration) trendsRemediationTime {
+	if len(durations) == 0 {
+		return trendsRemediationTime{}
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, duration := range sorted {
+		total += duration
+	}
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return trendsRemediationTime{
+		Fixed:      len(sorted),
+		MeanDays:   trendsDays(total / time.Duration(len(sorted))),
+		MedianDays: trendsDays(median),
+	}
+}
+
+// trendsDays rounds a duration to a tenth of a day
+func trendsDays(duration time.Duration) float64 {
+	return math.Round(duration.Hours()/trendsHoursPerDay*10) / 10
+}
+
+// oldestTrendsFindings lists the open results of the latest scan, the ones first found the longest ago first
+func oldestTrendsFindings(open map[string]*wrappers.ScanResult, firstSeen map[string]time.Time, now time.Time) []trendsFinding {
+	keys := make([]string, 0, len(open))
+	for key := range open {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := trendsFirstFound(open[keys[i]], firstSeen[keys[i]]), trendsFirstFound(open[keys[j]], firstSeen[keys[j]])
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > trendsOldestOpenLimit {
+		keys = keys[:trendsOldestOpenLimit]
+	}
+	findings := []trendsFinding{}
+	for _, key := range keys {
+		result := open[key]
+		firstFound := trendsFirstFound(result, firstSeen[key])
+		// the row is only used for its finding and location, the result URL is not needed
+		row := toSpreadsheetRow(result, &wrappers.ResultSummary{})
+		location := row.file
+		if row.packageName != "" {
+			location = strings.TrimSpace(row.packageName + " " + row.version)
+		} else if row.line != "" {
+			location += ":" + row.line
+		}
+		finding := trendsFinding{
+			Engine:      row.engine,
+			Severity:    trendsSeverity(result),
+			Finding:     row.query,
+			Location:    location,
+			FirstScanID: result.FirstScanID,
+		}
+		if !firstFound.IsZero() {
+			finding.FirstFoundAt = firstFound.Format(trendsDateFormat)
+			finding.AgeDays = trendsDays(max(now.Sub(firstFound), 0))
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// toTrendsChart stacks the open results of each scan by severity, the most severe at the bottom
+func toTrendsChart(report *trendsReport) *trendsChart {
+	chart := &trendsChart{
+		Width:  trendsChartWidth,
+		Height: trendsChartHeight,
+		Left:   trendsChartPadding,
+		Right:  trendsChartWidth - trendsChartPadding,
+		Top:    trendsChartPadding,
+		Bottom: trendsChartHeight - trendsChartPadding,
+	}
+	if len(report.Scans) == 0 {
+		return chart
+	}
+	for i := range report.Scans {
+		chart.MaxOpen = max(chart.MaxOpen, report.Scans[i].Open.Total)
+	}
+	chart.FirstDate = report.scanResults[0].scan.CreatedAt.Format(trendsDateFormat)
+	chart.LastDate = report.scanResults[len(report.scanResults)-1].scan.CreatedAt.Format(trendsDateFormat)
+	plotWidth := float64(chart.Right - chart.Left)
+	plotHeight := float64(chart.Bottom - chart.Top)
+	slot := plotWidth / float64(len(report.Scans))
+	for i := range report.Scans {
+		scan := &report.Scans[i]
+		bar := trendsBar{
+			X:     trendsChartCoordinate(float64(chart.Left) + float64(i)*slot + slot*(1-trendsChartBarRatio)/2),
+			Width: trendsChartCoordinate(slot * trendsChartBarRatio),
+			Title: fmt.Sprintf("%s: %d open, %d new, %d fixed, %d triaged", scan.CreatedAt, scan.Open.Total, scan.New.Total,
+				scan.Fixed.Total, scan.Triaged.Total),
+		}
+		y := float64(chart.Bottom)
+		for _, severity := range report.Severities {
+			count := scan.Open.BySeverity[severity]
+			if count == 0 || chart.MaxOpen == 0 {
+				continue
+			}
+			height := plotHeight * float64(count) / float64(chart.MaxOpen)
+			y -= height
+			bar.Segments = append(bar.Segments, trendsBarSegment{
+				Y:        trendsChartCoordinate(y),
+				Height:   trendsChartCoordinate(height),
+				Severity: severity,
+			})
+		}
+		chart.Bars = append(chart.Bars, bar)
+	}
+	return chart
+}
+
+func trendsChartCoordinate(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func printTrendsReport(w io.Writer, report *trendsReport, format string) error {
+	switch {
+	case printer.IsFormat(format, printer.FormatJSON):
+		return printer.Print(w, report, printer.FormatJSON)
+	case printer.IsFormat(format, printer.FormatHTML):
+		trendsTemplate, err := htmlTemplate.New("trendsTemplate").Parse(wrappers.TrendsHTMLTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to parse the HTML template", failedGettingTrends)
+		}
+		return trendsTemplate.Execute(w, report)
+	default:
+		trendsTemplate, err := template.New("trendsTemplate").Parse(wrappers.TrendsMarkdownTemplate)
+		if err != nil {
+			return errors.Wrapf(err, "%s: failed to parse the markdown template", failedGettingTrends)
+		}
+		return trendsTemplate.Execute(w, report)
+	}
+}
@@ -0,0 +1,277 @@
+//go:build !integration
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
+	"gotest.tools/assert"
+)
+
+var trendsTestStart = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// trendsScansWrapper returns the scans in the wrong order, with one scan before the period
+type trendsScansWrapper struct {
+	mock.ScansMockWrapper
+	params map[string]string
+}
+
+func (s *trendsScansWrapper) Get(filters map[string]string) (*wrappers.ScansCollectionResponseModel, *wrappers.ErrorModel, error) {
+	s.params = filters
+	return &wrappers.ScansCollectionResponseModel{
+		FilteredTotalCount: 3,
+		Scans: []wrappers.ScanResponseModel{
+			{ID: "second", CreatedAt: trendsTestStart.AddDate(0, 0, 10)},
+			{ID: "first", CreatedAt: trendsTestStart},
+			{ID: "before", CreatedAt: trendsTestStart.AddDate(0, 0, -1)},
+		},
+	}, nil, nil
+}
+
+func trendsTestResult(engine, id, severity, status, state, firstFoundAt string) *wrappers.ScanResult {
+	return &wrappers.ScanResult{
+		Type:         engine,
+		ID:           id,
+		SimilarityID: id,
+		Severity:     severity,
+		Status:       status,
+		State:        state,
+		FirstFoundAt: firstFoundAt,
+		FirstScanID:  id + "-scan",
+		ScanResultData: wrappers.ScanResultData{
+			QueryName: "Privileged_Container",
+			Filename:  "deploy.yaml",
+			Line:      3,
+		},
+	}
+}
+
+// trendsTestReport has three scans 10 days apart: a is fixed by the second scan, d is found by the second scan and
+// fixed by the third one, b stays open and c is not exploitable
+func trendsTestReport() *trendsReport {
+	a := trendsTestResult(params.SastType, "a", "HIGH", "NEW", "TO_VERIFY", trendsTestStart.AddDate(0, 0, -5).Format(time.RFC3339))
+	b := trendsTestResult(params.KicsType, "b", "MEDIUM", "RECURRENT", "CONFIRMED", trendsTestStart.AddDate(0, 0, -30).Format(time.RFC3339))
+	c := trendsTestResult(params.SastType, "c", "LOW", "NEW", notExploitable, "")
+	d := trendsTestResult(params.SastType, "d", "HIGH", "NEW", "TO_VERIFY", "")
+	scan := func(id string, days int, results ...*wrappers.ScanResult) *trendsScanInput {
+		return &trendsScanInput{
+			scan:    &wrappers.ScanResponseModel{ID: id, CreatedAt: trendsTestStart.AddDate(0, 0, days)},
+			results: &wrappers.ScanResultsCollection{Results: results},
+		}
+	}
+	return &trendsReport{scanResults: []*trendsScanInput{
+		scan("1", 0, a, b, c),
+		scan("2", 10, b, c, d),
+		scan("3", 20, b),
+	}}
+}
+
+func TestParseTrendsSince(t *testing.T) {
+	for since, expected := range map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"12W": 12 * 7 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		period, err := parseTrendsSince(since)
+		assert.NilError(t, err)
+		assert.Equal(t, period, expected)
+	}
+	for _, since := range []string{"", "0d", "-1d", "d", "ninety days"} {
+		_, err := parseTrendsSince(since)
+		assert.ErrorContains(t, err, "invalid --since")
+	}
+}
+
+func TestFindTrendsScans(t *testing.T) {
+	scansWrapper := &trendsScansWrapper{}
+	scans, err := findTrendsScans(scansWrapper, "project", "main", trendsTestStart)
+	assert.NilError(t, err)
+	assert.Equal(t, scansWrapper.params[params.ProjectIDQueryParam], "project")
+	assert.Equal(t, scansWrapper.params[params.BranchQueryParam], "main")
+	assert.Equal(t, scansWrapper.params[params.StatusesQueryParam], "Completed")
+	assert.Equal(t, scansWrapper.params[params.FromDateQueryParam], "2023-01-01T00:00:00Z")
+	assert.Equal(t, len(scans), 2)
+	assert.Equal(t, scans[0].ID, "first")
+	assert.Equal(t, scans[1].ID, "second")
+}
+
+func TestComputeTrends(t *testing.T) {
+	report := trendsTestReport()
+	computeTrends(report, trendsTestStart.AddDate(0, 0, 30))
+
+	assert.DeepEqual(t, report.Severities, []string{"high", "medium"})
+	assert.DeepEqual(t, report.Engines, []string{params.KicsType, params.SastType})
+	assert.Equal(t, len(report.Scans), 3)
+	first, second, third := report.Scans[0], report.Scans[1], report.Scans[2]
+	assert.Equal(t, first.Open.Total, 2)
+	assert.Equal(t, first.New.Total, 1)
+	assert.Equal(t, first.Fixed.Total, 0)
+	assert.Equal(t, second.Open.Total, 2)
+	assert.Equal(t, second.New.ByEngine[params.SastType], 1)
+	assert.Equal(t, second.Fixed.BySeverity["high"], 1)
+	assert.Equal(t, third.Open.BySeverity["medium"], 1)
+	assert.Equal(t, third.New.Total, 0)
+	assert.Equal(t, third.Fixed.Total, 1)
+
+	// a is fixed 15 days after it was first found, d 10 days after the scan that found it
+	assert.Equal(t, report.Remediation.Fixed, 2)
+	assert.Equal(t, report.Remediation.MeanDays, 12.5)
+	assert.Equal(t, report.Remediation.MedianDays, 12.5)
+	assert.Equal(t, len(report.Remediation.BySeverity), 1)
+	assert.Equal(t, report.Remediation.BySeverity[0].Severity, "high")
+
+	assert.DeepEqual(t, report.OldestOpen, []trendsFinding{{
+		Engine:       params.KicsType,
+		Severity:     "medium",
+		Finding:      "Privileged_Container",
+		Location:     "deploy.yaml:3",
+		FirstFoundAt: "2022-12-02",
+		FirstScanID:  "b-scan",
+		AgeDays:      60,
+	}})
+
+	assert.Equal(t, len(report.Chart.Bars), 3)
+	assert.Equal(t, report.Chart.MaxOpen, 2)
+	assert.Equal(t, len(report.Chart.Bars[0].Segments), 2)
+	assert.Equal(t, report.Chart.Bars[0].Segments[0].Severity, "high")
+	assert.Equal(t, report.Chart.Bars[0].Segments[1].Y, float64(report.Chart.Top))
+	assert.Equal(t, report.Chart.FirstDate, "2023-01-01")
+}
+
+// TestComputeTrendsDifferentEngines runs the second scan without kics: b is carried forward, neither fixed nor new,
+// until the third scan runs kics again and fixes it. The fourth scan runs sca for the first time.
+func TestComputeTrendsDifferentEngines(t *testing.T) {
+	a := trendsTestResult(params.SastType, "a", "HIGH", "NEW", "TO_VERIFY", trendsTestStart.AddDate(0, 0, -5).Format(time.RFC3339))
+	b := trendsTestResult(params.KicsType, "b", "MEDIUM", "RECURRENT", "CONFIRMED", trendsTestStart.AddDate(0, 0, -30).Format(time.RFC3339))
+	e := trendsTestResult(params.SastType, "e", "HIGH", "NEW", "TO_VERIFY", "")
+	f := trendsTestResult(params.KicsType, "f", "LOW", "NEW", "TO_VERIFY", "")
+	g := trendsTestResult(params.ScaType, "g", "HIGH", "RECURRENT", "TO_VERIFY", "")
+	h := trendsTestResult(params.ScaType, "h", "MEDIUM", "NEW", "TO_VERIFY", "")
+	scan := func(id string, days int, engines []string, results ...*wrappers.ScanResult) *trendsScanInput {
+		return &trendsScanInput{
+			scan:    &wrappers.ScanResponseModel{ID: id, CreatedAt: trendsTestStart.AddDate(0, 0, days), Engines: engines},
+			results: &wrappers.ScanResultsCollection{Results: results},
+		}
+	}
+	report := &trendsReport{scanResults: []*trendsScanInput{
+		scan("1", 0, []string{params.SastType, params.KicsType}, a, b),
+		scan("2", 10, []string{params.SastType}, e),
+		scan("3", 20, []string{params.SastType, params.IacType}, e, f),
+		scan("4", 30, []string{params.SastType, params.KicsType, params.ScaType}, e, f, g, h),
+	}}
+	computeTrends(report, trendsTestStart.AddDate(0, 0, 30))
+
+	second, third, fourth := report.Scans[1], report.Scans[2], report.Scans[3]
+	assert.Equal(t, second.Open.Total, 2)
+	assert.Equal(t, second.Open.ByEngine[params.KicsType], 1)
+	assert.Equal(t, second.New.Total, 1)
+	assert.Equal(t, second.New.ByEngine[params.SastType], 1)
+	assert.Equal(t, second.Fixed.Total, 1)
+	assert.Equal(t, second.Fixed.ByEngine[params.SastType], 1)
+
+	assert.Equal(t, third.Open.Total, 2)
+	assert.Equal(t, third.New.Total, 1)
+	assert.Equal(t, third.New.ByEngine[params.KicsType], 1)
+	assert.Equal(t, third.Fixed.Total, 1)
+	assert.Equal(t, third.Fixed.ByEngine[params.KicsType], 1)
+
+	assert.Equal(t, fourth.Open.Total, 4)
+	assert.Equal(t, fourth.New.Total, 1)
+	assert.Equal(t, fourth.New.BySeverity["medium"], 1)
+	assert.Equal(t, fourth.Fixed.Total, 0)
+
+	// a is fixed 15 days after it was first found, b 50 days after, when kics runs again
+	assert.Equal(t, report.Remediation.Fixed, 2)
+	assert.Equal(t, report.Remediation.MeanDays, 32.5)
+}
+
+// TestComputeTrendsTriaged triages a as not exploitable and b as ignored in the second scan: they are neither open
+// nor fixed and have no time to remediate. c is fixed by the third scan.
+func TestComputeTrendsTriaged(t *testing.T) {
+	a := trendsTestResult(params.SastType, "a", "HIGH", "NEW", "TO_VERIFY", trendsTestStart.AddDate(0, 0, -5).Format(time.RFC3339))
+	b := trendsTestResult(params.KicsType, "b", "MEDIUM", "NEW", "CONFIRMED", "")
+	c := trendsTestResult(params.SastType, "c", "LOW", "NEW", "TO_VERIFY", "")
+	triagedA := trendsTestResult(params.SastType, "a", "HIGH", "RECURRENT", notExploitable, a.FirstFoundAt)
+	ignoredB := trendsTestResult(params.KicsType, "b", "MEDIUM", "RECURRENT", ignored, "")
+	scan := func(id string, days int, results ...*wrappers.ScanResult) *trendsScanInput {
+		return &trendsScanInput{
+			scan:    &wrappers.ScanResponseModel{ID: id, CreatedAt: trendsTestStart.AddDate(0, 0, days)},
+			results: &wrappers.ScanResultsCollection{Results: results},
+		}
+	}
+	report := &trendsReport{scanResults: []*trendsScanInput{
+		scan("1", 0, a, b, c),
+		scan("2", 10, triagedA, ignoredB, c),
+		scan("3", 20, triagedA, ignoredB),
+	}}
+	computeTrends(report, trendsTestStart.AddDate(0, 0, 30))
+
+	second, third := report.Scans[1], report.Scans[2]
+	assert.Equal(t, second.Open.Total, 1)
+	assert.Equal(t, second.Fixed.Total, 0)
+	assert.Equal(t, second.Triaged.Total, 2)
+	assert.Equal(t, second.Triaged.BySeverity["high"], 1)
+	assert.Equal(t, second.Triaged.ByEngine[params.KicsType], 1)
+	assert.Equal(t, third.Open.Total, 0)
+	assert.Equal(t, third.Fixed.Total, 1)
+	assert.Equal(t, third.Triaged.Total, 0)
+
+	// only c is remediated, 20 days after the scan that found it
+	assert.Equal(t, report.Remediation.Fixed, 1)
+	assert.Equal(t, report.Remediation.MeanDays, 20.0)
+	assert.Equal(t, len(report.OldestOpen), 0)
+}
+
+func TestToTrendsRemediationTime(t *testing.T) {
+	remediationTime := toTrendsRemediationTime([]time.Duration{72 * time.Hour, 24 * time.Hour, 240 * time.Hour})
+	assert.Equal(t, remediationTime.Fixed, 3)
+	assert.Equal(t, remediationTime.MeanDays, 4.7)
+	assert.Equal(t, remediationTime.MedianDays, 3.0)
+	assert.Equal(t, toTrendsRemediationTime(nil), trendsRemediationTime{})
+}
+
+func TestPrintTrendsReport(t *testing.T) {
+	report := trendsTestReport()
+	report.ProjectName = "payments"
+	computeTrends(report, trendsTestStart.AddDate(0, 0, 30))
+
+	var markdown bytes.Buffer
+	assert.NilError(t, printTrendsReport(&markdown, report, printer.FormatSummaryMarkdown))
+	assert.Assert(t, strings.Contains(markdown.String(), "| Scan | Created | Open | New | Fixed | Triaged | high | medium | kics | sast |"))
+	assert.Assert(t, strings.Contains(markdown.String(), "| 2 | 2023-01-11 00:00:00 UTC | 2 | 1 | 1 | 0 | 1 | 1 | 1 | 1 |"))
+	assert.Assert(t, strings.Contains(markdown.String(), "| all | 2 | 12.5 | 12.5 |"))
+	assert.Assert(t, strings.Contains(markdown.String(), "| medium | kics | Privileged_Container | deploy.yaml:3 | 2022-12-02 | 60 |"))
+
+	var html bytes.Buffer
+	assert.NilError(t, printTrendsReport(&html, report, printer.FormatHTML))
+	assert.Assert(t, strings.Contains(html.String(), `<rect class="high"`))
+	assert.Assert(t, strings.Contains(html.String(), "<title>2023-01-21 00:00:00 UTC: 1 open, 0 new, 1 fixed, 0 triaged</title>"))
+
+	empty := &trendsReport{}
+	computeTrends(empty, trendsTestStart)
+	markdown.Reset()
+	assert.NilError(t, printTrendsReport(&markdown, empty, printer.FormatSummaryMarkdown))
+	assert.Assert(t, strings.Contains(markdown.String(), "No completed scans found"))
+	assert.Assert(t, strings.Contains(markdown.String(), "No fixed results"))
+}
+
+func TestRunGetResultsTrendsCommand(t *testing.T) {
+	execCmdNilAssertion(t, "results", "trends", "--project-id", "MOCK", "--since", "12w", "--format", "json")
+}
+
+func TestRunGetResultsTrendsCommandWithoutProject(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "trends")
+	assert.ErrorContains(t, err, "Please provide --project-id")
+}
+
+func TestRunGetResultsTrendsCommandInvalidSince(t *testing.T) {
+	err := execCmdNotNilAssertion(t, "results", "trends", "--project-id", "MOCK", "--since", "3 months")
+	assert.ErrorContains(t, err, "invalid --since 3 months")
+}
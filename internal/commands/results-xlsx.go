@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	xlsxMaxCellLength = 32767
+	xlsxMaxSheetName  = 31
+	xlsxMainNamespace = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelNamespace  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xlsxXMLHeader     = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+	xlsxContentTypes  = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`%s</Types>`
+	xlsxSheetContentType = `<Override PartName="/xl/worksheets/sheet%d.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`
+	xlsxRootRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+		`Target="xl/workbook.xml"/></Relationships>`
+	// xlsxStyles has the default style and a bold one
+	xlsxStyles = `<styleSheet xmlns="` + xlsxMainNamespace + `">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`
+)
+
+// xlsxWorkbook writes an Office Open XML workbook with inline strings, enough for the xlsx results report
+type xlsxWorkbook struct {
+	sheets []xlsxSheet
+}
+
+// xlsxSheet holds its rows of cells, a table sheet has its first row frozen and filters on it
+type xlsxSheet struct {
+	name  string
+	cells [][]xlsxCell
+	table bool
+}
+
+type xlsxCell struct {
+	value   string
+	numeric bool
+	bold    bool
+}
+
+func (w *xlsxWorkbook) addSheet(name string, cells [][]xlsxCell, table bool) {
+	w.sheets = append(w.sheets, xlsxSheet{name: xlsxSheetName(name), cells: cells, table: table})
+}
+
+func (w *xlsxWorkbook) write(out io.Writer) error {
+	archive := zip.NewWriter(out)
+	var contentTypes, sheets, rels strings.Builder
+	for i := range w.sheets {
+		number := i + 1
+		fmt.Fprintf(&contentTypes, xlsxSheetContentType, number)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(w.sheets[i].name), number, number)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, number, xlsxRelNamespace, number)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`, len(w.sheets)+1, xlsxRelNamespace)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, contentTypes.String())},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(`<workbook xmlns="%s" xmlns:r="%s"><sheets>%s</sheets></workbook>`,
+			xlsxMainNamespace, xlsxRelNamespace, sheets.String())},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i := range w.sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), w.sheets[i].xml()})
+	}
+	for _, part := range parts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(f, xlsxXMLHeader+part.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func (s *xlsxSheet) xml() string {
+	var sheet strings.Builder
+	columns := 0
+	for _, row := range s.cells {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	fmt.Fprintf(&sheet, `<worksheet xmlns="%s">`, xlsxMainNamespace)
+	if s.table {
+		sheet.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+			`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	sheet.WriteString("<sheetData>")
+	for i, row := range s.cells {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			reference := fmt.Sprintf("%s%d", xlsxColumnName(j), i+1)
+			style := ""
+			if cell.bold {
+				style = ` s="1"`
+			}
+			switch {
+			case cell.value == "":
+				fmt.Fprintf(&sheet, `<c r="%s"%s/>`, reference, style)
+			case cell.numeric:
+				fmt.Fprintf(&sheet, `<c r="%s"%s><v>%s</v></c>`, reference, style, xlsxEscape(cell.value))
+			default:
+				fmt.Fprintf(&sheet, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+					reference, style, xlsxEscape(xlsxTruncate(cell.value)))
+			}
+		}
+		sheet.WriteString("</row>")
+	}
+	sheet.WriteString("</sheetData>")
+	if s.table && columns > 0 {
+		fmt.Fprintf(&sheet, `<autoFilter ref="A1:%s%d"/>`, xlsxColumnName(columns-1), len(s.cells))
+	}
+	sheet.WriteString("</worksheet>")
+	return sheet.String()
+}
+
+// xlsxColumnName turns the index of a column into its letters, 0 is A and 26 is AA
+func xlsxColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSheetName drops the characters not allowed in sheet names and keeps the length under the limit
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if utf8.RuneCountInString(name) > xlsxMaxSheetName {
+		name = string([]rune(name)[:xlsxMaxSheetName])
+	}
+	return name
+}
+
+func xlsxTruncate(value string) string {
+	if utf8.RuneCountInString(value) <= xlsxMaxCellLength {
+		return value
+	}
+	return string([]rune(value)[:xlsxMaxCellLength])
+}
+
+func xlsxEscape(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
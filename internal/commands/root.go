@@ -12,6 +12,8 @@ import (
 	"github.com/checkmarx/ast-cli/internal/logger"
 	"github.com/checkmarx/ast-cli/internal/params"
 	"github.com/checkmarx/ast-cli/internal/wrappers/bitbucketserver"
+	"github.com/checkmarx/ast-cli/internal/wrappers/configuration"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
 	"github.com/pkg/errors"
 
 	"github.com/checkmarx/ast-cli/internal/wrappers"
@@ -50,6 +52,9 @@ func NewAstCLI(
 	featureFlagsWrapper wrappers.FeatureFlagsWrapper,
 	policyWrapper wrappers.PolicyWrapper,
 	sastMetadataWrapper wrappers.SastMetadataWrapper,
+	npmRegistryWrapper wrappers.NpmRegistryWrapper,
+	scaDBWrapper wrappers.ScaDBWrapper,
+	containerProvider container.Provider,
 ) *cobra.Command {
 	// Create the root
 	rootCmd := &cobra.Command{
@@ -80,7 +85,12 @@ func NewAstCLI(
 	rootCmd.PersistentFlags().String(params.ProxyFlag, "", params.ProxyFlagUsage)
 	rootCmd.PersistentFlags().Bool(params.IgnoreProxyFlag, false, params.IgnoreProxyFlagUsage)
 	rootCmd.PersistentFlags().String(params.ProxyTypeFlag, "", params.ProxyTypeFlagUsage)
+	rootCmd.PersistentFlags().String(params.ProxyPacFlag, "", params.ProxyPacFlagUsage)
 	rootCmd.PersistentFlags().String(params.NtlmProxyDomainFlag, "", params.NtlmProxyDomainFlagUsage)
+	rootCmd.PersistentFlags().String(params.KerberosKeytabFlag, "", params.KerberosKeytabFlagUsage)
+	rootCmd.PersistentFlags().String(params.KerberosPrincipalFlag, "", params.KerberosPrincipalFlagUsage)
+	rootCmd.PersistentFlags().String(params.KerberosKrb5ConfFlag, "", params.KerberosKrb5ConfFlagUsage)
+	rootCmd.PersistentFlags().String(params.KerberosCCacheFlag, "", params.KerberosCCacheFlagUsage)
 	rootCmd.PersistentFlags().String(params.TimeoutFlag, "", params.TimeoutFlagUsage)
 	rootCmd.PersistentFlags().String(params.BaseURIFlag, params.BaseURI, params.BaseURIFlagUsage)
 	rootCmd.PersistentFlags().String(params.BaseAuthURIFlag, params.BaseIAMURI, params.BaseAuthURIFlagUsage)
@@ -121,7 +131,12 @@ func NewAstCLI(
 	_ = viper.BindPFlag(params.TenantKey, rootCmd.PersistentFlags().Lookup(params.TenantFlag))
 	_ = viper.BindPFlag(params.ProxyKey, rootCmd.PersistentFlags().Lookup(params.ProxyFlag))
 	_ = viper.BindPFlag(params.ProxyTypeKey, rootCmd.PersistentFlags().Lookup(params.ProxyTypeFlag))
+	_ = viper.BindPFlag(params.ProxyPacKey, rootCmd.PersistentFlags().Lookup(params.ProxyPacFlag))
 	_ = viper.BindPFlag(params.ProxyDomainKey, rootCmd.PersistentFlags().Lookup(params.NtlmProxyDomainFlag))
+	_ = viper.BindPFlag(params.KerberosKeytabKey, rootCmd.PersistentFlags().Lookup(params.KerberosKeytabFlag))
+	_ = viper.BindPFlag(params.KerberosPrincipalKey, rootCmd.PersistentFlags().Lookup(params.KerberosPrincipalFlag))
+	_ = viper.BindPFlag(params.KerberosKrb5ConfKey, rootCmd.PersistentFlags().Lookup(params.KerberosKrb5ConfFlag))
+	_ = viper.BindPFlag(params.KerberosCCacheKey, rootCmd.PersistentFlags().Lookup(params.KerberosCCacheFlag))
 	_ = viper.BindPFlag(params.ClientTimeoutKey, rootCmd.PersistentFlags().Lookup(params.TimeoutFlag))
 	_ = viper.BindPFlag(params.BaseAuthURIKey, rootCmd.PersistentFlags().Lookup(params.BaseAuthURIFlag))
 	_ = viper.BindPFlag(params.AstAPIKey, rootCmd.PersistentFlags().Lookup(params.AstAPIKeyFlag))
@@ -156,11 +171,13 @@ func NewAstCLI(
 		scaRealTimeWrapper,
 		policyWrapper,
 		sastMetadataWrapper,
+		containerProvider,
 	)
 	projectCmd := NewProjectCommand(projectsWrapper, groupsWrapper)
 	resultsCmd := NewResultsCommand(
 		resultsWrapper,
 		scansWrapper,
+		projectsWrapper,
 		resultsSbomWrapper,
 		resultsPdfReportsWrapper,
 		codeBashingWrapper,
@@ -181,6 +198,10 @@ func NewAstCLI(
 		learnMoreWrapper,
 		tenantWrapper,
 		chatWrapper,
+		resultsWrapper,
+		npmRegistryWrapper,
+		scaDBWrapper,
+		containerProvider,
 	)
 	configCmd := util.NewConfigCommand()
 	triageCmd := NewResultsPredicatesCommand(resultsPredicatesWrapper)
@@ -220,7 +241,7 @@ func PrintConfiguration() {
 	logger.PrintfIfVerbose("CLI Version: %s", params.Version)
 	logger.PrintIfVerbose("CLI Configuration:")
 	for param := range util.Properties {
-		logger.PrintIfVerbose(fmt.Sprintf(configFormatString, param, viper.GetString(param)))
+		logger.PrintIfVerbose(fmt.Sprintf(configFormatString, param, configuration.MaskSecretProperty(param, viper.GetString(param))))
 	}
 }
 
@@ -86,6 +86,9 @@ func createASTTestCommand() *cobra.Command {
 		featureFlagsMockWrapper,
 		policyWrapper,
 		sastMetadataWrapper,
+		&mock.NpmRegistryMockWrapper{},
+		&mock.ScaDBMockWrapper{},
+		&mock.ContainerProviderMock{},
 	)
 }
 
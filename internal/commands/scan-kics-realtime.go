@@ -0,0 +1,428 @@
+package commands
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+)
+
+const (
+	// KicsFailOnExitCode is returned by kics-realtime when results match --fail-on, so hooks can tell
+	// findings apart from failures to run the scan
+	KicsFailOnExitCode        = 2
+	kicsNoSupportedFiles      = "no files supported by kics were found in %s"
+	kicsInvalidFailOn         = "invalid --fail-on severity '%s', use one of %s"
+	kicsInvalidOutputFormat   = "invalid --output-format '%s', use one of %s"
+	kicsFailOnMessage         = "%d kics results with severity %s were found"
+	kicsSarifToolName         = "KICS"
+	kicsSarifInformationURI   = "https://docs.kics.io"
+	kicsJUnitSuiteName        = "kics"
+	kicsCriticalSeverity      = "CRITICAL"
+	kicsContainerPathPrefix   = "path/"
+	kicsCopiedFilePermissions = 0666
+	kicsCopiedDirPermissions  = 0777
+)
+
+var (
+	kicsOutputFormats = []string{printer.FormatJSON, printer.FormatSarif, printer.FormatJUnit, printer.FormatTable}
+	kicsSeverities    = []string{kicsCriticalSeverity, highCx, mediumCx, lowCx, infoCx}
+	// directories of version control and dependencies, which hold no infrastructure files of the project
+	kicsSkippedDirectories = map[string]bool{".git": true, "node_modules": true, "vendor": true, ".terraform": true}
+)
+
+// kicsSourceFile is a file to scan: its path as given and its path relative to the directory mounted in the container
+type kicsSourceFile struct {
+	Path          string
+	ContainerPath string
+}
+
+// collectKicsFiles expands the --file inputs, files or directories, into the files kics supports. Include globs
+// select files, exclude globs drop them. A glob matches the path relative to the input directory or the file name,
+// '**' matches any number of directories.
+func collectKicsFiles(inputs, include, exclude []string) ([]kicsSourceFile, error) {
+	includeRegexps, err := compileGlobs(include)
+	if err != nil {
+		return nil, err
+	}
+	excludeRegexps, err := compileGlobs(exclude)
+	if err != nil {
+		return nil, err
+	}
+	var files []kicsSourceFile
+	seen := map[string]bool{}
+	add := func(path, relative string) {
+		if seen[path] || !contains(commonParams.KicsBaseFilters, filepath.Base(path)) ||
+			!matchesGlobs(includeRegexps, relative, true) || matchesGlobs(excludeRegexps, relative, false) {
+			return
+		}
+		seen[path] = true
+		files = append(files, kicsSourceFile{Path: path, ContainerPath: kicsContainerRelativePath(path)})
+	}
+	for _, input := range inputs {
+		info, statErr := os.Stat(input)
+		if statErr != nil || !info.IsDir() {
+			if len(inputs) == 1 && !contains(commonParams.KicsBaseFilters, input) {
+				return nil, errors.New(input + containerFileSourceIncompatible)
+			}
+			if statErr != nil {
+				return nil, errors.New(input + containerFileSourceError)
+			}
+			add(input, filepath.Base(input))
+			continue
+		}
+		walkErr := filepath.Walk(input, func(path string, fileInfo os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fileInfo.IsDir() {
+				if path != input && kicsSkippedDirectories[fileInfo.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			relative, err := filepath.Rel(input, path)
+			if err != nil {
+				return err
+			}
+			add(path, relative)
+			return nil
+		})
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf(kicsNoSupportedFiles, strings.Join(inputs, ", "))
+	}
+	return files, nil
+}
+
+// kicsContainerRelativePath keeps paths below the working directory as they are, so results point to the
+// same paths the user gave, other paths are placed under their absolute path
+func kicsContainerRelativePath(path string) string {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if workDir, err := os.Getwd(); err == nil {
+		if relative, err := filepath.Rel(workDir, absolute); err == nil && !strings.HasPrefix(relative, "..") {
+			return filepath.ToSlash(relative)
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(absolute, filepath.VolumeName(absolute))), "/")
+}
+
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	var regexps []*regexp.Regexp
+	for _, glob := range globs {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		compiled, err := regexp.Compile(globToRegexp(glob))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid glob '%s'", glob)
+		}
+		regexps = append(regexps, compiled)
+	}
+	return regexps, nil
+}
+
+func globToRegexp(glob string) string {
+	var builder strings.Builder
+	builder.WriteString("^")
+	glob = filepath.ToSlash(glob)
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				builder.WriteString("(.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				builder.WriteString(".*")
+				i++
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
+
+// matchesGlobs reports whether the path or its base name matches one of the globs, or whenEmpty without globs
+func matchesGlobs(regexps []*regexp.Regexp, path string, whenEmpty bool) bool {
+	if len(regexps) == 0 {
+		return whenEmpty
+	}
+	path = filepath.ToSlash(path)
+	for _, compiled := range regexps {
+		if compiled.MatchString(path) || compiled.MatchString(filepath.Base(path)) {
+			return true
+		}
+	}
+	return false
+}
+
+func copyKicsFiles(files []kicsSourceFile, kicsDir string) error {
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return errors.New(file.Path + containerFileSourceError)
+		}
+		destinationFile := filepath.Join(kicsDir, filepath.FromSlash(file.ContainerPath))
+		if err = os.MkdirAll(filepath.Dir(destinationFile), kicsCopiedDirPermissions); err != nil {
+			return errors.New(containerWriteFolderError)
+		}
+		if err = os.WriteFile(destinationFile, content, kicsCopiedFilePermissions); err != nil {
+			return errors.New(containerWriteFolderError)
+		}
+	}
+	return nil
+}
+
+// restoreKicsFileNames replaces the container paths in the results with the paths of the scanned files
+func restoreKicsFileNames(resultsModel *wrappers.KicsResultsCollection, files []kicsSourceFile) {
+	paths := map[string]string{}
+	for _, file := range files {
+		paths[file.ContainerPath] = file.Path
+	}
+	for i := range resultsModel.Results {
+		for j := range resultsModel.Results[i].Locations {
+			location := &resultsModel.Results[i].Locations[j]
+			name := filepath.ToSlash(location.Filename)
+			if _, after, found := strings.Cut(name, "/"+kicsContainerPathPrefix); found {
+				name = after
+			} else {
+				name = strings.TrimPrefix(name, kicsContainerPathPrefix)
+			}
+			if path, ok := paths[name]; ok {
+				location.Filename = path
+			}
+		}
+	}
+}
+
+func validateKicsRealtimeOptions(outputFormat string, failOn []string) error {
+	if !containsFold(kicsOutputFormats, outputFormat) {
+		return errors.Errorf(kicsInvalidOutputFormat, outputFormat, strings.Join(kicsOutputFormats, ", "))
+	}
+	for _, severity := range failOn {
+		if !containsFold(kicsSeverities, strings.TrimSpace(severity)) {
+			return errors.Errorf(kicsInvalidFailOn, severity, strings.ToLower(strings.Join(kicsSeverities, ", ")))
+		}
+	}
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkKicsFailOn returns an error with KicsFailOnExitCode when results have one of the --fail-on severities
+func checkKicsFailOn(resultsModel *wrappers.KicsResultsCollection, failOn []string) error {
+	if len(failOn) == 0 {
+		return nil
+	}
+	count := 0
+	for _, query := range resultsModel.Results {
+		for _, severity := range failOn {
+			if strings.EqualFold(query.Severity, strings.TrimSpace(severity)) {
+				count += len(query.Locations)
+			}
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	return wrappers.NewAstError(KicsFailOnExitCode, errors.Errorf(kicsFailOnMessage, count, strings.Join(failOn, ",")))
+}
+
+func printKicsRealtimeResults(w io.Writer, resultsModel *wrappers.KicsResultsCollection, outputFormat string) error {
+	switch {
+	case printer.IsFormat(outputFormat, printer.FormatSarif):
+		return printIndentedJSON(w, convertKicsToSarif(resultsModel))
+	case printer.IsFormat(outputFormat, printer.FormatJUnit):
+		output, err := xml.MarshalIndent(convertKicsToJUnit(resultsModel), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(w, xml.Header+string(output))
+		return nil
+	case printer.IsFormat(outputFormat, printer.FormatTable):
+		return printer.Print(w, toKicsResultViews(resultsModel), printer.FormatTable)
+	default:
+		return printKicsResults(w, resultsModel)
+	}
+}
+
+func printIndentedJSON(w io.Writer, view interface{}) error {
+	output, err := json.MarshalIndent(view, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, string(output))
+	return nil
+}
+
+func convertKicsToSarif(resultsModel *wrappers.KicsResultsCollection) *wrappers.SarifResultsCollection {
+	run := wrappers.SarifRun{
+		Tool: wrappers.SarifTool{Driver: wrappers.SarifDriver{
+			Name:           kicsSarifToolName,
+			Version:        resultsModel.Version,
+			InformationURI: kicsSarifInformationURI,
+			Rules:          []wrappers.SarifDriverRule{},
+		}},
+		Results: []wrappers.SarifScanResult{},
+	}
+	for _, query := range resultsModel.Results {
+		severity := kicsSeverity(query.Severity)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, wrappers.SarifDriverRule{
+			ID:              query.QueryID,
+			Name:            query.QueryName,
+			HelpURI:         query.QueryURL,
+			Help:            wrappers.SarifHelp{Text: query.Description, Markdown: query.Description},
+			FullDescription: wrappers.SarifDescription{Text: query.Description},
+			Properties: wrappers.SarifProperties{
+				SecuritySeverity: securities[severity],
+				Name:             query.QueryName,
+				ID:               query.QueryID,
+				Description:      query.Description,
+				Tags:             []string{commonParams.KicsType, query.Platform, query.Category},
+			},
+		})
+		for _, location := range query.Locations {
+			run.Results = append(run.Results, wrappers.SarifScanResult{
+				RuleID:       query.QueryID,
+				Level:        kicsSarifLevel(severity),
+				Message:      wrappers.SarifMessage{Text: fmt.Sprintf("%s: %s", query.QueryName, location.ActualValue)},
+				Fingerprints: map[string]string{sarifFingerprintKey: location.SimilarityID},
+				Locations: []wrappers.SarifLocation{{PhysicalLocation: wrappers.SarifPhysicalLocation{
+					ArtifactLocation: wrappers.SarifArtifactLocation{URI: filepath.ToSlash(location.Filename)},
+					Region:           &wrappers.SarifRegion{StartLine: location.Line},
+				}}},
+			})
+		}
+	}
+	return &wrappers.SarifResultsCollection{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []wrappers.SarifRun{run},
+	}
+}
+
+// kicsSeverity maps the kics severities to the Checkmarx One ones, critical is reported as high
+func kicsSeverity(severity string) string {
+	severity = strings.ToUpper(severity)
+	if severity == kicsCriticalSeverity {
+		return highCx
+	}
+	return severity
+}
+
+func kicsSarifLevel(severity string) string {
+	switch severity {
+	case highCx:
+		return highSarif
+	case mediumCx:
+		return mediumSarif
+	default:
+		return infoLowSarif
+	}
+}
+
+type kicsJUnitTestSuites struct {
+	XMLName  xml.Name             `xml:"testsuites"`
+	Name     string               `xml:"name,attr"`
+	Tests    int                  `xml:"tests,attr"`
+	Failures int                  `xml:"failures,attr"`
+	Suites   []kicsJUnitTestSuite `xml:"testsuite"`
+}
+
+type kicsJUnitTestSuite struct {
+	Name      string              `xml:"name,attr"`
+	Tests     int                 `xml:"tests,attr"`
+	Failures  int                 `xml:"failures,attr"`
+	TestCases []kicsJUnitTestCase `xml:"testcase"`
+}
+
+type kicsJUnitTestCase struct {
+	Name      string            `xml:"name,attr"`
+	ClassName string            `xml:"classname,attr"`
+	Failure   *kicsJUnitFailure `xml:"failure,omitempty"`
+}
+
+type kicsJUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// convertKicsToJUnit reports one test suite per query and one failed test case per result
+func convertKicsToJUnit(resultsModel *wrappers.KicsResultsCollection) *kicsJUnitTestSuites {
+	suites := &kicsJUnitTestSuites{Name: kicsJUnitSuiteName, Suites: []kicsJUnitTestSuite{}}
+	for _, query := range resultsModel.Results {
+		suite := kicsJUnitTestSuite{Name: fmt.Sprintf("%s (%s)", query.QueryName, query.Platform)}
+		for _, location := range query.Locations {
+			suite.TestCases = append(suite.TestCases, kicsJUnitTestCase{
+				Name:      fmt.Sprintf("%s:%d", filepath.ToSlash(location.Filename), location.Line),
+				ClassName: query.QueryID,
+				Failure: &kicsJUnitFailure{
+					Message: fmt.Sprintf("[%s] %s", query.Severity, query.QueryName),
+					Type:    query.Severity,
+					Text: fmt.Sprintf("%s\nExpected: %s\nActual: %s\n%s",
+						query.Description, location.ExpectedValue, location.ActualValue, query.QueryURL),
+				},
+			})
+		}
+		suite.Tests = len(suite.TestCases)
+		suite.Failures = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return suites
+}
+
+type kicsResultView struct {
+	Severity string
+	Query    string
+	Platform string
+	File     string
+	Line     uint
+}
+
+func toKicsResultViews(resultsModel *wrappers.KicsResultsCollection) []kicsResultView {
+	views := []kicsResultView{}
+	for _, query := range resultsModel.Results {
+		for _, location := range query.Locations {
+			views = append(views, kicsResultView{
+				Severity: query.Severity,
+				Query:    query.QueryName,
+				Platform: query.Platform,
+				File:     filepath.ToSlash(location.Filename),
+				Line:     location.Line,
+			})
+		}
+	}
+	return views
+}
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
+	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func createKicsTestTree(t *testing.T) string {
+	dir := t.TempDir()
+	for _, file := range []string{"main.tf", "modules/network/vpc.tf", "test/fixture.tf", "README.md", "docker/Dockerfile", ".git/config.yaml",
+		"node_modules/pkg/main.tf", "vendor/module/main.tf", ".terraform/modules/vpc/main.tf", "modules/build/deploy.yaml"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NilError(t, os.WriteFile(path, []byte("content"), 0600))
+	}
+	return dir
+}
+
+func kicsFileNames(dir string, files []kicsSourceFile) []string {
+	var names []string
+	for _, file := range files {
+		relative, _ := filepath.Rel(dir, file.Path)
+		names = append(names, filepath.ToSlash(relative))
+	}
+	return names
+}
+
+func TestCollectKicsFiles(t *testing.T) {
+	dir := createKicsTestTree(t)
+
+	files, err := collectKicsFiles([]string{dir}, nil, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, kicsFileNames(dir, files),
+		[]string{"docker/Dockerfile", "main.tf", "modules/build/deploy.yaml", "modules/network/vpc.tf", "test/fixture.tf"})
+
+	files, err = collectKicsFiles([]string{dir}, []string{"**/*.tf"}, []string{"test/**"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, kicsFileNames(dir, files), []string{"main.tf", "modules/network/vpc.tf"})
+
+	files, err = collectKicsFiles([]string{filepath.Join(dir, "main.tf"), filepath.Join(dir, "README.md")}, nil, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, kicsFileNames(dir, files), []string{"main.tf"})
+
+	_, err = collectKicsFiles([]string{filepath.Join(dir, "README.md")}, nil, nil)
+	assert.ErrorContains(t, err, "Provided file is not supported by kics")
+
+	_, err = collectKicsFiles([]string{dir}, []string{"*.yml"}, nil)
+	assert.ErrorContains(t, err, "no files supported by kics were found")
+}
+
+func TestRealtimeKicsFileWithComma(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "network,vpc.tf")
+	err := execCmdNotNilAssertion(t, "scan", "kics-realtime", "--file", missing)
+	assert.Error(t, err, missing+containerFileSourceError)
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	regexps, err := compileGlobs([]string{"modules/**/*.tf"})
+	assert.NilError(t, err)
+	assert.Assert(t, matchesGlobs(regexps, "modules/vpc.tf", false))
+	assert.Assert(t, matchesGlobs(regexps, "modules/network/aws/vpc.tf", false))
+	assert.Assert(t, !matchesGlobs(regexps, "main.tf", false))
+
+	regexps, err = compileGlobs([]string{"*.y?ml"})
+	assert.NilError(t, err)
+	assert.Assert(t, matchesGlobs(regexps, "k8s/deploy.yaml", false), "globs without '/' match the file name")
+	assert.Assert(t, !matchesGlobs(regexps, "k8s/deploy.yml", false))
+	assert.Assert(t, matchesGlobs(nil, "main.tf", true))
+}
+
+func newKicsTestResults() wrappers.KicsResultsCollection {
+	return wrappers.KicsResultsCollection{
+		Version: "1.7.13",
+		Results: []wrappers.KicsQueries{
+			{
+				QueryName: "Healthcheck Instruction Missing",
+				QueryID:   "b03a748a-542d-44f4-bb86-9199ab4fd2d5",
+				Severity:  "LOW",
+				Platform:  "Dockerfile",
+				Locations: []wrappers.KicsFiles{{Filename: "../../path/docker/Dockerfile", Line: 1, SimilarityID: "abc"}},
+			},
+			{
+				QueryName: "S3 Bucket Without Versioning",
+				QueryID:   "568a4d22-3517-44a6-a7ad-6a7eed88722c",
+				Severity:  "MEDIUM",
+				Platform:  "Terraform",
+				Locations: []wrappers.KicsFiles{{Filename: "../../path/main.tf", Line: 7}, {Filename: "../../path/main.tf", Line: 20}},
+			},
+		},
+	}
+}
+
+func TestRestoreKicsFileNames(t *testing.T) {
+	resultsModel := newKicsTestResults()
+	restoreKicsFileNames(&resultsModel, []kicsSourceFile{
+		{Path: "/repo/docker/Dockerfile", ContainerPath: "docker/Dockerfile"},
+		{Path: "/repo/main.tf", ContainerPath: "main.tf"},
+	})
+	assert.Equal(t, resultsModel.Results[0].Locations[0].Filename, "/repo/docker/Dockerfile")
+	assert.Equal(t, resultsModel.Results[1].Locations[1].Filename, "/repo/main.tf")
+}
+
+func TestCheckKicsFailOn(t *testing.T) {
+	resultsModel := newKicsTestResults()
+	assert.NilError(t, checkKicsFailOn(&resultsModel, nil))
+	assert.NilError(t, checkKicsFailOn(&resultsModel, []string{"high"}))
+
+	err := checkKicsFailOn(&resultsModel, []string{"high", "medium"})
+	var astErr *wrappers.AstError
+	assert.Assert(t, errors.As(err, &astErr))
+	assert.Equal(t, astErr.Code, KicsFailOnExitCode)
+	assert.Error(t, err, "2 kics results with severity high,medium were found")
+
+	assert.ErrorContains(t, validateKicsRealtimeOptions("json", []string{"severe"}), "invalid --fail-on severity 'severe'")
+	assert.ErrorContains(t, validateKicsRealtimeOptions("xml", nil), "invalid --output-format 'xml'")
+	assert.NilError(t, validateKicsRealtimeOptions("SARIF", []string{"critical", "info"}))
+}
+
+func TestPrintKicsRealtimeResults(t *testing.T) {
+	resultsModel := newKicsTestResults()
+
+	var sarifOutput bytes.Buffer
+	assert.NilError(t, printKicsRealtimeResults(&sarifOutput, &resultsModel, "sarif"))
+	var sarif wrappers.SarifResultsCollection
+	assert.NilError(t, json.Unmarshal(sarifOutput.Bytes(), &sarif))
+	assert.Equal(t, sarif.Version, "2.1.0")
+	assert.Equal(t, len(sarif.Runs[0].Tool.Driver.Rules), 2)
+	assert.Equal(t, len(sarif.Runs[0].Results), 3)
+	assert.Equal(t, sarif.Runs[0].Results[1].Level, "warning")
+	assert.Equal(t, sarif.Runs[0].Results[0].Fingerprints["similarityHash/v1"], "abc")
+	assert.Assert(t, sarif.Runs[0].Results[0].PartialFingerprints == nil)
+
+	var junitOutput bytes.Buffer
+	assert.NilError(t, printKicsRealtimeResults(&junitOutput, &resultsModel, "junit"))
+	assert.Assert(t, strings.Contains(junitOutput.String(), `<testsuites name="kics" tests="3" failures="3">`), junitOutput.String())
+
+	var tableOutput bytes.Buffer
+	assert.NilError(t, printKicsRealtimeResults(&tableOutput, &resultsModel, "table"))
+	assert.Assert(t, strings.Contains(tableOutput.String(), "S3 Bucket Without Versioning"))
+
+	var jsonOutput bytes.Buffer
+	assert.NilError(t, printKicsRealtimeResults(&jsonOutput, &resultsModel, "json"))
+	var results wrappers.KicsResultsCollection
+	assert.NilError(t, json.Unmarshal(jsonOutput.Bytes(), &results))
+	assert.Equal(t, len(results.Results), len(resultsModel.Results))
+}
+
+func TestCreateRealtimeKicsInvalidOutputFormat(t *testing.T) {
+	baseArgs := []string{scanCommand, kicsRealtimeCommand, fileSourceFlag, fileSourceValue, "--output-format", "xml"}
+	err := execCmdNotNilAssertion(t, baseArgs...)
+	assert.ErrorContains(t, err, "invalid --output-format 'xml'")
+}
+
+func TestRunKicsRealtimeWithContainerRuntime(t *testing.T) {
+	results, _ := json.Marshal(newKicsTestResults())
+	runtime := &mock.ContainerRuntimeMock{ExitCode: 50, Files: map[string]string{"/path/results.json": string(results)}}
+	cmd := scanRealtimeSubCommand(&mock.ContainerProviderMock{RuntimeMock: runtime})
+	err := executeTestCommand(cmd, fileSourceFlag, fileSourceValue, "--kics-image", "checkmarx/kics:v1.7.13", "--fail-on", "medium")
+	var astErr *wrappers.AstError
+	assert.Assert(t, errors.As(err, &astErr))
+	assert.Equal(t, astErr.Code, KicsFailOnExitCode)
+	assert.Equal(t, len(runtime.Runs), 1)
+	assert.Equal(t, runtime.Runs[0].Image, "checkmarx/kics:v1.7.13")
+	assert.Equal(t, runtime.Runs[0].Mounts[0].Target, "/path")
+}
+
+func TestRunKicsRealtimeContainerFailures(t *testing.T) {
+	pullErr := &container.Error{Kind: container.ErrImagePullFailed, Engine: container.DockerEngine}
+	cmd := scanRealtimeSubCommand(&mock.ContainerProviderMock{RuntimeMock: &mock.ContainerRuntimeMock{PullErr: pullErr}})
+	err := executeTestCommand(cmd, fileSourceFlag, fileSourceValue)
+	assert.ErrorContains(t, err, util.ImagePullFailedMessage)
+
+	daemonErr := &container.Error{Kind: container.ErrDaemonNotRunning, Engine: container.PodmanEngine}
+	cmd = scanRealtimeSubCommand(&mock.ContainerProviderMock{RuntimeMock: &mock.ContainerRuntimeMock{RunErr: daemonErr}})
+	err = executeTestCommand(cmd, fileSourceFlag, fileSourceValue)
+	assert.Error(t, err, util.NotRunningEngineMessage)
+
+	cmd = scanRealtimeSubCommand(&mock.ContainerProviderMock{RuntimeMock: &mock.ContainerRuntimeMock{ExitCode: 1}})
+	err = executeTestCommand(cmd, fileSourceFlag, fileSourceValue)
+	assert.Error(t, err, "kics scan failed with exit code 1: unknown kics exit code")
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/MakeNowJust/heredoc"
 	commonParams "github.com/checkmarx/ast-cli/internal/params"
 	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
 	"github.com/mssola/user_agent"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -50,11 +51,6 @@ const (
 	git                             = "git"
 	invalidSSHSource                = "provided source does not need a key. Make sure you are defining the right source or remove the flag --ssh-key"
 	errorUnzippingFile              = "an error occurred while unzipping file. Reason: "
-	containerRun                    = "run"
-	containerVolumeFlag             = "-v"
-	containerNameFlag               = "--name"
-	containerRemove                 = "--rm"
-	containerImage                  = "checkmarx/kics:latest"
 	containerScan                   = "scan"
 	containerScanPathFlag           = "-p"
 	containerScanPath               = "/path"
@@ -71,7 +67,6 @@ const (
 	containerFileSourceIncompatible = ". Provided file is not supported by kics"
 	containerFileSourceError        = " Error reading file"
 	containerResultsFileFormat      = "%s/results.json"
-	containerVolumeFormat           = "%s:/path"
 	containerTempDirPattern         = "kics"
 	kicsContainerPrefixName         = "cli-kics-realtime-"
 	cleanupMaxRetries               = 3
@@ -117,7 +112,6 @@ var (
 		),
 	)
 	aditionalParameters []string
-	kicsErrorCodes      = []string{"50", "40", "30", "20"}
 )
 
 func NewScanCommand(
@@ -134,6 +128,7 @@ func NewScanCommand(
 	scaRealTimeWrapper wrappers.ScaRealTimeWrapper,
 	policyWrapper wrappers.PolicyWrapper,
 	sastMetadataWrapper wrappers.SastMetadataWrapper,
+	containerProvider container.Provider,
 ) *cobra.Command {
 	scanCmd := &cobra.Command{
 		Use:   "scan",
@@ -175,7 +170,7 @@ func NewScanCommand(
 
 	logsCmd := scanLogsSubCommand(logsWrapper)
 
-	kicsRealtimeCmd := scanRealtimeSubCommand()
+	kicsRealtimeCmd := scanRealtimeSubCommand(containerProvider)
 
 	scaRealtimeCmd := scarealtime.NewScaRealtimeCommand(scaRealTimeWrapper)
 
@@ -201,7 +196,7 @@ func NewScanCommand(
 	return scanCmd
 }
 
-func scanRealtimeSubCommand() *cobra.Command {
+func scanRealtimeSubCommand(containerProvider container.Provider) *cobra.Command {
 	kicsContainerID := uuid.New()
 	viper.Set(commonParams.KicsContainerNameKey, kicsContainerPrefixName+kicsContainerID.String())
 	realtimeScanCmd := &cobra.Command{
@@ -211,6 +206,8 @@ func scanRealtimeSubCommand() *cobra.Command {
 		Example: heredoc.Doc(
 			`
 			$ cx scan kics-realtime --file <file> --additional-params <additional-params> --engine <engine>
+			$ cx scan kics-realtime --file ./infra --exclude "**/test/**" --output-format sarif --fail-on high,medium
+			$ cx scan kics-realtime --file main.tf,Dockerfile --output-format junit --kics-image checkmarx/kics:v1.7.13
 		`,
 		),
 		Annotations: map[string]string{
@@ -220,7 +217,7 @@ func scanRealtimeSubCommand() *cobra.Command {
 			`,
 			),
 		},
-		RunE: runKicksRealtime(),
+		RunE: runKicksRealtime(containerProvider),
 	}
 	realtimeScanCmd.PersistentFlags().
 		StringSliceVar(
@@ -228,15 +225,40 @@ func scanRealtimeSubCommand() *cobra.Command {
 			"Additional scan options supported by kics. "+
 				"Should follow comma separated format. For example : --additional-params -v, --exclude-results,fec62a97d569662093dbb9739360942f",
 		)
-	realtimeScanCmd.PersistentFlags().String(
+	realtimeScanCmd.PersistentFlags().StringArray(
 		commonParams.KicsRealtimeFile,
-		"",
-		"Path to input file for kics realtime scanner",
+		[]string{},
+		"Paths to input files or directories for kics realtime scanner, e.g. the files changed in a commit",
+	)
+	realtimeScanCmd.PersistentFlags().StringSlice(
+		commonParams.KicsRealtimeInclude,
+		[]string{},
+		"Glob patterns of the files to scan in the input directories, e.g. **/*.tf",
+	)
+	realtimeScanCmd.PersistentFlags().StringSlice(
+		commonParams.KicsRealtimeExclude,
+		[]string{},
+		"Glob patterns of the files to skip, e.g. **/test/**",
+	)
+	realtimeScanCmd.PersistentFlags().String(
+		commonParams.KicsRealtimeOutputFormat,
+		printer.FormatJSON,
+		fmt.Sprintf("Format of the results: %s", strings.Join(kicsOutputFormats, ", ")),
+	)
+	realtimeScanCmd.PersistentFlags().StringSlice(
+		commonParams.KicsRealtimeFailOn,
+		[]string{},
+		fmt.Sprintf("Exit with code %d when results have one of these severities, e.g. high,medium", KicsFailOnExitCode),
+	)
+	realtimeScanCmd.PersistentFlags().String(
+		commonParams.KicsRealtimeImage,
+		util.KicsContainerImage,
+		"Container image of kics, pin a tag to get reproducible results",
 	)
 	realtimeScanCmd.PersistentFlags().String(
 		commonParams.KicsRealtimeEngine,
-		"docker",
-		"Name in the $PATH for the container engine to run kics. Example:podman.",
+		container.DockerEngine,
+		util.KicsEngineFlagUsage,
 	)
 	markFlagAsRequired(realtimeScanCmd, commonParams.KicsRealtimeFile)
 	return realtimeScanCmd
@@ -531,6 +553,11 @@ func scanCreateSubCommand(
 		printer.FormatPDF,
 		printer.FormatSummaryMarkdown,
 		printer.FormatGL,
+		printer.FormatGLDependency,
+		printer.FormatGLContainer,
+		printer.FormatHTML,
+		printer.FormatCSV,
+		printer.FormatXLSX,
 	)
 	createScanCmd.PersistentFlags().String(commonParams.APIDocumentationFlag, "", apiDocumentationFlagDescription)
 	createScanCmd.PersistentFlags().String(commonParams.ExploitablePathFlag, "", exploitablePathFlagDescription)
@@ -540,6 +567,8 @@ func scanCreateSubCommand(
 	createScanCmd.PersistentFlags().String(commonParams.ReportFormatPdfToEmailFlag, "", pdfToEmailFlagDescription)
 	createScanCmd.PersistentFlags().String(commonParams.ReportSbomFormatFlag, defaultSbomOption, sbomReportFlagDescription)
 	createScanCmd.PersistentFlags().String(commonParams.ReportFormatPdfOptionsFlag, defaultPdfOptionsDataSections, pdfOptionsFlagDescription)
+	createScanCmd.PersistentFlags().String(commonParams.ReportFormatPdfModeFlag, pdfModeServer, pdfModeFlagDescription)
+	createScanCmd.PersistentFlags().String(commonParams.ReportColumnsFlag, "", spreadsheetColumnsFlagDescription+strings.Join(spreadsheetColumnNames(), ","))
 	createScanCmd.PersistentFlags().String(commonParams.TargetFlag, "cx_result", "Output file")
 	createScanCmd.PersistentFlags().String(commonParams.TargetPathFlag, ".", "Output Path")
 	createScanCmd.PersistentFlags().StringSlice(commonParams.FilterFlag, []string{}, filterResultsListFlagUsage)
@@ -1708,7 +1737,9 @@ func createReportsAfterScan(
 	reportFormats, _ := cmd.Flags().GetString(commonParams.TargetFormatFlag)
 	formatPdfToEmail, _ := cmd.Flags().GetString(commonParams.ReportFormatPdfToEmailFlag)
 	formatPdfOptions, _ := cmd.Flags().GetString(commonParams.ReportFormatPdfOptionsFlag)
+	formatPdfMode, _ := cmd.Flags().GetString(commonParams.ReportFormatPdfModeFlag)
 	formatSbomOptions, _ := cmd.Flags().GetString(commonParams.ReportSbomFormatFlag)
+	reportColumns, _ := cmd.Flags().GetString(commonParams.ReportColumnsFlag)
 	useSCALocalFlow, _ := cmd.Flags().GetBool(commonParams.ReportSbomFormatLocalFlowFlag)
 	retrySBOM, _ := cmd.Flags().GetInt(commonParams.RetrySBOMFlag)
 
@@ -1738,10 +1769,13 @@ func createReportsAfterScan(
 		reportFormats,
 		formatPdfToEmail,
 		formatPdfOptions,
+		formatPdfMode,
 		formatSbomOptions,
+		reportColumns,
 		targetFile,
 		targetPath,
 		params,
+		nil,
 	)
 }
 
@@ -1755,12 +1789,16 @@ func applyThreshold(
 		return nil
 	}
 
-	thresholdMap := parseThreshold(threshold)
-
 	summaryMap, err := getSummaryThresholdMap(resultsWrapper, scanResponseModel)
 	if err != nil {
 		return err
 	}
+	return checkThreshold(threshold, summaryMap)
+}
+
+// checkThreshold fails when the count of results of an engine and severity reaches its threshold limit
+func checkThreshold(threshold string, summaryMap map[string]int) error {
+	thresholdMap := parseThreshold(threshold)
 
 	var errorBuilder strings.Builder
 	var messageBuilder strings.Builder
@@ -1821,6 +1859,10 @@ func getSummaryThresholdMap(resultsWrapper wrappers.ResultsWrapper, scan *wrappe
 	if err != nil {
 		return nil, err
 	}
+	return summaryThresholdMap(results), nil
+}
+
+func summaryThresholdMap(results *wrappers.ScanResultsCollection) map[string]int {
 	summaryMap := make(map[string]int)
 	for _, result := range results.Results {
 		if isExploitable(result.State) {
@@ -1828,7 +1870,7 @@ func getSummaryThresholdMap(resultsWrapper wrappers.ResultsWrapper, scan *wrappe
 			summaryMap[key]++
 		}
 	}
-	return summaryMap, nil
+	return summaryMap
 }
 
 func isExploitable(state string) bool {
@@ -2159,23 +2201,40 @@ func runDownloadLogs(logsWrapper wrappers.LogsWrapper) func(*cobra.Command, []st
 	}
 }
 
-func runKicksRealtime() func(*cobra.Command, []string) error {
+func runKicksRealtime(containerProvider container.Provider) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, _ []string) error {
+		outputFormat, _ := cmd.Flags().GetString(commonParams.KicsRealtimeOutputFormat)
+		failOn, _ := cmd.Flags().GetStringSlice(commonParams.KicsRealtimeFailOn)
+		if err := validateKicsRealtimeOptions(outputFormat, failOn); err != nil {
+			return err
+		}
+		engine, _ := cmd.Flags().GetString(commonParams.KicsRealtimeEngine)
+		runtime, err := containerProvider.Runtime(engine)
+		if err != nil {
+			return util.ContainerRunError(err)
+		}
 		// Create temp location and add it to container volumes
-		volumeMap, tempDir, err := createKicsScanEnv(cmd)
+		tempDir, files, err := createKicsScanEnv(cmd)
 		if err != nil {
 			return errors.Errorf("%s", err)
 		}
 
 		// Run kics container
-		err = runKicsScan(cmd, volumeMap, tempDir, aditionalParameters)
+		resultsModel, err := runKicsScan(cmd, runtime, tempDir, aditionalParameters)
 		// Removing temporary dir
 		logger.PrintIfVerbose(containerFolderRemoving)
 		removeErr := os.RemoveAll(tempDir)
 		if removeErr != nil {
 			logger.PrintIfVerbose(removeErr.Error())
 		}
-		return err
+		if err != nil {
+			return err
+		}
+		restoreKicsFileNames(&resultsModel, files)
+		if err = printKicsRealtimeResults(cmd.OutOrStdout(), &resultsModel, outputFormat); err != nil {
+			return errors.Errorf("%s", err)
+		}
+		return checkKicsFailOn(&resultsModel, failOn)
 	}
 }
 
@@ -2264,30 +2323,26 @@ func toScanView(scan *wrappers.ScanResponseModel) *scanView {
 	}
 }
 
-func createKicsScanEnv(cmd *cobra.Command) (volumeMap, kicsDir string, err error) {
-	kicsDir, err = ioutil.TempDir("", containerTempDirPattern)
-	if err != nil {
-		return "", "", errors.New(containerCreateFolderError)
-	}
-	kicsFilePath, _ := cmd.Flags().GetString(commonParams.KicsRealtimeFile)
-	if len(kicsFilePath) < 1 {
-		return "", "", errors.New(containerFileSourceMissing)
+func createKicsScanEnv(cmd *cobra.Command) (kicsDir string, files []kicsSourceFile, err error) {
+	kicsFilePaths, _ := cmd.Flags().GetStringArray(commonParams.KicsRealtimeFile)
+	if len(kicsFilePaths) < 1 {
+		return "", nil, errors.New(containerFileSourceMissing)
 	}
-	if !contains(commonParams.KicsBaseFilters, kicsFilePath) {
-		return "", "", errors.New(kicsFilePath + containerFileSourceIncompatible)
-	}
-	kicsFile, err := ioutil.ReadFile(kicsFilePath)
+	include, _ := cmd.Flags().GetStringSlice(commonParams.KicsRealtimeInclude)
+	exclude, _ := cmd.Flags().GetStringSlice(commonParams.KicsRealtimeExclude)
+	files, err = collectKicsFiles(kicsFilePaths, include, exclude)
 	if err != nil {
-		return "", "", errors.New(containerFileSourceError)
+		return "", nil, err
 	}
-	_, file := filepath.Split(kicsFilePath)
-	destinationFile := fmt.Sprintf("%s/%s", kicsDir, file)
-	err = ioutil.WriteFile(destinationFile, kicsFile, 0666)
+	kicsDir, err = ioutil.TempDir("", containerTempDirPattern)
 	if err != nil {
-		return "", "", errors.New(containerWriteFolderError)
+		return "", nil, errors.New(containerCreateFolderError)
 	}
-	volumeMap = fmt.Sprintf(containerVolumeFormat, kicsDir)
-	return volumeMap, kicsDir, nil
+	if err = copyKicsFiles(files, kicsDir); err != nil {
+		_ = os.RemoveAll(kicsDir)
+		return "", nil, err
+	}
+	return kicsDir, files, nil
 }
 
 func contains(s []string, str string) bool {
@@ -2319,16 +2374,15 @@ func readKicsResultsFile(tempDir string) (wrappers.KicsResultsCollection, error)
 	return resultsModel, nil
 }
 
-func runKicsScan(cmd *cobra.Command, volumeMap, tempDir string, additionalParameters []string) error {
-	var errs error
-	kicsRunArgs := []string{
-		containerRun,
-		containerRemove,
-		containerVolumeFlag,
-		volumeMap,
-		containerNameFlag,
-		viper.GetString(commonParams.KicsContainerNameKey),
-		containerImage,
+func runKicsScan(
+	cmd *cobra.Command,
+	runtime container.Runtime,
+	tempDir string,
+	additionalParameters []string,
+) (wrappers.KicsResultsCollection, error) {
+	var resultsModel wrappers.KicsResultsCollection
+	kicsImage, _ := cmd.Flags().GetString(commonParams.KicsRealtimeImage)
+	kicsArgs := []string{
 		containerScan,
 		containerScanPathFlag,
 		containerScanPath,
@@ -2338,73 +2392,49 @@ func runKicsScan(cmd *cobra.Command, volumeMap, tempDir string, additionalParame
 		containerScanFormatOutput,
 	}
 	// join the additional parameters
-	if len(additionalParameters) > 0 {
-		kicsRunArgs = append(kicsRunArgs, additionalParameters...)
+	kicsArgs = append(kicsArgs, additionalParameters...)
+	if err := runtime.EnsureImage(kicsImage, cmd.ErrOrStderr()); err != nil {
+		return resultsModel, util.ContainerRunError(err)
 	}
 	logger.PrintIfVerbose(containerStarting)
 	logger.PrintIfVerbose(containerFormatInfo)
-	kicsCmd, _ := cmd.Flags().GetString(commonParams.KicsRealtimeEngine)
-	out, err := exec.Command(kicsCmd, kicsRunArgs...).CombinedOutput()
-	logger.PrintIfVerbose(string(out))
-	var resultsModel wrappers.KicsResultsCollection
-	/* 	NOTE: the kics container returns 40 instead of 0 when successful!! This
-	definitely an incorrect behavior but the following check gets past it.
-	*/
-	if err == nil {
-		// no results
-		if resultsModel.Results == nil {
-			resultsModel.Results = []wrappers.KicsQueries{}
-		}
-		errs = printKicsResults(&resultsModel)
-		if errs != nil {
-			return errors.Errorf("%s", errs)
-		}
-		return nil
+	result, err := runtime.Run(&container.RunOptions{
+		Image:  kicsImage,
+		Name:   viper.GetString(commonParams.KicsContainerNameKey),
+		Remove: true,
+		Mounts: []container.Mount{{Source: tempDir, Target: containerScanPath}},
+		Args:   kicsArgs,
+	})
+	if err != nil {
+		return resultsModel, util.ContainerRunError(err)
+	}
+	logger.PrintIfVerbose(string(result.Output))
+	status := wrappers.DecodeKicsExitCode(result.ExitCode)
+	if !status.Completed {
+		return resultsModel, errors.Errorf("kics scan failed with exit code %d: %s", result.ExitCode, status.Description)
+	}
+	logger.PrintfIfVerbose("kics exit code %d: %s", result.ExitCode, status.Description)
+	resultsModel, err = readKicsResultsFile(tempDir)
+	if os.IsNotExist(err) && result.ExitCode == 0 {
+		err = nil
 	}
-
 	if err != nil {
-		errorMessage := err.Error()
-		extractedErrorCode := errorMessage[strings.LastIndex(errorMessage, " ")+1:]
-
-		if contains(kicsErrorCodes, extractedErrorCode) {
-			resultsModel, errs = readKicsResultsFile(tempDir)
-			if errs != nil {
-				return errors.Errorf("%s", errs)
-			}
-			errs = printKicsResults(&resultsModel)
-			if errs != nil {
-				return errors.Errorf("%s", errs)
-			}
-			return nil
-		}
-		exitError, hasExistError := err.(*exec.ExitError)
-		if hasExistError {
-			if exitError.ExitCode() == util.EngineNoRunningCode {
-				logger.PrintIfVerbose(errorMessage)
-				return errors.Errorf(util.NotRunningEngineMessage)
-			}
-		} else {
-			if strings.Contains(errorMessage, util.InvalidEngineError) || strings.Contains(
-				errorMessage,
-				util.InvalidEngineErrorWindows,
-			) {
-				logger.PrintIfVerbose(errorMessage)
-				return errors.Errorf(util.InvalidEngineMessage)
-			}
-		}
-		return errors.Errorf("Check container engine state. Failed: %s", errorMessage)
+		return resultsModel, errors.Errorf("%s", err)
 	}
-
-	return nil
+	// no results
+	if resultsModel.Results == nil {
+		resultsModel.Results = []wrappers.KicsQueries{}
+	}
+	return resultsModel, nil
 }
 
-func printKicsResults(resultsModel *wrappers.KicsResultsCollection) error {
+func printKicsResults(w io.Writer, resultsModel *wrappers.KicsResultsCollection) error {
 	var resultsJSON []byte
 	resultsJSON, errs := json.Marshal(resultsModel)
 	if errs != nil {
 		return errors.Errorf("%s", errs)
 	}
-	fmt.Println(string(resultsJSON))
+	_, _ = fmt.Fprintln(w, string(resultsJSON))
 	return nil
 }
 
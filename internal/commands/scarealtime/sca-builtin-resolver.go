@@ -0,0 +1,627 @@
+package scarealtime
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/checkmarx/ast-cli/internal/wrappers/remediation"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+)
+
+// Resolving module types reported by the built-in resolver, named as SCA Resolver names them
+const (
+	npmModuleType    = "Npm"
+	yarnModuleType   = "Yarn"
+	pnpmModuleType   = "Pnpm"
+	goModuleType     = "GoModules"
+	pipModuleType    = "Pip"
+	poetryModuleType = "Poetry"
+	mavenModuleType  = "Maven"
+	nugetModuleType  = "Nuget"
+)
+
+// Manifests and lockfiles read by the built-in resolver
+const (
+	packageJSONFile      = "package.json"
+	goModFile            = "go.mod"
+	goSumFile            = "go.sum"
+	requirementsFile     = "requirements.txt"
+	poetryLockFile       = "poetry.lock"
+	pyprojectFile        = "pyproject.toml"
+	pomFile              = "pom.xml"
+	nugetLockFile        = "packages.lock.json"
+	scaResolvedStatus    = "Resolved"
+	maxPropertyExpansion = 10
+)
+
+var npmModuleTypes = map[string]string{
+	remediation.NpmManager:  npmModuleType,
+	remediation.YarnManager: yarnModuleType,
+	remediation.PnpmManager: pnpmModuleType,
+}
+
+// builtinSkippedDirs are directories of installed packages, build outputs and version control that the built-in
+// resolver does not look into
+var builtinSkippedDirs = map[string]bool{
+	"node_modules": true,
+	".git":         true,
+	"vendor":       true,
+	"target":       true,
+	"bin":          true,
+	"obj":          true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+}
+
+var (
+	requirementNameRegex   = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)`)
+	pinnedRequirementRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*===?\s*([^\s;,]+)\s*(?:;.*)?$`)
+	pythonNameRegex        = regexp.MustCompile(`[-_.]+`)
+	mavenPropertyRegex     = regexp.MustCompile(`\$\{([^}]+)\}`)
+)
+
+// resolveDependencies lists the dependencies of a project by parsing its manifests and lockfiles, without running
+// the package managers or SCA Resolver. Each manifest gives a resolution, manifests that cannot be fully resolved
+// are reported with the failed status.
+func resolveDependencies(projectDir string) (ScaResultsFile, error) {
+	logger.PrintIfVerbose("Resolving dependencies with the built-in resolver...")
+	results := ScaResultsFile{}
+	err := filepath.WalkDir(projectDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != projectDir && builtinSkippedDirs[entry.Name()] {
+			return filepath.SkipDir
+		}
+		resolutions, err := resolveDirectory(projectDir, path)
+		if err != nil {
+			return err
+		}
+		results.DependencyResolutionResults = append(results.DependencyResolutionResults, resolutions...)
+		return nil
+	})
+	if err != nil {
+		return ScaResultsFile{}, errors.Wrapf(err, "Failed to resolve the dependencies of %s", projectDir)
+	}
+	return results, nil
+}
+
+// resolveDirectory resolves the manifests of a single directory
+func resolveDirectory(projectDir, dir string) ([]DependencyResolution, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]bool{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files[entry.Name()] = true
+		}
+	}
+	var resolutions []DependencyResolution
+	add := func(manifest string, resolve func(dir string, files map[string]bool) (DependencyResolution, error)) {
+		resolution, resolveErr := resolve(dir, files)
+		if resolveErr != nil {
+			resolution.Dependencies = nil
+			resolution.DependencyResolverStatus = scaResolverFailedStatus
+			resolution.Message = resolveErr.Error()
+		} else if resolution.DependencyResolverStatus == "" {
+			resolution.DependencyResolverStatus = scaResolvedStatus
+		}
+		relative, relErr := filepath.Rel(projectDir, filepath.Join(dir, manifest))
+		if relErr != nil {
+			relative = filepath.Join(dir, manifest)
+		}
+		resolution.PackageManagerFile = filepath.ToSlash(relative)
+		sort.Slice(resolution.Dependencies, func(i, j int) bool {
+			return resolution.Dependencies[i].ID.NodeID < resolution.Dependencies[j].ID.NodeID
+		})
+		logger.PrintfIfVerbose("Resolved %d dependencies from %s", len(resolution.Dependencies), resolution.PackageManagerFile)
+		resolutions = append(resolutions, resolution)
+	}
+	if files[packageJSONFile] {
+		add(packageJSONFile, resolveNpm)
+	}
+	switch {
+	case files[goModFile]:
+		add(goModFile, resolveGoModules)
+	case files[goSumFile]:
+		add(goSumFile, resolveGoModules)
+	}
+	if files[requirementsFile] {
+		add(requirementsFile, resolveRequirements)
+	}
+	switch {
+	case files[poetryLockFile] && files[pyprojectFile]:
+		add(pyprojectFile, resolvePoetry)
+	case files[poetryLockFile]:
+		add(poetryLockFile, resolvePoetry)
+	}
+	if files[pomFile] {
+		add(pomFile, resolveMaven)
+	}
+	if files[nugetLockFile] {
+		add(nugetLockFile, resolveNuget)
+	}
+	return resolutions, nil
+}
+
+func dependencyID(name, version string) ID {
+	return ID{NodeID: name + "@" + version, Name: name, Version: version}
+}
+
+func newDependency(moduleType, name, version string, isDirect bool) Dependency {
+	return Dependency{ID: dependencyID(name, version), IsDirect: isDirect, ResolvingModuleType: moduleType}
+}
+
+// unresolvedError reports the dependencies that the built-in resolver skipped
+func unresolvedError(reason string, unresolved []string) error {
+	return errors.Errorf("%s, these dependencies were not resolved: %s", reason, strings.Join(unresolved, ", "))
+}
+
+// partiallyResolved marks a resolution as failed while keeping the dependencies it resolved
+func partiallyResolved(resolution DependencyResolution, err error) DependencyResolution {
+	resolution.DependencyResolverStatus = scaResolverFailedStatus
+	resolution.Message = err.Error()
+	return resolution
+}
+
+// resolveNpm reads the npm, yarn or pnpm lockfile next to a package.json
+func resolveNpm(dir string, files map[string]bool) (DependencyResolution, error) {
+	resolution := DependencyResolution{ResolvingModuleType: npmModuleType}
+	packageJSON, err := os.ReadFile(filepath.Join(dir, packageJSONFile))
+	if err != nil {
+		return resolution, err
+	}
+	for _, name := range remediation.NpmLockfiles {
+		if !files[name] {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return resolution, err
+		}
+		lockfile, err := remediation.ParseLockfile(name, string(content), string(packageJSON))
+		if err != nil {
+			return resolution, err
+		}
+		resolution.ResolvingModuleType = npmModuleTypes[lockfile.Manager]
+		direct := map[*remediation.LockPackage]bool{}
+		for _, p := range lockfile.Direct {
+			direct[p] = true
+		}
+		for _, p := range lockfile.Packages() {
+			dependency := newDependency(resolution.ResolvingModuleType, p.Name, p.Version, direct[p])
+			for _, child := range p.Dependencies {
+				dependency.Children = append(dependency.Children, dependencyID(child.Name, child.Version))
+			}
+			resolution.Dependencies = append(resolution.Dependencies, dependency)
+		}
+		return resolution, nil
+	}
+	return resolution, errors.Errorf(
+		"No lockfile was found next to %s, the built-in resolver needs one of %s",
+		packageJSONFile,
+		strings.Join(remediation.NpmLockfiles, ", "),
+	)
+}
+
+// goRequirement is a module required by a go.mod
+type goRequirement struct {
+	version  string
+	indirect bool
+}
+
+// resolveGoModules reads the requirements of a go.mod, which lists the whole build list since Go 1.17, and the
+// modules of the go.sum that older go.mod files leave out
+func resolveGoModules(dir string, files map[string]bool) (DependencyResolution, error) {
+	resolution := DependencyResolution{ResolvingModuleType: goModuleType}
+	requirements := map[string]goRequirement{}
+	if files[goModFile] {
+		content, err := os.ReadFile(filepath.Join(dir, goModFile))
+		if err != nil {
+			return resolution, err
+		}
+		requirements = parseGoMod(string(content))
+	}
+	if files[goSumFile] {
+		content, err := os.ReadFile(filepath.Join(dir, goSumFile))
+		if err != nil {
+			return resolution, err
+		}
+		for module, version := range parseGoSum(string(content)) {
+			if _, found := requirements[module]; !found {
+				requirements[module] = goRequirement{version: version, indirect: true}
+			}
+		}
+	}
+	for module, requirement := range requirements {
+		resolution.Dependencies = append(resolution.Dependencies,
+			newDependency(goModuleType, module, requirement.version, !requirement.indirect))
+	}
+	return resolution, nil
+}
+
+// parseGoMod returns the requirements of a go.mod
+func parseGoMod(content string) map[string]goRequirement {
+	requirements := map[string]goRequirement{}
+	inRequireBlock := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.Contains(line, "// indirect")
+		if comment := strings.Index(line, "//"); comment >= 0 {
+			line = strings.TrimSpace(line[:comment])
+		}
+		switch {
+		case inRequireBlock && line == ")":
+			inRequireBlock = false
+			continue
+		case line == "require (":
+			inRequireBlock = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require"))
+		case !inRequireBlock:
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 { //nolint:gomnd
+			requirements[strings.Trim(fields[0], `"`)] = goRequirement{version: fields[1], indirect: indirect}
+		}
+	}
+	return requirements
+}
+
+// parseGoSum returns the highest version of each module of a go.sum whose content is downloaded, the modules of
+// which only the go.mod is checked are not built
+func parseGoSum(content string) map[string]string {
+	versions := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") { //nolint:gomnd
+			continue
+		}
+		module, version := fields[0], fields[1]
+		if current, found := versions[module]; !found || remediation.CompareVersions(version, current) > 0 {
+			versions[module] = version
+		}
+	}
+	return versions
+}
+
+// resolveRequirements reads the pinned requirements of a requirements.txt, the others need pip to be resolved
+func resolveRequirements(dir string, _ map[string]bool) (DependencyResolution, error) {
+	resolution := DependencyResolution{ResolvingModuleType: pipModuleType}
+	content, err := os.ReadFile(filepath.Join(dir, requirementsFile))
+	if err != nil {
+		return resolution, err
+	}
+	var unresolved []string
+	// lines ending with a backslash continue on the next line
+	joined := strings.ReplaceAll(string(content), "\\\n", " ")
+	scanner := bufio.NewScanner(strings.NewReader(joined))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, " #"); comment >= 0 {
+			line = line[:comment]
+		}
+		if options := strings.Index(line, " --"); options >= 0 {
+			// per requirement options, such as --hash
+			line = line[:options]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		match := pinnedRequirementRegex.FindStringSubmatch(line)
+		if match == nil {
+			unresolved = append(unresolved, line)
+			continue
+		}
+		resolution.Dependencies = append(resolution.Dependencies, newDependency(pipModuleType, match[1], match[2], true))
+	}
+	if len(unresolved) > 0 {
+		return partiallyResolved(resolution, unresolvedError("Only pinned requirements are resolved without SCA Resolver", unresolved)), nil
+	}
+	return resolution, nil
+}
+
+type poetryLock struct {
+	Package []struct {
+		Name         string                 `toml:"name"`
+		Version      string                 `toml:"version"`
+		Category     string                 `toml:"category"`
+		Dependencies map[string]interface{} `toml:"dependencies"`
+	} `toml:"package"`
+}
+
+type pyproject struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// normalizePythonName normalizes a Python package name as PEP 503 does
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameRegex.ReplaceAllString(name, "-"))
+}
+
+// resolvePoetry reads a poetry.lock, the pyproject.toml next to it tells the direct dependencies
+func resolvePoetry(dir string, files map[string]bool) (DependencyResolution, error) {
+	resolution := DependencyResolution{ResolvingModuleType: poetryModuleType}
+	content, err := os.ReadFile(filepath.Join(dir, poetryLockFile))
+	if err != nil {
+		return resolution, err
+	}
+	lock := poetryLock{}
+	if err = toml.Unmarshal(content, &lock); err != nil {
+		return resolution, errors.Wrapf(err, "Failed to parse %s", poetryLockFile)
+	}
+	direct, development := map[string]bool{}, map[string]bool{}
+	if files[pyprojectFile] {
+		content, err = os.ReadFile(filepath.Join(dir, pyprojectFile))
+		if err != nil {
+			return resolution, err
+		}
+		direct, development, err = pyprojectDependencies(content)
+		if err != nil {
+			return resolution, err
+		}
+	}
+	locked := map[string]string{}
+	for _, p := range lock.Package {
+		locked[normalizePythonName(p.Name)] = p.Version
+	}
+	for _, p := range lock.Package {
+		name := normalizePythonName(p.Name)
+		dependency := newDependency(poetryModuleType, p.Name, p.Version, direct[name] || development[name])
+		dependency.IsDevelopment = p.Category == "dev" || (development[name] && !direct[name])
+		for child := range p.Dependencies {
+			if version, found := locked[normalizePythonName(child)]; found {
+				dependency.Children = append(dependency.Children, dependencyID(child, version))
+			}
+		}
+		sort.Slice(dependency.Children, func(i, j int) bool {
+			return dependency.Children[i].NodeID < dependency.Children[j].NodeID
+		})
+		resolution.Dependencies = append(resolution.Dependencies, dependency)
+	}
+	return resolution, nil
+}
+
+// pyprojectDependencies returns the normalized names of the dependencies and development dependencies of a
+// pyproject.toml, from its PEP 621 project table or from its Poetry tables
+func pyprojectDependencies(content []byte) (direct, development map[string]bool, err error) {
+	project := pyproject{}
+	if err = toml.Unmarshal(content, &project); err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed to parse %s", pyprojectFile)
+	}
+	direct, development = map[string]bool{}, map[string]bool{}
+	addRequirements := func(names map[string]bool, requirements []string) {
+		for _, requirement := range requirements {
+			if match := requirementNameRegex.FindStringSubmatch(strings.TrimSpace(requirement)); match != nil {
+				names[normalizePythonName(match[1])] = true
+			}
+		}
+	}
+	addRequirements(direct, project.Project.Dependencies)
+	for _, requirements := range project.Project.OptionalDependencies {
+		addRequirements(direct, requirements)
+	}
+	for name := range project.Tool.Poetry.Dependencies {
+		if name != "python" {
+			direct[normalizePythonName(name)] = true
+		}
+	}
+	for name := range project.Tool.Poetry.DevDependencies {
+		development[normalizePythonName(name)] = true
+	}
+	for group, dependencies := range project.Tool.Poetry.Group {
+		for name := range dependencies.Dependencies {
+			if group == "main" {
+				direct[normalizePythonName(name)] = true
+			} else {
+				development[normalizePythonName(name)] = true
+			}
+		}
+	}
+	return direct, development, nil
+}
+
+type pomProject struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	DependencyManagement struct {
+		Dependencies []pomDependency `xml:"dependencies>dependency"`
+	} `xml:"dependencyManagement"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// resolveMaven reads the dependencies declared by a pom.xml, with the versions of its properties and dependency
+// management. Transitive dependencies, parent poms and BOMs need Maven to be resolved.
+func resolveMaven(dir string, _ map[string]bool) (DependencyResolution, error) {
+	resolution := DependencyResolution{ResolvingModuleType: mavenModuleType}
+	content, err := os.ReadFile(filepath.Join(dir, pomFile))
+	if err != nil {
+		return resolution, err
+	}
+	project := pomProject{}
+	if err = xml.Unmarshal(content, &project); err != nil {
+		return resolution, errors.Wrapf(err, "Failed to parse %s", pomFile)
+	}
+	properties := map[string]string{
+		"project.groupId":        firstNonEmpty(project.GroupID, project.Parent.GroupID),
+		"project.version":        firstNonEmpty(project.Version, project.Parent.Version),
+		"project.parent.groupId": project.Parent.GroupID,
+		"project.parent.version": project.Parent.Version,
+	}
+	properties["pom.groupId"], properties["pom.version"] = properties["project.groupId"], properties["project.version"]
+	for _, property := range project.Properties.Entries {
+		properties[property.XMLName.Local] = strings.TrimSpace(property.Value)
+	}
+	expand := func(value string) string {
+		value = strings.TrimSpace(value)
+		for i := 0; i < maxPropertyExpansion && strings.Contains(value, "${"); i++ {
+			value = mavenPropertyRegex.ReplaceAllStringFunc(value, func(reference string) string {
+				if property, found := properties[reference[2:len(reference)-1]]; found {
+					return property
+				}
+				return reference
+			})
+		}
+		return value
+	}
+	managed := map[string]string{}
+	for _, dependency := range project.DependencyManagement.Dependencies {
+		managed[expand(dependency.GroupID)+":"+expand(dependency.ArtifactID)] = expand(dependency.Version)
+	}
+	var unresolved []string
+	for _, dependency := range project.Dependencies {
+		name := expand(dependency.GroupID) + ":" + expand(dependency.ArtifactID)
+		version := expand(dependency.Version)
+		if version == "" {
+			version = managed[name]
+		}
+		version, ok := mavenExactVersion(version)
+		if !ok {
+			unresolved = append(unresolved, name)
+			continue
+		}
+		resolved := newDependency(mavenModuleType, name, version, true)
+		resolved.IsTestDependency = strings.EqualFold(strings.TrimSpace(dependency.Scope), "test")
+		resolution.Dependencies = append(resolution.Dependencies, resolved)
+	}
+	if len(unresolved) > 0 {
+		return partiallyResolved(resolution, unresolvedError("The versions of these dependencies need Maven to be resolved", unresolved)), nil
+	}
+	return resolution, nil
+}
+
+// mavenExactVersion returns the version of a dependency unless it is missing, a property that was not expanded or
+// a range. [1.2.3] is the exact version 1.2.3.
+func mavenExactVersion(version string) (string, bool) {
+	if strings.HasPrefix(version, "[") && strings.HasSuffix(version, "]") && !strings.Contains(version, ",") {
+		version = strings.TrimSpace(version[1 : len(version)-1])
+	}
+	if version == "" || strings.ContainsAny(version, "${[()],") {
+		return "", false
+	}
+	return version, true
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+type nugetLockfile struct {
+	Dependencies map[string]map[string]nugetLockPackage `json:"dependencies"`
+}
+
+type nugetLockPackage struct {
+	Type         string            `json:"type"`
+	Resolved     string            `json:"resolved"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// resolveNuget reads a NuGet packages.lock.json, the packages of all the target frameworks are merged
+func resolveNuget(dir string, _ map[string]bool) (DependencyResolution, error) {
+	resolution := DependencyResolution{ResolvingModuleType: nugetModuleType}
+	content, err := os.ReadFile(filepath.Join(dir, nugetLockFile))
+	if err != nil {
+		return resolution, err
+	}
+	lockfile := nugetLockfile{}
+	if err = json.Unmarshal(content, &lockfile); err != nil {
+		return resolution, errors.Wrapf(err, "Failed to parse %s", nugetLockFile)
+	}
+	dependencies := map[string]*Dependency{}
+	for _, packages := range lockfile.Dependencies {
+		for name, p := range packages {
+			if p.Type == "Project" || p.Resolved == "" {
+				continue
+			}
+			id := dependencyID(name, p.Resolved)
+			dependency, found := dependencies[id.NodeID]
+			if !found {
+				created := newDependency(nugetModuleType, name, p.Resolved, false)
+				dependency = &created
+				dependencies[id.NodeID] = dependency
+			}
+			dependency.IsDirect = dependency.IsDirect || p.Type == "Direct"
+			for child, versionRange := range p.Dependencies {
+				version := strings.Trim(versionRange, "[]() ")
+				if locked, lockedFound := packages[child]; lockedFound && locked.Resolved != "" {
+					version = locked.Resolved
+				}
+				childID := dependencyID(child, version)
+				if !containsID(dependency.Children, childID) {
+					dependency.Children = append(dependency.Children, childID)
+				}
+			}
+		}
+	}
+	for _, dependency := range dependencies {
+		sort.Slice(dependency.Children, func(i, j int) bool {
+			return dependency.Children[i].NodeID < dependency.Children[j].NodeID
+		})
+		resolution.Dependencies = append(resolution.Dependencies, *dependency)
+	}
+	return resolution, nil
+}
+
+func containsID(ids []ID, id ID) bool {
+	for _, existing := range ids {
+		if existing.NodeID == id.NodeID {
+			return true
+		}
+	}
+	return false
+}
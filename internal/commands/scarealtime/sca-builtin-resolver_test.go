@@ -0,0 +1,314 @@
+package scarealtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
+	"gotest.tools/assert"
+)
+
+const testPackageJSON = `{"name": "app", "dependencies": {"express": "^4.17.1"}}`
+
+const testPackageLock = `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "^4.17.1"}},
+    "node_modules/express": {"version": "4.17.1", "dependencies": {"qs": "6.7.0"}},
+    "node_modules/qs": {"version": "6.7.0"}
+  }
+}`
+
+const testGoMod = `module example.com/app
+
+go 1.21
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.14.0 // indirect
+)
+
+require github.com/spf13/cobra v1.8.0
+`
+
+const testGoSum = `github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=
+github.com/pkg/errors v0.9.1/go.mod h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=
+github.com/spf13/cobra v1.8.0 h1:7aJaZx1B85qltLMc546zn58BxxfZdR/W22ej9CFoEf0=
+github.com/spf13/pflag v1.0.3 h1:zPAT6CGy6wXeQ7NtTnaTerfKOsV6V6F8agHXFiazDkg=
+github.com/spf13/pflag v1.0.5 h1:iy+VFUOCP1a+8yFto/drg2CJ5u0yRoB7fZw3DKv/JXA=
+github.com/spf13/pflag v1.0.5/go.mod h1:McXfInJRxz4Pb2aYTpIhL7jvAAiXsZgfP9WY2sZvbTk=
+golang.org/x/text v0.14.0 h1:ScX5w1eTa3QqT8oi6+ziP7dTV1S2+ALU0bI+0zXKWiQ=
+gopkg.in/yaml.v2 v2.4.0/go.mod h1:RDklbk79AGWmwhnvt/jzKDBBRqMabacRgl5DmOxN1Lt=
+`
+
+const testRequirements = `# production dependencies
+requests[security]==2.31.0 \
+    --hash=sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f
+Django===4.2.1 ; python_version >= "3.8"
+flask>=2.0
+`
+
+const testPyproject = `[tool.poetry]
+name = "app"
+
+[tool.poetry.dependencies]
+python = "^3.10"
+requests = "^2.31"
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^7.4"
+`
+
+const testPoetryLock = `[[package]]
+name = "requests"
+version = "2.31.0"
+description = "Python HTTP for Humans."
+optional = false
+python-versions = ">=3.7"
+files = [
+    {file = "requests-2.31.0.tar.gz", hash = "sha256:942c5a758f98d790eaed1a29cb6eefc7ffb0d1cf7af05c3d2791656dbd6ad1e1"},
+]
+
+[package.dependencies]
+certifi = ">=2017.4.17"
+urllib3 = ">=1.21.1,<3"
+
+[[package]]
+name = "certifi"
+version = "2023.7.22"
+optional = false
+python-versions = ">=3.6"
+
+[[package]]
+name = "urllib3"
+version = "2.0.4"
+optional = false
+python-versions = ">=3.7"
+
+[[package]]
+name = "pytest"
+version = "7.4.0"
+optional = false
+python-versions = ">=3.7"
+`
+
+const testPom = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <properties>
+    <junit.version>4.10</junit.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.apache.commons</groupId>
+        <artifactId>commons-text</artifactId>
+        <version>1.9</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>${junit.version}</version>
+      <scope>test</scope>
+    </dependency>
+    <dependency>
+      <groupId>org.apache.commons</groupId>
+      <artifactId>commons-text</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>app-core</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+    </dependency>
+  </dependencies>
+</project>
+`
+
+const testNugetLock = `{
+  "version": 1,
+  "dependencies": {
+    "net6.0": {
+      "Newtonsoft.Json": {
+        "type": "Direct",
+        "requested": "[13.0.1, )",
+        "resolved": "13.0.1",
+        "contentHash": "ppPFpBcvxdsfUonNcvITKqLl3bqxWbDCZIzDWHzjpdAHRFfZe0Dw9HmA0+za13IdyrgJwpkDTDA9fHaxOrt20A=="
+      },
+      "Serilog.Sinks.File": {
+        "type": "Direct",
+        "requested": "[5.0.0, )",
+        "resolved": "5.0.0",
+        "dependencies": {"Serilog": "2.10.0"}
+      },
+      "Serilog": {
+        "type": "Transitive",
+        "resolved": "2.10.0"
+      },
+      "App.Core": {
+        "type": "Project"
+      }
+    },
+    "net8.0": {
+      "Newtonsoft.Json": {
+        "type": "Direct",
+        "requested": "[13.0.1, )",
+        "resolved": "13.0.1"
+      }
+    }
+  }
+}`
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+// summarize returns name@version of the dependencies with a mark for the direct ones
+func summarize(resolution DependencyResolution) []string {
+	var dependencies []string
+	for _, dependency := range resolution.Dependencies {
+		summary := dependency.ID.NodeID
+		if dependency.IsDirect {
+			summary += " direct"
+		}
+		if dependency.IsDevelopment || dependency.IsTestDependency {
+			summary += " dev"
+		}
+		dependencies = append(dependencies, summary)
+	}
+	return dependencies
+}
+
+func TestBuiltinResolverNpm(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"web/package.json":                      testPackageJSON,
+		"web/package-lock.json":                 testPackageLock,
+		"web/node_modules/express/package.json": `{"name": "express"}`,
+		"tools/package.json":                    testPackageJSON,
+	})
+	results, err := resolveDependencies(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results.DependencyResolutionResults), 2)
+
+	tools := results.DependencyResolutionResults[0]
+	assert.Equal(t, tools.PackageManagerFile, "tools/package.json")
+	assert.Equal(t, tools.DependencyResolverStatus, scaResolverFailedStatus)
+	assert.Assert(t, tools.Message != "")
+
+	web := results.DependencyResolutionResults[1]
+	assert.Equal(t, web.PackageManagerFile, "web/package.json")
+	assert.Equal(t, web.ResolvingModuleType, npmModuleType)
+	assert.Equal(t, web.DependencyResolverStatus, scaResolvedStatus)
+	assert.DeepEqual(t, summarize(web), []string{"express@4.17.1 direct", "qs@6.7.0"})
+	assert.DeepEqual(t, web.Dependencies[0].Children, []ID{dependencyID("qs", "6.7.0")})
+}
+
+func TestBuiltinResolverGoModules(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"go.mod": testGoMod, "go.sum": testGoSum})
+	results, err := resolveDependencies(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results.DependencyResolutionResults), 1)
+	resolution := results.DependencyResolutionResults[0]
+	assert.Equal(t, resolution.PackageManagerFile, "go.mod")
+	assert.DeepEqual(t, summarize(resolution), []string{
+		"github.com/pkg/errors@v0.9.1 direct",
+		"github.com/spf13/cobra@v1.8.0 direct",
+		"github.com/spf13/pflag@v1.0.5",
+		"golang.org/x/text@v0.14.0",
+	})
+}
+
+func TestBuiltinResolverPython(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"requirements.txt":   testRequirements,
+		"api/pyproject.toml": testPyproject,
+		"api/poetry.lock":    testPoetryLock,
+	})
+	results, err := resolveDependencies(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(results.DependencyResolutionResults), 2)
+
+	requirements := results.DependencyResolutionResults[0]
+	assert.Equal(t, requirements.PackageManagerFile, "requirements.txt")
+	assert.Equal(t, requirements.DependencyResolverStatus, scaResolverFailedStatus)
+	assert.Equal(t, requirements.Message,
+		"Only pinned requirements are resolved without SCA Resolver, these dependencies were not resolved: flask>=2.0")
+	assert.DeepEqual(t, summarize(requirements), []string{"Django@4.2.1 direct", "requests@2.31.0 direct"})
+
+	poetry := results.DependencyResolutionResults[1]
+	assert.Equal(t, poetry.PackageManagerFile, "api/pyproject.toml")
+	assert.Equal(t, poetry.ResolvingModuleType, poetryModuleType)
+	assert.DeepEqual(t, summarize(poetry), []string{
+		"certifi@2023.7.22",
+		"pytest@7.4.0 direct dev",
+		"requests@2.31.0 direct",
+		"urllib3@2.0.4",
+	})
+	assert.DeepEqual(t, poetry.Dependencies[2].Children, []ID{
+		dependencyID("certifi", "2023.7.22"),
+		dependencyID("urllib3", "2.0.4"),
+	})
+}
+
+func TestBuiltinResolverMaven(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"pom.xml": testPom})
+	results, err := resolveDependencies(dir)
+	assert.NilError(t, err)
+	resolution := results.DependencyResolutionResults[0]
+	assert.Equal(t, resolution.ResolvingModuleType, mavenModuleType)
+	assert.Equal(t, resolution.DependencyResolverStatus, scaResolverFailedStatus)
+	assert.Equal(t, resolution.Message,
+		"The versions of these dependencies need Maven to be resolved, these dependencies were not resolved: org.slf4j:slf4j-api")
+	assert.DeepEqual(t, summarize(resolution), []string{
+		"com.example:app-core@1.0.0 direct",
+		"junit:junit@4.10 direct dev",
+		"org.apache.commons:commons-text@1.9 direct",
+	})
+}
+
+func TestBuiltinResolverNuget(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"src/Api/packages.lock.json": testNugetLock})
+	results, err := resolveDependencies(dir)
+	assert.NilError(t, err)
+	resolution := results.DependencyResolutionResults[0]
+	assert.Equal(t, resolution.PackageManagerFile, "src/Api/packages.lock.json")
+	assert.DeepEqual(t, summarize(resolution), []string{
+		"Newtonsoft.Json@13.0.1 direct",
+		"Serilog.Sinks.File@5.0.0 direct",
+		"Serilog@2.10.0",
+	})
+	assert.DeepEqual(t, resolution.Dependencies[1].Children, []ID{dependencyID("Serilog", "2.10.0")})
+	assert.Equal(t, GetPackageManagerFromResolvingModuleType["nuget"], "Nuget")
+}
+
+func TestRunScaRealtimeBuiltinResolver(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"pom.xml": testPom})
+	cmd := NewScaRealtimeCommand(mock.ScaRealTimeHTTPMockWrapper{})
+	cmd.SetArgs([]string{"scan", "sca-realtime", "--project-dir", dir, "--resolver", "builtin"})
+	err := cmd.Execute()
+	assert.NilError(t, err)
+}
+
+func TestRunScaRealtimeInvalidResolver(t *testing.T) {
+	cmd := NewScaRealtimeCommand(mock.ScaRealTimeHTTPMockWrapper{})
+	cmd.SetArgs([]string{"scan", "sca-realtime", "--project-dir", projectDirectory, "--resolver", "maven"})
+	err := cmd.Execute()
+	assert.Error(t, err, "Invalid resolver maven, the available resolvers are sca-resolver and builtin")
+}
@@ -5,26 +5,29 @@ package scarealtime
 import (
 	"archive/tar"
 	"compress/gzip"
-	"fmt"
 	"io"
 	"io/fs"
-	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
 )
 
-// UnzipOrExtractFiles Extracts SCA Resolver files
-func UnzipOrExtractFiles() error {
-	logger.PrintIfVerbose("Extracting files in: " + ScaResolverWorkingDir)
-	gzipStream, err := os.Open(filepath.Join(ScaResolverWorkingDir, Params.SCAResolverFileName))
+// UnzipOrExtractFiles Extracts SCA Resolver files of an archive in a directory
+func UnzipOrExtractFiles(archivePath, destination string) error {
+	logger.PrintIfVerbose("Extracting files in: " + destination)
+	gzipStream, err := os.Open(archivePath)
 	if err != nil {
-		fmt.Println("error")
+		return err
 	}
+	defer func() {
+		_ = gzipStream.Close()
+	}()
 	uncompressedStream, err := gzip.NewReader(gzipStream)
 	if err != nil {
-		log.Fatal("ExtractTarGz: NewReader failed")
+		return errors.Wrap(err, "ExtractTarGz: NewReader failed")
 	}
 
 	tarReader := tar.NewReader(uncompressedStream)
@@ -37,22 +40,29 @@ func UnzipOrExtractFiles() error {
 		}
 
 		if err != nil {
-			log.Fatalf("ExtractTarGz: Next() failed: %s", err.Error())
+			return errors.Wrap(err, "ExtractTarGz: Next() failed")
+		}
+
+		extractedFilePath := filepath.Join(destination, header.Name)
+		// Check for directory traversal
+		if extractedFilePath != filepath.Clean(destination) &&
+			!strings.HasPrefix(extractedFilePath, filepath.Clean(destination)+string(os.PathSeparator)) {
+			return errors.Errorf("ExtractTarGz: illegal file path: %s", header.Name)
 		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if err := os.Mkdir(header.Name, 0755); err != nil { //nolint:gomnd
-				log.Fatalf("ExtractTarGz: Mkdir() failed: %s", err.Error())
+			if err := os.MkdirAll(extractedFilePath, 0755); err != nil { //nolint:gomnd
+				return errors.Wrap(err, "ExtractTarGz: Mkdir() failed")
 			}
 		case tar.TypeReg:
-			extractedFilePath := filepath.Join(ScaResolverWorkingDir, header.Name)
 			outFile, err := os.Create(extractedFilePath)
 			if err != nil {
-				log.Fatalf("ExtractTarGz: Create() failed: %s", err.Error())
+				return errors.Wrap(err, "ExtractTarGz: Create() failed")
 			}
 			if _, err = io.Copy(outFile, tarReader); err != nil {
-				log.Fatalf("ExtractTarGz: Copy() failed: %s", err.Error())
+				_ = outFile.Close()
+				return errors.Wrap(err, "ExtractTarGz: Copy() failed")
 			}
 			err = outFile.Close()
 			if err != nil {
@@ -63,7 +73,7 @@ func UnzipOrExtractFiles() error {
 				return err
 			}
 		default:
-			log.Fatalf(
+			return errors.Errorf(
 				"ExtractTarGz: uknown type: %v in %s",
 				header.Typeflag,
 				header.Name)
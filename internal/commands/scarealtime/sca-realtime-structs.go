@@ -3,12 +3,9 @@ package scarealtime
 import "time"
 
 type ScaRealTime struct {
-	ExecutableFilePath         string
-	HashFilePath               string
-	SCAResolverDownloadURL     string
-	SCAResolverHashDownloadURL string
-	SCAResolverFileName        string
-	SCAResolverHashFileName    string
+	ExecutableFileName string
+	// SCAResolverFileName is the name of the SCA Resolver archive on the download site
+	SCAResolverFileName string
 }
 
 type ScaResultsFile struct {
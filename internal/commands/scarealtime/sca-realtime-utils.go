@@ -1,30 +1,55 @@
 package scarealtime
 
 import (
-	"bytes"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/user"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
 	"github.com/checkmarx/ast-cli/internal/wrappers"
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 )
 
+const (
+	latestSCAResolverVersion = "latest"
+	scaResolverCacheDirName  = ".checkmarx/sca-resolver"
+	// scaResolverVersionsDir maps the pinned versions to the digests of their archives, pinned versions do not
+	// change so they are used from the cache without network access
+	scaResolverVersionsDir = "versions"
+	checksumExtension      = ".sha256sum"
+	signatureExtension     = ".sig"
+	cacheDirPermissions    = 0700
+	cacheFilePermissions   = 0600
+)
+
+var sha256Regex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
 var ScaResolverWorkingDir = filepath.Join(os.TempDir(), "SCARealtime")
 
 var GetPackageManagerFromResolvingModuleType = map[string]string{
 	"composer":  "Php",
 	"gomodules": "Go",
 	"pip":       "Python",
+	"poetry":    "Python",
 	"rubygems":  "Ruby",
 	"npm":       "Npm",
 	"yarn":      "Npm",
+	"pnpm":      "Npm",
 	"bower":     "Npm",
 	"lerna":     "Npm",
 	"sbt":       "Maven",
@@ -34,88 +59,224 @@ var GetPackageManagerFromResolvingModuleType = map[string]string{
 	"swiftpm":   "Ios",
 	"carthage":  "Ios",
 	"cocoapods": "Ios",
+	"nuget":     "Nuget",
 }
 
-// downloadSCAResolverAndHashFileIfNeeded Downloads SCA Realtime if it is not downloaded yet
-func downloadSCAResolverAndHashFileIfNeeded(scaRealTime *ScaRealTime) error {
+// installSCAResolver returns the executable of a version of SCA Resolver. The versions are cached by the digest
+// of their archive in a directory shared by all the projects, the least recently used ones are removed. A download
+// is verified against the published SHA-256 checksum, and against its detached ed25519 signature when
+// CX_SCA_RESOLVER_PUBLIC_KEY is set, before it is extracted. No signature of SCA Resolver is published yet, so the
+// key has no default and downloads are verified against their checksum only until it is set.
+func installSCAResolver(version string) (string, error) {
 	logger.PrintIfVerbose("Handling SCA Resolver...")
-
-	if downloadNotNeeded(scaRealTime) {
-		logger.PrintIfVerbose("SCA Resolver already exists and is up to date. Skipping download.")
-		return nil
+	cacheDir, err := scaResolverCacheDir()
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Join(cacheDir, scaResolverVersionsDir), cacheDirPermissions); err != nil {
+		return "", err
+	}
+	pinned := version != latestSCAResolverVersion
+	versionFile := filepath.Join(cacheDir, scaResolverVersionsDir, url.PathEscape(version))
+	if pinned {
+		if digest, readErr := os.ReadFile(versionFile); readErr == nil {
+			if executable, found := cachedSCAResolver(cacheDir, string(digest)); found {
+				logger.PrintIfVerbose("SCA Resolver " + version + " is cached. Skipping download.")
+				return executable, nil
+			}
+		}
 	}
 
-	// Create temporary working directory if not exists
-	err := createWorkingDirectory()
+	archiveURL := strings.TrimSuffix(viper.GetString(commonParams.ScaResolverMirrorKey), "/") + "/" +
+		url.PathEscape(version) + "/" + Params.SCAResolverFileName
+	digest, err := downloadChecksum(archiveURL + checksumExtension)
 	if err != nil {
-		return err
+		return "", err
+	}
+	executable, found := cachedSCAResolver(cacheDir, digest)
+	if !found {
+		executable, err = downloadSCAResolver(cacheDir, archiveURL, digest)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		logger.PrintIfVerbose("SCA Resolver is cached and up to date. Skipping download.")
+	}
+	if pinned {
+		if err = os.WriteFile(versionFile, []byte(digest), cacheFilePermissions); err != nil {
+			return "", err
+		}
 	}
+	cleanSCAResolverCache(cacheDir)
+	return executable, nil
+}
 
-	// Download SCA Resolver
-	err = downloadFile(scaRealTime.SCAResolverDownloadURL, scaRealTime.SCAResolverFileName)
+// scaResolverCacheDir returns the cache directory, from the configuration or in the home directory
+func scaResolverCacheDir() (string, error) {
+	if dir := viper.GetString(commonParams.ScaResolverCacheDirKey); dir != "" {
+		return dir, nil
+	}
+	usr, err := user.Current()
 	if err != nil {
-		return err
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, scaResolverCacheDirName), nil
+}
+
+// cachedSCAResolver returns the executable extracted from the archive with a digest, marking it as used
+func cachedSCAResolver(cacheDir, digest string) (string, bool) {
+	if !sha256Regex.MatchString(digest) {
+		return "", false
+	}
+	dir := filepath.Join(cacheDir, strings.ToLower(digest))
+	executable := filepath.Join(dir, Params.ExecutableFileName)
+	if exists, _ := fileExists(executable); !exists {
+		return "", false
 	}
+	now := time.Now()
+	_ = os.Chtimes(dir, now, now)
+	return executable, true
+}
 
-	// Download SCA Resolver hash file
-	err = downloadSCAResolverHashFile(scaRealTime.SCAResolverHashDownloadURL, scaRealTime.SCAResolverHashFileName)
+// downloadSCAResolver downloads, verifies and extracts an archive of SCA Resolver in the cache
+func downloadSCAResolver(cacheDir, archiveURL, digest string) (string, error) {
+	publicKey, err := scaResolverPublicKey()
 	if err != nil {
-		return err
+		return "", err
+	}
+	archive, err := downloadFile(archiveURL)
+	if err != nil {
+		return "", err
+	}
+	actual := sha256.Sum256(archive)
+	if !strings.EqualFold(hex.EncodeToString(actual[:]), digest) {
+		return "", errors.Errorf("The SHA-256 checksum of %s does not match the published checksum", archiveURL)
+	}
+	if err = verifySCAResolverSignature(publicKey, archiveURL, archive); err != nil {
+		return "", err
 	}
 
-	// Unzip or extract SCA Resolver depending on which OS it is running
-	err = UnzipOrExtractFiles()
+	extractDir, err := os.MkdirTemp(cacheDir, "extract-")
 	if err != nil {
-		return err
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(extractDir)
+	}()
+	archivePath := filepath.Join(extractDir, Params.SCAResolverFileName)
+	if err = os.WriteFile(archivePath, archive, cacheFilePermissions); err != nil {
+		return "", err
+	}
+	installDir := filepath.Join(extractDir, "install")
+	if err = os.MkdirAll(installDir, cacheDirPermissions); err != nil {
+		return "", err
+	}
+	if err = UnzipOrExtractFiles(archivePath, installDir); err != nil {
+		return "", err
+	}
+	if exists, _ := fileExists(filepath.Join(installDir, Params.ExecutableFileName)); !exists {
+		return "", errors.Errorf("%s does not contain %s", archiveURL, Params.ExecutableFileName)
+	}
+	// the extraction is moved to its final place at once, an interrupted download leaves no partial version
+	dir := filepath.Join(cacheDir, strings.ToLower(digest))
+	_ = os.RemoveAll(dir)
+	if err = os.Rename(installDir, dir); err != nil {
+		return "", err
 	}
+	return filepath.Join(dir, Params.ExecutableFileName), nil
+}
 
-	return nil
+// scaResolverPublicKey returns the key SCA Resolver is signed with, nil when the signature is not verified
+func scaResolverPublicKey() (ed25519.PublicKey, error) {
+	encodedKey := viper.GetString(commonParams.ScaResolverPublicKeyKey)
+	if encodedKey == "" {
+		logger.Printf("Warning: %s is not set, SCA Resolver is only verified against its published checksum",
+			commonParams.ScaResolverPublicKeyEnv)
+		return nil, nil
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.Errorf("Invalid %s, expected a base64 ed25519 public key", commonParams.ScaResolverPublicKeyEnv)
+	}
+	return publicKey, nil
 }
 
-// createWorkingDirectory Creates a working directory to handle SCA Realtime functionality
-func createWorkingDirectory() error {
-	logger.PrintIfVerbose("Creating temporary directory to handle SCA Realtime...")
-	err := os.MkdirAll(ScaResolverWorkingDir, fs.ModePerm)
+// verifySCAResolverSignature checks the detached ed25519 signature of an archive, published next to it
+func verifySCAResolverSignature(publicKey ed25519.PublicKey, archiveURL string, archive []byte) error {
+	if publicKey == nil {
+		return nil
+	}
+	signature, err := downloadFile(archiveURL + signatureExtension)
 	if err != nil {
-		return err
+		// a missing signature fails the verification, it does not fall back to the built-in resolver
+		return errors.Errorf("The signature of %s could not be downloaded: %v", archiveURL, err)
+	}
+	// the signature is published raw or base64 encoded
+	if len(signature) != ed25519.SignatureSize {
+		signature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return errors.Errorf("The signature of %s is invalid", archiveURL)
+		}
+	}
+	if !ed25519.Verify(publicKey, archive, signature) {
+		return errors.Errorf("The signature of %s is invalid", archiveURL)
 	}
-
 	return nil
 }
 
-// downloadNotNeeded Checks if SCA Resolver is already available and if there is no need to download a new version
-func downloadNotNeeded(scaRealTime *ScaRealTime) bool {
-	logger.PrintIfVerbose("Checking if SCA Resolver already exists...")
-	executableFileExists, _ := fileExists(scaRealTime.ExecutableFilePath)
-
-	if !executableFileExists {
-		return false
+// downloadChecksum downloads a checksum file, in the format of sha256sum: the digest followed by the file name
+func downloadChecksum(checksumURL string) (string, error) {
+	content, err := downloadFile(checksumURL)
+	if err != nil {
+		return "", err
 	}
-
-	logger.PrintIfVerbose("SCA Resolver exists. Checking if it is the latest version...")
-
-	isSCALastVersion, _ := isLastSCAResolverVersion(scaRealTime.HashFilePath, scaRealTime.SCAResolverHashDownloadURL, scaRealTime.SCAResolverHashFileName)
-
-	return isSCALastVersion
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 || !sha256Regex.MatchString(fields[0]) {
+		return "", errors.Errorf("%s is not a SHA-256 checksum file", checksumURL)
+	}
+	return strings.ToLower(fields[0]), nil
 }
 
-// isLastSCAResolverVersion Checks if SCA Resolver is updated by comparing hashes
-func isLastSCAResolverVersion(scaResolverHashFilePath, scaResolverHashURL, scaResolverZipFileNameHash string) (bool, error) {
-	existingHash, _ := getHashValue(scaResolverHashFilePath)
-
-	// Download SCA Resolver hash file
-	err := downloadSCAResolverHashFile(scaResolverHashURL, scaResolverZipFileNameHash)
+// cleanSCAResolverCache removes the least recently used versions beyond the configured cache size
+func cleanSCAResolverCache(cacheDir string) {
+	size := viper.GetInt(commonParams.ScaResolverCacheSizeKey)
+	if size < 1 {
+		size = 1
+	}
+	entries, err := os.ReadDir(cacheDir)
 	if err != nil {
-		return false, err
+		return
 	}
+	type cachedVersion struct {
+		path     string
+		lastUsed time.Time
+	}
+	var versions []cachedVersion
+	for _, entry := range entries {
+		info, infoErr := entry.Info()
+		if infoErr != nil || !entry.IsDir() || !sha256Regex.MatchString(entry.Name()) {
+			continue
+		}
+		versions = append(versions, cachedVersion{path: filepath.Join(cacheDir, entry.Name()), lastUsed: info.ModTime()})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].lastUsed.After(versions[j].lastUsed)
+	})
+	for i := size; i < len(versions); i++ {
+		logger.PrintIfVerbose("Removing unused SCA Resolver from the cache: " + versions[i].path)
+		_ = os.RemoveAll(versions[i].path)
+	}
+}
 
-	currentHash, _ := getHashValue(scaResolverHashFilePath)
-
-	if !bytes.Equal(existingHash, currentHash) {
-		logger.PrintIfVerbose("SCA Resolver is out of date.")
+// createWorkingDirectory Creates a working directory to handle SCA Realtime functionality
+func createWorkingDirectory() error {
+	logger.PrintIfVerbose("Creating temporary directory to handle SCA Realtime...")
+	err := os.MkdirAll(ScaResolverWorkingDir, fs.ModePerm)
+	if err != nil {
+		return err
 	}
 
-	return bytes.Equal(existingHash, currentHash), nil
+	return nil
 }
 
 // fileExists Check if a file exists in a specific directory
@@ -130,61 +291,44 @@ func fileExists(path string) (bool, error) {
 	return false, err
 }
 
-// getHashValue Gets the hash value of a file
-func getHashValue(hashFilePath string) ([]byte, error) {
-	f, err := os.Open(hashFilePath)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer func() {
-		_ = f.Close()
-	}()
-
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return nil, err
-	}
-
-	return h.Sum(nil), nil
+// downloadError is a download that failed because of the network or of the status of the response, unlike
+// the failures to verify what was downloaded
+type downloadError struct {
+	url    string
+	status string
+	err    error
 }
 
-// downloadSCAResolverHashFile Downloads SCA Resolver hash file
-func downloadSCAResolverHashFile(scaResolverHashURL, scaResolverZipFileNameHash string) error {
-	err := downloadFile(scaResolverHashURL, scaResolverZipFileNameHash)
-	if err != nil {
-		return err
+func (e *downloadError) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("Downloading %s failed - %s", e.url, e.err.Error())
 	}
+	return fmt.Sprintf("Downloading %s failed - %s", e.url, e.status)
+}
 
-	return nil
+func (e *downloadError) Unwrap() error {
+	return e.err
 }
 
 // downloadFile Downloads a file
-func downloadFile(downloadURLPath, fileName string) error {
-	logger.PrintIfVerbose("Downloading " + fileName + " from: " + downloadURLPath)
+func downloadFile(downloadURLPath string) ([]byte, error) {
+	logger.PrintIfVerbose("Downloading " + downloadURLPath)
 
 	response, err := wrappers.SendHTTPRequestByFullURL(http.MethodGet, downloadURLPath, http.NoBody, false, 0, "", true)
 	if err != nil {
-		return errors.Errorf("Invoking HTTP request to upload file failed - %s", err.Error())
+		return nil, &downloadError{url: downloadURLPath, err: err}
 	}
 	defer func() {
 		_ = response.Body.Close()
 	}()
-
-	scaResolverZipFile, err := os.Create(filepath.Join(ScaResolverWorkingDir, fileName))
-	if err != nil {
-		fmt.Printf("Error creating SCA resolver zip file: %s", err)
-		return err
+	if response.StatusCode != http.StatusOK {
+		return nil, &downloadError{url: downloadURLPath, status: response.Status}
 	}
-	defer func() {
-		_ = scaResolverZipFile.Close()
-	}()
 
-	// Write the body to file
-	_, err = io.Copy(scaResolverZipFile, response.Body)
+	content, err := io.ReadAll(response.Body)
 	if err != nil {
-		fmt.Printf("Error writing the body response to zip file: %s", err)
-		return err
+		return nil, &downloadError{url: downloadURLPath, err: err}
 	}
 
-	return nil
+	return content, nil
 }
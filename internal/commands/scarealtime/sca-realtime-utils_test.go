@@ -0,0 +1,188 @@
+//go:build linux || darwin
+
+package scarealtime
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"gotest.tools/assert"
+)
+
+// scaResolverMirror serves SCA Resolver archives as the download site does
+type scaResolverMirror struct {
+	files     map[string][]byte
+	downloads int
+}
+
+func (m *scaResolverMirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	content, found := m.files[r.URL.Path]
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if filepath.Ext(r.URL.Path) == ".gz" {
+		m.downloads++
+	}
+	_, _ = w.Write(content)
+}
+
+// publish adds a version with its checksum and its signature
+func (m *scaResolverMirror) publish(t *testing.T, version, script string, privateKey ed25519.PrivateKey) {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	assert.NilError(t, tarWriter.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755}))
+	assert.NilError(t, tarWriter.WriteHeader(&tar.Header{
+		Name: Params.ExecutableFileName, Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(script)),
+	}))
+	_, err := tarWriter.Write([]byte(script))
+	assert.NilError(t, err)
+	assert.NilError(t, tarWriter.Close())
+	assert.NilError(t, gzipWriter.Close())
+
+	archive := buffer.Bytes()
+	digest := sha256.Sum256(archive)
+	path := "/cli/" + version + "/" + Params.SCAResolverFileName
+	m.files[path] = archive
+	m.files[path+checksumExtension] = []byte(hex.EncodeToString(digest[:]) + "  " + Params.SCAResolverFileName + "\n")
+	m.files[path+signatureExtension] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, archive)))
+}
+
+func setupSCAResolverMirror(t *testing.T) (*scaResolverMirror, *httptest.Server, ed25519.PrivateKey, string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NilError(t, err)
+	mirror := &scaResolverMirror{files: map[string][]byte{}}
+	server := httptest.NewServer(mirror)
+	cacheDir := t.TempDir()
+	viper.Set(params.ScaResolverMirrorKey, server.URL+"/cli/")
+	viper.Set(params.ScaResolverCacheDirKey, cacheDir)
+	viper.Set(params.ScaResolverPublicKeyKey, base64.StdEncoding.EncodeToString(publicKey))
+	viper.Set(params.ScaResolverCacheSizeKey, 1)
+	t.Cleanup(func() {
+		server.Close()
+		viper.Set(params.ScaResolverMirrorKey, "https://sca-downloads.s3.amazonaws.com/cli")
+		viper.Set(params.ScaResolverCacheDirKey, "")
+		viper.Set(params.ScaResolverPublicKeyKey, "")
+		viper.Set(params.ScaResolverCacheSizeKey, 3)
+	})
+	return mirror, server, privateKey, cacheDir
+}
+
+func TestInstallSCAResolverCachesVerifiedDownloads(t *testing.T) {
+	mirror, server, privateKey, cacheDir := setupSCAResolverMirror(t)
+	mirror.publish(t, "latest", "#!/bin/sh\necho latest\n", privateKey)
+	mirror.publish(t, "2.7.4", "#!/bin/sh\necho 2.7.4\n", privateKey)
+
+	latest, err := installSCAResolver("latest")
+	assert.NilError(t, err)
+	content, err := os.ReadFile(latest)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "#!/bin/sh\necho latest\n")
+	assert.Equal(t, filepath.Dir(filepath.Dir(latest)), cacheDir)
+
+	// only the checksum is downloaded when the cached version is up to date
+	again, err := installSCAResolver("latest")
+	assert.NilError(t, err)
+	assert.Equal(t, again, latest)
+	assert.Equal(t, mirror.downloads, 1)
+
+	// with a cache of one version, the least recently used one is removed
+	pinned, err := installSCAResolver("2.7.4")
+	assert.NilError(t, err)
+	assert.Assert(t, pinned != latest)
+	exists, _ := fileExists(latest)
+	assert.Assert(t, !exists)
+
+	// pinned versions are used from the cache without network access
+	server.Close()
+	cached, err := installSCAResolver("2.7.4")
+	assert.NilError(t, err)
+	assert.Equal(t, cached, pinned)
+	assert.Equal(t, mirror.downloads, 2)
+}
+
+func TestInstallSCAResolverRejectsUnverifiedDownloads(t *testing.T) {
+	mirror, _, privateKey, cacheDir := setupSCAResolverMirror(t)
+	mirror.publish(t, "latest", "#!/bin/sh\necho latest\n", privateKey)
+	path := "/cli/latest/" + Params.SCAResolverFileName
+
+	mirror.files[path+signatureExtension] = []byte(base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize)))
+	_, err := installSCAResolver("latest")
+	assert.ErrorContains(t, err, "The signature of")
+
+	mirror.files[path] = append(mirror.files[path], 0)
+	_, err = installSCAResolver("latest")
+	assert.ErrorContains(t, err, "does not match the published checksum")
+
+	_, err = installSCAResolver("0.0.1")
+	assert.ErrorContains(t, err, "404")
+
+	entries, err := os.ReadDir(cacheDir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1, "only the versions directory is left in the cache")
+}
+
+func TestInstallSCAResolverWithoutPublicKey(t *testing.T) {
+	mirror, _, privateKey, _ := setupSCAResolverMirror(t)
+	mirror.publish(t, "latest", "#!/bin/sh\necho latest\n", privateKey)
+	path := "/cli/latest/" + Params.SCAResolverFileName
+	delete(mirror.files, path+signatureExtension)
+	viper.Set(params.ScaResolverPublicKeyKey, "")
+
+	// without a key only the checksum is verified
+	executable, err := installSCAResolver("latest")
+	assert.NilError(t, err)
+	content, err := os.ReadFile(executable)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "#!/bin/sh\necho latest\n")
+
+	mirror.publish(t, "2.7.4", "#!/bin/sh\necho 2.7.4\n", privateKey)
+	mirror.files["/cli/2.7.4/"+Params.SCAResolverFileName] = append(mirror.files["/cli/2.7.4/"+Params.SCAResolverFileName], 0)
+	_, err = installSCAResolver("2.7.4")
+	assert.ErrorContains(t, err, "does not match the published checksum")
+
+	// a configured key requires the signature
+	viper.Set(params.ScaResolverPublicKeyKey, base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey)))
+	mirror.publish(t, "2.7.5", "#!/bin/sh\necho 2.7.5\n", privateKey)
+	delete(mirror.files, "/cli/2.7.5/"+Params.SCAResolverFileName+signatureExtension)
+	_, err = installSCAResolver("2.7.5")
+	assert.ErrorContains(t, err, "could not be downloaded")
+	var downloadErr *downloadError
+	assert.Assert(t, !errors.As(err, &downloadErr), "a missing signature must not fall back to the built-in resolver")
+
+	viper.Set(params.ScaResolverPublicKeyKey, "not a key")
+	_, err = installSCAResolver("2.7.5")
+	assert.ErrorContains(t, err, "Invalid "+params.ScaResolverPublicKeyEnv)
+}
+
+func TestRunScaRealtimeFallsBackToBuiltinResolver(t *testing.T) {
+	setupSCAResolverMirror(t)
+	projectDir := writeTestFiles(t, map[string]string{"pom.xml": testPom})
+	results, err := resolveProjectDependencies(scaResolverName, latestSCAResolverVersion, projectDir)
+	assert.NilError(t, err)
+	assert.Equal(t, results.DependencyResolutionResults[0].ResolvingModuleType, mavenModuleType)
+}
+
+func TestRunScaRealtimeRejectsUnverifiedSCAResolver(t *testing.T) {
+	mirror, _, privateKey, _ := setupSCAResolverMirror(t)
+	mirror.publish(t, "latest", "#!/bin/sh\necho latest\n", privateKey)
+	path := "/cli/latest/" + Params.SCAResolverFileName
+	mirror.files[path+signatureExtension] = []byte(base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize)))
+	projectDir := writeTestFiles(t, map[string]string{"pom.xml": testPom})
+	_, err := resolveProjectDependencies(scaResolverName, latestSCAResolverVersion, projectDir)
+	assert.ErrorContains(t, err, "The signature of")
+}
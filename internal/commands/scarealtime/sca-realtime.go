@@ -15,6 +15,7 @@ import (
 	"github.com/MakeNowJust/heredoc"
 	"github.com/checkmarx/ast-cli/internal/logger"
 	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/scadb"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -25,14 +26,25 @@ const scaResolverFailedStatus = "failedtoresolve"
 const scaResolverProjectName = "cx-cli-sca-realtime-project"
 const bitSize = 32
 
+// Resolvers that list the dependencies of the project
+const (
+	scaResolverName     = "sca-resolver"
+	builtinResolverName = "builtin"
+)
+
 func NewScaRealtimeCommand(scaRealTimeWrapper wrappers.ScaRealTimeWrapper) *cobra.Command {
 	scaRealtimeScanCmd := &cobra.Command{
 		Use:   "sca-realtime",
 		Short: "Create and run sca scan",
-		Long:  "The sca-realtime command enables the ability to create, run and retrieve results from a sca scan using sca resolver.",
+		Long: "The sca-realtime command enables the ability to create, run and retrieve results from a sca scan using sca resolver. " +
+			"The built-in resolver parses the manifests and lockfiles of the project instead, without downloading SCA Resolver, " +
+			"and is used when SCA Resolver cannot be downloaded.",
 		Example: heredoc.Doc(
 			`
 			$ cx scan sca-realtime --project-dir .
+			$ cx scan sca-realtime --project-dir . --resolver builtin
+			$ cx scan sca-realtime --project-dir . --offline
+			$ cx scan sca-realtime --project-dir . --sca-resolver-version 2.7.4
 		`,
 		),
 		// TODO: update documentation link
@@ -53,6 +65,42 @@ func NewScaRealtimeCommand(scaRealTimeWrapper wrappers.ScaRealTimeWrapper) *cobr
 		"Path to the project on which SCA Resolver will run",
 	)
 
+	scaRealtimeScanCmd.PersistentFlags().String(
+		commonParams.ScaRealtimeResolver,
+		scaResolverName,
+		fmt.Sprintf(
+			"Resolver of the project dependencies. Available resolvers: %s, %s. "+
+				"The %s resolver parses npm, yarn and pnpm lockfiles, go.mod and go.sum, requirements.txt, poetry.lock, "+
+				"pom.xml and NuGet packages.lock.json files without resolving them with the package managers",
+			scaResolverName,
+			builtinResolverName,
+			builtinResolverName,
+		),
+	)
+
+	scaRealtimeScanCmd.PersistentFlags().Bool(
+		commonParams.ScaRealtimeOffline,
+		false,
+		fmt.Sprintf(
+			"Match the dependencies against the local vulnerability database installed by 'cx utils sca-db sync' "+
+				"instead of the SCA API. The %s resolver is used unless --%s is set",
+			builtinResolverName,
+			commonParams.ScaRealtimeResolver,
+		),
+	)
+
+	scaRealtimeScanCmd.PersistentFlags().String(
+		commonParams.ScaResolverVersion,
+		latestSCAResolverVersion,
+		fmt.Sprintf(
+			"Version of SCA Resolver to run. Downloads come from %s and are cached in %s, by default in ~/.checkmarx/sca-resolver. "+
+				"They are verified against their published checksum, and against their signature when %s is set",
+			commonParams.ScaResolverMirrorEnv,
+			commonParams.ScaResolverCacheDirEnv,
+			commonParams.ScaResolverPublicKeyEnv,
+		),
+	)
+
 	err := scaRealtimeScanCmd.MarkPersistentFlagRequired(commonParams.ScaRealtimeProjectDir)
 	if err != nil {
 		log.Fatal(err)
@@ -70,22 +118,33 @@ func RunScaRealtime(scaRealTimeWrapper wrappers.ScaRealTimeWrapper) func(*cobra.
 			return err
 		}
 
-		fmt.Println("Running SCA Realtime...")
+		resolver, _ := cmd.Flags().GetString(commonParams.ScaRealtimeResolver)
+		if resolver != scaResolverName && resolver != builtinResolverName {
+			return errors.Errorf("Invalid resolver %s, the available resolvers are %s and %s", resolver, scaResolverName, builtinResolverName)
+		}
 
-		// Handle SCA Resolver. Checks if it already exists and if it is in the latest version
-		err = downloadSCAResolverAndHashFileIfNeeded(&Params)
-		if err != nil {
-			return err
+		vulnerabilitiesWrapper := scaRealTimeWrapper
+		offline, _ := cmd.Flags().GetBool(commonParams.ScaRealtimeOffline)
+		if offline {
+			if !cmd.Flags().Changed(commonParams.ScaRealtimeResolver) {
+				resolver = builtinResolverName
+			}
+			vulnerabilitiesWrapper, err = openOfflineDatabase()
+			if err != nil {
+				return err
+			}
 		}
 
-		// Run SCA Resolver in the provided directory
-		err = executeSCAResolver(projectDirPath)
+		fmt.Println("Running SCA Realtime...")
+
+		scaResolverVersion, _ := cmd.Flags().GetString(commonParams.ScaResolverVersion)
+		scaResolverResults, err := resolveProjectDependencies(resolver, scaResolverVersion, projectDirPath)
 		if err != nil {
 			return err
 		}
 
 		// Gets SCA vulnerabilities from SCA APIs
-		err = GetSCAVulnerabilities(scaRealTimeWrapper)
+		err = getSCAVulnerabilities(vulnerabilitiesWrapper, scaResolverResults)
 		if err != nil {
 			return err
 		}
@@ -94,8 +153,55 @@ func RunScaRealtime(scaRealTimeWrapper wrappers.ScaRealTimeWrapper) func(*cobra.
 	}
 }
 
+// openOfflineDatabase opens the vulnerability database installed by cx utils sca-db sync
+func openOfflineDatabase() (wrappers.ScaRealTimeWrapper, error) {
+	dir, err := scadb.Dir()
+	if err != nil {
+		return nil, err
+	}
+	db, err := scadb.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	logger.PrintfIfVerbose("Using version %s of the SCA vulnerability database", db.Manifest.Version)
+	return db, nil
+}
+
+// resolveProjectDependencies lists the dependencies of the project with the selected resolver. The built-in
+// resolver is used when SCA Resolver cannot be downloaded, as in air-gapped environments, a download failing
+// its verification is an error.
+func resolveProjectDependencies(resolver, scaResolverVersion, projectDirPath string) (ScaResultsFile, error) {
+	if resolver == builtinResolverName {
+		return resolveDependencies(projectDirPath)
+	}
+
+	err := createWorkingDirectory()
+	if err != nil {
+		return ScaResultsFile{}, err
+	}
+
+	// Handle SCA Resolver. Uses the cached version unless a newer one is published
+	executable, err := installSCAResolver(scaResolverVersion)
+	var downloadErr *downloadError
+	if errors.As(err, &downloadErr) {
+		logger.Printf("Failed to download SCA Resolver, falling back to the built-in resolver: %s", err)
+		return resolveDependencies(projectDirPath)
+	}
+	if err != nil {
+		return ScaResultsFile{}, err
+	}
+
+	// Run SCA Resolver in the provided directory
+	err = executeSCAResolver(executable, projectDirPath)
+	if err != nil {
+		return ScaResultsFile{}, err
+	}
+
+	return readSCAResolverResultsFromFile()
+}
+
 // executeSCAResolver Executes sca resolver for a specific path
-func executeSCAResolver(projectPath string) error {
+func executeSCAResolver(executable, projectPath string) error {
 	args := []string{
 		"offline",
 		"-s",
@@ -108,7 +214,7 @@ func executeSCAResolver(projectPath string) error {
 
 	logger.PrintIfVerbose(fmt.Sprintf("Running SCA resolver with args: %v \n", args))
 
-	out, err := exec.Command(Params.ExecutableFilePath, args...).Output()
+	out, err := exec.Command(executable, args...).Output()
 	logger.PrintIfVerbose(string(out))
 	if err != nil {
 		return err
@@ -126,6 +232,11 @@ func GetSCAVulnerabilities(scaRealTimeWrapper wrappers.ScaRealTimeWrapper) error
 		return err
 	}
 
+	return getSCAVulnerabilities(scaRealTimeWrapper, scaResolverResults)
+}
+
+// getSCAVulnerabilities Call SCA API to get vulnerabilities of resolved dependencies
+func getSCAVulnerabilities(scaRealTimeWrapper wrappers.ScaRealTimeWrapper, scaResolverResults ScaResultsFile) error {
 	var modelResults []wrappers.ScaVulnerabilitiesResponseModel
 	var scaRealtimeScanErrors []wrappers.ScaRealtimeScanError
 
@@ -203,7 +314,7 @@ func GetSCAVulnerabilities(scaRealTimeWrapper wrappers.ScaRealTimeWrapper) error
 	}
 
 	// Convert SCA Results to Scan Results to make it easier to display it in IDEs
-	err = convertToScanResults(modelResults, scaRealtimeScanErrors)
+	err := convertToScanResults(modelResults, scaRealtimeScanErrors)
 	if err != nil {
 		return err
 	}
@@ -1,12 +1,19 @@
 package scarealtime
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
 	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
+	"github.com/checkmarx/ast-cli/internal/wrappers/scadb"
+	"github.com/spf13/viper"
 	"gotest.tools/assert"
 )
 
@@ -16,6 +23,8 @@ var (
 )
 
 func TestRunScaRealtime(t *testing.T) {
+	viper.Set(params.ScaResolverCacheDirKey, t.TempDir())
+	t.Cleanup(func() { viper.Set(params.ScaResolverCacheDirKey, "") })
 	args := []string{"scan", "sca-realtime", "--project-dir", projectDirectory}
 	cmd := NewScaRealtimeCommand(mock.ScaRealTimeHTTPMockWrapper{})
 	cmd.SetArgs(args)
@@ -57,3 +66,48 @@ func TestRequiredProjectDir(t *testing.T) {
 	err := cmd.Execute()
 	assert.Error(t, err, "Provided path does not exist: "+invalidProjectPath, err.Error())
 }
+
+func TestRunScaRealtimeOffline(t *testing.T) {
+	dbDir := filepath.Join(t.TempDir(), "sca-db")
+	viper.Set(params.ScaDBDirKey, dbDir)
+	defer viper.Set(params.ScaDBDirKey, "")
+	projectDir := writeTestFiles(t, map[string]string{"pom.xml": testPom})
+	args := []string{"scan", "sca-realtime", "--project-dir", projectDir, "--offline"}
+
+	cmd := NewScaRealtimeCommand(mock.ScaRealTimeHTTPMockWrapper{})
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "cx utils sca-db sync")
+
+	advisory := scadb.Advisory{AffectedVersions: []string{"[4.7,4.13.1)"}}
+	advisory.Cve = "CVE-2020-15250"
+	snapshot, err := json.Marshal(scadb.Snapshot{Version: "2024.05.01", Packages: []scadb.Package{
+		{PackageManager: "Maven", PackageName: "junit:junit", Vulnerabilities: []scadb.Advisory{advisory}},
+	}})
+	assert.NilError(t, err)
+	digest := sha256.Sum256(snapshot)
+	manifest := &wrappers.ScaDBManifest{Version: "2024.05.01", SHA256: hex.EncodeToString(digest[:])}
+	assert.NilError(t, scadb.Install(dbDir, manifest, snapshot))
+
+	db, err := openOfflineDatabase()
+	assert.NilError(t, err)
+	results, err := resolveDependencies(projectDir)
+	assert.NilError(t, err)
+	var requests []wrappers.ScaDependencyBodyRequest
+	for _, dependency := range results.DependencyResolutionResults[0].Dependencies {
+		requests = append(requests, wrappers.ScaDependencyBodyRequest{
+			PackageName:    dependency.ID.Name,
+			Version:        dependency.ID.Version,
+			PackageManager: GetPackageManagerFromResolvingModuleType["maven"],
+		})
+	}
+	models, _, err := db.GetScaVulnerabilitiesPackages(requests)
+	assert.NilError(t, err)
+	assert.Equal(t, models[1].PackageName, "junit:junit")
+	assert.Equal(t, models[1].Vulnerabilities[0].Cve, "CVE-2020-15250")
+
+	cmd = NewScaRealtimeCommand(mock.ScaRealTimeHTTPMockWrapper{})
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.NilError(t, err)
+}
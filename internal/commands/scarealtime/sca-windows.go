@@ -14,18 +14,14 @@ import (
 )
 
 var Params = ScaRealTime{
-	ExecutableFilePath:         filepath.Join(ScaResolverWorkingDir, "ScaResolver.exe"),
-	HashFilePath:               filepath.Join(ScaResolverWorkingDir, "ScaResolver.zip.sha256sum"),
-	SCAResolverDownloadURL:     "https://sca-downloads.s3.amazonaws.com/cli/latest/ScaResolver-win64.zip",
-	SCAResolverHashDownloadURL: "https://sca-downloads.s3.amazonaws.com/cli/latest/ScaResolver-win64.zip.sha256sum",
-	SCAResolverFileName:        "ScaResolver.zip",
-	SCAResolverHashFileName:    "ScaResolver.zip.sha256sum",
+	ExecutableFileName:  "ScaResolver.exe",
+	SCAResolverFileName: "ScaResolver-win64.zip",
 }
 
-// UnzipOrExtractFiles Extracts SCA Resolver files
-func UnzipOrExtractFiles() error {
-	logger.PrintIfVerbose("Unzipping files in:  " + ScaResolverWorkingDir)
-	r, err := zip.OpenReader(filepath.Join(ScaResolverWorkingDir, Params.SCAResolverFileName))
+// UnzipOrExtractFiles Extracts SCA Resolver files of an archive in a directory
+func UnzipOrExtractFiles(archivePath, destination string) error {
+	logger.PrintIfVerbose("Unzipping files in:  " + destination)
+	r, err := zip.OpenReader(archivePath)
 	if err != nil {
 		return err
 	}
@@ -47,10 +43,10 @@ func UnzipOrExtractFiles() error {
 			}
 		}()
 
-		path := filepath.Join(ScaResolverWorkingDir, f.Name)
+		path := filepath.Join(destination, f.Name)
 
 		// Check for ZipSlip (Directory traversal)
-		if !strings.HasPrefix(path, filepath.Clean(ScaResolverWorkingDir)+string(os.PathSeparator)) {
+		if !strings.HasPrefix(path, filepath.Clean(destination)+string(os.PathSeparator)) {
 			return fmt.Errorf("illegal file path: %s", path)
 		}
 
@@ -1,20 +1,30 @@
 package util
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/MakeNowJust/heredoc"
 
 	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
 	"github.com/checkmarx/ast-cli/internal/wrappers/configuration"
+	"github.com/checkmarx/ast-cli/internal/wrappers/secrets"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 const (
-	failedSettingProp = "Failed to set property"
-	propNameFlag      = "prop-name"
-	propValFlag       = "prop-value"
+	failedSettingProp  = "Failed to set property"
+	propNameFlag       = "prop-name"
+	propValFlag        = "prop-value"
+	allProfilesFlag    = "all-profiles"
+	profileNameFlag    = "name"
+	profileFromFlag    = "from"
+	backendFlag        = "backend"
+	nonInteractiveFlag = "non-interactive"
+	testConnectionFlag = "test-connection"
+	activeProfileMark  = "*"
 )
 
 var Properties = map[string]bool{
@@ -23,11 +33,15 @@ var Properties = map[string]bool{
 	params.TenantKey:                true,
 	params.ProxyKey:                 true,
 	params.ProxyTypeKey:             true,
+	params.NoProxyKey:               true,
+	params.ProxyPacKey:              true,
+	params.ProxyHostsKey:            true,
 	params.AccessKeyIDConfigKey:     true,
 	params.AccessKeySecretConfigKey: true,
 	params.AstAPIKey:                true,
 	params.BranchKey:                true,
 	params.ClientTimeoutKey:         true,
+	params.SecretsBackendKey:        true,
 }
 
 func NewConfigCommand() *cobra.Command {
@@ -43,11 +57,11 @@ func NewConfigCommand() *cobra.Command {
 			AST Tenant []: organization
 			Do you want to use API Key authentication? (Y/N): Y
 			AST API Key []: myapikey
+
+			$ cx configure --non-interactive --base-uri https://ast.checkmarx.net/ --tenant organization --apikey <api-key> --test-connection
 		`,
 		),
-		Run: func(cmd *cobra.Command, args []string) {
-			configuration.PromptConfiguration()
-		},
+		RunE: runConfigure,
 		Annotations: map[string]string{
 			"utils:env": heredoc.Doc(
 				`
@@ -65,13 +79,18 @@ func NewConfigCommand() *cobra.Command {
 	showCmd := &cobra.Command{
 		Use:   "show",
 		Short: "Shows effective profile configuration",
-		Run: func(cmd *cobra.Command, args []string) {
-			configuration.ShowConfiguration()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			allProfiles, _ := cmd.Flags().GetBool(allProfilesFlag)
+			if allProfiles {
+				return configuration.ShowAllProfiles()
+			}
+			return configuration.ShowConfiguration()
 		},
 		Example: heredoc.Doc(
 			`
 			$ cx configure show
 			Current Effective Configuration
+                     Profile: default
                      BaseURI: 
               BaseAuthURIKey: 
                   AST Tenant: 
@@ -107,22 +126,184 @@ func NewConfigCommand() *cobra.Command {
 			),
 		},
 	}
+	configureCmd.Flags().Bool(nonInteractiveFlag, false,
+		"Take the configuration from --base-uri, --base-auth-uri, --tenant, --apikey or --client-id and --client-secret without prompting")
+	configureCmd.Flags().Bool(testConnectionFlag, false, "Request an access token and call Checkmarx One with the new configuration")
+	showCmd.PersistentFlags().Bool(allProfilesFlag, false, "Show the configuration of every profile")
+
 	setCmd.PersistentFlags().String(propNameFlag, "", "Name of property set")
 	setCmd.PersistentFlags().String(propValFlag, "", "Value of property set")
 
-	configureCmd.AddCommand(showCmd, setCmd)
+	configureCmd.AddCommand(showCmd, setCmd, newProfileCommand(), newMigrateSecretsCommand())
 	return configureCmd
 }
 
+func newProfileCommand() *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage configuration profiles",
+		Long: "Manage named configuration profiles, e.g. one per tenant. " +
+			"The profile in use is taken from --profile, then CX_PROFILE, then 'cx configure profile use'",
+		Example: heredoc.Doc(
+			`
+			$ cx configure profile create --name staging
+			$ cx configure --profile staging
+			$ cx configure profile use --name staging
+		`,
+		),
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the configuration profiles, the active profile is marked with *",
+		RunE:  runListProfiles,
+		Example: heredoc.Doc(
+			`
+			$ cx configure profile list
+			* default
+			  staging
+		`,
+		),
+	}
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an empty configuration profile",
+		RunE: runProfileAction("Created profile", func(name, _ string) error {
+			return configuration.CreateProfile(name)
+		}),
+		Example: heredoc.Doc(
+			`
+			$ cx configure profile create --name staging
+		`,
+		),
+	}
+	copyCmd := &cobra.Command{
+		Use:   "copy",
+		Short: "Create a configuration profile from a copy of another one",
+		RunE: runProfileAction("Created profile", func(name, from string) error {
+			return configuration.CopyProfile(from, name)
+		}),
+		Example: heredoc.Doc(
+			`
+			$ cx configure profile copy --from prod --name prod-eu
+		`,
+		),
+	}
+	copyCmd.PersistentFlags().String(profileFromFlag, params.Profile, "Name of the profile to copy")
+	deleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a configuration profile",
+		RunE: runProfileAction("Deleted profile", func(name, _ string) error {
+			return configuration.DeleteProfile(name)
+		}),
+		Example: heredoc.Doc(
+			`
+			$ cx configure profile delete --name staging
+		`,
+		),
+	}
+	useCmd := &cobra.Command{
+		Use:   "use",
+		Short: "Set the profile used when neither --profile nor CX_PROFILE is given",
+		RunE: runProfileAction("Using profile", func(name, _ string) error {
+			return configuration.UseProfile(name)
+		}),
+		Example: heredoc.Doc(
+			`
+			$ cx configure profile use --name staging
+		`,
+		),
+	}
+
+	for _, cmd := range []*cobra.Command{createCmd, copyCmd, deleteCmd, useCmd} {
+		cmd.PersistentFlags().String(profileNameFlag, "", "Name of the profile")
+		_ = cmd.MarkPersistentFlagRequired(profileNameFlag)
+	}
+
+	profileCmd.AddCommand(listCmd, createCmd, copyCmd, deleteCmd, useCmd)
+	return profileCmd
+}
+
+func runConfigure(cmd *cobra.Command, _ []string) error {
+	nonInteractive, _ := cmd.Flags().GetBool(nonInteractiveFlag)
+	testConnection, _ := cmd.Flags().GetBool(testConnectionFlag)
+	var check func() error
+	if testConnection {
+		check = wrappers.CheckConnectivity
+	}
+	if !nonInteractive {
+		return configuration.PromptConfiguration(check)
+	}
+	if err := configuration.ConfigureNonInteractive(check); err != nil {
+		return err
+	}
+	if check != nil {
+		fmt.Fprintln(cmd.OutOrStdout(), "Connection test succeeded")
+	}
+	return nil
+}
+
+func newMigrateSecretsCommand() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate-secrets",
+		Short: "Move the credentials of the active profile to another secret backend",
+		Long: fmt.Sprintf("Move the API key and client secret of the active profile to a secret backend (%s) "+
+			"and use it for credentials configured later on. The configuration keeps a reference such as "+
+			"keyring://cx/default/apikey instead of the secret. The encrypted backend reads its passphrase from %s.",
+			strings.Join(secrets.Backends, ", "), params.SecretsPassphraseEnv),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backend, _ := cmd.Flags().GetString(backendFlag)
+			return configuration.MigrateSecrets(backend)
+		},
+		Example: heredoc.Doc(
+			`
+			$ cx configure migrate-secrets --backend keyring
+			Migrated [ cx_apikey ] to [ keyring://cx/default/apikey ]
+		`,
+		),
+	}
+	migrateCmd.PersistentFlags().String(backendFlag, secrets.KeyringBackend, "Secret backend to move the credentials to")
+	return migrateCmd
+}
+
+func runListProfiles(cmd *cobra.Command, _ []string) error {
+	profiles, err := configuration.ListProfiles()
+	if err != nil {
+		return err
+	}
+	active, err := configuration.ActiveProfile()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		marker := " "
+		if profile == active {
+			marker = activeProfileMark
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", marker, profile)
+	}
+	return nil
+}
+
+func runProfileAction(message string, action func(name, from string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString(profileNameFlag)
+		from, _ := cmd.Flags().GetString(profileFromFlag)
+		if err := action(name, from); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s [ %s ]\n", message, name)
+		return nil
+	}
+}
+
 func runSetValue() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		propName, _ := cmd.Flags().GetString(propNameFlag)
 		propValue, _ := cmd.Flags().GetString(propValFlag)
 		if Properties[strings.ToLower(propName)] {
-			configuration.SetConfigProperty(propName, propValue)
-		} else {
-			return errors.Errorf("%s: unknown property or bad value", failedSettingProp)
+			return configuration.SetConfigProperty(propName, propValue)
 		}
-		return nil
+		return errors.Errorf("%s: unknown property or bad value", failedSettingProp)
 	}
 }
@@ -1,8 +1,19 @@
 package util
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/configuration"
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
 	"gotest.tools/assert"
 )
 
@@ -23,3 +34,331 @@ func TestNewConfigCommand(t *testing.T) {
 	assert.Assert(t, err != nil)
 	assert.Assert(t, err.Error() == "Failed to set property: unknown property or bad value")
 }
+
+// isolateConfigDir points the configuration directory to a temporary home, so tests never touch ~/.checkmarx
+func isolateConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+}
+
+func activeProfile(t *testing.T) string {
+	profile, err := configuration.ActiveProfile()
+	assert.NilError(t, err)
+	return profile
+}
+
+func TestConfigureProfileLifecycle(t *testing.T) {
+	isolateConfigDir(t)
+	cmd := NewConfigCommand()
+
+	err := executeTestCommand(cmd, "profile", "create", "--name", "cli-test-staging")
+	assert.NilError(t, err)
+	err = executeTestCommand(cmd, "profile", "create", "--name", "cli-test-staging")
+	assert.Error(t, err, "profile 'cli-test-staging' already exists")
+	err = executeTestCommand(cmd, "profile", "create", "--name", "bad/name")
+	assert.ErrorContains(t, err, "invalid profile name")
+
+	err = executeTestCommand(cmd, "profile", "copy", "--from", "cli-test-staging", "--name", "cli-test-staging-copy")
+	assert.NilError(t, err)
+	err = executeTestCommand(cmd, "profile", "copy", "--from", "cli-test-missing", "--name", "cli-test-other")
+	assert.Error(t, err, "profile 'cli-test-missing' does not exist")
+
+	profiles, err := configuration.ListProfiles()
+	assert.NilError(t, err)
+	assert.Equal(t, profiles[0], "default")
+	assert.Assert(t, Contains(profiles, "cli-test-staging"))
+	assert.Assert(t, Contains(profiles, "cli-test-staging-copy"))
+
+	err = executeTestCommand(cmd, "profile", "use", "--name", "cli-test-staging")
+	assert.NilError(t, err)
+	assert.Equal(t, activeProfile(t), "cli-test-staging")
+	err = executeTestCommand(cmd, "profile", "list")
+	assert.NilError(t, err)
+	err = executeTestCommand(cmd, "show", "--all-profiles")
+	assert.NilError(t, err)
+
+	err = executeTestCommand(cmd, "profile", "delete", "--name", "cli-test-staging")
+	assert.NilError(t, err)
+	assert.Equal(t, activeProfile(t), "default", "deleting the active profile should restore the default one")
+	err = executeTestCommand(cmd, "profile", "delete", "--name", "default")
+	assert.Error(t, err, "the default profile cannot be deleted")
+	err = executeTestCommand(cmd, "profile", "use", "--name", "cli-test-staging")
+	assert.Error(t, err, "profile 'cli-test-staging' does not exist")
+}
+
+func TestActiveProfileFromEnv(t *testing.T) {
+	t.Setenv(params.ProfileEnv, "tenant-a")
+	assert.Equal(t, activeProfile(t), "tenant-a")
+	assert.Equal(t, configuration.ProfileConfigName("tenant-a"), "checkmarxcli_tenant-a")
+	assert.Equal(t, configuration.ProfileConfigName("default"), "checkmarxcli")
+}
+
+func TestActiveProfileInvalidName(t *testing.T) {
+	isolateConfigDir(t)
+	t.Setenv(params.ProfileEnv, "../../x")
+	_, err := configuration.ActiveProfile()
+	assert.Error(t, err, "invalid profile name '../../x', use letters, digits, '-' and '_' only")
+	err = executeTestCommand(NewConfigCommand(), "show")
+	assert.ErrorContains(t, err, "invalid profile name '../../x'")
+
+	t.Setenv(params.ProfileEnv, "")
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"cx", "configure", "show", "--profile", "../other"}
+	_, err = configuration.ActiveProfile()
+	assert.ErrorContains(t, err, "invalid profile name '../other'")
+
+	os.Args = []string{"cx"}
+	assert.NilError(t, os.MkdirAll(configuration.ConfigDir(), 0700))
+	assert.NilError(t, os.WriteFile(filepath.Join(configuration.ConfigDir(), "active_profile"), []byte("a/b"), 0600))
+	_, err = configuration.ActiveProfile()
+	assert.ErrorContains(t, err, "invalid profile name 'a/b'")
+}
+
+func TestMaskSecretProperty(t *testing.T) {
+	assert.Equal(t, configuration.MaskSecretProperty(params.AccessKeySecretConfigKey, "my-client-secret"), "******cret")
+	assert.Equal(t, configuration.MaskSecretProperty(params.AstAPIKey, "key"), "******")
+	assert.Equal(t, configuration.MaskSecretProperty(params.BaseURIKey, "https://ast.checkmarx.net"), "https://ast.checkmarx.net")
+}
+
+func TestConfigureMigrateSecrets(t *testing.T) {
+	const profile = "cli-test-secrets"
+	isolateConfigDir(t)
+	cmd := NewConfigCommand()
+	t.Setenv(params.ProfileEnv, profile)
+	t.Setenv(params.SecretsPassphraseEnv, "test-passphrase")
+	viper.Set(params.SecretsFileKey, filepath.Join(t.TempDir(), "secrets.enc"))
+	defer func() {
+		viper.Set(params.SecretsFileKey, "")
+		viper.Set(params.SecretsBackendKey, "")
+		viper.Set(params.AstAPIKey, "")
+	}()
+	assert.NilError(t, configuration.CreateProfile(profile))
+	profilePath := filepath.Join(configuration.ConfigDir(), configuration.ProfileConfigName(profile)+".yaml")
+	assert.NilError(t, os.WriteFile(profilePath, []byte("cx_apikey: my-api-key\n"), 0600))
+
+	err := executeTestCommand(cmd, "migrate-secrets", "--backend", "encrypted")
+	assert.NilError(t, err)
+	content, err := os.ReadFile(profilePath)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), "encrypted://cx/cli-test-secrets/apikey"))
+	assert.Assert(t, !strings.Contains(string(content), "my-api-key"), "the secret must leave the profile file")
+	apiKey, err := wrappers.GetSecretProperty(params.AstAPIKey)
+	assert.NilError(t, err)
+	assert.Equal(t, apiKey, "my-api-key")
+
+	err = executeTestCommand(cmd, "migrate-secrets", "--backend", "file")
+	assert.NilError(t, err)
+	content, err = os.ReadFile(profilePath)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), "my-api-key"))
+
+	err = executeTestCommand(cmd, "migrate-secrets", "--backend", "vault")
+	assert.ErrorContains(t, err, "unknown secret backend 'vault'")
+}
+
+func resetConnectionConfiguration() {
+	for _, key := range []string{
+		params.BaseURIKey, params.BaseAuthURIKey, params.TenantKey,
+		params.AstAPIKey, params.AccessKeyIDConfigKey, params.AccessKeySecretConfigKey,
+	} {
+		viper.Set(key, "")
+	}
+}
+
+func signedTestToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-key"))
+	assert.NilError(t, err)
+	return token
+}
+
+// newFakeCheckmarxServer serves the token endpoint of the 'organization' realm, accepting the client secret
+// 'good-secret', and any authenticated API call
+func newFakeCheckmarxServer(t *testing.T, newServer func(handler http.Handler) *httptest.Server) *httptest.Server {
+	var server *httptest.Server
+	server = newServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/auth/realms/organization/protocol/openid-connect/token":
+			_ = r.ParseForm()
+			if r.PostForm.Get("client_secret") != "good-secret" && r.PostForm.Get("refresh_token") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			token := signedTestToken(t, jwt.MapClaims{"ast-base-url": server.URL})
+			_, _ = w.Write([]byte(`{"access_token":"` + token + `"}`))
+		case strings.HasPrefix(r.URL.Path, "/auth/realms/"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "):
+			_, _ = w.Write([]byte(`{"scans":[]}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func assertConnectivityFailure(t *testing.T, err error, category string) {
+	var connectivityErr *wrappers.ConnectivityError
+	assert.Assert(t, errors.As(err, &connectivityErr), "expected a connectivity error, got %v", err)
+	assert.Equal(t, connectivityErr.Category, category)
+}
+
+func TestConfigureNonInteractiveValidation(t *testing.T) {
+	cmd := NewConfigCommand()
+	defer resetConnectionConfiguration()
+
+	resetConnectionConfiguration()
+	err := executeTestCommand(cmd, "--non-interactive")
+	assert.ErrorContains(t, err, "provide --apikey or both --client-id and --client-secret")
+
+	viper.Set(params.AccessKeyIDConfigKey, "client")
+	viper.Set(params.AccessKeySecretConfigKey, "secret")
+	viper.Set(params.TenantKey, "organization")
+	err = executeTestCommand(cmd, "--non-interactive")
+	assert.ErrorContains(t, err, "provide --base-uri")
+
+	viper.Set(params.BaseURIKey, "ast.checkmarx.net")
+	err = executeTestCommand(cmd, "--non-interactive")
+	assert.ErrorContains(t, err, "invalid cx_base_uri 'ast.checkmarx.net'")
+
+	viper.Set(params.BaseURIKey, "https://ast.checkmarx.net")
+	viper.Set(params.TenantKey, "my tenant")
+	err = executeTestCommand(cmd, "--non-interactive")
+	assert.ErrorContains(t, err, "invalid tenant 'my tenant'")
+
+	viper.Set(params.TenantKey, "organization")
+	err = executeTestCommand(cmd, "--non-interactive")
+	assert.NilError(t, err)
+
+	resetConnectionConfiguration()
+	viper.Set(params.AstAPIKey, "not-a-token")
+	err = executeTestCommand(cmd, "--non-interactive")
+	assert.ErrorContains(t, err, "invalid API key")
+}
+
+func TestConfigureNonInteractiveTestConnection(t *testing.T) {
+	cmd := NewConfigCommand()
+	defer resetConnectionConfiguration()
+	server := newFakeCheckmarxServer(t, httptest.NewServer)
+
+	resetConnectionConfiguration()
+	viper.Set(params.BaseURIKey, server.URL)
+	viper.Set(params.TenantKey, "organization")
+	viper.Set(params.AccessKeyIDConfigKey, "client")
+	viper.Set(params.AccessKeySecretConfigKey, "good-secret")
+	err := executeTestCommand(cmd, "--non-interactive", "--test-connection")
+	assert.NilError(t, err)
+
+	viper.Set(params.AccessKeySecretConfigKey, "bad-secret")
+	err = executeTestCommand(cmd, "--non-interactive", "--test-connection")
+	assertConnectivityFailure(t, err, wrappers.UnauthorizedFailure)
+
+	viper.Set(params.AccessKeySecretConfigKey, "good-secret")
+	viper.Set(params.TenantKey, "other")
+	err = executeTestCommand(cmd, "--non-interactive", "--test-connection")
+	assertConnectivityFailure(t, err, wrappers.WrongTenantFailure)
+
+	resetConnectionConfiguration()
+	viper.Set(params.TenantKey, "organization")
+	viper.Set(params.AstAPIKey, signedTestToken(t, jwt.MapClaims{"aud": server.URL + "/auth/realms/other"}))
+	err = executeTestCommand(cmd, "--non-interactive", "--test-connection")
+	assertConnectivityFailure(t, err, wrappers.WrongRealmFailure)
+
+	viper.Set(params.AstAPIKey, signedTestToken(t, jwt.MapClaims{"aud": server.URL + "/auth/realms/organization"}))
+	err = executeTestCommand(cmd, "--non-interactive", "--test-connection")
+	assert.NilError(t, err)
+}
+
+func TestConfigureNonInteractiveTransportFailures(t *testing.T) {
+	cmd := NewConfigCommand()
+	defer resetConnectionConfiguration()
+	server := newFakeCheckmarxServer(t, httptest.NewTLSServer)
+
+	resetConnectionConfiguration()
+	viper.Set(params.BaseURIKey, server.URL)
+	viper.Set(params.TenantKey, "organization")
+	viper.Set(params.AccessKeyIDConfigKey, "client")
+	viper.Set(params.AccessKeySecretConfigKey, "good-secret")
+	err := executeTestCommand(cmd, "--non-interactive", "--test-connection")
+	assertConnectivityFailure(t, err, wrappers.TLSFailure)
+
+	viper.Set(params.BaseURIKey, "https://cx-connectivity-test.invalid")
+	err = executeTestCommand(cmd, "--non-interactive", "--test-connection")
+	assertConnectivityFailure(t, err, wrappers.DNSFailure)
+}
+
+func TestConfigureInteractiveRetriesInvalidValues(t *testing.T) {
+	defer resetConnectionConfiguration()
+	resetConnectionConfiguration()
+	stdin, input, err := os.Pipe()
+	assert.NilError(t, err)
+	previousStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() {
+		os.Stdin = previousStdin
+	}()
+	_, err = input.WriteString("ast.checkmarx.net\nhttps://ast.checkmarx.net\n\nbad tenant\norganization\nY\n\n")
+	assert.NilError(t, err)
+	assert.NilError(t, input.Close())
+
+	err = executeTestCommand(NewConfigCommand())
+	assert.NilError(t, err)
+	assert.Equal(t, viper.GetString(params.BaseURIKey), "https://ast.checkmarx.net")
+	assert.Equal(t, viper.GetString(params.TenantKey), "organization")
+}
+
+func TestConfigureWritesOnlyAfterConnectionTest(t *testing.T) {
+	isolateConfigDir(t)
+	defer resetConnectionConfiguration()
+	server := newFakeCheckmarxServer(t, httptest.NewServer)
+	secretsFile := filepath.Join(t.TempDir(), "secrets.enc")
+	t.Setenv(params.SecretsPassphraseEnv, "test-passphrase")
+	viper.Set(params.SecretsFileKey, secretsFile)
+	viper.Set(params.SecretsBackendKey, "encrypted")
+	defer func() {
+		viper.Set(params.SecretsFileKey, "")
+		viper.Set(params.SecretsBackendKey, "")
+	}()
+
+	resetConnectionConfiguration()
+	viper.Set(params.BaseURIKey, server.URL)
+	viper.Set(params.TenantKey, "organization")
+	viper.Set(params.AccessKeyIDConfigKey, "client")
+	viper.Set(params.AccessKeySecretConfigKey, "bad-secret")
+	err := executeTestCommand(NewConfigCommand(), "--non-interactive", "--test-connection")
+	assertConnectivityFailure(t, err, wrappers.UnauthorizedFailure)
+	_, err = os.Stat(secretsFile)
+	assert.Assert(t, os.IsNotExist(err), "the secret must not be stored when the connection test fails")
+
+	resetConnectionConfiguration()
+	viper.Set(params.BaseURIKey, "https://previous.checkmarx.net")
+	viper.Set(params.TenantKey, "previous")
+	stdin, input, err := os.Pipe()
+	assert.NilError(t, err)
+	previousStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() {
+		os.Stdin = previousStdin
+	}()
+	_, err = input.WriteString(server.URL + "\n\norganization\nN\nclient\nbad-secret\nN\n")
+	assert.NilError(t, err)
+	assert.NilError(t, input.Close())
+	err = executeTestCommand(NewConfigCommand(), "--test-connection")
+	assertConnectivityFailure(t, err, wrappers.UnauthorizedFailure)
+	assert.Equal(t, viper.GetString(params.BaseURIKey), "https://previous.checkmarx.net")
+	assert.Equal(t, viper.GetString(params.TenantKey), "previous")
+	assert.Equal(t, viper.GetString(params.AccessKeySecretConfigKey), "")
+	_, err = os.Stat(secretsFile)
+	assert.Assert(t, os.IsNotExist(err), "the secret must not be stored when the connection test is declined")
+
+	viper.Set(params.BaseURIKey, server.URL)
+	viper.Set(params.TenantKey, "organization")
+	viper.Set(params.AccessKeyIDConfigKey, "client")
+	viper.Set(params.AccessKeySecretConfigKey, "good-secret")
+	err = executeTestCommand(NewConfigCommand(), "--non-interactive", "--test-connection")
+	assert.NilError(t, err)
+	_, err = os.Stat(secretsFile)
+	assert.NilError(t, err)
+}
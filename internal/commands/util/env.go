@@ -2,9 +2,14 @@ package util
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/configuration"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +22,7 @@ func NewEnvCheckCommand() *cobra.Command {
 		Example: heredoc.Doc(
 			`
 			$ cx utils env
+			$ cx utils env --explain-proxy https://github.example.com
 		`,
 		),
 		Annotations: map[string]string{
@@ -28,15 +34,36 @@ func NewEnvCheckCommand() *cobra.Command {
 		},
 		RunE: runEnvChecks(),
 	}
+	cmd.PersistentFlags().String(params.ExplainProxyFlag, "", params.ExplainProxyFlagUsage)
 	return cmd
 }
 
 func runEnvChecks() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		explainProxyURL, _ := cmd.Flags().GetString(params.ExplainProxyFlag)
+		if explainProxyURL != "" {
+			return explainProxy(explainProxyURL)
+		}
 		fmt.Printf("\nDetected Environment Variables:\n\n")
 		for param := range Properties {
-			fmt.Printf(formatString, param, os.Getenv(param))
+			fmt.Printf(formatString, param, configuration.MaskSecretProperty(param, os.Getenv(param)))
 		}
 		return nil
 	}
 }
+
+func explainProxy(rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Host == "" {
+		return errors.Errorf(invalidFlag, params.ExplainProxyFlag)
+	}
+	route, err := wrappers.ResolveProxyRoute(target)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nProxy route:\n\n")
+	fmt.Printf(formatString, "URL", target.Redacted())
+	fmt.Printf(formatString, "Route", route)
+	fmt.Printf(formatString, "Reason", route.Reason)
+	return nil
+}
@@ -16,18 +16,26 @@ const (
 	FormatJSON            = "json"
 	FormatSarif           = "sarif"
 	FormatSonar           = "sonar"
+	FormatSonarV10        = "sonar-v10"
 	FormatSummary         = "summaryHTML"
 	FormatSummaryJSON     = "summaryJSON"
 	FormatSummaryConsole  = "summaryConsole"
 	FormatList            = "list"
 	FormatTable           = "table"
 	FormatHTML            = "html"
+	FormatCSV             = "csv"
+	FormatXLSX            = "xlsx"
 	FormatPDF             = "pdf"
 	FormatMarkdown        = "md"
 	FormatSummaryMarkdown = "markdown"
 	FormatSbom            = "sbom"
 	FormatXML             = "xml"
 	FormatGL              = "gl-sast"
+	FormatGLDependency    = "gl-dependency"
+	FormatGLContainer     = "gl-container"
+	FormatJUnit           = "junit"
+	FormatTree            = "tree"
+	FormatDot             = "dot"
 )
 
 func Print(w io.Writer, view interface{}, format string) error {
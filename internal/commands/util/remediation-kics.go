@@ -0,0 +1,518 @@
+package util
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
+	"github.com/checkmarx/ast-cli/internal/wrappers/remediation"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Status of a kics remediation in the report
+const (
+	RemediationProposed    = "proposed"
+	RemediationApplied     = "applied"
+	RemediationSkipped     = "skipped"
+	RemediationUnavailable = "unavailable"
+)
+
+const (
+	remediationPromptAnswers = "Apply this remediation? [y]es, [n]o, [a]ll remaining, [q]uit: "
+	patchPathPrefixFrom      = "a/"
+	patchPathPrefixTo        = "b/"
+	kicsPreviewAllCopy       = "all"
+	// kicsScanMountDirectory is where scans run by the cli mount the sources, results refer to files below it
+	kicsScanMountDirectory = "path/"
+	kicsFileOutsideFolder  = "File %s of the results is outside of the kics files folder %s"
+)
+
+// KicsRemediation is a remediation of a kics result, identified by the similarity id of the result
+type KicsRemediation struct {
+	SimilarityID    string `json:"similarity_id"`
+	QueryName       string `json:"query_name"`
+	Severity        string `json:"severity"`
+	File            string `json:"file"`
+	Line            uint   `json:"line"`
+	RemediationType string `json:"remediation_type"`
+	Status          string `json:"status"`
+	Patch           string `json:"patch,omitempty"`
+}
+
+// KicsRemediationReport records the remediations proposed or applied by 'utils remediation kics'
+type KicsRemediationReport struct {
+	ResultsFile  string                          `json:"results_file"`
+	KicsFiles    string                          `json:"kics_files"`
+	DryRun       bool                            `json:"dry_run"`
+	Summary      wrappers.KicsRemediationSummary `json:"summary"`
+	Remediations []*KicsRemediation              `json:"remediations"`
+	// Patch is the diff of all the files changed by the remediations
+	Patch string `json:"patch"`
+}
+
+// runKicsRemediationPreview computes the patch of each remediation on copies of the kics files, then either
+// prints the patches (--dry-run), or applies the remediations confirmed by the user (--interactive) or all of them
+func runKicsRemediationPreview(cmd *cobra.Command, runtime container.Runtime, resultsDir string) error {
+	kicsResultsPath, _ := cmd.Flags().GetString(commonParams.KicsRemediationFile)
+	kicsFilesPath, _ := cmd.Flags().GetString(commonParams.KicsProjectFile)
+	dryRun, _ := cmd.Flags().GetBool(commonParams.RemediationDryRun)
+	interactive, _ := cmd.Flags().GetBool(commonParams.RemediationInteractive)
+	patchFile, _ := cmd.Flags().GetString(commonParams.RemediationPatchFile)
+	reportFile, _ := cmd.Flags().GetString(commonParams.RemediationReportFile)
+
+	model, err := readKicsResults(kicsResultsPath)
+	if err != nil {
+		return err
+	}
+	remediations, files, err := loadKicsRemediations(model, kicsFilesPath, kicsSimilarityFilter)
+	if err != nil {
+		return err
+	}
+	originals, err := readKicsFiles(kicsFilesPath, files)
+	if err != nil {
+		return err
+	}
+	// a single kics run computes the patch of each remediation on its own copy and of all of them together
+	copies := map[string][]string{kicsPreviewAllCopy: nil}
+	for i, kicsRemediation := range remediations {
+		copies[strconv.Itoa(i)] = []string{kicsRemediation.SimilarityID}
+		copies[kicsPreviewAllCopy] = append(copies[kicsPreviewAllCopy], kicsRemediation.SimilarityID)
+	}
+	previews, err := previewKicsRemediations(cmd, runtime, resultsDir, model, kicsFilesPath, originals, copies)
+	if err != nil {
+		return err
+	}
+	available := 0
+	for i, kicsRemediation := range remediations {
+		kicsRemediation.Patch = previews[strconv.Itoa(i)].patch
+		if kicsRemediation.Patch == "" {
+			kicsRemediation.Status = RemediationUnavailable
+		} else {
+			available++
+		}
+	}
+	if interactive {
+		err = selectKicsRemediations(cmd.InOrStdin(), cmd.OutOrStdout(), remediations)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, kicsRemediation := range remediations {
+			if kicsRemediation.Status == "" {
+				kicsRemediation.Status = RemediationApplied
+			}
+		}
+	}
+	selected := selectedSimilarityIDs(remediations)
+	report := &KicsRemediationReport{
+		ResultsFile:  kicsResultsPath,
+		KicsFiles:    kicsFilesPath,
+		DryRun:       dryRun,
+		Remediations: remediations,
+		Summary:      wrappers.KicsRemediationSummary{AvailableRemediation: len(remediations)},
+	}
+	if len(selected) > 0 {
+		// unavailable remediations change nothing, the copy with all of them holds the result unless some were skipped
+		selectedPreview := previews[kicsPreviewAllCopy]
+		if len(selected) < available {
+			previews, err = previewKicsRemediations(
+				cmd, runtime, resultsDir, model, kicsFilesPath, originals, map[string][]string{kicsPreviewAllCopy: selected},
+			)
+			if err != nil {
+				return err
+			}
+			selectedPreview = previews[kicsPreviewAllCopy]
+		}
+		report.Patch = selectedPreview.patch
+		if !dryRun {
+			err = writeKicsFiles(kicsFilesPath, originals, selectedPreview.contents)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, kicsRemediation := range remediations {
+		if dryRun && kicsRemediation.Status == RemediationApplied {
+			kicsRemediation.Status = RemediationProposed
+		}
+		if kicsRemediation.Status == RemediationApplied {
+			report.Summary.AppliedRemediation++
+		}
+	}
+	if dryRun {
+		err = writeRemediationPatch(cmd.OutOrStdout(), patchFile, report.Patch)
+		if err != nil {
+			return err
+		}
+	} else {
+		summary, _ := json.Marshal(report.Summary)
+		fmt.Fprintln(cmd.OutOrStdout(), string(summary))
+	}
+	if reportFile != "" {
+		return writeKicsRemediationReport(reportFile, report)
+	}
+	return nil
+}
+
+func readKicsResults(resultsPath string) (*wrappers.KicsResultsCollection, error) {
+	content, err := os.ReadFile(resultsPath)
+	if err != nil {
+		return nil, err
+	}
+	model := wrappers.KicsResultsCollection{}
+	err = json.Unmarshal(content, &model)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse kics results file %s", resultsPath)
+	}
+	return &model, nil
+}
+
+// loadKicsRemediations returns the remediable results, once per similarity id, and the files they refer to,
+// relative to the kics files folder
+func loadKicsRemediations(
+	model *wrappers.KicsResultsCollection,
+	kicsFilesPath string,
+	similarityIDs []string,
+) (remediations []*KicsRemediation, files []string, err error) {
+	seenIDs := map[string]bool{}
+	seenFiles := map[string]bool{}
+	for _, query := range model.Results {
+		for _, location := range query.Locations {
+			file, err := kicsRelativePath(kicsFilesPath, location.Filename)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !seenFiles[file] {
+				seenFiles[file] = true
+				files = append(files, file)
+			}
+			if location.RemediationType == "" || seenIDs[location.SimilarityID] {
+				continue
+			}
+			if len(similarityIDs) > 0 && !containsExactly(similarityIDs, location.SimilarityID) {
+				continue
+			}
+			seenIDs[location.SimilarityID] = true
+			remediations = append(remediations, &KicsRemediation{
+				SimilarityID:    location.SimilarityID,
+				QueryName:       query.QueryName,
+				Severity:        query.Severity,
+				File:            file,
+				Line:            location.Line,
+				RemediationType: location.RemediationType,
+			})
+		}
+	}
+	return remediations, files, nil
+}
+
+// kicsRelativePath returns the path, with forward slashes, of a file of the results relative to the kics files
+// folder. Relative paths are relative to the folder and absolute paths are resolved against it. Paths outside the
+// folder are read as files of scans run by the cli, under the directory they mount, like ../../path/main.tf, and
+// other paths outside the folder fall back to their file name, as results of scans mounting another directory
+// refer to files by their container path.
+func kicsRelativePath(kicsFilesPath, filename string) (string, error) {
+	root, err := filepath.Abs(kicsFilesPath)
+	if err != nil {
+		return "", err
+	}
+	relative := path.Clean(filepath.ToSlash(filename))
+	mounted := strings.TrimLeft(relative, "/")
+	for strings.HasPrefix(mounted, "../") {
+		mounted = strings.TrimPrefix(mounted, "../")
+	}
+	if filepath.IsAbs(filename) {
+		if relative, err = filepath.Rel(root, filename); err != nil {
+			relative = ".."
+		}
+	}
+	if isOutsideKicsFolder(relative) {
+		if strings.HasPrefix(mounted, kicsScanMountDirectory) {
+			relative = strings.TrimPrefix(mounted, kicsScanMountDirectory)
+		} else {
+			relative = path.Base(mounted)
+		}
+	}
+	relative, err = filepath.Rel(root, filepath.Join(root, filepath.FromSlash(relative)))
+	if err != nil || relative == "." || isOutsideKicsFolder(relative) {
+		return "", errors.Errorf(kicsFileOutsideFolder, filename, kicsFilesPath)
+	}
+	return filepath.ToSlash(relative), nil
+}
+
+func isOutsideKicsFolder(relative string) bool {
+	relative = filepath.ToSlash(relative)
+	return relative == ".." || strings.HasPrefix(relative, "../")
+}
+
+func containsExactly(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// kicsPreview is the result of remediations on a copy of the kics files
+type kicsPreview struct {
+	patch    string
+	contents map[string]string
+}
+
+// previewKicsRemediations runs kics once over copies of the kics files, each copy named after its key in copies
+// and remediated with the similarity ids of its value, and returns the preview of each copy
+func previewKicsRemediations(
+	cmd *cobra.Command,
+	runtime container.Runtime,
+	resultsDir string,
+	model *wrappers.KicsResultsCollection,
+	kicsFilesPath string,
+	originals map[string]string,
+	copies map[string][]string,
+) (map[string]*kicsPreview, error) {
+	workDir, err := os.MkdirTemp("", "kics-remediation")
+	if err != nil {
+		return nil, errors.New(directoryError)
+	}
+	defer func() {
+		_ = os.RemoveAll(workDir)
+	}()
+	var similarityIDs []string
+	for name, ids := range copies {
+		for file, content := range originals {
+			copyPath := filepath.Join(workDir, name, filepath.FromSlash(file))
+			if err = os.MkdirAll(filepath.Dir(copyPath), os.ModePerm); err != nil {
+				return nil, errors.New(containerWriteFolderError)
+			}
+			if err = os.WriteFile(copyPath, []byte(content), permission); err != nil {
+				return nil, errors.New(containerWriteFolderError)
+			}
+		}
+		similarityIDs = append(similarityIDs, ids...)
+	}
+	err = writeKicsPreviewResults(cmd, resultsDir, model, kicsFilesPath, originals, copies)
+	if err != nil {
+		return nil, err
+	}
+	_, err = runKicsRemediate(cmd, runtime, resultsDir, workDir, similarityIDs)
+	if err != nil {
+		return nil, err
+	}
+	previews := map[string]*kicsPreview{}
+	for name := range copies {
+		contents, err := readKicsFiles(filepath.Join(workDir, name), sortedKeys(originals))
+		if err != nil {
+			return nil, err
+		}
+		previews[name] = &kicsPreview{patch: diffKicsFiles(sortedKeys(originals), originals, contents), contents: contents}
+	}
+	return previews, nil
+}
+
+// writeKicsPreviewResults replaces the results file given to kics with one where each copy holds the locations
+// of its similarity ids
+func writeKicsPreviewResults(
+	cmd *cobra.Command,
+	resultsDir string,
+	model *wrappers.KicsResultsCollection,
+	kicsFilesPath string,
+	originals map[string]string,
+	copies map[string][]string,
+) error {
+	preview := *model
+	preview.Results = nil
+	for _, query := range model.Results {
+		previewQuery := query
+		previewQuery.Locations = nil
+		for _, location := range query.Locations {
+			file, err := kicsRelativePath(kicsFilesPath, location.Filename)
+			if err != nil {
+				return err
+			}
+			if _, found := originals[file]; !found {
+				continue
+			}
+			for name, ids := range copies {
+				if containsExactly(ids, location.SimilarityID) {
+					copyLocation := location
+					copyLocation.Filename = filesContainerLocation + name + "/" + file
+					previewQuery.Locations = append(previewQuery.Locations, copyLocation)
+				}
+			}
+		}
+		if len(previewQuery.Locations) > 0 {
+			preview.Results = append(preview.Results, previewQuery)
+		}
+	}
+	content, err := json.Marshal(preview)
+	if err != nil {
+		return err
+	}
+	kicsResultsPath, _ := cmd.Flags().GetString(commonParams.KicsRemediationFile)
+	err = os.WriteFile(filepath.Join(resultsDir, filepath.Base(kicsResultsPath)), content, permission)
+	if err != nil {
+		return errors.New(containerWriteFolderError)
+	}
+	return nil
+}
+
+// readKicsFiles reads the files of the kics files folder, files that do not exist are ignored like kics does
+func readKicsFiles(dir string, files []string) (map[string]string, error) {
+	contents := map[string]string{}
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if os.IsNotExist(err) {
+			logger.PrintIfVerbose(fmt.Sprintf("File %s of the results was not found in %s", file, dir))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		contents[file] = string(content)
+	}
+	return contents, nil
+}
+
+// writeKicsFiles writes the remediated files that changed back to the kics files folder
+func writeKicsFiles(dir string, originals, contents map[string]string) error {
+	for _, file := range sortedKeys(contents) {
+		if contents[file] == originals[file] {
+			continue
+		}
+		err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(file)), []byte(contents[file]), permission)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to write remediated file %s", file)
+		}
+	}
+	return nil
+}
+
+func diffKicsFiles(files []string, originals, contents map[string]string) string {
+	var patch strings.Builder
+	for _, file := range files {
+		original, found := originals[file]
+		if !found {
+			continue
+		}
+		patch.WriteString(remediation.UnifiedDiff(patchPathPrefixFrom+file, patchPathPrefixTo+file, original, contents[file]))
+	}
+	return patch.String()
+}
+
+// selectKicsRemediations walks the remediations with a patch and asks the user to accept or skip each of them
+func selectKicsRemediations(in io.Reader, out io.Writer, remediations []*KicsRemediation) error {
+	reader := bufio.NewReader(in)
+	acceptAll := false
+	quit := false
+	for i, kicsRemediation := range remediations {
+		if kicsRemediation.Status == RemediationUnavailable {
+			continue
+		}
+		if quit {
+			kicsRemediation.Status = RemediationSkipped
+			continue
+		}
+		if acceptAll {
+			kicsRemediation.Status = RemediationApplied
+			continue
+		}
+		fmt.Fprintf(
+			out,
+			"\n[%d/%d] %s %s - %s:%d (%s)\n%s",
+			i+1,
+			len(remediations),
+			kicsRemediation.Severity,
+			kicsRemediation.QueryName,
+			kicsRemediation.File,
+			kicsRemediation.Line,
+			kicsRemediation.SimilarityID,
+			kicsRemediation.Patch,
+		)
+		answer, err := promptRemediation(reader, out)
+		if err != nil {
+			return err
+		}
+		switch answer {
+		case "y", "yes":
+			kicsRemediation.Status = RemediationApplied
+		case "a", "all":
+			kicsRemediation.Status = RemediationApplied
+			acceptAll = true
+		case "q", "quit":
+			kicsRemediation.Status = RemediationSkipped
+			quit = true
+		default:
+			kicsRemediation.Status = RemediationSkipped
+		}
+	}
+	return nil
+}
+
+// promptRemediation reads an answer, the end of the input quits
+func promptRemediation(reader *bufio.Reader, out io.Writer) (string, error) {
+	for {
+		fmt.Fprint(out, remediationPromptAnswers)
+		line, err := reader.ReadString('\n')
+		if err == io.EOF && line == "" {
+			fmt.Fprintln(out)
+			return "q", nil
+		}
+		if err != nil && err != io.EOF {
+			return "", errors.Wrap(err, "Failed to read the answer")
+		}
+		switch answer := strings.ToLower(strings.TrimSpace(line)); answer {
+		case "y", "yes", "n", "no", "a", "all", "q", "quit":
+			return answer, nil
+		}
+		if err == io.EOF {
+			return "q", nil
+		}
+	}
+}
+
+func selectedSimilarityIDs(remediations []*KicsRemediation) []string {
+	var ids []string
+	for _, kicsRemediation := range remediations {
+		if kicsRemediation.Status == RemediationApplied {
+			ids = append(ids, kicsRemediation.SimilarityID)
+		}
+	}
+	return ids
+}
+
+func writeRemediationPatch(out io.Writer, patchFile, patch string) error {
+	if patchFile == "" {
+		_, err := fmt.Fprint(out, patch)
+		return err
+	}
+	err := os.WriteFile(patchFile, []byte(patch), permission)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to write patch file %s", patchFile)
+	}
+	logger.PrintIfVerbose(fmt.Sprintf("Patch written to %s", patchFile))
+	return nil
+}
+
+func writeKicsRemediationReport(reportFile string, report *KicsRemediationReport) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(reportFile, content, permission)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to write report file %s", reportFile)
+	}
+	return nil
+}
@@ -0,0 +1,675 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/remediation"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Status of a package in the SCA remediation report
+const (
+	ScaRemediationUpgraded      = "upgraded"
+	ScaRemediationProposed      = "proposed"
+	ScaRemediationNotDeclared   = "not declared"
+	ScaRemediationUpToDate      = "up to date"
+	ScaRemediationFailed        = "failed"
+	ScaRemediationNotRemediable = "not remediable"
+	notExploitableState         = "NOT_EXPLOITABLE"
+	failedListingScaResults     = "Failed listing the SCA results of the scan"
+	packageIdentifierSeparator  = "-"
+	npmPackageManager           = "npm"
+	transitiveStrategyAuto      = "auto"
+	transitiveStrategyUpgrade   = "upgrade"
+	transitiveStrategyOverride  = "override"
+	// the number of dependency paths grows quickly with the size of the graph, a few of them explain why a
+	// package is installed
+	maxDisplayedDependencyPaths = 50
+)
+
+// directories that hold dependencies or build outputs rather than manifests of the project
+var skippedManifestDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"build":        true,
+	"dist":         true,
+	"bin":          true,
+	"obj":          true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+}
+
+// ScaRemediationView is a line of the SCA remediation report
+type ScaRemediationView struct {
+	Package         string `format:"name:Package" json:"package"`
+	PackageManager  string `format:"name:Package manager" json:"packageManager"`
+	CurrentVersion  string `format:"name:Current version" json:"currentVersion"`
+	Version         string `format:"name:Version" json:"version"`
+	Vulnerabilities string `format:"name:Vulnerabilities" json:"vulnerabilities"`
+	Manifests       string `format:"name:Manifests" json:"manifests"`
+	Status          string `format:"name:Status" json:"status"`
+	Remediation     string `format:"name:Remediation;omitempty" json:"remediation,omitempty"`
+	DependencyPaths string `format:"name:Dependency paths;omitempty" json:"dependencyPaths,omitempty"`
+	Message         string `format:"name:Message;omitempty" json:"message,omitempty"`
+}
+
+// scaUpgrade is the upgrade of a vulnerable package that fixes all its selected vulnerabilities
+type scaUpgrade struct {
+	packageManager  string
+	name            string
+	currentVersion  string
+	version         string
+	vulnerabilities []string
+	manifests       []string
+	// transitive is set when no result reports the package as a direct dependency
+	transitive bool
+	// fixes tells how transitive packages are remediated, by upgrading a direct dependency or by an override
+	fixes []string
+	// paths are the dependency paths that install a vulnerable version of a package
+	paths []string
+	// locked is set when a lockfile installs the package
+	locked bool
+	// unfixed lists the vulnerabilities without a recommended version
+	unfixed []string
+	errors  []string
+	// notRemediable is set for transitive dependencies of package managers whose lockfiles are not remediated
+	notRemediable bool
+}
+
+// scaRemediator applies the upgrades to the package manager files of a project, keeping their original content
+// for the patch of a dry run
+type scaRemediator struct {
+	projectDir         string
+	transitiveStrategy string
+	npmVersions        remediation.NpmVersions
+	originals          map[string]string
+	contents           map[string]string
+	lockfiles          map[string]*remediation.Lockfile
+}
+
+func runBulkScaRemediation(
+	cmd *cobra.Command,
+	resultsWrapper wrappers.ResultsWrapper,
+	npmRegistryWrapper wrappers.NpmRegistryWrapper,
+) error {
+	projectDir, _ := cmd.Flags().GetString(commonParams.ScaRealtimeProjectDir)
+	severities, _ := cmd.Flags().GetStringSlice(commonParams.SeverityFlag)
+	dryRun, _ := cmd.Flags().GetBool(commonParams.RemediationDryRun)
+	patchFile, _ := cmd.Flags().GetString(commonParams.RemediationPatchFile)
+	format, _ := cmd.Flags().GetString(commonParams.FormatFlag)
+	transitiveStrategy, _ := cmd.Flags().GetString(commonParams.RemediationTransitiveStrategy)
+	switch transitiveStrategy {
+	case transitiveStrategyAuto, transitiveStrategyUpgrade, transitiveStrategyOverride:
+	default:
+		return errors.Errorf(
+			"Invalid value for --%s: %s, the valid values are %s, %s and %s",
+			commonParams.RemediationTransitiveStrategy,
+			transitiveStrategy,
+			transitiveStrategyAuto,
+			transitiveStrategyUpgrade,
+			transitiveStrategyOverride,
+		)
+	}
+
+	results, err := loadScaRemediationResults(cmd, resultsWrapper)
+	if err != nil {
+		return err
+	}
+	upgrades := buildScaUpgrades(results, severities)
+	manifests, err := findManifests(projectDir)
+	if err != nil {
+		return err
+	}
+	remediator := &scaRemediator{
+		projectDir:         projectDir,
+		transitiveStrategy: transitiveStrategy,
+		npmVersions:        npmVersionsLookup(npmRegistryWrapper),
+		originals:          map[string]string{},
+		contents:           map[string]string{},
+		lockfiles:          map[string]*remediation.Lockfile{},
+	}
+	for _, upgrade := range upgrades {
+		if upgrade.version == "" || upgrade.notRemediable {
+			continue
+		}
+		for _, manifest := range manifests {
+			if !remediation.IsManifestOf(upgrade.packageManager, manifest) {
+				continue
+			}
+			err = remediator.remediate(upgrade, manifest)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if !dryRun {
+		for manifest, content := range remediator.contents {
+			err = writePackageFile(manifest, content)
+			if err != nil {
+				return errors.Wrapf(err, "Failed to write %s", manifest)
+			}
+		}
+	}
+	views := toScaRemediationViews(upgrades, projectDir, dryRun)
+	err = printer.Print(cmd.OutOrStdout(), views, format)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		// the patch would make the JSON report unreadable, it goes to stderr unless written to --patch-file
+		out := cmd.OutOrStdout()
+		if printer.IsFormat(format, printer.FormatJSON) {
+			out = cmd.ErrOrStderr()
+		}
+		err = writeRemediationPatch(out, patchFile, scaRemediationPatch(projectDir, remediator.originals, remediator.contents))
+		if err != nil {
+			return err
+		}
+	}
+	failed := 0
+	for _, view := range views {
+		if view.Status == ScaRemediationFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d package(s) could not be remediated", failed)
+	}
+	return nil
+}
+
+// loadScaRemediationResults reads the results of --results-file, or gets the results of --scan-id with their
+// package data
+func loadScaRemediationResults(cmd *cobra.Command, resultsWrapper wrappers.ResultsWrapper) (*wrappers.ScanResultsCollection, error) {
+	resultsFile, _ := cmd.Flags().GetString(commonParams.RemediationResultsFile)
+	if resultsFile != "" {
+		content, err := os.ReadFile(resultsFile)
+		if err != nil {
+			return nil, err
+		}
+		results := &wrappers.ScanResultsCollection{}
+		err = json.Unmarshal(content, results)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse results file %s", resultsFile)
+		}
+		return results, nil
+	}
+	scanID, _ := cmd.Flags().GetString(commonParams.ScanIDFlag)
+	params := map[string]string{commonParams.ScanIDQueryParam: scanID}
+	results, errorModel, err := resultsWrapper.GetAllResultsByScanID(params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", failedListingScaResults)
+	}
+	if errorModel != nil {
+		return nil, errors.Errorf("%s: CODE: %d, %s", failedListingScaResults, errorModel.Code, errorModel.Message)
+	}
+	packages, errorModel, err := resultsWrapper.GetAllResultsPackageByScanID(params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", failedListingScaResults)
+	}
+	if errorModel != nil {
+		return nil, errors.Errorf("%s: CODE: %d, %s", failedListingScaResults, errorModel.Code, errorModel.Message)
+	}
+	if packages != nil {
+		packagesByID := map[string]*wrappers.ScaPackageCollection{}
+		for i := range *packages {
+			packagesByID[(*packages)[i].ID] = &(*packages)[i]
+		}
+		for _, result := range results.Results {
+			if result.ScanResultData.ScaPackageCollection == nil {
+				result.ScanResultData.ScaPackageCollection = packagesByID[result.ScanResultData.PackageIdentifier]
+			}
+		}
+	}
+	return results, nil
+}
+
+// buildScaUpgrades groups the vulnerable direct dependencies, and the vulnerable transitive npm packages, by package.
+// The upgrade of a package is the highest recommended version of its vulnerabilities.
+func buildScaUpgrades(results *wrappers.ScanResultsCollection, severities []string) []*scaUpgrade {
+	upgradesByPackage := map[string]*scaUpgrade{}
+	// transitive dependencies of other package managers than npm are reported without being remediated
+	notRemediable := map[string]*scaUpgrade{}
+	for _, result := range results.Results {
+		if result.Type != commonParams.ScaType || strings.EqualFold(result.State, notExploitableState) {
+			continue
+		}
+		if len(severities) > 0 && !containsFold(severities, result.Severity) {
+			continue
+		}
+		packageManager, name, currentVersion := parseScaPackage(result)
+		if name == "" {
+			continue
+		}
+		packageData := result.ScanResultData.ScaPackageCollection
+		transitive := packageData != nil && !packageData.IsDirectDependency
+		remediable := !transitive || strings.EqualFold(packageManager, npmPackageManager)
+		upgrades := upgradesByPackage
+		if !remediable {
+			logger.PrintIfVerbose("Not remediating transitive dependency " + result.ScanResultData.PackageIdentifier)
+			upgrades = notRemediable
+		}
+		key := packageManager + ":" + name
+		upgrade, found := upgrades[key]
+		if !found {
+			upgrade = &scaUpgrade{
+				packageManager: packageManager, name: name, currentVersion: currentVersion, transitive: true, notRemediable: !remediable,
+			}
+			upgrades[key] = upgrade
+		}
+		upgrade.transitive = upgrade.transitive && transitive
+		upgrade.vulnerabilities = append(upgrade.vulnerabilities, result.ID)
+		recommended := ""
+		if result.ScanResultData.RecommendedVersion != nil {
+			recommended = fmt.Sprint(result.ScanResultData.RecommendedVersion)
+		}
+		if recommended == "" {
+			upgrade.unfixed = append(upgrade.unfixed, result.ID)
+			continue
+		}
+		if upgrade.version == "" || remediation.CompareVersions(recommended, upgrade.version) > 0 {
+			upgrade.version = recommended
+		}
+	}
+	for key, upgrade := range notRemediable {
+		if _, found := upgradesByPackage[key]; !found {
+			upgradesByPackage[key] = upgrade
+		}
+	}
+	upgrades := make([]*scaUpgrade, 0, len(upgradesByPackage))
+	for _, upgrade := range upgradesByPackage {
+		sort.Strings(upgrade.vulnerabilities)
+		upgrades = append(upgrades, upgrade)
+	}
+	sort.Slice(upgrades, func(i, j int) bool {
+		if upgrades[i].packageManager != upgrades[j].packageManager {
+			return upgrades[i].packageManager < upgrades[j].packageManager
+		}
+		return upgrades[i].name < upgrades[j].name
+	})
+	return upgrades
+}
+
+// parseScaPackage returns the package manager, name and version of the package of a result, from its dependency
+// path when available, otherwise from its identifier Manager-name-version
+func parseScaPackage(result *wrappers.ScanResult) (packageManager, name, version string) {
+	identifier := result.ScanResultData.PackageIdentifier
+	packageManager, rest, found := strings.Cut(identifier, packageIdentifierSeparator)
+	if !found {
+		return "", "", ""
+	}
+	packageData := result.ScanResultData.ScaPackageCollection
+	if packageData != nil {
+		for _, path := range packageData.DependencyPathArray {
+			if len(path) > 0 && path[0].ID == identifier && path[0].Name != "" {
+				return packageManager, path[0].Name, path[0].Version
+			}
+		}
+	}
+	separator := strings.LastIndex(rest, packageIdentifierSeparator)
+	if separator < 0 {
+		return packageManager, rest, ""
+	}
+	return packageManager, rest[:separator], rest[separator+1:]
+}
+
+// findManifests returns the supported package manager files of the project
+func findManifests(projectDir string) ([]string, error) {
+	var manifests []string
+	err := filepath.WalkDir(projectDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != projectDir && skippedManifestDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if remediation.IsPackageFileSupported(path) {
+			manifests = append(manifests, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to list the package manager files of %s", projectDir)
+	}
+	return manifests, nil
+}
+
+// remediate upgrades the package in a manifest. The npm projects with a lockfile are remediated from the
+// dependency paths of the lockfile, the other manifests only when they declare the package.
+func (r *scaRemediator) remediate(upgrade *scaUpgrade, manifest string) error {
+	if strings.EqualFold(upgrade.packageManager, npmPackageManager) {
+		lockfile, lockfileName, err := r.npmLockfile(manifest)
+		if err != nil {
+			return err
+		}
+		if lockfile != nil {
+			return r.remediateNpmProject(upgrade, manifest, lockfileName, lockfile)
+		}
+	}
+	if upgrade.transitive {
+		return nil
+	}
+	_, err := r.upgradeManifest(upgrade, manifest, upgrade.name, upgrade.version)
+	return err
+}
+
+// content returns the current content of a manifest, with the upgrades applied so far
+func (r *scaRemediator) content(manifest string) (string, error) {
+	if content, found := r.contents[manifest]; found {
+		return content, nil
+	}
+	if original, found := r.originals[manifest]; found {
+		return original, nil
+	}
+	original, err := readPackageFile(manifest)
+	if err != nil {
+		return "", err
+	}
+	r.originals[manifest] = original
+	return original, nil
+}
+
+// upgradeManifest upgrades a package in the content of a manifest, manifests that do not declare it are left
+// unchanged
+func (r *scaRemediator) upgradeManifest(upgrade *scaUpgrade, manifest, name, version string) (upgraded bool, err error) {
+	content, err := r.content(manifest)
+	if err != nil {
+		return false, err
+	}
+	if name == upgrade.name && upgrade.currentVersion != "" && remediation.CompareVersions(version, upgrade.currentVersion) <= 0 {
+		return false, nil
+	}
+	parser, err := remediation.NewPackage(manifest, content, name, version)
+	if err != nil {
+		return false, err
+	}
+	upgradedContent, err := parser.Parser()
+	var notFound *remediation.PackageNotFoundError
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	if err != nil {
+		upgrade.errors = append(upgrade.errors, fmt.Sprintf("%s: %v", manifest, err))
+		return false, nil
+	}
+	r.contents[manifest] = upgradedContent
+	upgrade.addManifest(manifest)
+	return true, nil
+}
+
+// npmLockfile returns the lockfile next to a package.json, nil when the project has none
+func (r *scaRemediator) npmLockfile(manifest string) (*remediation.Lockfile, string, error) {
+	dir := filepath.Dir(manifest)
+	for _, name := range remediation.NpmLockfiles {
+		lockfileName := filepath.Join(dir, name)
+		if lockfile, found := r.lockfiles[lockfileName]; found {
+			return lockfile, lockfileName, nil
+		}
+		content, err := os.ReadFile(lockfileName)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		packageJSON, err := r.content(manifest)
+		if err != nil {
+			return nil, "", err
+		}
+		lockfile, err := remediation.ParseLockfile(lockfileName, string(content), packageJSON)
+		if err != nil {
+			return nil, "", err
+		}
+		r.lockfiles[lockfileName] = lockfile
+		return lockfile, lockfileName, nil
+	}
+	return nil, "", nil
+}
+
+// remediateNpmProject remediates the installed versions of a package older than its upgrade. A direct dependency
+// is upgraded in the package.json, a transitive one by upgrading the direct dependencies that install it when a
+// published version of them installs the upgrade, otherwise by overriding its version.
+func (r *scaRemediator) remediateNpmProject(upgrade *scaUpgrade, manifest, lockfileName string, lockfile *remediation.Lockfile) error {
+	var transitivePaths [][]*remediation.LockPackage
+	direct := false
+	for _, path := range lockfile.Paths(upgrade.name, "") {
+		upgrade.locked = true
+		if remediation.CompareVersions(path[len(path)-1].Version, upgrade.version) >= 0 {
+			continue
+		}
+		upgrade.paths = append(upgrade.paths, r.relative(lockfileName)+": "+remediation.FormatDependencyPath(path))
+		if len(path) == 1 {
+			direct = true
+		} else {
+			transitivePaths = append(transitivePaths, path)
+		}
+	}
+	if direct {
+		_, err := r.upgradeManifest(upgrade, manifest, upgrade.name, upgrade.version)
+		if err != nil {
+			return err
+		}
+	}
+	if len(transitivePaths) == 0 {
+		return nil
+	}
+	directUpgrades, err := r.npmDirectUpgrades(upgrade, transitivePaths)
+	if err != nil {
+		return err
+	}
+	if directUpgrades != nil {
+		for _, name := range sortedKeys(directUpgrades) {
+			upgraded, err := r.upgradeManifest(upgrade, manifest, name, directUpgrades[name])
+			if err != nil {
+				return err
+			}
+			if !upgraded {
+				upgrade.errors = append(upgrade.errors, fmt.Sprintf("%s: failed to upgrade %s", r.relative(manifest), name))
+				continue
+			}
+			upgrade.fixes = append(upgrade.fixes, fmt.Sprintf("upgrade %s to %s", name, directUpgrades[name]))
+		}
+		return nil
+	}
+	if r.transitiveStrategy == transitiveStrategyUpgrade {
+		upgrade.errors = append(upgrade.errors, fmt.Sprintf(
+			"%s: no published version of the direct dependencies installs %s %s", r.relative(manifest), upgrade.name, upgrade.version))
+		return nil
+	}
+	content, err := r.content(manifest)
+	if err != nil {
+		return err
+	}
+	overridden, err := remediation.AddNpmOverride(content, lockfile.Manager, upgrade.name, upgrade.version)
+	if err != nil {
+		upgrade.errors = append(upgrade.errors, fmt.Sprintf("%s: %v", manifest, err))
+		return nil
+	}
+	r.contents[manifest] = overridden
+	upgrade.addManifest(manifest)
+	upgrade.fixes = append(upgrade.fixes, "add "+remediation.NpmOverrideField(lockfile.Manager))
+	return nil
+}
+
+// npmDirectUpgrades returns the upgrades of the direct dependencies that fix all the paths, nil when a path cannot
+// be fixed this way
+func (r *scaRemediator) npmDirectUpgrades(upgrade *scaUpgrade, paths [][]*remediation.LockPackage) (map[string]string, error) {
+	if r.transitiveStrategy == transitiveStrategyOverride {
+		return nil, nil
+	}
+	pathsByDirect := map[string][][]*remediation.LockPackage{}
+	for _, path := range paths {
+		pathsByDirect[path[0].Name] = append(pathsByDirect[path[0].Name], path)
+	}
+	directUpgrades := map[string]string{}
+	for name, directPaths := range pathsByDirect {
+		version, err := remediation.FindNpmDirectUpgrade(directPaths, upgrade.version, r.npmVersions)
+		if err != nil {
+			logger.PrintIfVerbose(fmt.Sprintf("Failed to find an upgrade of %s that fixes %s: %v", name, upgrade.name, err))
+			return nil, nil
+		}
+		if version == "" {
+			return nil, nil
+		}
+		directUpgrades[name] = version
+	}
+	return directUpgrades, nil
+}
+
+func (r *scaRemediator) relative(path string) string {
+	relative, err := filepath.Rel(r.projectDir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(relative)
+}
+
+func (u *scaUpgrade) addManifest(manifest string) {
+	for _, m := range u.manifests {
+		if m == manifest {
+			return
+		}
+	}
+	u.manifests = append(u.manifests, manifest)
+}
+
+// npmVersionsLookup returns the published versions of npm packages with their dependencies, each package is
+// requested once
+func npmVersionsLookup(npmRegistryWrapper wrappers.NpmRegistryWrapper) remediation.NpmVersions {
+	cache := map[string]map[string]map[string]string{}
+	return func(name string) (map[string]map[string]string, error) {
+		if versions, found := cache[name]; found {
+			return versions, nil
+		}
+		packument, err := npmRegistryWrapper.GetPackument(name)
+		if err != nil {
+			return nil, err
+		}
+		versions := map[string]map[string]string{}
+		for version, metadata := range packument.Versions {
+			dependencies := map[string]string{}
+			for dependency, versionRange := range metadata.OptionalDependencies {
+				dependencies[dependency] = versionRange
+			}
+			for dependency, versionRange := range metadata.Dependencies {
+				dependencies[dependency] = versionRange
+			}
+			versions[version] = dependencies
+		}
+		cache[name] = versions
+		return versions, nil
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toScaRemediationViews(upgrades []*scaUpgrade, projectDir string, dryRun bool) []ScaRemediationView {
+	views := make([]ScaRemediationView, 0, len(upgrades))
+	for _, upgrade := range upgrades {
+		var manifests []string
+		for _, manifest := range upgrade.manifests {
+			relative, err := filepath.Rel(projectDir, manifest)
+			if err != nil {
+				relative = manifest
+			}
+			manifests = append(manifests, filepath.ToSlash(relative))
+		}
+		view := ScaRemediationView{
+			Package:         upgrade.name,
+			PackageManager:  upgrade.packageManager,
+			CurrentVersion:  upgrade.currentVersion,
+			Version:         upgrade.version,
+			Vulnerabilities: strings.Join(upgrade.vulnerabilities, ","),
+			Manifests:       strings.Join(manifests, ","),
+			Remediation:     strings.Join(upgrade.fixes, ","),
+			DependencyPaths: formatDependencyPaths(upgrade.paths),
+		}
+		messages := upgrade.errors
+		if upgrade.notRemediable {
+			messages = append(messages, "transitive dependency, only transitive npm dependencies are remediated")
+		} else if upgrade.transitive && !upgrade.locked {
+			messages = append(messages, "transitive dependency that no lockfile of the project installs")
+		}
+		if len(upgrade.unfixed) > 0 {
+			messages = append(messages, "no recommended version for "+strings.Join(upgrade.unfixed, ","))
+		}
+		view.Message = strings.Join(messages, "; ")
+		switch {
+		case upgrade.notRemediable:
+			view.Status = ScaRemediationNotRemediable
+		case len(upgrade.errors) > 0 || upgrade.version == "":
+			view.Status = ScaRemediationFailed
+		case upgrade.currentVersion != "" && remediation.CompareVersions(upgrade.version, upgrade.currentVersion) <= 0,
+			upgrade.locked && len(upgrade.paths) == 0:
+			view.Status = ScaRemediationUpToDate
+		case len(upgrade.manifests) == 0:
+			view.Status = ScaRemediationNotDeclared
+		case dryRun:
+			view.Status = ScaRemediationProposed
+		default:
+			view.Status = ScaRemediationUpgraded
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// formatDependencyPaths joins the first dependency paths of a package for the report
+func formatDependencyPaths(paths []string) string {
+	if len(paths) <= maxDisplayedDependencyPaths {
+		return strings.Join(paths, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(paths[:maxDisplayedDependencyPaths], "; "), len(paths)-maxDisplayedDependencyPaths)
+}
+
+// scaRemediationPatch returns the diff of the manifests changed by the remediation
+func scaRemediationPatch(projectDir string, originals, contents map[string]string) string {
+	manifests := make([]string, 0, len(contents))
+	for manifest := range contents {
+		manifests = append(manifests, manifest)
+	}
+	sort.Strings(manifests)
+	var patch strings.Builder
+	for _, manifest := range manifests {
+		relative, err := filepath.Rel(projectDir, manifest)
+		if err != nil {
+			relative = manifest
+		}
+		relative = filepath.ToSlash(relative)
+		patch.WriteString(remediation.UnifiedDiff(patchPathPrefixFrom+relative, patchPathPrefixTo+relative, originals[manifest], contents[manifest]))
+	}
+	return patch.String()
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
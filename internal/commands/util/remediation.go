@@ -5,34 +5,29 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
 	"github.com/checkmarx/ast-cli/internal/logger"
 	commonParams "github.com/checkmarx/ast-cli/internal/params"
 	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
 	"github.com/checkmarx/ast-cli/internal/wrappers/remediation"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 const (
-	npmPackageFilename        = "package.json"
 	permission                = 0644
 	containerStarting         = "Starting kics container"
 	filesContainerLocation    = "/files/"
-	filesContainerVolume      = ":/files"
 	resultsContainerLocation  = "/kics/"
-	containerRemove           = "--rm"
-	containerImage            = "checkmarx/kics:latest"
-	containerNameFlag         = "--name"
+	KicsContainerImage        = "checkmarx/kics:latest"
 	remediateCommand          = "remediate"
 	resultsFlag               = "--results"
-	volumeFlag                = "-v"
-	containerRun              = "run"
 	allRemediationsApplied    = "All remediations available were applied"
 	someRemediationsApplied   = "Some remediations available were not applied or there are errors in the results file.Please check kics logs"
 	directoryError            = "Failed creating temporary directory for kics remediation command"
@@ -41,19 +36,21 @@ const (
 	kicsIncludeIdsFlag        = "--include-ids"
 	containerName             = "cli-remediate-kics"
 	separator                 = ","
-	InvalidEngineError        = "not found in $PATH"
-	InvalidEngineErrorWindows = "not found in %PATH%"
 	InvalidEngineMessage      = "Please verify if engine is installed"
 	NotRunningEngineMessage   = "Please verify if engine is running"
-	EngineNoRunningCode       = 125
+	ImagePullFailedMessage    = "Failed to pull the kics image, check the image name and the registry access"
+	KicsEngineFlagUsage       = "Name in the $PATH for the container engine to run kics: docker, podman, nerdctl or auto to use the first one installed"
+	remediationExitCode       = 70
+	remediationSummaryLines   = 3
 )
 
-var (
-	kicsSimilarityFilter []string
-	kicsErrorCodes       = []string{"70"}
-)
+var kicsSimilarityFilter []string
 
-func NewRemediationCommand() *cobra.Command {
+func NewRemediationCommand(
+	resultsWrapper wrappers.ResultsWrapper,
+	npmRegistryWrapper wrappers.NpmRegistryWrapper,
+	containerProvider container.Provider,
+) *cobra.Command {
 	remediationCmd := &cobra.Command{
 		Use:   "remediation",
 		Short: "Remediate vulnerabilities",
@@ -71,22 +68,29 @@ func NewRemediationCommand() *cobra.Command {
 			),
 		},
 	}
-	scaRemediationCmd := RemediationScaCommand()
-	kicsRemediationCmd := RemediationKicsCommand()
+	scaRemediationCmd := RemediationScaCommand(resultsWrapper, npmRegistryWrapper)
+	kicsRemediationCmd := RemediationKicsCommand(containerProvider)
 	remediationCmd.AddCommand(scaRemediationCmd, kicsRemediationCmd)
 	return remediationCmd
 }
 
-func RemediationScaCommand() *cobra.Command {
+func RemediationScaCommand(resultsWrapper wrappers.ResultsWrapper, npmRegistryWrapper wrappers.NpmRegistryWrapper) *cobra.Command {
 	scaRemediateCmd := &cobra.Command{
 		Use:   "sca",
 		Short: "Remediate sca vulnerabilities",
-		Long: `To remediate package files vulnerabilities detected by the sca engine
+		Long: `To remediate package files vulnerabilities detected by the sca engine, one package at a time or all the
+vulnerable dependencies of a scan. Vulnerable transitive npm packages are remediated from the package-lock.json,
+yarn.lock or pnpm-lock.yaml of the project, by upgrading the direct dependency that installs them or by overriding
+their version
 	`,
-		RunE: runRemediationScaCmd(),
+		RunE: runRemediationScaCmd(resultsWrapper, npmRegistryWrapper),
 		Example: heredoc.Doc(
 			`
 			$ cx utils remediation sca --package <package> --package-files <package-files> --package-version <package-version>
+			$ cx utils remediation sca --scan-id <scan-id> --project-dir <project-dir> --severity critical,high --dry-run
+			$ cx utils remediation sca --results-file cx_result.json
+			$ cx utils remediation sca --results-file cx_result.json --transitive-strategy override
+			$ cx utils remediation sca --results-file cx_result.json --dry-run --format json --patch-file fixes.patch
 		`,
 		),
 		Annotations: map[string]string{
@@ -99,7 +103,8 @@ func RemediationScaCommand() *cobra.Command {
 	scaRemediateCmd.PersistentFlags().StringSlice(
 		commonParams.RemediationFiles,
 		[]string{},
-		"Path to input package files to remediate the package version",
+		"Path to input package files to remediate the package version: package.json, pom.xml, build.gradle, build.gradle.kts, "+
+			"requirements.txt, pyproject.toml, go.mod, *.csproj, packages.config or composer.json",
 	)
 	scaRemediateCmd.PersistentFlags().String(commonParams.RemediationPackage, "", "Name of the package to be replaced")
 	scaRemediateCmd.PersistentFlags().String(
@@ -107,22 +112,66 @@ func RemediationScaCommand() *cobra.Command {
 		"",
 		"Version of the package to be replaced",
 	)
-	_ = scaRemediateCmd.MarkPersistentFlagRequired(commonParams.RemediationFiles)
-	_ = scaRemediateCmd.MarkPersistentFlagRequired(commonParams.RemediationPackage)
-	_ = scaRemediateCmd.MarkPersistentFlagRequired(commonParams.RemediationPackageVersion)
+	scaRemediateCmd.PersistentFlags().String(
+		commonParams.ScanIDFlag,
+		"",
+		"ID of the scan whose SCA results are remediated, instead of --package",
+	)
+	scaRemediateCmd.PersistentFlags().String(
+		commonParams.RemediationResultsFile,
+		"",
+		"Path to a JSON report of 'cx results show' whose SCA results are remediated, instead of --package",
+	)
+	scaRemediateCmd.PersistentFlags().String(
+		commonParams.ScaRealtimeProjectDir,
+		".",
+		"Path to the project whose package manager files are remediated with --scan-id or --results-file",
+	)
+	scaRemediateCmd.PersistentFlags().StringSlice(
+		commonParams.SeverityFlag,
+		[]string{},
+		"Severities of the SCA results to remediate with --scan-id or --results-file, all of them when not set",
+	)
+	scaRemediateCmd.PersistentFlags().Bool(
+		commonParams.RemediationDryRun,
+		false,
+		"Print the changes instead of writing the package manager files",
+	)
+	scaRemediateCmd.PersistentFlags().String(
+		commonParams.RemediationPatchFile,
+		"",
+		"Path of the file where the patch of --dry-run is written, the patch is printed when not set, to stderr with --format json",
+	)
+	scaRemediateCmd.PersistentFlags().String(
+		commonParams.RemediationTransitiveStrategy,
+		transitiveStrategyAuto,
+		fmt.Sprintf(
+			"How vulnerable transitive npm packages are remediated: %s upgrades the direct dependency that installs them "+
+				"when a published version fixes them, otherwise overrides their version; %s only upgrades; %s only overrides",
+			transitiveStrategyAuto, transitiveStrategyUpgrade, transitiveStrategyOverride,
+		),
+	)
+	scaRemediateCmd.PersistentFlags().String(
+		commonParams.FormatFlag,
+		printer.FormatTable,
+		fmt.Sprintf(commonParams.FormatFlagUsageFormat, []string{printer.FormatTable, printer.FormatJSON, printer.FormatList}),
+	)
+	scaRemediateCmd.MarkFlagsMutuallyExclusive(commonParams.ScanIDFlag, commonParams.RemediationResultsFile)
 	return scaRemediateCmd
 }
 
-func RemediationKicsCommand() *cobra.Command {
+func RemediationKicsCommand(containerProvider container.Provider) *cobra.Command {
 	kicsRemediateCmd := &cobra.Command{
 		Use:   "kics",
 		Short: "Remediate kics vulnerabilities",
 		Long: `To remediate package files vulnerabilities detected by the kics engine
 	`,
-		RunE: runRemediationKicsCmd(),
+		RunE: runRemediationKicsCmd(containerProvider),
 		Example: heredoc.Doc(
 			`
 			$ cx utils remediation kics --results-file <results-file> --kics-files <kics-files>
+			$ cx utils remediation kics --results-file <results-file> --kics-files <kics-files> --dry-run --patch-file fixes.patch
+			$ cx utils remediation kics --results-file <results-file> --kics-files <kics-files> --interactive --report-file report.json
 		`,
 		),
 		Annotations: map[string]string{
@@ -152,20 +201,63 @@ func RemediationKicsCommand() *cobra.Command {
 	)
 	kicsRemediateCmd.PersistentFlags().String(
 		commonParams.KicsRealtimeEngine,
-		"docker",
-		"Name in the $PATH for the container engine to run kics. Example:podman.",
+		container.DockerEngine,
+		KicsEngineFlagUsage,
+	)
+	kicsRemediateCmd.PersistentFlags().String(
+		commonParams.KicsRealtimeImage,
+		KicsContainerImage,
+		"Container image of kics, pin a tag to get reproducible results",
+	)
+	kicsRemediateCmd.PersistentFlags().Bool(
+		commonParams.RemediationDryRun,
+		false,
+		"Print the patch of the remediations instead of changing the files",
+	)
+	kicsRemediateCmd.PersistentFlags().String(
+		commonParams.RemediationPatchFile,
+		"",
+		"Path of the file where the patch of --dry-run is written, the patch is printed when not set",
+	)
+	kicsRemediateCmd.PersistentFlags().Bool(
+		commonParams.RemediationInteractive,
+		false,
+		"Show the patch of each remediation and ask whether to apply it",
+	)
+	kicsRemediateCmd.PersistentFlags().String(
+		commonParams.RemediationReportFile,
+		"",
+		"Path of the JSON report of the remediations and their patches",
 	)
 	_ = kicsRemediateCmd.MarkPersistentFlagRequired(commonParams.KicsRemediationFile)
 	_ = kicsRemediateCmd.MarkPersistentFlagRequired(commonParams.KicsProjectFile)
 	return kicsRemediateCmd
 }
 
-func runRemediationScaCmd() func(cmd *cobra.Command, args []string) error {
+func runRemediationScaCmd(
+	resultsWrapper wrappers.ResultsWrapper,
+	npmRegistryWrapper wrappers.NpmRegistryWrapper,
+) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		scanID, _ := cmd.Flags().GetString(commonParams.ScanIDFlag)
+		resultsFile, _ := cmd.Flags().GetString(commonParams.RemediationResultsFile)
+		if scanID != "" || resultsFile != "" {
+			return runBulkScaRemediation(cmd, resultsWrapper, npmRegistryWrapper)
+		}
 		// Check if input file is supported
 		filePaths, _ := cmd.Flags().GetStringSlice(commonParams.RemediationFiles)
 		packageName, _ := cmd.Flags().GetString(commonParams.RemediationPackage)
 		packageVersion, _ := cmd.Flags().GetString(commonParams.RemediationPackageVersion)
+		if len(filePaths) == 0 || packageName == "" || packageVersion == "" {
+			return errors.Errorf(
+				"required flag(s) \"%s\", \"%s\", \"%s\" not set, or use --%s or --%s",
+				commonParams.RemediationPackage,
+				commonParams.RemediationFiles,
+				commonParams.RemediationPackageVersion,
+				commonParams.ScanIDFlag,
+				commonParams.RemediationResultsFile,
+			)
+		}
 		var err error
 		for _, filePath := range filePaths {
 			if IsPackageFileSupported(filePath) {
@@ -175,10 +267,9 @@ func runRemediationScaCmd() func(cmd *cobra.Command, args []string) error {
 					return fileErr
 				}
 				// Call the parser for each specific package manager
-				p := remediation.PackageContentJSON{
-					FileContent:       fileContent,
-					PackageIdentifier: packageName,
-					PackageVersion:    packageVersion,
+				p, fileErr := remediation.NewPackage(filePath, fileContent, packageName, packageVersion)
+				if fileErr != nil {
+					return fileErr
 				}
 				parserOutput, fileErr := p.Parser()
 				if fileErr != nil {
@@ -215,18 +306,33 @@ func writePackageFile(filename, output string) error {
 }
 
 func IsPackageFileSupported(filename string) bool {
-	return strings.Contains(filename, npmPackageFilename)
+	return remediation.IsPackageFileSupported(filename)
 }
 
-func runRemediationKicsCmd() func(cmd *cobra.Command, args []string) error {
+func runRemediationKicsCmd(containerProvider container.Provider) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		// Create temp location, add it to container volumes and copy the results inside it
-		volumeMap, tempDir, err := createKicsRemediateEnv(cmd)
+		tempDir, err := createKicsRemediateEnv(cmd)
 		if err != nil {
 			return errors.Errorf("%s", err)
 		}
+		defer func() {
+			_ = os.RemoveAll(tempDir)
+		}()
+		engine, _ := cmd.Flags().GetString(commonParams.KicsRealtimeEngine)
+		runtime, err := containerProvider.Runtime(engine)
+		if err != nil {
+			return ContainerRunError(err)
+		}
+		dryRun, _ := cmd.Flags().GetBool(commonParams.RemediationDryRun)
+		interactive, _ := cmd.Flags().GetBool(commonParams.RemediationInteractive)
+		reportFile, _ := cmd.Flags().GetString(commonParams.RemediationReportFile)
 		// Run kics container
-		err = runKicsRemediation(cmd, volumeMap, tempDir)
+		if dryRun || interactive || reportFile != "" {
+			err = runKicsRemediationPreview(cmd, runtime, tempDir)
+		} else {
+			err = runKicsRemediation(cmd, runtime, tempDir)
+		}
 		if err != nil {
 			return errors.Errorf("%s", err)
 		}
@@ -234,98 +340,109 @@ func runRemediationKicsCmd() func(cmd *cobra.Command, args []string) error {
 	}
 }
 
-func runKicsRemediation(cmd *cobra.Command, volumeMap, tempDir string) error {
+func runKicsRemediation(cmd *cobra.Command, runtime container.Runtime, resultsDir string) error {
 	kicsFilesPath, _ := cmd.Flags().GetString(commonParams.KicsProjectFile)
+	result, err := runKicsRemediate(cmd, runtime, resultsDir, kicsFilesPath, kicsSimilarityFilter)
+	if err != nil {
+		return err
+	}
+	fmt.Println(buildRemediationSummary(string(result.Output)))
+	return nil
+}
+
+// runKicsRemediate runs kics remediate on the files of filesDir, restricted to the given similarity ids when any
+func runKicsRemediate(
+	cmd *cobra.Command,
+	runtime container.Runtime,
+	resultsDir, filesDir string,
+	similarityIDs []string,
+) (*container.RunResult, error) {
 	kicsResultsPath, _ := cmd.Flags().GetString(commonParams.KicsRemediationFile)
+	kicsImage, _ := cmd.Flags().GetString(commonParams.KicsRealtimeImage)
 	_, file := filepath.Split(kicsResultsPath)
-	kicsRunArgs := []string{
-		containerRun,
-		containerRemove,
-		volumeFlag,
-		volumeMap,
-		volumeFlag,
-		kicsFilesPath + filesContainerVolume,
-		containerNameFlag,
-		containerName,
-		containerImage,
+	kicsArgs := []string{
 		remediateCommand,
 		resultsFlag,
 		resultsContainerLocation + file,
 		kicsVerboseFlag,
 	}
-	if len(kicsSimilarityFilter) > 0 {
-		kicsRunArgs = append(kicsRunArgs, kicsIncludeIdsFlag)
-		kicsSimilarityFilterString := strings.Join(kicsSimilarityFilter, separator)
-		kicsRunArgs = append(kicsRunArgs, kicsSimilarityFilterString)
+	if len(similarityIDs) > 0 {
+		kicsArgs = append(kicsArgs, kicsIncludeIdsFlag, strings.Join(similarityIDs, separator))
+	}
+	if err := runtime.EnsureImage(kicsImage, cmd.ErrOrStderr()); err != nil {
+		return nil, ContainerRunError(err)
 	}
 	logger.PrintIfVerbose(containerStarting)
-	kicsCmd, _ := cmd.Flags().GetString(commonParams.KicsRealtimeEngine)
-	out, err := exec.Command(kicsCmd, kicsRunArgs...).CombinedOutput()
-	logger.PrintIfVerbose(string(out))
-	/* 	NOTE: the kics container returns 40 instead of 0 when successful!! This
-	definitely an incorrect behavior but the following check gets past it.
-	*/
-	if err == nil {
+	result, err := runtime.Run(&container.RunOptions{
+		Image:  kicsImage,
+		Name:   containerName,
+		Remove: true,
+		Mounts: []container.Mount{
+			{Source: resultsDir, Target: strings.TrimSuffix(resultsContainerLocation, "/")},
+			{Source: filesDir, Target: strings.TrimSuffix(filesContainerLocation, "/")},
+		},
+		Args: kicsArgs,
+	})
+	if err != nil {
+		return nil, ContainerRunError(err)
+	}
+	logger.PrintIfVerbose(string(result.Output))
+	status := wrappers.DecodeKicsExitCode(result.ExitCode)
+	if !status.Completed {
+		return nil, errors.Errorf("kics remediation failed with exit code %d: %s", result.ExitCode, status.Description)
+	}
+	if result.ExitCode == remediationExitCode {
+		logger.PrintIfVerbose(someRemediationsApplied)
+	} else {
 		logger.PrintIfVerbose(allRemediationsApplied)
-		fmt.Println(buildRemediationSummary(string(out)))
-		return nil
 	}
-	if err != nil {
-		errorMessage := err.Error()
-		extractedErrorCode := errorMessage[strings.LastIndex(errorMessage, " ")+1:]
-		os.RemoveAll(tempDir)
-		if contains(kicsErrorCodes, extractedErrorCode) {
-			logger.PrintIfVerbose(someRemediationsApplied)
-			fmt.Println(buildRemediationSummary(string(out)))
-			return nil
-		}
-		exitError, hasExistError := err.(*exec.ExitError)
-		if hasExistError {
-			if exitError.ExitCode() == EngineNoRunningCode {
-				logger.PrintIfVerbose(errorMessage)
-				return errors.Errorf(NotRunningEngineMessage)
-			}
-		} else {
-			if strings.Contains(errorMessage, InvalidEngineError) || strings.Contains(errorMessage, InvalidEngineErrorWindows) {
-				logger.PrintIfVerbose(errorMessage)
-				return errors.Errorf(InvalidEngineMessage)
-			}
-		}
+	return result, nil
+}
 
-		return errors.Errorf("Check container engine state. Failed: %s", errorMessage)
+// ContainerRunError turns a failure of the container engine into the message shown to the user
+func ContainerRunError(err error) error {
+	logger.PrintIfVerbose(err.Error())
+	switch {
+	case errors.Is(err, container.ErrEngineNotInstalled):
+		return errors.Errorf(InvalidEngineMessage)
+	case errors.Is(err, container.ErrDaemonNotRunning):
+		return errors.Errorf(NotRunningEngineMessage)
+	case errors.Is(err, container.ErrImagePullFailed):
+		return errors.Errorf("%s: %v", ImagePullFailedMessage, err)
+	default:
+		return errors.Errorf("Check container engine state. Failed: %v", err)
 	}
-	return nil
 }
 
-func createKicsRemediateEnv(cmd *cobra.Command) (volume, kicsDir string, err error) {
+func createKicsRemediateEnv(cmd *cobra.Command) (kicsDir string, err error) {
 	kicsDir, err = ioutil.TempDir("", "kics")
 	if err != nil {
-		return "", "", errors.New(directoryError)
+		return "", errors.New(directoryError)
 	}
 	kicsResultsPath, _ := cmd.Flags().GetString(commonParams.KicsRemediationFile)
 	_, file := filepath.Split(kicsResultsPath)
 	if file == "" {
-		return "", "", errors.New(" No results file was provided")
+		return "", errors.New(" No results file was provided")
 	}
 	kicsFile, err := ioutil.ReadFile(kicsResultsPath)
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
 	// transform the file_name attribute to match container location
-	kicsFile, err = filenameMatcher(kicsFile)
+	kicsFilesPath, _ := cmd.Flags().GetString(commonParams.KicsProjectFile)
+	kicsFile, err = filenameMatcher(kicsFile, kicsFilesPath)
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
 	destinationFile := fmt.Sprintf("%s/%s", kicsDir, file)
 	err = ioutil.WriteFile(destinationFile, kicsFile, 0666)
 	if err != nil {
-		return "", "", errors.New(containerWriteFolderError)
+		return "", errors.New(containerWriteFolderError)
 	}
-	volume = fmt.Sprintf("%s:/kics", kicsDir)
-	return volume, kicsDir, nil
+	return kicsDir, nil
 }
 
-func filenameMatcher(kicsFile []byte) (kicsFileUpdated []byte, err error) {
+func filenameMatcher(kicsFile []byte, kicsFilesPath string) (kicsFileUpdated []byte, err error) {
 	model := wrappers.KicsResultsCollection{}
 	err = json.Unmarshal(kicsFile, &model)
 	if err != nil {
@@ -333,7 +450,10 @@ func filenameMatcher(kicsFile []byte) (kicsFileUpdated []byte, err error) {
 	}
 	for indexResults := range model.Results {
 		for indexLocations := range model.Results[indexResults].Locations {
-			file := filepath.Base(model.Results[indexResults].Locations[indexLocations].Filename)
+			file, err := kicsRelativePath(kicsFilesPath, model.Results[indexResults].Locations[indexLocations].Filename)
+			if err != nil {
+				return nil, err
+			}
 			model.Results[indexResults].Locations[indexLocations].Filename = filesContainerLocation + file
 		}
 	}
@@ -356,15 +476,19 @@ func contains(s []string, str string) bool {
 func buildRemediationSummary(out string) (summary string) {
 	model := wrappers.KicsRemediationSummary{}
 	s := strings.Split(out, "\n")
+	if len(s) < remediationSummaryLines {
+		summaryByte, _ := json.Marshal(model)
+		return string(summaryByte)
+	}
 	// Always comes in the -3 position of the kics output
 	availableFixCount := strings.Split(s[len(s)-3], ":")
-	if len(availableFixCount) > 0 {
+	if len(availableFixCount) > 1 {
 		intAvailableFixCount, _ := strconv.Atoi(strings.ReplaceAll(availableFixCount[1], " ", ""))
 		model.AvailableRemediation = intAvailableFixCount
 	}
 	// Always comes in the -2 position of the kics output
 	appliedFixCount := strings.Split(s[len(s)-2], ":")
-	if len(appliedFixCount) > 0 {
+	if len(appliedFixCount) > 1 {
 		intAppliedFixCount, _ := strconv.Atoi(strings.ReplaceAll(appliedFixCount[1], " ", ""))
 		model.AppliedRemediation = intAppliedFixCount
 	}
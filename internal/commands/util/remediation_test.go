@@ -1,9 +1,17 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
+	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
 	"gotest.tools/assert"
 )
 
@@ -29,12 +37,12 @@ const (
 )
 
 func TestNewRemediationCommand(t *testing.T) {
-	cmd := NewRemediationCommand()
+	cmd := NewRemediationCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{}, &mock.ContainerProviderMock{})
 	assert.Assert(t, cmd != nil, "Remediation command must exist")
 }
 
 func TestRemediationScaCommand(t *testing.T) {
-	cmd := RemediationScaCommand()
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
 	err := executeTestCommand(
 		cmd,
 		packageFileFlag,
@@ -48,7 +56,7 @@ func TestRemediationScaCommand(t *testing.T) {
 }
 
 func TestRemediationScaCommandUnsupported(t *testing.T) {
-	cmd := RemediationScaCommand()
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
 	err := executeTestCommand(
 		cmd,
 		packageFileFlag,
@@ -62,7 +70,7 @@ func TestRemediationScaCommandUnsupported(t *testing.T) {
 }
 
 func TestRemediationScaCommandPackageNotFound(t *testing.T) {
-	cmd := RemediationScaCommand()
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
 	err := executeTestCommand(
 		cmd,
 		packageFileFlag,
@@ -76,35 +84,35 @@ func TestRemediationScaCommandPackageNotFound(t *testing.T) {
 }
 
 func TestRemediationKicsCommand(t *testing.T) {
-	cmd := RemediationKicsCommand()
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{})
 	abs, _ := filepath.Abs(kicsFileValue)
 	err := executeTestCommand(cmd, resultsFileFlag, resultFileValue, kicsFileFlag, abs)
 	assert.Assert(t, err == nil, "Remediation command must pass")
 }
 
 func TestRemediationKicsCommandInvalidResults(t *testing.T) {
-	cmd := RemediationKicsCommand()
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{})
 	abs, _ := filepath.Abs(kicsFileValue)
 	err := executeTestCommand(cmd, resultsFileFlag, invalidResultFileValue, kicsFileFlag, abs)
 	assert.Assert(t, err != nil, "No results file was provided")
 }
 
 func TestRemediationKicsCommandEngineFlag(t *testing.T) {
-	cmd := RemediationKicsCommand()
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{})
 	abs, _ := filepath.Abs(kicsFileValue)
 	err := executeTestCommand(cmd, resultsFileFlag, resultFileValue, kicsFileFlag, abs, engineFlag, engineValue)
 	assert.Assert(t, err == nil, "Remediation command must pass")
 }
 
 func TestRemediationKicsCommandInvalidEngine(t *testing.T) {
-	cmd := RemediationKicsCommand()
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{})
 	abs, _ := filepath.Abs(kicsFileValue)
 	err := executeTestCommand(cmd, resultsFileFlag, resultFileValue, kicsFileFlag, abs, engineFlag, invalidEngineValue)
 	assert.Assert(t, err != nil, InvalidEngineMessage)
 }
 
 func TestRemediationKicsCommandSimilarityFilter(t *testing.T) {
-	cmd := RemediationKicsCommand()
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{})
 	abs, _ := filepath.Abs(kicsFileValue)
 	err := executeTestCommand(
 		cmd,
@@ -117,3 +125,530 @@ func TestRemediationKicsCommandSimilarityFilter(t *testing.T) {
 	)
 	assert.Assert(t, err == nil, "Remediation command must pass")
 }
+
+// remediateKicsMock appends a line per included similarity id to the files of the results, like kics remediate
+// would fix them
+func remediateKicsMock(options *container.RunOptions) error {
+	var ids []string
+	var resultsFile string
+	for i, arg := range options.Args {
+		switch arg {
+		case kicsIncludeIdsFlag:
+			ids = strings.Split(options.Args[i+1], separator)
+		case resultsFlag:
+			resultsFile = options.Args[i+1]
+		}
+	}
+	mounts := map[string]string{}
+	for _, mount := range options.Mounts {
+		mounts[mount.Target+"/"] = mount.Source
+	}
+	content, err := os.ReadFile(filepath.Join(mounts[resultsContainerLocation], strings.TrimPrefix(resultsFile, resultsContainerLocation)))
+	if err != nil {
+		return err
+	}
+	var model wrappers.KicsResultsCollection
+	if err = json.Unmarshal(content, &model); err != nil {
+		return err
+	}
+	for _, query := range model.Results {
+		for _, location := range query.Locations {
+			if location.RemediationType == "" || len(ids) > 0 && !containsExactly(ids, location.SimilarityID) {
+				continue
+			}
+			path := filepath.Join(mounts[filesContainerLocation], strings.TrimPrefix(location.Filename, filesContainerLocation))
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, permission)
+			if err != nil {
+				return err
+			}
+			_, err = file.WriteString("# remediated " + location.SimilarityID[:8] + "\n")
+			_ = file.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func createKicsFilesCopy(t *testing.T) string {
+	dir := t.TempDir()
+	content, err := os.ReadFile("../data/positive1.tf")
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "positive1.tf"), content, permission))
+	return dir
+}
+
+func TestRemediationKicsCommandDryRun(t *testing.T) {
+	kicsFiles := createKicsFilesCopy(t)
+	original, _ := os.ReadFile(filepath.Join(kicsFiles, "positive1.tf"))
+	runtime := &mock.ContainerRuntimeMock{OnRun: remediateKicsMock}
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{RuntimeMock: runtime})
+	patchFile := filepath.Join(t.TempDir(), "fixes.patch")
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	err := executeTestCommand(
+		cmd, resultsFileFlag, resultFileValue, kicsFileFlag, kicsFiles,
+		"--dry-run", "--patch-file", patchFile, "--report-file", reportFile,
+	)
+	assert.NilError(t, err)
+	// a single run computes the patch of each remediation and of all of them
+	assert.Equal(t, len(runtime.Runs), 1)
+	current, _ := os.ReadFile(filepath.Join(kicsFiles, "positive1.tf"))
+	assert.Equal(t, string(current), string(original), "dry run must not change the files")
+
+	patch, err := os.ReadFile(patchFile)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(string(patch), "--- a/positive1.tf\n+++ b/positive1.tf\n"))
+	assert.Assert(t, strings.Contains(string(patch), "+# remediated b42a1948\n+# remediated 9574288c\n+# remediated cc22618d\n"))
+
+	var report KicsRemediationReport
+	content, _ := os.ReadFile(reportFile)
+	assert.NilError(t, json.Unmarshal(content, &report))
+	assert.Assert(t, report.DryRun)
+	assert.Equal(t, len(report.Remediations), 3)
+	assert.Equal(t, report.Remediations[0].SimilarityID, "b42a19486a8e18324a9b2c06147b1c49feb3ba39a0e4aeafec5665e60f98d047")
+	assert.Equal(t, report.Remediations[0].Status, RemediationProposed)
+	assert.Assert(t, strings.Contains(report.Remediations[0].Patch, "+# remediated b42a1948\n"))
+	assert.Assert(t, !strings.Contains(report.Remediations[0].Patch, "9574288c"))
+	assert.Equal(t, report.Summary.AvailableRemediation, 3)
+	assert.Equal(t, report.Summary.AppliedRemediation, 0)
+}
+
+func TestRemediationKicsCommandInteractive(t *testing.T) {
+	kicsFiles := createKicsFilesCopy(t)
+	runtime := &mock.ContainerRuntimeMock{OnRun: remediateKicsMock}
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{RuntimeMock: runtime})
+	cmd.SetIn(strings.NewReader("y\nmaybe\nn\nq\n"))
+	var output bytes.Buffer
+	cmd.SetOut(&output)
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	err := executeTestCommand(cmd, resultsFileFlag, resultFileValue, kicsFileFlag, kicsFiles, "--interactive", "--report-file", reportFile)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output.String(), "[1/3] HIGH ALB Listening on HTTP - positive1.tf:9"))
+	assert.Equal(t, strings.Count(output.String(), remediationPromptAnswers), 4)
+
+	current, _ := os.ReadFile(filepath.Join(kicsFiles, "positive1.tf"))
+	assert.Assert(t, strings.HasSuffix(string(current), "# remediated b42a1948\n"))
+
+	var report KicsRemediationReport
+	content, _ := os.ReadFile(reportFile)
+	assert.NilError(t, json.Unmarshal(content, &report))
+	statuses := []string{report.Remediations[0].Status, report.Remediations[1].Status, report.Remediations[2].Status}
+	assert.DeepEqual(t, statuses, []string{RemediationApplied, RemediationSkipped, RemediationSkipped})
+	assert.Equal(t, report.Summary.AppliedRemediation, 1)
+	assert.Assert(t, strings.Contains(report.Patch, "+# remediated b42a1948\n"))
+	// the skipped remediations need a second run for the patch of the accepted one
+	assert.Equal(t, len(runtime.Runs), 2)
+}
+
+// nestedKicsResults refers to two files named main.tf in different folders, as a scan of the cli reports them
+const nestedKicsResults = `{"queries": [
+  {"query_name": "Network Open", "severity": "HIGH", "files": [
+    {"file_name": "../../path/network/main.tf", "similarity_id": "aaaaaaaa1111", "line": 2, "remediation_type": "replacement"}]},
+  {"query_name": "Bucket Public", "severity": "MEDIUM", "files": [
+    {"file_name": "../../path/storage/main.tf", "similarity_id": "bbbbbbbb2222", "line": 5, "remediation_type": "addition"}]}]}`
+
+func createNestedKicsFiles(t *testing.T) (resultsFile, kicsFiles string) {
+	kicsFiles = t.TempDir()
+	for _, file := range []string{"network/main.tf", "storage/main.tf"} {
+		path := filepath.Join(kicsFiles, filepath.FromSlash(file))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NilError(t, os.WriteFile(path, []byte("# "+file+"\n"), permission))
+	}
+	resultsFile = filepath.Join(t.TempDir(), "results.json")
+	assert.NilError(t, os.WriteFile(resultsFile, []byte(nestedKicsResults), permission))
+	return resultsFile, kicsFiles
+}
+
+func TestRemediationKicsCommandNestedFiles(t *testing.T) {
+	resultsFile, kicsFiles := createNestedKicsFiles(t)
+	runtime := &mock.ContainerRuntimeMock{OnRun: remediateKicsMock}
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{RuntimeMock: runtime})
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	var output bytes.Buffer
+	cmd.SetOut(&output)
+	err := executeTestCommand(cmd, resultsFileFlag, resultsFile, kicsFileFlag, kicsFiles, "--dry-run", "--report-file", reportFile)
+	assert.NilError(t, err)
+	assert.Equal(t, len(runtime.Runs), 1)
+
+	var report KicsRemediationReport
+	content, _ := os.ReadFile(reportFile)
+	assert.NilError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, report.Remediations[0].File, "network/main.tf")
+	assert.Equal(t, report.Remediations[0].Patch,
+		"--- a/network/main.tf\n+++ b/network/main.tf\n@@ -1 +1,2 @@\n # network/main.tf\n+# remediated aaaaaaaa\n")
+	assert.Equal(t, report.Remediations[1].File, "storage/main.tf")
+	assert.Equal(t, report.Remediations[1].Patch,
+		"--- a/storage/main.tf\n+++ b/storage/main.tf\n@@ -1 +1,2 @@\n # storage/main.tf\n+# remediated bbbbbbbb\n")
+	assert.Equal(t, output.String(), report.Remediations[0].Patch+report.Remediations[1].Patch)
+
+	cmd = RemediationKicsCommand(&mock.ContainerProviderMock{RuntimeMock: runtime})
+	err = executeTestCommand(cmd, resultsFileFlag, resultsFile, kicsFileFlag, kicsFiles, "--report-file", reportFile)
+	assert.NilError(t, err)
+	network, _ := os.ReadFile(filepath.Join(kicsFiles, "network", "main.tf"))
+	assert.Equal(t, string(network), "# network/main.tf\n# remediated aaaaaaaa\n")
+	storage, _ := os.ReadFile(filepath.Join(kicsFiles, "storage", "main.tf"))
+	assert.Equal(t, string(storage), "# storage/main.tf\n# remediated bbbbbbbb\n")
+
+	// without a preview kics remediates the files in place
+	cmd = RemediationKicsCommand(&mock.ContainerProviderMock{RuntimeMock: runtime})
+	err = executeTestCommand(cmd, resultsFileFlag, resultsFile, kicsFileFlag, kicsFiles, similarityIDFlag, "bbbbbbbb2222")
+	assert.NilError(t, err)
+	storage, _ = os.ReadFile(filepath.Join(kicsFiles, "storage", "main.tf"))
+	assert.Equal(t, string(storage), "# storage/main.tf\n# remediated bbbbbbbb\n# remediated bbbbbbbb\n")
+	network, _ = os.ReadFile(filepath.Join(kicsFiles, "network", "main.tf"))
+	assert.Equal(t, string(network), "# network/main.tf\n# remediated aaaaaaaa\n")
+}
+
+func TestKicsRelativePath(t *testing.T) {
+	root := t.TempDir()
+	for filename, expected := range map[string]string{
+		"../../path/network/main.tf":              "network/main.tf",
+		"/path/network/main.tf":                   "network/main.tf",
+		"/path/main.tf":                           "main.tf",
+		"network/./main.tf":                       "network/main.tf",
+		filepath.Join(root, "storage", "main.tf"): "storage/main.tf",
+		"/src/network/main.tf":                    "main.tf",
+		"../outside.tf":                           "outside.tf",
+		"../../path/../../etc/passwd":             "passwd",
+	} {
+		relative, err := kicsRelativePath(root, filename)
+		assert.NilError(t, err, filename)
+		assert.Equal(t, relative, expected, filename)
+	}
+	for _, filename := range []string{"/", ".", ".."} {
+		_, err := kicsRelativePath(root, filename)
+		assert.ErrorContains(t, err, "is outside of the kics files folder", filename)
+	}
+}
+
+func TestRemediationKicsCommandContainerPaths(t *testing.T) {
+	resultsFile, kicsFiles := createNestedKicsFiles(t)
+	results := strings.Replace(nestedKicsResults, "../../path/network/main.tf", "/path/network/main.tf", 1)
+	results = strings.Replace(results, "../../path/storage/main.tf", "/src/main.tf", 1)
+	assert.NilError(t, os.WriteFile(resultsFile, []byte(results), permission))
+	assert.NilError(t, os.WriteFile(filepath.Join(kicsFiles, "main.tf"), []byte("# main.tf\n"), permission))
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{RuntimeMock: &mock.ContainerRuntimeMock{OnRun: remediateKicsMock}})
+	err := executeTestCommand(cmd, resultsFileFlag, resultsFile, kicsFileFlag, kicsFiles)
+	assert.NilError(t, err)
+	network, _ := os.ReadFile(filepath.Join(kicsFiles, "network", "main.tf"))
+	assert.Equal(t, string(network), "# network/main.tf\n# remediated aaaaaaaa\n")
+	main, _ := os.ReadFile(filepath.Join(kicsFiles, "main.tf"))
+	assert.Equal(t, string(main), "# main.tf\n# remediated bbbbbbbb\n")
+}
+
+func TestRemediationKicsCommandUnavailableRemediation(t *testing.T) {
+	kicsFiles := createKicsFilesCopy(t)
+	cmd := RemediationKicsCommand(&mock.ContainerProviderMock{})
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	err := executeTestCommand(
+		cmd, resultsFileFlag, resultFileValue, kicsFileFlag, kicsFiles, similarityIDFlag, similarityIDValue, "--report-file", reportFile,
+	)
+	assert.NilError(t, err)
+	var report KicsRemediationReport
+	content, _ := os.ReadFile(reportFile)
+	assert.NilError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, len(report.Remediations), 2)
+	assert.Equal(t, report.Remediations[0].Status, RemediationUnavailable)
+	assert.Equal(t, report.Summary.AppliedRemediation, 0)
+}
+
+func TestRemediationScaCommandMultipleManifests(t *testing.T) {
+	dir := t.TempDir()
+	goMod := filepath.Join(dir, "go.mod")
+	requirements := filepath.Join(dir, "requirements.txt")
+	assert.NilError(t, os.WriteFile(goMod, []byte("module demo\n\nrequire golang.org/x/net v0.7.0 // indirect\n"), permission))
+	assert.NilError(t, os.WriteFile(requirements, []byte("# web\nflask==1.1.2\n"), permission))
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	err := executeTestCommand(cmd, packageFileFlag, goMod+","+requirements, packageFlag, "golang.org/x/net", packageVersionFlag, "v0.17.0")
+	assert.ErrorContains(t, err, "Package golang.org/x/net not found")
+	content, _ := os.ReadFile(goMod)
+	assert.Equal(t, string(content), "module demo\n\nrequire golang.org/x/net v0.17.0 // indirect\n")
+}
+
+const scaRemediationResults = `{"results": [
+  {"type": "sca", "id": "CVE-2013-7285", "severity": "HIGH", "state": "TO_VERIFY", "data": {
+    "packageIdentifier": "Maven-com.thoughtworks.xstream:xstream-1.4.5", "recommendedVersion": "1.4.20",
+    "scaPackageData": {"id": "Maven-com.thoughtworks.xstream:xstream-1.4.5", "isDirectDependency": true,
+      "dependencyPaths": [[{"id": "Maven-com.thoughtworks.xstream:xstream-1.4.5", "name": "com.thoughtworks.xstream:xstream", "version": "1.4.5"}]]}}},
+  {"type": "sca", "id": "CVE-2019-10744", "severity": "HIGH", "state": "TO_VERIFY", "data": {
+    "packageIdentifier": "Npm-lodash-4.17.11", "recommendedVersion": "4.17.12",
+    "scaPackageData": {"id": "Npm-lodash-4.17.11", "isDirectDependency": true}}},
+  {"type": "sca", "id": "CVE-2021-23337", "severity": "HIGH", "state": "TO_VERIFY", "data": {
+    "packageIdentifier": "Npm-lodash-4.17.11", "recommendedVersion": "4.17.21",
+    "scaPackageData": {"id": "Npm-lodash-4.17.11", "isDirectDependency": true}}},
+  {"type": "sca", "id": "CVE-2020-28500", "severity": "MEDIUM", "state": "TO_VERIFY", "data": {
+    "packageIdentifier": "Npm-tree-kill-1.2.1", "recommendedVersion": "1.2.2",
+    "scaPackageData": {"id": "Npm-tree-kill-1.2.1", "isDirectDependency": true}}},
+  {"type": "sca", "id": "CVE-2022-25883", "severity": "HIGH", "state": "TO_VERIFY", "data": {
+    "packageIdentifier": "Npm-semver-5.7.1", "recommendedVersion": "5.7.2",
+    "scaPackageData": {"id": "Npm-semver-5.7.1", "isDirectDependency": false}}},
+  {"type": "sca", "id": "CVE-2022-42889", "severity": "HIGH", "state": "TO_VERIFY", "data": {
+    "packageIdentifier": "Maven-org.apache.commons:commons-text-1.9", "recommendedVersion": "1.10.0",
+    "scaPackageData": {"id": "Maven-org.apache.commons:commons-text-1.9", "isDirectDependency": false}}},
+  {"type": "sca", "id": "CVE-2017-18640", "severity": "HIGH", "state": "NOT_EXPLOITABLE", "data": {
+    "packageIdentifier": "Maven-org.yaml:snakeyaml-1.19", "recommendedVersion": "1.26",
+    "scaPackageData": {"id": "Maven-org.yaml:snakeyaml-1.19", "isDirectDependency": true}}},
+  {"type": "sast", "id": "1", "severity": "HIGH", "data": {}}
+]}`
+
+func createScaRemediationProject(t *testing.T) (projectDir, resultsFile string) {
+	projectDir = t.TempDir()
+	files := map[string]string{
+		"pom.xml": "<project>\n  <dependencies>\n    <dependency>\n      <groupId>com.thoughtworks.xstream</groupId>\n" +
+			"      <artifactId>xstream</artifactId>\n      <version>1.4.5</version> <!-- xml -->\n    </dependency>\n  </dependencies>\n</project>\n",
+		"web/package.json":                     "{\n  \"dependencies\": {\n    \"lodash\": \"4.17.11\",\n    \"tree-kill\": \"1.2.1\"\n  }\n}",
+		"web/node_modules/lodash/package.json": "{\n  \"dependencies\": {\n    \"lodash\": \"4.17.11\"\n  }\n}",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NilError(t, os.WriteFile(path, []byte(content), permission))
+	}
+	resultsFile = filepath.Join(t.TempDir(), "cx_result.json")
+	assert.NilError(t, os.WriteFile(resultsFile, []byte(scaRemediationResults), permission))
+	return projectDir, resultsFile
+}
+
+func TestRemediationScaCommandResultsFile(t *testing.T) {
+	projectDir, resultsFile := createScaRemediationProject(t)
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	var output bytes.Buffer
+	cmd.SetOut(&output)
+	err := executeTestCommand(cmd, "--results-file", resultsFile, "--project-dir", projectDir, "--severity", "high", "--format", "json")
+	assert.NilError(t, err)
+
+	var views []ScaRemediationView
+	assert.NilError(t, json.Unmarshal(output.Bytes(), &views))
+	assert.DeepEqual(t, views, []ScaRemediationView{
+		{
+			Package: "com.thoughtworks.xstream:xstream", PackageManager: "Maven", CurrentVersion: "1.4.5", Version: "1.4.20",
+			Vulnerabilities: "CVE-2013-7285", Manifests: "pom.xml", Status: ScaRemediationUpgraded,
+		},
+		{
+			Package: "org.apache.commons:commons-text", PackageManager: "Maven", CurrentVersion: "1.9", Version: "1.10.0",
+			Vulnerabilities: "CVE-2022-42889", Status: ScaRemediationNotRemediable,
+			Message: "transitive dependency, only transitive npm dependencies are remediated",
+		},
+		{
+			Package: "lodash", PackageManager: "Npm", CurrentVersion: "4.17.11", Version: "4.17.21",
+			Vulnerabilities: "CVE-2019-10744,CVE-2021-23337", Manifests: "web/package.json", Status: ScaRemediationUpgraded,
+		},
+		{
+			Package: "semver", PackageManager: "Npm", CurrentVersion: "5.7.1", Version: "5.7.2", Vulnerabilities: "CVE-2022-25883",
+			Status: ScaRemediationNotDeclared, Message: "transitive dependency that no lockfile of the project installs",
+		},
+	})
+	pom, _ := os.ReadFile(filepath.Join(projectDir, "pom.xml"))
+	assert.Assert(t, strings.Contains(string(pom), "<version>1.4.20</version> <!-- xml -->"))
+	packageJSON, _ := os.ReadFile(filepath.Join(projectDir, "web", "package.json"))
+	assert.Assert(t, strings.Contains(string(packageJSON), `"lodash": "4.17.21"`))
+	assert.Assert(t, strings.Contains(string(packageJSON), `"tree-kill": "1.2.1"`), "medium severity must not be remediated")
+	vendored, _ := os.ReadFile(filepath.Join(projectDir, "web", "node_modules", "lodash", "package.json"))
+	assert.Assert(t, strings.Contains(string(vendored), `"lodash": "4.17.11"`), "node_modules must not be changed")
+}
+
+func TestRemediationScaCommandDryRun(t *testing.T) {
+	projectDir, resultsFile := createScaRemediationProject(t)
+	pom, _ := os.ReadFile(filepath.Join(projectDir, "pom.xml"))
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	var output bytes.Buffer
+	cmd.SetOut(&output)
+	err := executeTestCommand(cmd, "--results-file", resultsFile, "--project-dir", projectDir, "--dry-run")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(output.String(), "--- a/pom.xml\n+++ b/pom.xml\n"))
+	assert.Assert(t, strings.Contains(output.String(), "-      <version>1.4.5</version> <!-- xml -->\n+      <version>1.4.20</version> <!-- xml -->\n"))
+	assert.Assert(t, strings.Contains(output.String(), ScaRemediationProposed))
+	assert.Assert(t, strings.Contains(output.String(), "tree-kill"))
+	current, _ := os.ReadFile(filepath.Join(projectDir, "pom.xml"))
+	assert.Equal(t, string(current), string(pom), "dry run must not change the files")
+}
+
+func TestRemediationScaCommandDryRunJSON(t *testing.T) {
+	projectDir, resultsFile := createScaRemediationProject(t)
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	var output, stderr bytes.Buffer
+	cmd.SetOut(&output)
+	cmd.SetErr(&stderr)
+	err := executeTestCommand(cmd, "--results-file", resultsFile, "--project-dir", projectDir, "--dry-run", "--format", "json")
+	assert.NilError(t, err)
+	var views []ScaRemediationView
+	assert.NilError(t, json.Unmarshal(output.Bytes(), &views))
+	assert.Assert(t, strings.Contains(stderr.String(), "--- a/pom.xml\n+++ b/pom.xml\n"))
+
+	patchFile := filepath.Join(t.TempDir(), "fixes.patch")
+	output.Reset()
+	cmd = RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	cmd.SetOut(&output)
+	err = executeTestCommand(cmd, "--results-file", resultsFile, "--project-dir", projectDir, "--dry-run", "--patch-file", patchFile)
+	assert.NilError(t, err)
+	patch, _ := os.ReadFile(patchFile)
+	assert.Assert(t, strings.Contains(string(patch), "--- a/pom.xml\n+++ b/pom.xml\n"))
+	assert.Assert(t, !strings.Contains(output.String(), "--- a/pom.xml"))
+}
+
+func TestRemediationScaCommandMissingFlags(t *testing.T) {
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	err := executeTestCommand(cmd, packageFlag, packageValue)
+	assert.ErrorContains(t, err, "or use --scan-id or --results-file")
+	cmd = RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	err = executeTestCommand(cmd, "--scan-id", "id", "--results-file", "cx_result.json")
+	assert.ErrorContains(t, err, "none of the others can be")
+}
+
+const transitiveRemediationResults = `{"results": [
+  {"type": "sca", "id": "CVE-2022-24999", "severity": "HIGH", "state": "TO_VERIFY", "data": {
+    "packageIdentifier": "Npm-qs-6.7.0", "recommendedVersion": "6.10.3",
+    "scaPackageData": {"id": "Npm-qs-6.7.0", "isDirectDependency": false}}}
+]}`
+
+const transitivePackageJSON = `{
+  "name": "app",
+  "dependencies": {
+    "express": "4.17.1"
+  }
+}
+`
+
+const transitivePackageLock = `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "4.17.1"}},
+    "node_modules/body-parser": {"version": "1.19.0", "dependencies": {"qs": "6.7.0"}},
+    "node_modules/express": {"version": "4.17.1", "dependencies": {"body-parser": "1.19.0", "qs": "6.7.0"}},
+    "node_modules/qs": {"version": "6.7.0"}
+  }
+}`
+
+const transitiveYarnLock = `express@4.17.1:
+  version "4.17.1"
+  dependencies:
+    body-parser "1.19.0"
+    qs "6.7.0"
+
+body-parser@1.19.0:
+  version "1.19.0"
+  dependencies:
+    qs "6.7.0"
+
+qs@6.7.0:
+  version "6.7.0"
+`
+
+func createTransitiveRemediationProject(t *testing.T, lockfileName, lockfile string) (projectDir, resultsFile string) {
+	projectDir = t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(projectDir, "package.json"), []byte(transitivePackageJSON), permission))
+	assert.NilError(t, os.WriteFile(filepath.Join(projectDir, lockfileName), []byte(lockfile), permission))
+	resultsFile = filepath.Join(t.TempDir(), "cx_result.json")
+	assert.NilError(t, os.WriteFile(resultsFile, []byte(transitiveRemediationResults), permission))
+	return projectDir, resultsFile
+}
+
+func npmRegistryMock() *mock.NpmRegistryMockWrapper {
+	versions := func(dependencies map[string]map[string]string) map[string]wrappers.NpmPackageVersion {
+		packageVersions := map[string]wrappers.NpmPackageVersion{}
+		for version, versionDependencies := range dependencies {
+			packageVersions[version] = wrappers.NpmPackageVersion{Version: version, Dependencies: versionDependencies}
+		}
+		return packageVersions
+	}
+	return &mock.NpmRegistryMockWrapper{Packuments: map[string]*wrappers.NpmPackument{
+		"express": {Name: "express", Versions: versions(map[string]map[string]string{
+			"4.17.1": {"body-parser": "1.19.0", "qs": "6.7.0"},
+			"4.17.2": {"body-parser": "1.19.1", "qs": "6.9.6"},
+			"4.18.2": {"body-parser": "1.20.1", "qs": "6.11.0"},
+		})},
+		"body-parser": {Name: "body-parser", Versions: versions(map[string]map[string]string{
+			"1.19.0": {"qs": "6.7.0"},
+			"1.19.1": {"qs": "6.9.6"},
+			"1.20.1": {"qs": "6.11.0"},
+		})},
+		"qs": {Name: "qs", Versions: versions(map[string]map[string]string{"6.7.0": {}, "6.9.6": {}, "6.11.0": {}})},
+	}}
+}
+
+func runTransitiveRemediation(t *testing.T, registry *mock.NpmRegistryMockWrapper, args ...string) ScaRemediationView {
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, registry)
+	var output bytes.Buffer
+	cmd.SetOut(&output)
+	err := executeTestCommand(cmd, append(args, "--format", "json")...)
+	var views []ScaRemediationView
+	// a failure prints the usage after the report
+	assert.NilError(t, json.NewDecoder(&output).Decode(&views))
+	assert.Equal(t, len(views), 1)
+	if views[0].Status != ScaRemediationFailed {
+		assert.NilError(t, err)
+	}
+	return views[0]
+}
+
+func TestRemediationScaCommandTransitiveUpgrade(t *testing.T) {
+	projectDir, resultsFile := createTransitiveRemediationProject(t, "package-lock.json", transitivePackageLock)
+	view := runTransitiveRemediation(t, npmRegistryMock(), "--results-file", resultsFile, "--project-dir", projectDir)
+	assert.Equal(t, view.Status, ScaRemediationUpgraded)
+	assert.Equal(t, view.Remediation, "upgrade express to 4.18.2")
+	assert.Equal(t, view.DependencyPaths,
+		"package-lock.json: express@4.17.1 > qs@6.7.0; package-lock.json: express@4.17.1 > body-parser@1.19.0 > qs@6.7.0")
+	packageJSON, _ := os.ReadFile(filepath.Join(projectDir, "package.json"))
+	assert.Assert(t, strings.Contains(string(packageJSON), `"express": "4.18.2"`), string(packageJSON))
+	assert.Assert(t, !strings.Contains(string(packageJSON), "overrides"))
+}
+
+func TestRemediationScaCommandTransitiveOverride(t *testing.T) {
+	projectDir, resultsFile := createTransitiveRemediationProject(t, "package-lock.json", transitivePackageLock)
+	registry := npmRegistryMock()
+	view := runTransitiveRemediation(t, registry,
+		"--results-file", resultsFile, "--project-dir", projectDir, "--transitive-strategy", "override")
+	assert.Equal(t, view.Status, ScaRemediationUpgraded)
+	assert.Equal(t, view.Remediation, "add overrides")
+	assert.Equal(t, len(registry.Requests), 0, "the registry must not be requested")
+	packageJSON, _ := os.ReadFile(filepath.Join(projectDir, "package.json"))
+	assert.Equal(t, string(packageJSON), `{
+  "name": "app",
+  "dependencies": {
+    "express": "4.17.1"
+  },
+  "overrides": {
+    "qs": "6.10.3"
+  }
+}
+`)
+}
+
+func TestRemediationScaCommandTransitiveYarnResolution(t *testing.T) {
+	projectDir, resultsFile := createTransitiveRemediationProject(t, "yarn.lock", transitiveYarnLock)
+	// the registry is not reachable, the version is overridden
+	view := runTransitiveRemediation(t, &mock.NpmRegistryMockWrapper{}, "--results-file", resultsFile, "--project-dir", projectDir)
+	assert.Equal(t, view.Remediation, "add resolutions")
+	assert.Equal(t, view.DependencyPaths, "yarn.lock: express@4.17.1 > qs@6.7.0; yarn.lock: express@4.17.1 > body-parser@1.19.0 > qs@6.7.0")
+	packageJSON, _ := os.ReadFile(filepath.Join(projectDir, "package.json"))
+	assert.Assert(t, strings.Contains(string(packageJSON), "\"resolutions\": {\n    \"qs\": \"6.10.3\"\n  }"), string(packageJSON))
+}
+
+func TestRemediationScaCommandTransitiveUpgradeOnly(t *testing.T) {
+	projectDir, resultsFile := createTransitiveRemediationProject(t, "package-lock.json", transitivePackageLock)
+	registry := npmRegistryMock()
+	delete(registry.Packuments["express"].Versions, "4.18.2")
+	view := runTransitiveRemediation(t, registry,
+		"--results-file", resultsFile, "--project-dir", projectDir, "--transitive-strategy", "upgrade", "--dry-run")
+	assert.Equal(t, view.Status, ScaRemediationFailed)
+	assert.Equal(t, view.Message, "package.json: no published version of the direct dependencies installs qs 6.10.3")
+}
+
+func TestRemediationScaCommandInvalidTransitiveStrategy(t *testing.T) {
+	cmd := RemediationScaCommand(&mock.ResultsMockWrapper{}, &mock.NpmRegistryMockWrapper{})
+	err := executeTestCommand(cmd, "--results-file", "cx_result.json", "--transitive-strategy", "latest")
+	assert.ErrorContains(t, err, "Invalid value for --transitive-strategy")
+}
+
+func TestFormatDependencyPaths(t *testing.T) {
+	var paths []string
+	for i := 0; i < maxDisplayedDependencyPaths+3; i++ {
+		paths = append(paths, fmt.Sprintf("package-lock.json: dep%d@1.0.0 > qs@6.7.0", i))
+	}
+	assert.Equal(t, formatDependencyPaths(paths[:2]), "package-lock.json: dep0@1.0.0 > qs@6.7.0; package-lock.json: dep1@1.0.0 > qs@6.7.0")
+	formatted := formatDependencyPaths(paths)
+	assert.Assert(t, strings.HasSuffix(formatted, fmt.Sprintf("dep%d@1.0.0 > qs@6.7.0; and 3 more", maxDisplayedDependencyPaths-1)))
+	assert.Equal(t, strings.Count(formatted, "qs@6.7.0"), maxDisplayedDependencyPaths)
+}
@@ -0,0 +1,118 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/checkmarx/ast-cli/internal/commands/util/printer"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/scadb"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Results of a database sync
+const (
+	scaDBInstalled = "Installed"
+	scaDBUpToDate  = "UpToDate"
+	// the database has no official download location yet, it is synced from a mirror set up by the user
+	scaDBNotConfigured = "The SCA vulnerability database has no default download location, set %s to the URL of the " +
+		"mirror publishing it and %s to the base64 ed25519 public key signing it"
+)
+
+type ScaDBView struct {
+	Version   string    `format:"name:Version" json:"version"`
+	Published time.Time `format:"name:Published;time:2006-01-02 15:04:05" json:"published"`
+	Directory string    `format:"name:Directory" json:"directory"`
+	Status    string    `format:"name:Status" json:"status"`
+}
+
+func NewScaDBCommand(scaDBWrapper wrappers.ScaDBWrapper) *cobra.Command {
+	scaDBCmd := &cobra.Command{
+		Use:   "sca-db",
+		Short: "Manage the offline SCA vulnerability database",
+		Long: fmt.Sprintf("The sca-db command manages the local SCA vulnerability database, "+
+			"which lets 'cx scan sca-realtime --offline' find vulnerabilities without network access. "+
+			"The database is synced from a mirror, which requires %s and %s to be set.",
+			commonParams.ScaDBURLEnv, commonParams.ScaDBPublicKeyEnv),
+		Example: heredoc.Doc(
+			`
+			$ cx utils sca-db sync
+		`,
+		),
+	}
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Download the latest SCA vulnerability database from a mirror",
+		Long: fmt.Sprintf(
+			"The sync command downloads a version of the SCA vulnerability database and installs it in %s, "+
+				"by default in ~/.checkmarx/sca-db. There is no default download location: %s must be set to the URL of "+
+				"a mirror publishing <version>/manifest.json for each version and latest, and the snapshots must be signed "+
+				"by the ed25519 key configured in %s.",
+			commonParams.ScaDBDirEnv,
+			commonParams.ScaDBURLEnv,
+			commonParams.ScaDBPublicKeyEnv,
+		),
+		Example: heredoc.Doc(
+			`
+			$ CX_SCA_DB_URL=https://mirror.example.com/sca-db CX_SCA_DB_PUBLIC_KEY=<public-key> cx utils sca-db sync
+			$ cx utils sca-db sync --db-version 2024.05.01
+		`,
+		),
+		RunE: runScaDBSync(scaDBWrapper),
+	}
+	syncCmd.PersistentFlags().String(commonParams.ScaDBVersion, "", "Version of the database to install, the latest by default")
+	syncCmd.PersistentFlags().String(
+		commonParams.FormatFlag,
+		"",
+		fmt.Sprintf(
+			commonParams.FormatFlagUsageFormat,
+			[]string{printer.FormatTable, printer.FormatJSON, printer.FormatList},
+		),
+	)
+	scaDBCmd.AddCommand(syncCmd)
+	return scaDBCmd
+}
+
+func runScaDBSync(scaDBWrapper wrappers.ScaDBWrapper) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		version, _ := cmd.Flags().GetString(commonParams.ScaDBVersion)
+		format, _ := cmd.Flags().GetString(commonParams.FormatFlag)
+		if format == "" {
+			format = defaultFormat
+		}
+		if viper.GetString(commonParams.ScaDBURLKey) == "" || viper.GetString(commonParams.ScaDBPublicKeyKey) == "" {
+			return errors.Errorf(scaDBNotConfigured, commonParams.ScaDBURLEnv, commonParams.ScaDBPublicKeyEnv)
+		}
+		dir, err := scadb.Dir()
+		if err != nil {
+			return err
+		}
+		manifest, err := scaDBWrapper.GetManifest(version)
+		if err != nil {
+			return err
+		}
+		view := &ScaDBView{Version: manifest.Version, Published: manifest.Published, Directory: dir, Status: scaDBUpToDate}
+		installed, err := scadb.InstalledManifest(dir)
+		if err != nil {
+			return err
+		}
+		if installed == nil || installed.Version != manifest.Version || installed.SHA256 != manifest.SHA256 {
+			snapshot, err := scaDBWrapper.DownloadSnapshot(manifest)
+			if err != nil {
+				return err
+			}
+			if err = scadb.Verify(manifest, snapshot); err != nil {
+				return err
+			}
+			if err = scadb.Install(dir, manifest, snapshot); err != nil {
+				return err
+			}
+			view.Status = scaDBInstalled
+		}
+		return printer.Print(cmd.OutOrStdout(), view, format)
+	}
+}
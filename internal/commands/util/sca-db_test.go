@@ -0,0 +1,148 @@
+package util
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/mock"
+	"github.com/checkmarx/ast-cli/internal/wrappers/scadb"
+	"github.com/spf13/viper"
+	"gotest.tools/assert"
+)
+
+// newScaDBMock serves a signed snapshot as the latest version of the database, in a temporary directory
+func newScaDBMock(t *testing.T) *mock.ScaDBMockWrapper {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NilError(t, err)
+	viper.Set(params.ScaDBPublicKeyKey, base64.StdEncoding.EncodeToString(publicKey))
+	viper.Set(params.ScaDBDirKey, filepath.Join(t.TempDir(), "sca-db"))
+	viper.Set(params.ScaDBURLKey, "https://example.com")
+	t.Cleanup(func() {
+		viper.Set(params.ScaDBPublicKeyKey, "")
+		viper.Set(params.ScaDBDirKey, "")
+		viper.Set(params.ScaDBURLKey, "")
+	})
+	content, err := json.Marshal(scadb.Snapshot{Version: "2024.05.01", Packages: []scadb.Package{
+		{PackageManager: "Npm", PackageName: "lodash", Vulnerabilities: []scadb.Advisory{{AffectedVersions: []string{"<4.17.21"}}}},
+	}})
+	assert.NilError(t, err)
+	digest := sha256.Sum256(content)
+	manifest := &wrappers.ScaDBManifest{
+		Version:   "2024.05.01",
+		Published: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		URL:       "https://example.com/2024.05.01/snapshot.json",
+		SHA256:    hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, content)),
+	}
+	return &mock.ScaDBMockWrapper{
+		Manifests: map[string]*wrappers.ScaDBManifest{"latest": manifest, manifest.Version: manifest},
+		Snapshots: map[string][]byte{manifest.URL: content},
+	}
+}
+
+func runScaDBSyncCommand(t *testing.T, wrapper wrappers.ScaDBWrapper, args ...string) (*ScaDBView, error) {
+	cmd := NewScaDBCommand(wrapper)
+	var output bytes.Buffer
+	cmd.SetOut(&output)
+	cmd.SetArgs(append([]string{"sync", "--format", "json"}, args...))
+	err := cmd.Execute()
+	if err != nil {
+		return nil, err
+	}
+	view := &ScaDBView{}
+	assert.NilError(t, json.NewDecoder(&output).Decode(view))
+	return view, nil
+}
+
+func TestScaDBSync(t *testing.T) {
+	wrapper := newScaDBMock(t)
+	view, err := runScaDBSyncCommand(t, wrapper)
+	assert.NilError(t, err)
+	assert.Equal(t, view.Version, "2024.05.01")
+	assert.Equal(t, view.Status, scaDBInstalled)
+	assert.Equal(t, view.Directory, viper.GetString(params.ScaDBDirKey))
+
+	db, err := scadb.Open(view.Directory)
+	assert.NilError(t, err)
+	assert.Equal(t, len(db.Vulnerabilities("Npm", "lodash", "4.17.20")), 1)
+
+	// the installed version is not downloaded again
+	view, err = runScaDBSyncCommand(t, wrapper, "--db-version", "2024.05.01")
+	assert.NilError(t, err)
+	assert.Equal(t, view.Status, scaDBUpToDate)
+	assert.Equal(t, wrapper.Downloads, 1)
+}
+
+func TestScaDBSyncRejectsUnsignedSnapshot(t *testing.T) {
+	wrapper := newScaDBMock(t)
+	wrapper.Manifests["latest"].Signature = base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))
+	_, err := runScaDBSyncCommand(t, wrapper)
+	assert.Error(t, err, "The signature of version 2024.05.01 of the SCA vulnerability database is invalid")
+	manifest, err := scadb.InstalledManifest(viper.GetString(params.ScaDBDirKey))
+	assert.NilError(t, err)
+	assert.Assert(t, manifest == nil)
+}
+
+func TestScaDBSyncUnknownVersion(t *testing.T) {
+	_, err := runScaDBSyncCommand(t, newScaDBMock(t), "--db-version", "2020.01.01")
+	assert.Error(t, err, "Version 2020.01.01 of the SCA vulnerability database was not found")
+}
+
+func TestScaDBSyncNotConfigured(t *testing.T) {
+	wrapper := newScaDBMock(t)
+	viper.Set(params.ScaDBURLKey, "")
+	_, err := runScaDBSyncCommand(t, wrapper)
+	assert.ErrorContains(t, err, "has no default download location, set "+params.ScaDBURLEnv)
+
+	viper.Set(params.ScaDBURLKey, "https://example.com")
+	viper.Set(params.ScaDBPublicKeyKey, "")
+	_, err = runScaDBSyncCommand(t, wrapper)
+	assert.ErrorContains(t, err, params.ScaDBPublicKeyEnv+" to the base64 ed25519 public key")
+	assert.Equal(t, wrapper.Downloads, 0)
+}
+
+func TestScaDBSyncFromMirror(t *testing.T) {
+	wrapper := newScaDBMock(t)
+	manifest := *wrapper.Manifests["latest"]
+	snapshot := wrapper.Snapshots[manifest.URL]
+	manifest.URL = "snapshot.json"
+	manifestContent, err := json.Marshal(manifest)
+	assert.NilError(t, err)
+	files := map[string][]byte{
+		"/sca-db/latest/manifest.json":     manifestContent,
+		"/sca-db/2024.05.01/manifest.json": manifestContent,
+		"/sca-db/latest/snapshot.json":     snapshot,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, found := files[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+	viper.Set(params.ScaDBURLKey, server.URL+"/sca-db/")
+
+	view, err := runScaDBSyncCommand(t, wrappers.NewScaDBHTTPWrapper())
+	assert.NilError(t, err)
+	assert.Equal(t, view.Version, "2024.05.01")
+	assert.Equal(t, view.Status, scaDBInstalled)
+	db, err := scadb.Open(view.Directory)
+	assert.NilError(t, err)
+	assert.Equal(t, len(db.Vulnerabilities("Npm", "lodash", "4.17.20")), 1)
+
+	_, err = runScaDBSyncCommand(t, wrappers.NewScaDBHTTPWrapper(), "--db-version", "2020.01.01")
+	assert.Error(t, err, "Version 2020.01.01 of the SCA vulnerability database was not found")
+}
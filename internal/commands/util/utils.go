@@ -9,6 +9,7 @@ import (
 	"github.com/checkmarx/ast-cli/internal/commands/util/usercount"
 	"github.com/checkmarx/ast-cli/internal/wrappers"
 	"github.com/checkmarx/ast-cli/internal/wrappers/bitbucketserver"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +29,10 @@ func NewUtilsCommand(
 	learnMoreWrapper wrappers.LearnMoreWrapper,
 	tenantWrapper wrappers.TenantConfigurationWrapper,
 	chatWrapper wrappers.ChatWrapper,
+	resultsWrapper wrappers.ResultsWrapper,
+	npmRegistryWrapper wrappers.NpmRegistryWrapper,
+	scaDBWrapper wrappers.ScaDBWrapper,
+	containerProvider container.Provider,
 ) *cobra.Command {
 	utilsCmd := &cobra.Command{
 		Use:   "utils",
@@ -52,7 +57,7 @@ func NewUtilsCommand(
 
 	prDecorationCmd := NewPRDecorationCommand(prWrapper)
 
-	remediationCmd := NewRemediationCommand()
+	remediationCmd := NewRemediationCommand(resultsWrapper, npmRegistryWrapper, containerProvider)
 
 	learnMoreCmd := NewLearnMoreCommand(learnMoreWrapper)
 
@@ -60,6 +65,8 @@ func NewUtilsCommand(
 
 	maskSecretsCmd := NewMaskSecretsCommand(chatWrapper)
 
+	scaDBCmd := NewScaDBCommand(scaDBWrapper)
+
 	utilsCmd.AddCommand(
 		completionCmd,
 		envCheckCmd,
@@ -75,6 +82,7 @@ func NewUtilsCommand(
 		remediationCmd,
 		tenantCmd,
 		maskSecretsCmd,
+		scaDBCmd,
 	)
 
 	return utilsCmd
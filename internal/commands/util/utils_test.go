@@ -9,6 +9,6 @@ import (
 const mockFormatErrorMessage = "Invalid format MOCK"
 
 func TestNewUtilsCommand(t *testing.T) {
-	cmd := NewUtilsCommand(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	cmd := NewUtilsCommand(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Assert(t, cmd != nil, "Utils command must exist")
 }
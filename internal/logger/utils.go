@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"strings"
+	"sync"
 	"unicode/utf8"
 
 	"github.com/checkmarx/ast-cli/internal/params"
@@ -22,6 +23,11 @@ var sanitizeFlags = []string{
 	params.UploadURLEnv,
 }
 
+var (
+	sanitizeValues     []string
+	sanitizeValuesLock sync.Mutex
+)
+
 func Print(msg string) {
 	if utf8.Valid([]byte(msg)) {
 		log.Print(sanitizeLogs(msg))
@@ -64,6 +70,17 @@ func PrintResponse(r *http.Response, body bool) {
 	PrintIfVerbose(string(requestDump))
 }
 
+// SanitizeValue hides a value from the logs, used for secrets that are not held by a flag or property,
+// e.g. resolved from a secret backend
+func SanitizeValue(value string) {
+	if len(value) == 0 {
+		return
+	}
+	sanitizeValuesLock.Lock()
+	defer sanitizeValuesLock.Unlock()
+	sanitizeValues = append(sanitizeValues, value)
+}
+
 func sanitizeLogs(msg string) string {
 	for _, flag := range sanitizeFlags {
 		value := viper.GetString(flag)
@@ -71,5 +88,10 @@ func sanitizeLogs(msg string) string {
 			msg = strings.ReplaceAll(msg, value, "***")
 		}
 	}
+	sanitizeValuesLock.Lock()
+	defer sanitizeValuesLock.Unlock()
+	for _, value := range sanitizeValues {
+		msg = strings.ReplaceAll(msg, value, "***")
+	}
 	return msg
 }
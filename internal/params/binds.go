@@ -8,9 +8,17 @@ var EnvVarsBinds = []struct {
 	{BaseURIKey, BaseURIEnv, ""},
 	{ProxyTypeKey, ProxyTypeEnv, "basic"},
 	{ProxyDomainKey, ProxyDomainEnv, ""},
+	{KerberosKeytabKey, KerberosKeytabEnv, ""},
+	{KerberosPrincipalKey, KerberosPrincipalEnv, ""},
+	{KerberosKrb5ConfKey, KerberosKrb5ConfEnv, ""},
+	{KerberosCCacheKey, KerberosCCacheEnv, ""},
 	{BaseAuthURIKey, BaseAuthURIEnv, ""},
 	{AstAPIKey, AstAPIKeyEnv, ""},
 	{IgnoreProxyKey, IgnoreProxyEnv, ""},
+	{ProxyPacKey, ProxyPacEnv, ""},
+	{ProxyHostsKey, ProxyHostsEnv, ""},
+	{SecretsBackendKey, SecretsBackendEnv, "file"},
+	{SecretsFileKey, SecretsFileEnv, ""},
 	{AgentNameKey, AgentNameEnv, "ASTCLI"},
 	{CodeBashingPathKey, ScansPathEnv, "api/codebashing/lessons"},
 	{ScansPathKey, ScansPathEnv, "api/scans"},
@@ -60,4 +68,12 @@ var EnvVarsBinds = []struct {
 	{ResultsSbomReportProxyPathKey, ResultsSbomReportProxyPathEnv, "api/sca/risk-management/risk-reports"},
 	{FeatureFlagsKey, FeatureFlagsEnv, "api/flags"},
 	{PolicyEvaluationPathKey, PolicyEvaluationPathEnv, "api/policy_management_service_uri/evaluation"},
+	{NpmRegistryKey, NpmRegistryEnv, "https://registry.npmjs.org"},
+	{ScaDBURLKey, ScaDBURLEnv, ""},
+	{ScaDBDirKey, ScaDBDirEnv, ""},
+	{ScaDBPublicKeyKey, ScaDBPublicKeyEnv, ""},
+	{ScaResolverMirrorKey, ScaResolverMirrorEnv, "https://sca-downloads.s3.amazonaws.com/cli"},
+	{ScaResolverPublicKeyKey, ScaResolverPublicKeyEnv, ""},
+	{ScaResolverCacheDirKey, ScaResolverCacheDirEnv, ""},
+	{ScaResolverCacheSizeKey, ScaResolverCacheSizeEnv, "3"},
 }
@@ -2,6 +2,7 @@ package params
 
 const (
 	TenantEnv                           = "CX_TENANT"
+	ProfileEnv                          = "CX_PROFILE"
 	BranchEnv                           = "CX_BRANCH"
 	BaseURIEnv                          = "CX_BASE_URI"
 	ClientTimeoutEnv                    = "CX_TIMEOUT"
@@ -9,6 +10,10 @@ const (
 	CxProxyEnv                          = "CX_HTTP_PROXY"
 	ProxyTypeEnv                        = "CX_PROXY_AUTH_TYPE"
 	ProxyDomainEnv                      = "CX_PROXY_NTLM_DOMAIN"
+	KerberosKeytabEnv                   = "CX_PROXY_KERBEROS_KEYTAB"
+	KerberosPrincipalEnv                = "CX_PROXY_KERBEROS_PRINCIPAL"
+	KerberosKrb5ConfEnv                 = "CX_PROXY_KERBEROS_KRB5_CONF"
+	KerberosCCacheEnv                   = "CX_PROXY_KERBEROS_CCACHE"
 	BaseAuthURIEnv                      = "CX_BASE_AUTH_URI"
 	AstAPIKeyEnv                        = "CX_APIKEY"
 	AccessKeyIDEnv                      = "CX_CLIENT_ID"
@@ -59,4 +64,20 @@ const (
 	UploadURLEnv                        = "CX_UPLOAD_URL"
 	PolicyEvaluationPathEnv             = "CX_POLICY_EVALUATION_PATH"
 	IgnoreProxyEnv                      = "CX_IGNORE_PROXY"
+	NoProxyEnv                          = "NO_PROXY"
+	NoProxyLowerCaseEnv                 = "no_proxy"
+	CxNoProxyEnv                        = "CX_NO_PROXY"
+	ProxyPacEnv                         = "CX_PROXY_PAC"
+	ProxyHostsEnv                       = "CX_PROXY_HOSTS"
+	SecretsBackendEnv                   = "CX_SECRETS_BACKEND"
+	SecretsFileEnv                      = "CX_SECRETS_FILE"
+	SecretsPassphraseEnv                = "CX_SECRETS_PASSPHRASE"
+	NpmRegistryEnv                      = "CX_NPM_REGISTRY"
+	ScaDBURLEnv                         = "CX_SCA_DB_URL"
+	ScaDBDirEnv                         = "CX_SCA_DB_DIR"
+	ScaDBPublicKeyEnv                   = "CX_SCA_DB_PUBLIC_KEY"
+	ScaResolverMirrorEnv                = "CX_SCA_RESOLVER_MIRROR"
+	ScaResolverPublicKeyEnv             = "CX_SCA_RESOLVER_PUBLIC_KEY"
+	ScaResolverCacheDirEnv              = "CX_SCA_RESOLVER_CACHE_DIR"
+	ScaResolverCacheSizeEnv             = "CX_SCA_RESOLVER_CACHE_SIZE"
 )
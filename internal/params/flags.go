@@ -50,12 +50,24 @@ const (
 	ProxyFlagUsage                = "Proxy server to send communication through"
 	IgnoreProxyFlag               = "ignore-proxy"
 	IgnoreProxyFlagUsage          = "Ignore proxy configuration"
+	ProxyPacFlag                  = "proxy-pac"
+	ProxyPacFlagUsage             = "URL or file path of a proxy auto-config (PAC) file used to select the proxy per request"
+	ExplainProxyFlag              = "explain-proxy"
+	ExplainProxyFlagUsage         = "Print the proxy route that would be taken for the given URL"
 	ProxyTypeFlag                 = "proxy-auth-type"
-	ProxyTypeFlagUsage            = "Proxy authentication type, (basic or ntlm)"
+	ProxyTypeFlagUsage            = "Proxy authentication type, (basic, ntlm or negotiate)"
 	TimeoutFlag                   = "timeout"
 	TimeoutFlagUsage              = "Timeout for network activity, (default 5 seconds)"
 	NtlmProxyDomainFlag           = "proxy-ntlm-domain"
 	NtlmProxyDomainFlagUsage      = "Window domain when using NTLM proxy"
+	KerberosKeytabFlag            = "proxy-kerberos-keytab"
+	KerberosKeytabFlagUsage       = "Kerberos keytab used for negotiate proxy authentication, instead of the credential cache"
+	KerberosPrincipalFlag         = "proxy-kerberos-principal"
+	KerberosPrincipalFlagUsage    = "Kerberos principal (user@REALM) of the keytab used for negotiate proxy authentication"
+	KerberosKrb5ConfFlag          = "proxy-kerberos-krb5-conf"
+	KerberosKrb5ConfFlagUsage     = "Kerberos configuration file used for negotiate proxy authentication, (default /etc/krb5.conf)"
+	KerberosCCacheFlag            = "proxy-kerberos-ccache"
+	KerberosCCacheFlagUsage       = "Kerberos credential cache used for negotiate proxy authentication, (default KRB5CCNAME)"
 	BaseURIFlagUsage              = "The base system URI"
 	BaseAuthURIFlag               = "base-auth-uri"
 	BaseAuthURIFlagUsage          = "The base system IAM URI"
@@ -70,13 +82,15 @@ const (
 	PasswordFlag                  = "password"
 	PasswordSh                    = "p"
 	ProfileFlag                   = "profile"
-	ProfileFlagUsage              = "The default configuration profile"
+	ProfileFlagUsage              = "The configuration profile to use, overrides CX_PROFILE and 'cx configure profile use'"
 	Help                          = "help"
 	TargetFlag                    = "output-name"
 	TargetPathFlag                = "output-path"
 	TargetFormatFlag              = "report-format"
 	ReportFormatPdfToEmailFlag    = "report-pdf-email"
 	ReportFormatPdfOptionsFlag    = "report-pdf-options"
+	ReportFormatPdfModeFlag       = "report-pdf-mode"
+	ReportColumnsFlag             = "report-columns"
 	ReportSbomFormatFlag          = "report-sbom-format"
 	ReportSbomFormatLocalFlowFlag = "report-sbom-local-flow"
 	ProjectName                   = "project-name"
@@ -86,14 +100,29 @@ const (
 	KicsRealtimeFile              = "file"
 	KicsRealtimeEngine            = "engine"
 	KicsRealtimeAdditionalParams  = "additional-params"
+	KicsRealtimeInclude           = "include"
+	KicsRealtimeExclude           = "exclude"
+	KicsRealtimeOutputFormat      = "output-format"
+	KicsRealtimeFailOn            = "fail-on"
+	KicsRealtimeImage             = "kics-image"
 	ScaRealtimeProjectDir         = "project-dir"
 	ScaRealtimeProjectDirSh       = "p"
+	ScaRealtimeResolver           = "resolver"
+	ScaRealtimeOffline            = "offline"
+	ScaResolverVersion            = "sca-resolver-version"
+	ScaDBVersion                  = "db-version"
 	RemediationFiles              = "package-files"
 	KicsRemediationFile           = "results-file"
 	KicsProjectFile               = "kics-files"
 	KicsSimilarityFilter          = "similarity-ids"
 	RemediationPackage            = "package"
 	RemediationPackageVersion     = "package-version"
+	RemediationDryRun             = "dry-run"
+	RemediationResultsFile        = "results-file"
+	RemediationPatchFile          = "patch-file"
+	RemediationInteractive        = "interactive"
+	RemediationReportFile         = "report-file"
+	RemediationTransitiveStrategy = "transitive-strategy"
 	TagList                       = "tags"
 	GroupList                     = "groups"
 	ProjectGroupList              = "project-groups"
@@ -135,6 +164,13 @@ const (
 	LastSastScanTime         = "sca-last-sast-scan-time"
 	ProjecPrivatePackageFlag = "project-private-package"
 	SastRedundancyFlag       = "sast-redundancy"
+	ScaPathsPackageFlag      = "package"
+	BaseScanIDFlag           = "base-scan-id"
+	ResultsImportFileFlag    = "file"
+	ComplianceMappingFlag    = "compliance-mapping"
+	ProjectIDsFlag           = "project-ids"
+	ParallelismFlag          = "parallelism"
+	SinceFlag                = "since"
 
 	ScaPrivatePackageVersionFlag = "sca-private-package-version"
 
@@ -196,6 +232,7 @@ const (
 	StatusesQueryParam         = "statuses"
 	StatusQueryParam           = "status"
 	BranchNameQueryParam       = "branch-name"
+	BranchQueryParam           = "branch"
 	ProjectIDQueryParam        = "project-id"
 	FromDateQueryParam         = "from-date"
 	ToDateQueryParam           = "to-date"
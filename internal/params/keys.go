@@ -9,6 +9,10 @@ var (
 	ProxyKey                            = strings.ToLower(ProxyEnv)
 	ProxyTypeKey                        = strings.ToLower(ProxyTypeEnv)
 	ProxyDomainKey                      = strings.ToLower(ProxyDomainEnv)
+	KerberosKeytabKey                   = strings.ToLower(KerberosKeytabEnv)
+	KerberosPrincipalKey                = strings.ToLower(KerberosPrincipalEnv)
+	KerberosKrb5ConfKey                 = strings.ToLower(KerberosKrb5ConfEnv)
+	KerberosCCacheKey                   = strings.ToLower(KerberosCCacheEnv)
 	BaseAuthURIKey                      = strings.ToLower(BaseAuthURIEnv)
 	ClientTimeoutKey                    = strings.ToLower(ClientTimeoutEnv)
 	AstAPIKey                           = strings.ToLower(AstAPIKeyEnv)
@@ -16,6 +20,11 @@ var (
 	GroupsPathKey                       = strings.ToLower(GroupsPathEnv)
 	AgentNameKey                        = strings.ToLower(AgentNameEnv)
 	IgnoreProxyKey                      = strings.ToLower(IgnoreProxyEnv)
+	NoProxyKey                          = strings.ToLower(NoProxyEnv)
+	ProxyPacKey                         = strings.ToLower(ProxyPacEnv)
+	ProxyHostsKey                       = strings.ToLower(ProxyHostsEnv)
+	SecretsBackendKey                   = strings.ToLower(SecretsBackendEnv)
+	SecretsFileKey                      = strings.ToLower(SecretsFileEnv)
 	CodeBashingPathKey                  = strings.ToLower(CodeBashingPathEnv)
 	ProjectsPathKey                     = strings.ToLower(ProjectsPathEnv)
 	ResultsPathKey                      = strings.ToLower(ResultsPathEnv)
@@ -59,4 +68,12 @@ var (
 	ResultsSbomReportProxyPathKey       = strings.ToLower(ResultsSbomReportProxyPathEnv)
 	FeatureFlagsKey                     = strings.ToLower(FeatureFlagsEnv)
 	PolicyEvaluationPathKey             = strings.ToLower(PolicyEvaluationPathEnv)
+	NpmRegistryKey                      = strings.ToLower(NpmRegistryEnv)
+	ScaDBURLKey                         = strings.ToLower(ScaDBURLEnv)
+	ScaDBDirKey                         = strings.ToLower(ScaDBDirEnv)
+	ScaDBPublicKeyKey                   = strings.ToLower(ScaDBPublicKeyEnv)
+	ScaResolverMirrorKey                = strings.ToLower(ScaResolverMirrorEnv)
+	ScaResolverPublicKeyKey             = strings.ToLower(ScaResolverPublicKeyEnv)
+	ScaResolverCacheDirKey              = strings.ToLower(ScaResolverCacheDirEnv)
+	ScaResolverCacheSizeKey             = strings.ToLower(ScaResolverCacheSizeEnv)
 )
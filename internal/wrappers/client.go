@@ -2,6 +2,7 @@ package wrappers
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"net/http/httptrace"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -19,15 +21,20 @@ import (
 	"github.com/checkmarx/ast-cli/internal/logger"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"golang.org/x/net/proxy"
 
 	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers/negotiate"
 	"github.com/checkmarx/ast-cli/internal/wrappers/ntlm"
+	"github.com/checkmarx/ast-cli/internal/wrappers/secrets"
 )
 
 const (
 	expiryGraceSeconds      = 10
 	NoTimeout               = 0
 	ntlmProxyToken          = "ntlm"
+	negotiateProxyToken     = "negotiate"
+	socks5Scheme            = "socks5"
 	checkmarxURLError       = "Could not reach provided Checkmarx server"
 	invalidCredentialsError = "Provided credentials are invalid"
 	APIKeyDecodeErrorFormat = "Token decoding error: %s"
@@ -65,8 +72,12 @@ const baseURLKey = "ast-base-url"
 
 const audienceClaimKey = "aud"
 
-var cachedAccessToken string
-var cachedAccessTime time.Time
+var (
+	cachedAccessToken string
+	cachedAccessTime  time.Time
+	// accessTokenLock guards the cached access token, requests sent concurrently wait for the one fetching it
+	accessTokenLock sync.Mutex
+)
 
 func setAgentName(req *http.Request) {
 	agentStr := viper.GetString(commonParams.AgentNameKey) + "/" + commonParams.Version
@@ -75,16 +86,14 @@ func setAgentName(req *http.Request) {
 
 func GetClient(timeout uint) *http.Client {
 	proxyTypeStr := viper.GetString(commonParams.ProxyTypeKey)
-	proxyStr := viper.GetString(commonParams.ProxyKey)
-	ignoreProxy := viper.GetBool(commonParams.IgnoreProxyKey)
 
 	var client *http.Client
-	if ignoreProxy {
-		client = basicProxyClient(timeout, "")
-	} else if proxyTypeStr == ntlmProxyToken {
-		client = ntmlProxyClient(timeout, proxyStr)
+	if proxyTypeStr == ntlmProxyToken {
+		client = ntmlProxyClient(timeout)
+	} else if proxyTypeStr == negotiateProxyToken {
+		client = negotiateProxyClient(timeout)
 	} else {
-		client = basicProxyClient(timeout, proxyStr)
+		client = basicProxyClient(timeout)
 	}
 
 	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
@@ -105,45 +114,115 @@ func GetClient(timeout uint) *http.Client {
 	return client
 }
 
-func basicProxyClient(timeout uint, proxyStr string) *http.Client {
+func basicProxyClient(timeout uint) *http.Client {
 	insecure := viper.GetBool("insecure")
-	u, _ := url.Parse(proxyStr)
-	var tr *http.Transport
-	if len(proxyStr) > 0 {
-		logger.PrintIfVerbose("Creating HTTP Client with Proxy: " + proxyStr)
-		tr = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-			Proxy:           http.ProxyURL(u),
-		}
-	} else {
-		logger.PrintIfVerbose("Creating HTTP Client.")
-		tr = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
-		}
+	logger.PrintIfVerbose("Creating HTTP Client.")
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+		Proxy:           proxyForRequest,
 	}
 	return &http.Client{Transport: tr, Timeout: time.Duration(timeout) * time.Second}
 }
 
-func ntmlProxyClient(timeout uint, proxyStr string) *http.Client {
+func ntmlProxyClient(timeout uint) *http.Client {
+	logger.PrintIfVerbose("Creating HTTP client using NTLM Proxy.")
+	return authenticatedProxyClient(timeout, ntlmProxyDialContext)
+}
+
+func negotiateProxyClient(timeout uint) *http.Client {
+	logger.PrintIfVerbose("Creating HTTP client using Negotiate (Kerberos) Proxy.")
+	tokenProvider := negotiate.NewKerberosTokenProvider(negotiate.KerberosSettings{
+		Krb5Conf:  viper.GetString(commonParams.KerberosKrb5ConfKey),
+		Keytab:    viper.GetString(commonParams.KerberosKeytabKey),
+		Principal: viper.GetString(commonParams.KerberosPrincipalKey),
+		CCache:    viper.GetString(commonParams.KerberosCCacheKey),
+	})
+	return authenticatedProxyClient(timeout, func(dialer *net.Dialer, proxyURL *url.URL) ntlm.DialContext {
+		ntlmFallback := ntlmProxyDialContext(dialer, proxyURL)
+		return negotiate.NewNegotiateProxyDialContext(dialer, proxyURL, tokenProvider, ntlmFallback, nil)
+	})
+}
+
+func ntlmProxyDialContext(dialer *net.Dialer, proxyURL *url.URL) ntlm.DialContext {
+	domainStr := viper.GetString(commonParams.ProxyDomainKey)
+	proxyUser := proxyURL.User.Username()
+	proxyPass, _ := proxyURL.User.Password()
+	return ntlm.NewNTLMProxyDialContext(dialer, proxyURL, proxyUser, proxyPass, domainStr, nil)
+}
+
+// proxyTargetKey holds the URL of the request in the context its connection is dialed with
+type proxyTargetKey struct{}
+
+// proxyTargetTransport keeps the URL of each request in its context, so the dialer resolves the proxy route
+// of the request itself and not only of its address
+type proxyTargetTransport struct {
+	*http.Transport
+}
+
+func (t *proxyTargetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.Transport.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyTargetKey{}, req.URL)))
+}
+
+// proxyTarget is the URL of the request being dialed, else a URL built from the address with the scheme of its port
+func proxyTarget(ctx context.Context, addr string) *url.URL {
+	if target, ok := ctx.Value(proxyTargetKey{}).(*url.URL); ok && target != nil {
+		return target
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return &url.URL{Scheme: "https", Host: addr}
+	}
+	switch port {
+	case "80":
+		return &url.URL{Scheme: "http", Host: host}
+	case "443":
+		return &url.URL{Scheme: "https", Host: host}
+	default:
+		return &url.URL{Scheme: "https", Host: addr}
+	}
+}
+
+// authenticatedProxyClient tunnels through proxies that need a connection based handshake, the proxy is
+// resolved per connection so routes that bypass the proxy are dialed directly.
+func authenticatedProxyClient(timeout uint, proxyDialContext func(dialer *net.Dialer, proxyURL *url.URL) ntlm.DialContext) *http.Client {
 	dialer := &net.Dialer{
 		Timeout:   30 * time.Second,
 		KeepAlive: 30 * time.Second,
 	}
-	u, _ := url.Parse(proxyStr)
-	domainStr := viper.GetString(commonParams.ProxyDomainKey)
-	proxyUser := u.User.Username()
-	proxyPass, _ := u.User.Password()
-	logger.PrintIfVerbose("Creating HTTP client using NTLM Proxy using: " + proxyStr)
-	ntlmDialContext := ntlm.NewNTLMProxyDialContext(dialer, u, proxyUser, proxyPass, domainStr, nil)
 	return &http.Client{
-		Transport: &http.Transport{
-			Proxy:       nil,
-			DialContext: ntlmDialContext,
-		},
+		Transport: &proxyTargetTransport{Transport: &http.Transport{
+			Proxy: nil,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				route, err := ResolveProxyRoute(proxyTarget(ctx, addr))
+				if err != nil {
+					return nil, err
+				}
+				logger.PrintIfVerbose(fmt.Sprintf("Proxy route for %s: %s (%s)", addr, route, route.Reason))
+				if route.Proxy == nil {
+					return dialer.DialContext(ctx, network, addr)
+				}
+				// SOCKS proxies take no part in the NTLM or Negotiate handshake of HTTP proxies
+				if route.Proxy.Scheme == socks5Scheme {
+					return socksDialContext(ctx, dialer, route.Proxy, network, addr)
+				}
+				return proxyDialContext(dialer, route.Proxy)(ctx, network, addr)
+			},
+		}},
 		Timeout: time.Duration(timeout) * time.Second,
 	}
 }
 
+func socksDialContext(ctx context.Context, dialer *net.Dialer, proxyURL *url.URL, network, addr string) (net.Conn, error) {
+	socksDialer, err := proxy.FromURL(proxyURL, dialer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid SOCKS proxy %s", proxyURL.Redacted())
+	}
+	if contextDialer, ok := socksDialer.(proxy.ContextDialer); ok {
+		return contextDialer.DialContext(ctx, network, addr)
+	}
+	return socksDialer.Dial(network, addr)
+}
+
 func getURLAndAccessToken(path string) (urlFromPath, accessToken string, err error) {
 	accessToken, err = GetAccessToken()
 	if err != nil {
@@ -378,10 +457,18 @@ func GetAccessToken() (string, error) {
 		return "", err
 	}
 	tokenExpirySeconds := viper.GetInt(commonParams.TokenExpirySecondsKey)
+	accessTokenLock.Lock()
 	accessToken := getClientCredentialsFromCache(tokenExpirySeconds)
+	accessTokenLock.Unlock()
 	accessKeyID := viper.GetString(commonParams.AccessKeyIDConfigKey)
-	accessKeySecret := viper.GetString(commonParams.AccessKeySecretConfigKey)
-	astAPIKey := viper.GetString(commonParams.AstAPIKey)
+	accessKeySecret, err := GetSecretProperty(commonParams.AccessKeySecretConfigKey)
+	if err != nil {
+		return "", err
+	}
+	astAPIKey, err := GetSecretProperty(commonParams.AstAPIKey)
+	if err != nil {
+		return "", err
+	}
 	if accessKeyID == "" && astAPIKey == "" {
 		return "", errors.Errorf(fmt.Sprintf(FailedToAuth, "access key ID"))
 	} else if accessKeySecret == "" && astAPIKey == "" {
@@ -419,11 +506,19 @@ func enrichWithPasswordCredentials(
 	return nil
 }
 
+// GetSecretProperty returns a credential property, resolving it when the configuration holds a reference
+// to a secret backend instead of the secret itself
+func GetSecretProperty(key string) (string, error) {
+	return secrets.Resolve(viper.GetString(key))
+}
+
 func getClientCredentials(accessKeyID, accessKeySecret, astAPKey, authURI string) (string, error) {
 	logger.PrintIfVerbose("Fetching API access token.")
 	tokenExpirySeconds := viper.GetInt(commonParams.TokenExpirySecondsKey)
 
 	var err error
+	accessTokenLock.Lock()
+	defer accessTokenLock.Unlock()
 	accessToken := getClientCredentialsFromCache(tokenExpirySeconds)
 
 	if accessToken == "" {
@@ -444,6 +539,7 @@ func getClientCredentials(accessKeyID, accessKeySecret, astAPKey, authURI string
 	return accessToken, nil
 }
 
+// getClientCredentialsFromCache returns the cached access token unless it expired, the caller holds accessTokenLock
 func getClientCredentialsFromCache(tokenExpirySeconds int) string {
 	logger.PrintIfVerbose("Checking cache for API access token.")
 	expired := time.Since(cachedAccessTime) > time.Duration(tokenExpirySeconds-expiryGraceSeconds)*time.Second
@@ -455,9 +551,11 @@ func getClientCredentialsFromCache(tokenExpirySeconds int) string {
 	return ""
 }
 
+// writeCredentialsToCache caches the access token, the caller holds accessTokenLock. The token is hidden from
+// the logs without changing the configuration, which concurrent requests read.
 func writeCredentialsToCache(accessToken string) {
 	logger.PrintIfVerbose("Storing API access token to cache.")
-	viper.Set(commonParams.AstToken, accessToken)
+	logger.SanitizeValue(accessToken)
 	cachedAccessToken = accessToken
 	cachedAccessTime = time.Now()
 }
@@ -585,7 +683,10 @@ func getAuthURI() (string, error) {
 	var err error
 	override := viper.GetBool(commonParams.ApikeyOverrideFlag)
 
-	apiKey := viper.GetString(commonParams.AstAPIKey)
+	apiKey, err := GetSecretProperty(commonParams.AstAPIKey)
+	if err != nil {
+		return "", err
+	}
 	if len(apiKey) > 0 {
 		logger.PrintIfVerbose("Base Auth URI - Extract from API KEY")
 		authURI, err = extractFromTokenClaims(apiKey, audienceClaimKey)
@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers/secrets"
+	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
 
@@ -16,76 +18,180 @@ const configDirName = "/.checkmarx"
 const obfuscateLimit = 4
 const homeDirectoryPermissions = 0700
 
-func PromptConfiguration() {
+var secretProperties = map[string]bool{
+	params.AccessKeySecretConfigKey:            true,
+	params.AstAPIKey:                           true,
+	strings.ToLower(params.AccessKeySecretEnv): true,
+	strings.ToLower(params.AstAPIKeyEnv):       true,
+}
+
+// secretPaths names the secret of each credential property within a secret backend
+var secretPaths = map[string]string{
+	params.AccessKeySecretConfigKey: "client-secret",
+	params.AstAPIKey:                "apikey",
+}
+
+// connectionProperties are the properties set by configure, the secrets come first so they are stored in the
+// secret backend before the profile is written
+var connectionProperties = []string{
+	params.AstAPIKey,
+	params.AccessKeySecretConfigKey,
+	params.BaseURIKey,
+	params.BaseAuthURIKey,
+	params.TenantKey,
+	params.AccessKeyIDConfigKey,
+}
+
+// PromptConfiguration asks for each property, re-prompting values that fail validation. When check is set
+// it runs after the prompts, and on failure the user can go through the prompts again. The answers are only
+// written to the profile once check succeeds, otherwise the previous values are restored.
+func PromptConfiguration(check func() error) error {
 	reader := bufio.NewReader(os.Stdin)
-	baseURI := viper.GetString(params.BaseURIKey)
+	fmt.Print("Setup guide: https://checkmarx.com/resource/documents/en/34965-68621-checkmarx-one-cli-quick-start-guide.html\n\n")
+	previous := map[string]string{}
+	for _, propName := range connectionProperties {
+		previous[propName] = viper.GetString(propName)
+	}
+	changed := map[string]string{}
+	for {
+		promptProperties(reader, changed)
+		if check == nil {
+			return writeProperties(changed)
+		}
+		err := check()
+		if err == nil {
+			fmt.Println("Connection test succeeded")
+			return writeProperties(changed)
+		}
+		fmt.Printf("Connection test failed: %v\n", err)
+		if !strings.EqualFold(readLine(reader, "Do you want to change the configuration? (Y/N): "), "Y") {
+			for propName, value := range previous {
+				viper.Set(propName, value)
+			}
+			return err
+		}
+	}
+}
+
+// promptProperties sets the answers in memory only and records them in changed
+func promptProperties(reader *bufio.Reader, changed map[string]string) {
+	set := func(propName, value string) {
+		viper.Set(propName, value)
+		changed[propName] = value
+	}
 	baseURISrc := viper.GetString(params.BaseURIKey)
 	baseAuthURI := viper.GetString(params.BaseAuthURIKey)
-	accessKeySecret := viper.GetString(params.AccessKeySecretConfigKey)
-	accessKey := viper.GetString(params.AccessKeyIDConfigKey)
-	accessAPIKey := viper.GetString(params.AstAPIKey)
-	tenant := viper.GetString(params.TenantKey)
-	fmt.Print("Setup guide: https://checkmarx.com/resource/documents/en/34965-68621-checkmarx-one-cli-quick-start-guide.html\n\n")
 	// Prompt for Base URI
-	fmt.Printf("AST Base URI [%s]: ", baseURI)
-	baseURI, _ = reader.ReadString('\n')
-	baseURI = strings.Replace(baseURI, "\n", "", -1)
-	baseURI = strings.Replace(baseURI, "\r", "", -1)
+	baseURI := promptValue(reader, fmt.Sprintf("AST Base URI [%s]: ", baseURISrc), func(value string) error {
+		return ValidateURI(params.BaseURIKey, value)
+	})
 	if len(baseURI) > 0 {
-		setConfigPropertyQuiet(params.BaseURIKey, baseURI)
+		set(params.BaseURIKey, baseURI)
 	}
 	// Prompt for Base Auth URI
 	if len(baseAuthURI) < 1 {
 		baseAuthURI = baseURISrc
 	}
-	fmt.Printf("AST Base Auth URI (IAM) [%s]: ", baseAuthURI)
-	baseAuthURI, _ = reader.ReadString('\n')
-	baseAuthURI = strings.Replace(baseAuthURI, "\n", "", -1)
-	baseAuthURI = strings.Replace(baseAuthURI, "\r", "", -1)
+	baseAuthURI = promptValue(reader, fmt.Sprintf("AST Base Auth URI (IAM) [%s]: ", baseAuthURI), func(value string) error {
+		return ValidateURI(params.BaseAuthURIKey, value)
+	})
 	if len(baseAuthURI) > 0 {
-		setConfigPropertyQuiet(params.BaseAuthURIKey, baseAuthURI)
+		set(params.BaseAuthURIKey, baseAuthURI)
 	}
 	// Prompt for tenant name
-	fmt.Printf("AST Tenant [%s]: ", tenant)
-	tenant, _ = reader.ReadString('\n')
-	tenant = strings.Replace(tenant, "\n", "", -1)
-	tenant = strings.Replace(tenant, "\r", "", -1)
+	tenant := promptValue(reader, fmt.Sprintf("AST Tenant [%s]: ", viper.GetString(params.TenantKey)), ValidateTenant)
 	if len(tenant) > 0 {
-		setConfigPropertyQuiet(params.TenantKey, tenant)
+		set(params.TenantKey, tenant)
 	}
 	// Prompt for access credentials type
-	fmt.Printf("Do you want to use API Key authentication? (Y/N): ")
-	authType, _ := reader.ReadString('\n')
-	authType = strings.Replace(authType, "\n", "", -1)
-	authType = strings.Replace(authType, "\r", "", -1)
+	authType := readLine(reader, "Do you want to use API Key authentication? (Y/N): ")
 	if strings.EqualFold(authType, "Y") {
-		fmt.Printf("AST API Key [%s]: ", obfuscateString(accessAPIKey))
-		accessAPIKey, _ = reader.ReadString('\n')
-		accessAPIKey = strings.Replace(accessAPIKey, "\n", "", -1)
-		accessAPIKey = strings.Replace(accessAPIKey, "\r", "", -1)
+		accessAPIKey := promptValue(reader, fmt.Sprintf("AST API Key [%s]: ", obfuscateString(viper.GetString(params.AstAPIKey))), ValidateAPIKey)
 		if len(accessAPIKey) > 0 {
-			setConfigPropertyQuiet(params.AstAPIKey, accessAPIKey)
-			setConfigPropertyQuiet(params.AccessKeyIDConfigKey, "")
-			setConfigPropertyQuiet(params.AccessKeySecretConfigKey, "")
+			set(params.AstAPIKey, accessAPIKey)
+			set(params.AccessKeyIDConfigKey, "")
+			set(params.AccessKeySecretConfigKey, "")
 		}
 	} else {
-		fmt.Printf("Checkmarx One Client ID [%s]: ", obfuscateString(accessKey))
-		accessKey, _ = reader.ReadString('\n')
-		accessKey = strings.Replace(accessKey, "\n", "", -1)
-		accessKey = strings.Replace(accessKey, "\r", "", -1)
+		accessKey := readLine(reader, fmt.Sprintf("Checkmarx One Client ID [%s]: ", obfuscateString(viper.GetString(params.AccessKeyIDConfigKey))))
 		if len(accessKey) > 0 {
-			setConfigPropertyQuiet(params.AccessKeyIDConfigKey, accessKey)
-			setConfigPropertyQuiet(params.AstAPIKey, "")
+			set(params.AccessKeyIDConfigKey, accessKey)
+			set(params.AstAPIKey, "")
 		}
-		fmt.Printf("Client Secret [%s]: ", obfuscateString(accessKeySecret))
-		accessKeySecret, _ = reader.ReadString('\n')
-		accessKeySecret = strings.Replace(accessKeySecret, "\n", "", -1)
-		accessKeySecret = strings.Replace(accessKeySecret, "\r", "", -1)
+		accessKeySecret := readLine(reader, fmt.Sprintf("Client Secret [%s]: ", obfuscateString(viper.GetString(params.AccessKeySecretConfigKey))))
 		if len(accessKeySecret) > 0 {
-			setConfigPropertyQuiet(params.AccessKeySecretConfigKey, accessKeySecret)
-			setConfigPropertyQuiet(params.AstAPIKey, "")
+			set(params.AccessKeySecretConfigKey, accessKeySecret)
+			set(params.AstAPIKey, "")
+		}
+	}
+}
+
+// writeProperties writes the changed properties to the profile, credentials through the secret backend
+func writeProperties(changed map[string]string) error {
+	for _, propName := range connectionProperties {
+		value, ok := changed[propName]
+		if !ok {
+			continue
+		}
+		if _, secret := secretPaths[propName]; secret {
+			if err := setSecretPropertyQuiet(propName, value); err != nil {
+				return err
+			}
+			continue
+		}
+		setConfigPropertyQuiet(propName, value)
+	}
+	return nil
+}
+
+func readLine(reader *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	line, _ := reader.ReadString('\n')
+	line = strings.Replace(line, "\n", "", -1)
+	return strings.Replace(line, "\r", "", -1)
+}
+
+// promptValue reads a value until it passes validation, an empty answer keeps the current value
+func promptValue(reader *bufio.Reader, prompt string, validate func(value string) error) string {
+	for {
+		value := readLine(reader, prompt)
+		if value == "" {
+			return value
+		}
+		err := validate(value)
+		if err == nil {
+			return value
+		}
+		fmt.Printf("%v, please try again\n", err)
+	}
+}
+
+// ConfigureNonInteractive validates the configuration given by flags, environment variables and the active
+// profile and writes it to the profile. When check is set it runs first, and nothing is written on failure.
+func ConfigureNonInteractive(check func() error) error {
+	if err := ValidateConfiguration(); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	changed := map[string]string{}
+	for _, propName := range []string{params.BaseURIKey, params.BaseAuthURIKey, params.TenantKey} {
+		if value := viper.GetString(propName); value != "" {
+			changed[propName] = value
 		}
 	}
+	if apiKey := viper.GetString(params.AstAPIKey); apiKey != "" {
+		changed[params.AstAPIKey] = apiKey
+		changed[params.AccessKeyIDConfigKey] = ""
+		changed[params.AccessKeySecretConfigKey] = ""
+	} else {
+		changed[params.AccessKeyIDConfigKey] = viper.GetString(params.AccessKeyIDConfigKey)
+		changed[params.AccessKeySecretConfigKey] = viper.GetString(params.AccessKeySecretConfigKey)
+	}
+	return writeProperties(changed)
 }
 
 func obfuscateString(str string) string {
@@ -109,21 +215,115 @@ func setConfigPropertyQuiet(propName, propValue string) {
 	}
 }
 
-func SetConfigProperty(propName, propValue string) {
-	fmt.Println("Setting property [", propName, "] to value [", propValue, "]")
+// setSecretPropertyQuiet stores a credential in the configured secret backend and writes the returned
+// reference, or the credential itself for the file backend, to the configuration
+func setSecretPropertyQuiet(propName, propValue string) error {
+	if propValue == "" || secrets.IsReference(propValue) {
+		setConfigPropertyQuiet(propName, propValue)
+		return nil
+	}
+	path, err := secretPath(propName)
+	if err != nil {
+		return err
+	}
+	value, err := secrets.Save(viper.GetString(params.SecretsBackendKey), path, propValue)
+	if err != nil {
+		return err
+	}
+	setConfigPropertyQuiet(propName, value)
+	return nil
+}
+
+func secretPath(propName string) (string, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+	return "cx/" + profile + "/" + secretPaths[strings.ToLower(propName)], nil
+}
+
+func SetConfigProperty(propName, propValue string) error {
+	fmt.Println("Setting property [", propName, "] to value [", MaskSecretProperty(propName, propValue), "]")
+	if _, ok := secretPaths[strings.ToLower(propName)]; ok {
+		return setSecretPropertyQuiet(strings.ToLower(propName), propValue)
+	}
 	setConfigPropertyQuiet(propName, propValue)
+	return nil
 }
 
-func LoadConfiguration() {
+// MigrateSecrets moves the credentials stored in the active profile to another secret backend and
+// makes it the backend for credentials configured later on
+func MigrateSecrets(backend string) error {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return err
+	}
+	profileConfig := viper.New()
+	profileConfig.SetConfigFile(profileConfigPath(profile))
+	profileConfig.SetConfigType("yaml")
+	if err = profileConfig.ReadInConfig(); err != nil {
+		return errors.Wrapf(err, "failed to read profile '%s'", profile)
+	}
+	var previous []string
+	for _, propName := range []string{params.AstAPIKey, params.AccessKeySecretConfigKey} {
+		current := profileConfig.GetString(propName)
+		if current == "" {
+			continue
+		}
+		secret, err := secrets.Resolve(current)
+		if err != nil {
+			return err
+		}
+		path, err := secretPath(propName)
+		if err != nil {
+			return err
+		}
+		value, err := secrets.Save(backend, path, secret)
+		if err != nil {
+			return err
+		}
+		profileConfig.Set(propName, value)
+		viper.Set(propName, value)
+		if value != current {
+			previous = append(previous, current)
+		}
+		fmt.Printf("Migrated [ %s ] to [ %s ]\n", propName, MaskSecretProperty(propName, value))
+	}
+	profileConfig.Set(params.SecretsBackendKey, backend)
+	viper.Set(params.SecretsBackendKey, backend)
+	if err := profileConfig.WriteConfig(); err != nil {
+		return err
+	}
+	// the previous secrets are only removed once the profile points to the new ones
+	for _, value := range previous {
+		if err := secrets.Delete(value); err != nil {
+			return errors.Wrapf(err, "failed to delete the previous secret %s", value)
+		}
+	}
+	return nil
+}
+
+// ConfigDir returns the directory holding the configuration files of all profiles, under $HOME when it is set
+func ConfigDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home + configDirName
+	}
 	usr, err := user.Current()
 	if err != nil {
 		log.Fatal("Cannot file home directory.", err)
 	}
-	fullPath := usr.HomeDir + configDirName
+	return usr.HomeDir + configDirName
+}
+
+func LoadConfiguration() {
+	fullPath := ConfigDir()
 	verifyConfigDir(fullPath)
+	profile, err := ActiveProfile()
+	if err != nil {
+		log.Fatal("Cannot load the configuration. ", err)
+	}
 	viper.AddConfigPath(fullPath)
-	configFile := "checkmarxcli"
-	viper.SetConfigName(configFile)
+	viper.SetConfigName(ProfileConfigName(profile))
 	viper.SetConfigType("yaml")
 	_ = viper.ReadInConfig()
 }
@@ -138,21 +338,43 @@ func verifyConfigDir(fullPath string) {
 	}
 }
 
-func ShowConfiguration() {
+func ShowConfiguration() error {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return err
+	}
 	fmt.Println("Current Effective Configuration")
 
+	fmt.Printf("%30v", "Profile: ")
+	fmt.Println(profile)
+	printConfiguration(viper.GetString)
+	return nil
+}
+
+func printConfiguration(getString func(key string) string) {
 	fmt.Printf("%30v", "BaseURI: ")
-	fmt.Println(viper.GetString(params.BaseURIKey))
+	fmt.Println(getString(params.BaseURIKey))
 	fmt.Printf("%30v", "BaseAuthURIKey: ")
-	fmt.Println(viper.GetString(params.BaseAuthURIKey))
+	fmt.Println(getString(params.BaseAuthURIKey))
 	fmt.Printf("%30v", "Checkmarx One Tenant: ")
-	fmt.Println(viper.GetString(params.TenantKey))
+	fmt.Println(getString(params.TenantKey))
 	fmt.Printf("%30v", "Client ID: ")
-	fmt.Println(viper.GetString(params.AccessKeyIDConfigKey))
+	fmt.Println(getString(params.AccessKeyIDConfigKey))
 	fmt.Printf("%30v", "Client Secret: ")
-	fmt.Println(obfuscateString(viper.GetString(params.AccessKeySecretConfigKey)))
+	fmt.Println(MaskSecretProperty(params.AccessKeySecretConfigKey, getString(params.AccessKeySecretConfigKey)))
 	fmt.Printf("%30v", "APIKey: ")
-	fmt.Println(obfuscateString(viper.GetString(params.AstAPIKey)))
+	fmt.Println(MaskSecretProperty(params.AstAPIKey, getString(params.AstAPIKey)))
+	fmt.Printf("%30v", "Secrets Backend: ")
+	fmt.Println(getString(params.SecretsBackendKey))
 	fmt.Printf("%30v", "Proxy: ")
-	fmt.Println(viper.GetString(params.ProxyKey))
+	fmt.Println(getString(params.ProxyKey))
+}
+
+// MaskSecretProperty obfuscates the value of properties holding credentials so they can be printed,
+// references to a secret backend are not secret and printed as they are
+func MaskSecretProperty(propName, propValue string) string {
+	if secretProperties[strings.ToLower(propName)] && !secrets.IsReference(propValue) {
+		return obfuscateString(propValue)
+	}
+	return propValue
 }
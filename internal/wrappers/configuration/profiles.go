@@ -0,0 +1,207 @@
+package configuration
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	configFileName         = "checkmarxcli"
+	configFileExtension    = ".yaml"
+	profileSeparator       = "_"
+	activeProfileFileName  = "active_profile"
+	configFilePermissions  = 0600
+	profileNamePattern     = "^[A-Za-z0-9_-]+$"
+	invalidProfileNameMsg  = "invalid profile name '%s', use letters, digits, '-' and '_' only"
+	profileNotFoundMsg     = "profile '%s' does not exist"
+	profileExistsMsg       = "profile '%s' already exists"
+	deleteDefaultProfile   = "the default profile cannot be deleted"
+	profileFlagPrefix      = "--" + params.ProfileFlag
+	profileFlagWithValue   = profileFlagPrefix + "="
+	profileFlagValueOffset = 1
+)
+
+var profileNameRegex = regexp.MustCompile(profileNamePattern)
+
+// ActiveProfile returns the profile in use: the --profile flag, then CX_PROFILE, then the profile selected
+// with 'cx configure profile use' and finally the default profile. The configuration is loaded before the
+// flags are parsed, so the flag is looked up in the raw arguments. The name is part of the configuration
+// file path, so it is validated as 'cx configure profile' does.
+func ActiveProfile() (string, error) {
+	profile := profileFromArgs(os.Args)
+	if profile == "" {
+		profile = os.Getenv(params.ProfileEnv)
+	}
+	if profile == "" {
+		profile = persistedProfile()
+	}
+	if profile == "" {
+		return params.Profile, nil
+	}
+	if err := validateProfileName(profile); err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+func profileFromArgs(args []string) string {
+	profile := ""
+	for i, arg := range args {
+		if arg == profileFlagPrefix && i+profileFlagValueOffset < len(args) {
+			profile = args[i+profileFlagValueOffset]
+		} else if strings.HasPrefix(arg, profileFlagWithValue) {
+			profile = strings.TrimPrefix(arg, profileFlagWithValue)
+		}
+	}
+	return profile
+}
+
+// ProfileConfigName returns the configuration file name, without extension, of a profile
+func ProfileConfigName(profile string) string {
+	if profile == params.Profile {
+		return configFileName
+	}
+	return configFileName + profileSeparator + profile
+}
+
+func profileConfigPath(profile string) string {
+	return filepath.Join(ConfigDir(), ProfileConfigName(profile)+configFileExtension)
+}
+
+func validateProfileName(profile string) error {
+	if !profileNameRegex.MatchString(profile) {
+		return errors.Errorf(invalidProfileNameMsg, profile)
+	}
+	return nil
+}
+
+func profileExists(profile string) bool {
+	_, err := os.Stat(profileConfigPath(profile))
+	return err == nil
+}
+
+// ListProfiles returns the names of all profiles with a configuration file. The default profile is always listed.
+func ListProfiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(ConfigDir(), configFileName+"*"+configFileExtension))
+	if err != nil {
+		return nil, err
+	}
+	profiles := []string{params.Profile}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), configFileExtension)
+		if profile := strings.TrimPrefix(name, configFileName+profileSeparator); profile != name && validateProfileName(profile) == nil {
+			profiles = append(profiles, profile)
+		}
+	}
+	sort.Strings(profiles[1:])
+	return profiles, nil
+}
+
+func CreateProfile(profile string) error {
+	if err := validateProfileName(profile); err != nil {
+		return err
+	}
+	if profile == params.Profile || profileExists(profile) {
+		return errors.Errorf(profileExistsMsg, profile)
+	}
+	if err := os.MkdirAll(ConfigDir(), homeDirectoryPermissions); err != nil {
+		return err
+	}
+	return os.WriteFile(profileConfigPath(profile), []byte{}, configFilePermissions)
+}
+
+func CopyProfile(source, target string) error {
+	if err := validateProfileName(target); err != nil {
+		return err
+	}
+	if target == params.Profile || profileExists(target) {
+		return errors.Errorf(profileExistsMsg, target)
+	}
+	sourceFile, err := os.Open(profileConfigPath(source))
+	if err != nil {
+		return errors.Errorf(profileNotFoundMsg, source)
+	}
+	defer func() {
+		_ = sourceFile.Close()
+	}()
+	targetFile, err := os.OpenFile(profileConfigPath(target), os.O_WRONLY|os.O_CREATE|os.O_EXCL, configFilePermissions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = targetFile.Close()
+	}()
+	_, err = io.Copy(targetFile, sourceFile)
+	return err
+}
+
+// DeleteProfile removes a profile. When it was selected with UseProfile, the default profile becomes active again.
+func DeleteProfile(profile string) error {
+	if profile == params.Profile {
+		return errors.New(deleteDefaultProfile)
+	}
+	if !profileExists(profile) {
+		return errors.Errorf(profileNotFoundMsg, profile)
+	}
+	if err := os.Remove(profileConfigPath(profile)); err != nil {
+		return err
+	}
+	if persistedProfile() == profile {
+		return UseProfile(params.Profile)
+	}
+	return nil
+}
+
+// UseProfile persists the profile used when neither --profile nor CX_PROFILE is given
+func UseProfile(profile string) error {
+	if profile != params.Profile && !profileExists(profile) {
+		return errors.Errorf(profileNotFoundMsg, profile)
+	}
+	if err := os.MkdirAll(ConfigDir(), homeDirectoryPermissions); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(ConfigDir(), activeProfileFileName), []byte(profile), configFilePermissions)
+}
+
+func persistedProfile() string {
+	content, err := os.ReadFile(filepath.Join(ConfigDir(), activeProfileFileName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// ShowAllProfiles prints the stored configuration of every profile, secrets are masked
+func ShowAllProfiles() error {
+	profiles, err := ListProfiles()
+	if err != nil {
+		return err
+	}
+	active, err := ActiveProfile()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		profileConfig := viper.New()
+		profileConfig.SetConfigFile(profileConfigPath(profile))
+		profileConfig.SetConfigType("yaml")
+		_ = profileConfig.ReadInConfig()
+		marker := ""
+		if profile == active {
+			marker = " (active)"
+		}
+		fmt.Printf("Profile: %s%s\n", profile, marker)
+		printConfiguration(profileConfig.GetString)
+		fmt.Println()
+	}
+	return nil
+}
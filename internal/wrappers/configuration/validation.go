@@ -0,0 +1,86 @@
+package configuration
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers/secrets"
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	tenantPattern      = "^[A-Za-z0-9][A-Za-z0-9_-]*$"
+	invalidURIMsg      = "invalid %s '%s', expected an absolute http(s) URL such as https://ast.checkmarx.net"
+	invalidTenantMsg   = "invalid tenant '%s', use letters, digits, '-' and '_' only"
+	invalidAPIKeyMsg   = "invalid API key, expected a token generated in Checkmarx One: %v"
+	missingCredentials = "provide --apikey or both --client-id and --client-secret"
+	missingBaseURI     = "provide --base-uri when using --client-id and --client-secret"
+	missingTenant      = "provide --tenant when using --client-id and --client-secret"
+)
+
+var tenantRegex = regexp.MustCompile(tenantPattern)
+
+// ValidateURI checks that a base URI property holds an absolute http(s) URL
+func ValidateURI(propName, value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.Errorf(invalidURIMsg, propName, value)
+	}
+	return nil
+}
+
+func ValidateTenant(value string) error {
+	if !tenantRegex.MatchString(value) {
+		return errors.Errorf(invalidTenantMsg, value)
+	}
+	return nil
+}
+
+// ValidateAPIKey checks that an API key is a well-formed token, references to a secret backend are accepted as they are
+func ValidateAPIKey(value string) error {
+	if secrets.IsReference(value) {
+		return nil
+	}
+	if _, _, err := new(jwt.Parser).ParseUnverified(value, jwt.MapClaims{}); err != nil {
+		return errors.Errorf(invalidAPIKeyMsg, err)
+	}
+	return nil
+}
+
+// ValidateConfiguration checks the format of the effective configuration: the URIs, the tenant and that
+// either an API key or client credentials are set
+func ValidateConfiguration() error {
+	apiKey := viper.GetString(params.AstAPIKey)
+	clientID := viper.GetString(params.AccessKeyIDConfigKey)
+	clientSecret := viper.GetString(params.AccessKeySecretConfigKey)
+	if apiKey == "" && (clientID == "" || clientSecret == "") {
+		return errors.New(missingCredentials)
+	}
+	if apiKey != "" {
+		if err := ValidateAPIKey(apiKey); err != nil {
+			return err
+		}
+	} else {
+		if viper.GetString(params.BaseURIKey) == "" {
+			return errors.New(missingBaseURI)
+		}
+		if viper.GetString(params.TenantKey) == "" {
+			return errors.New(missingTenant)
+		}
+	}
+	for _, propName := range []string{params.BaseURIKey, params.BaseAuthURIKey} {
+		if value := strings.TrimSpace(viper.GetString(propName)); value != "" {
+			if err := ValidateURI(propName, value); err != nil {
+				return err
+			}
+		}
+	}
+	if tenant := viper.GetString(params.TenantKey); tenant != "" {
+		return ValidateTenant(tenant)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package wrappers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// Categories of the failures reported by CheckConnectivity
+const (
+	DNSFailure          = "DNS"
+	TLSFailure          = "TLS"
+	UnauthorizedFailure = "Unauthorized"
+	WrongTenantFailure  = "Wrong tenant"
+	WrongRealmFailure   = "Wrong realm"
+	ConnectionFailure   = "Connection"
+)
+
+const (
+	realmsPathSegment    = "/realms/"
+	connectivityPageSize = "1"
+)
+
+// ConnectivityError is a failure of the connectivity check, with a hint on what to fix
+type ConnectivityError struct {
+	Category string
+	URL      string
+	Hint     string
+	Err      error
+}
+
+func (e *ConnectivityError) Error() string {
+	return fmt.Sprintf("%s error for %s: %v. %s", e.Category, e.URL, e.Err, e.Hint)
+}
+
+func (e *ConnectivityError) Unwrap() error {
+	return e.Err
+}
+
+// CheckConnectivity requests an access token with the configured credentials and uses it on a cheap API call.
+// Failures are returned as a *ConnectivityError. The token cache is bypassed so the current values are tested.
+func CheckConnectivity() error {
+	apiKey, err := GetSecretProperty(commonParams.AstAPIKey)
+	if err != nil {
+		return err
+	}
+	accessKeySecret, err := GetSecretProperty(commonParams.AccessKeySecretConfigKey)
+	if err != nil {
+		return err
+	}
+	accessKeyID := viper.GetString(commonParams.AccessKeyIDConfigKey)
+	if apiKey == "" && (accessKeyID == "" || accessKeySecret == "") {
+		return errors.Errorf(FailedToAuth, "API key or client credentials")
+	}
+	if err = checkAPIKeyRealm(apiKey); err != nil {
+		return err
+	}
+	authURI, err := getAuthURI()
+	if err != nil {
+		return err
+	}
+	payload := getCredentialsPayload(accessKeyID, accessKeySecret)
+	if apiKey != "" {
+		payload = getAPIKeyPayload(apiKey)
+	}
+	accessToken, err := requestConnectivityToken(authURI, payload)
+	if err != nil {
+		return err
+	}
+	return checkAPIAccess(accessToken)
+}
+
+// checkAPIKeyRealm compares the realm the API key was issued by with the configured tenant
+func checkAPIKeyRealm(apiKey string) error {
+	tenant := viper.GetString(commonParams.TenantKey)
+	if apiKey == "" || tenant == "" {
+		return nil
+	}
+	audience, err := extractFromTokenClaims(apiKey, audienceClaimKey)
+	if err != nil {
+		return err
+	}
+	_, realm, found := strings.Cut(audience, realmsPathSegment)
+	realm = strings.Trim(realm, "/")
+	if !found || realm == "" || strings.EqualFold(realm, tenant) {
+		return nil
+	}
+	return &ConnectivityError{
+		Category: WrongRealmFailure,
+		URL:      audience,
+		Hint:     fmt.Sprintf("Set the tenant to '%s' or use an API key of tenant '%s'", realm, tenant),
+		Err:      errors.Errorf("the API key belongs to realm '%s' but the tenant is '%s'", realm, tenant),
+	}
+}
+
+func requestConnectivityToken(authURI, payload string) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, authURI, strings.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	setAgentName(req)
+	req.Header.Add(contentTypeHeader, formURLContentType)
+	client := GetClient(viper.GetUint(commonParams.ClientTimeoutKey))
+	res, err := doPrivateRequest(client, req)
+	if err != nil {
+		return "", classifyTransportError(authURI, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	body, _ := io.ReadAll(res.Body)
+	switch res.StatusCode {
+	case http.StatusOK:
+		credentialsInfo := ClientCredentialsInfo{}
+		if err = json.Unmarshal(body, &credentialsInfo); err != nil {
+			return "", err
+		}
+		return credentialsInfo.AccessToken, nil
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		return "", &ConnectivityError{
+			Category: UnauthorizedFailure,
+			URL:      authURI,
+			Hint:     "Check the API key or the client ID and secret",
+			Err:      errors.Errorf("%d %s", res.StatusCode, invalidCredentialsError),
+		}
+	case http.StatusNotFound:
+		return "", &ConnectivityError{
+			Category: WrongTenantFailure,
+			URL:      authURI,
+			Hint:     fmt.Sprintf("Check the tenant name '%s' and the base auth URI", viper.GetString(commonParams.TenantKey)),
+			Err:      errors.Errorf("%d tenant not found", res.StatusCode),
+		}
+	default:
+		return "", &ConnectivityError{
+			Category: ConnectionFailure,
+			URL:      authURI,
+			Hint:     "Check the base auth URI",
+			Err:      errors.Errorf("unexpected status %d", res.StatusCode),
+		}
+	}
+}
+
+func checkAPIAccess(accessToken string) error {
+	scansURL, err := GetURL(viper.GetString(commonParams.ScansPathKey), accessToken)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodGet, scansURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+	setAgentName(req)
+	query := req.URL.Query()
+	query.Add(commonParams.LimitQueryParam, connectivityPageSize)
+	req.URL.RawQuery = query.Encode()
+	enrichWithOath2Credentials(req, accessToken, bearerFormat)
+	client := GetClient(viper.GetUint(commonParams.ClientTimeoutKey))
+	res, err := doPrivateRequest(client, req)
+	if err != nil {
+		return classifyTransportError(scansURL, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &ConnectivityError{
+			Category: UnauthorizedFailure,
+			URL:      scansURL,
+			Hint:     "The token was rejected, check that the base URI belongs to the same tenant and region as the credentials",
+			Err:      errors.Errorf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+		}
+	default:
+		return &ConnectivityError{
+			Category: ConnectionFailure,
+			URL:      scansURL,
+			Hint:     "Check the base URI",
+			Err:      errors.Errorf("unexpected status %d", res.StatusCode),
+		}
+	}
+}
+
+func classifyTransportError(target string, err error) error {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return &ConnectivityError{
+			Category: DNSFailure,
+			URL:      target,
+			Hint:     fmt.Sprintf("Check the host name '%s' and your DNS or proxy settings", dnsErr.Name),
+			Err:      dnsErr,
+		}
+	}
+	if isTLSError(err) {
+		return &ConnectivityError{
+			Category: TLSFailure,
+			URL:      target,
+			Hint:     "Check the server certificate, a TLS inspecting proxy may need its CA in the system trust store or --insecure",
+			Err:      err,
+		}
+	}
+	return &ConnectivityError{
+		Category: ConnectionFailure,
+		URL:      target,
+		Hint:     "Check the URI and your proxy settings",
+		Err:      err,
+	}
+}
+
+func isTLSError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var verification *tls.CertificateVerificationError
+	var recordHeader tls.RecordHeaderError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid) ||
+		errors.As(err, &verification) || errors.As(err, &recordHeader)
+}
@@ -0,0 +1,217 @@
+package container
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
+)
+
+// Exit codes of 'docker run', also used by podman and nerdctl. Any other code is the exit code of the container.
+const (
+	engineErrorExitCode       = 125
+	commandNotInvokedExitCode = 126
+	commandNotFoundExitCode   = 127
+)
+
+const (
+	seLinuxEnforceFile   = "/sys/fs/selinux/enforce"
+	seLinuxEnforcing     = "1"
+	seLinuxSharedLabel   = "z"
+	readOnlyMountOption  = "ro"
+	rootlessSecurityName = "rootless"
+	podmanKeepIDUserNS   = "--userns=keep-id"
+)
+
+var (
+	daemonNotRunningOutputs = []string{
+		"cannot connect to the docker daemon",
+		"is the docker daemon running",
+		"error during connect",
+		"cannot connect to podman",
+		"unable to connect to podman",
+		"cannot access containerd socket",
+		"connection refused",
+	}
+	imagePullOutputs = []string{
+		"pull access denied",
+		"manifest unknown",
+		"unable to find image",
+		"error pulling image",
+		"failed to resolve reference",
+	}
+)
+
+type cliProvider struct{}
+
+// NewProvider returns the provider of the container engines installed on this host
+func NewProvider() Provider {
+	return &cliProvider{}
+}
+
+func (*cliProvider) Runtime(engine string) (Runtime, error) {
+	candidates := []string{engine}
+	if engine == "" || engine == AutoEngine {
+		candidates = Engines
+	}
+	var lookErr error
+	for _, candidate := range candidates {
+		path, err := exec.LookPath(candidate)
+		if err == nil {
+			return newCLIRuntime(path), nil
+		}
+		lookErr = err
+	}
+	return nil, &Error{Kind: ErrEngineNotInstalled, Engine: engine, Err: lookErr}
+}
+
+// cliRuntime drives the docker compatible CLI of docker, podman and nerdctl
+type cliRuntime struct {
+	path     string
+	flavor   string
+	rootless bool
+	seLinux  bool
+}
+
+func newCLIRuntime(path string) *cliRuntime {
+	runtime := &cliRuntime{path: path}
+	runtime.flavor = runtime.detectFlavor()
+	runtime.rootless = runtime.detectRootless()
+	runtime.seLinux = seLinuxEnforcing == readTrimmed(seLinuxEnforceFile)
+	logger.PrintfIfVerbose("Container engine %s (%s), rootless: %t, SELinux: %t", path, runtime.flavor, runtime.rootless, runtime.seLinux)
+	return runtime
+}
+
+// detectFlavor tells engines apart by name, then by version, e.g. podman installed as docker
+func (r *cliRuntime) detectFlavor() string {
+	name := strings.ToLower(filepath.Base(r.path))
+	for _, engine := range []string{PodmanEngine, NerdctlEngine} {
+		if strings.Contains(name, engine) {
+			return engine
+		}
+	}
+	version, _ := exec.Command(r.path, "--version").Output()
+	for _, engine := range []string{PodmanEngine, NerdctlEngine} {
+		if strings.Contains(strings.ToLower(string(version)), engine) {
+			return engine
+		}
+	}
+	return DockerEngine
+}
+
+func (r *cliRuntime) detectRootless() bool {
+	if r.flavor == PodmanEngine {
+		out, err := exec.Command(r.path, "info", "--format", "{{.Host.Security.Rootless}}").Output()
+		return err == nil && strings.TrimSpace(string(out)) == "true"
+	}
+	out, err := exec.Command(r.path, "info", "--format", "{{.SecurityOptions}}").Output()
+	return err == nil && strings.Contains(string(out), rootlessSecurityName)
+}
+
+func readTrimmed(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func (r *cliRuntime) Name() string {
+	return r.flavor
+}
+
+func (r *cliRuntime) EnsureImage(image string, progress io.Writer) error {
+	if exec.Command(r.path, "image", "inspect", image).Run() == nil {
+		return nil
+	}
+	logger.PrintfIfVerbose("Pulling image %s", image)
+	var output bytes.Buffer
+	pull := exec.Command(r.path, "pull", image)
+	pull.Stdout = io.MultiWriter(progress, &output)
+	pull.Stderr = io.MultiWriter(progress, &output)
+	if err := pull.Run(); err != nil {
+		runErr := r.classify(output.String(), err)
+		if errors.Is(runErr, ErrDaemonNotRunning) || errors.Is(runErr, ErrEngineNotInstalled) {
+			return runErr
+		}
+		return &Error{Kind: ErrImagePullFailed, Engine: r.flavor, Output: output.String(), Err: errors.Errorf("image %s", image)}
+	}
+	return nil
+}
+
+func (r *cliRuntime) Run(options *RunOptions) (*RunResult, error) {
+	args := r.runArgs(options)
+	logger.PrintfIfVerbose("Running %s %s", r.path, strings.Join(args, " "))
+	out, err := exec.Command(r.path, args...).CombinedOutput()
+	if err == nil {
+		return &RunResult{Output: out}, nil
+	}
+	exitError, isExitError := err.(*exec.ExitError)
+	if isExitError && !isEngineExitCode(exitError.ExitCode()) {
+		return &RunResult{ExitCode: exitError.ExitCode(), Output: out}, nil
+	}
+	return &RunResult{ExitCode: -1, Output: out}, r.classify(string(out), err)
+}
+
+func (r *cliRuntime) runArgs(options *RunOptions) []string {
+	args := []string{"run"}
+	if options.Remove {
+		args = append(args, "--rm")
+	}
+	if options.Name != "" {
+		args = append(args, "--name", options.Name)
+	}
+	// rootless podman maps the user to root in the container, keep the user id so written files stay owned by the user
+	if r.rootless && r.flavor == PodmanEngine {
+		args = append(args, podmanKeepIDUserNS)
+	}
+	for _, mount := range options.Mounts {
+		args = append(args, "-v", r.volumeSpec(mount))
+	}
+	args = append(args, options.Image)
+	return append(args, options.Args...)
+}
+
+// volumeSpec adds the shared SELinux label on enforcing hosts, otherwise the container cannot read the mount.
+// nerdctl does not support relabeling.
+func (r *cliRuntime) volumeSpec(mount Mount) string {
+	var options []string
+	if mount.ReadOnly {
+		options = append(options, readOnlyMountOption)
+	}
+	if r.seLinux && r.flavor != NerdctlEngine {
+		options = append(options, seLinuxSharedLabel)
+	}
+	spec := mount.Source + ":" + mount.Target
+	if len(options) > 0 {
+		spec += ":" + strings.Join(options, ",")
+	}
+	return spec
+}
+
+func isEngineExitCode(code int) bool {
+	return code == engineErrorExitCode || code == commandNotInvokedExitCode || code == commandNotFoundExitCode
+}
+
+func (r *cliRuntime) classify(output string, err error) error {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+		return &Error{Kind: ErrEngineNotInstalled, Engine: r.flavor, Output: output, Err: err}
+	}
+	lowerOutput := strings.ToLower(output)
+	for _, message := range daemonNotRunningOutputs {
+		if strings.Contains(lowerOutput, message) {
+			return &Error{Kind: ErrDaemonNotRunning, Engine: r.flavor, Output: output, Err: err}
+		}
+	}
+	for _, message := range imagePullOutputs {
+		if strings.Contains(lowerOutput, message) {
+			return &Error{Kind: ErrImagePullFailed, Engine: r.flavor, Output: output, Err: err}
+		}
+	}
+	return &Error{Kind: ErrEngineFailed, Engine: r.flavor, Output: output, Err: err}
+}
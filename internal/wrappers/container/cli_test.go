@@ -0,0 +1,69 @@
+package container
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func testRunOptions() *RunOptions {
+	return &RunOptions{
+		Image:  "checkmarx/kics:latest",
+		Name:   "kics",
+		Remove: true,
+		Mounts: []Mount{{Source: "/tmp/kics", Target: "/path"}, {Source: "/src", Target: "/files", ReadOnly: true}},
+		Args:   []string{"scan", "-p", "/path"},
+	}
+}
+
+func TestRunArgs(t *testing.T) {
+	runtime := &cliRuntime{flavor: DockerEngine}
+	assert.DeepEqual(t, runtime.runArgs(testRunOptions()), []string{
+		"run", "--rm", "--name", "kics", "-v", "/tmp/kics:/path", "-v", "/src:/files:ro", "checkmarx/kics:latest", "scan", "-p", "/path",
+	})
+}
+
+func TestRunArgsRootlessPodmanWithSELinux(t *testing.T) {
+	runtime := &cliRuntime{flavor: PodmanEngine, rootless: true, seLinux: true}
+	assert.DeepEqual(t, runtime.runArgs(testRunOptions()), []string{
+		"run", "--rm", "--name", "kics", "--userns=keep-id", "-v", "/tmp/kics:/path:z", "-v", "/src:/files:ro,z", "checkmarx/kics:latest", "scan", "-p", "/path",
+	})
+}
+
+func TestVolumeSpecNerdctlWithSELinux(t *testing.T) {
+	runtime := &cliRuntime{flavor: NerdctlEngine, rootless: true, seLinux: true}
+	assert.Equal(t, runtime.volumeSpec(Mount{Source: "/tmp/kics", Target: "/path"}), "/tmp/kics:/path")
+}
+
+func TestClassify(t *testing.T) {
+	runtime := &cliRuntime{flavor: DockerEngine}
+	cases := []struct {
+		output string
+		err    error
+		kind   error
+	}{
+		{"", exec.ErrNotFound, ErrEngineNotInstalled},
+		{"Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?", nil, ErrDaemonNotRunning},
+		{"Error: unable to connect to Podman socket", nil, ErrDaemonNotRunning},
+		{"Unable to find image 'checkmarx/kics:v0' locally\nmanifest unknown", nil, ErrImagePullFailed},
+		{"docker: invalid reference format.", nil, ErrEngineFailed},
+	}
+	for _, c := range cases {
+		err := c.err
+		if err == nil {
+			err = errors.New("exit status 125")
+		}
+		classified := runtime.classify(c.output, err)
+		assert.Assert(t, errors.Is(classified, c.kind), "%q classified as %v", c.output, classified)
+		var containerErr *Error
+		assert.Assert(t, errors.As(classified, &containerErr))
+		assert.Equal(t, containerErr.Engine, DockerEngine)
+	}
+}
+
+func TestProviderEngineNotInstalled(t *testing.T) {
+	_, err := NewProvider().Runtime("not-an-engine")
+	assert.Assert(t, errors.Is(err, ErrEngineNotInstalled))
+}
@@ -0,0 +1,89 @@
+package container
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Engines, in the order they are looked up with AutoEngine
+const (
+	DockerEngine  = "docker"
+	PodmanEngine  = "podman"
+	NerdctlEngine = "nerdctl"
+	AutoEngine    = "auto"
+)
+
+// Engines lists the supported container engines
+var Engines = []string{DockerEngine, PodmanEngine, NerdctlEngine}
+
+// Kinds of Error, match them with errors.Is
+var (
+	ErrEngineNotInstalled = errors.New("container engine is not installed")
+	ErrDaemonNotRunning   = errors.New("container engine is not running")
+	ErrImagePullFailed    = errors.New("failed to pull container image")
+	ErrEngineFailed       = errors.New("container engine failed to run the container")
+)
+
+// Error is a failure of the container engine itself, as opposed to a non-zero exit code of the container
+type Error struct {
+	Kind   error
+	Engine string
+	Output string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s: %v", e.Engine, e.Kind)
+	}
+	return fmt.Sprintf("%s: %v: %v", e.Engine, e.Kind, e.Err)
+}
+
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Mount is a host directory mounted in the container
+type Mount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// RunOptions describe a container to run
+type RunOptions struct {
+	Image  string
+	Name   string
+	Remove bool
+	Mounts []Mount
+	Args   []string
+}
+
+// RunResult is the outcome of a container that ran, ExitCode is the exit code of its entrypoint
+type RunResult struct {
+	ExitCode int
+	Output   []byte
+}
+
+// Runtime runs containers with a container engine
+type Runtime interface {
+	// Name returns the engine flavor: docker, podman or nerdctl
+	Name() string
+	// EnsureImage pulls the image when it is not available locally, writing the pull progress to progress
+	EnsureImage(image string, progress io.Writer) error
+	// Run runs a container and waits for it. Failures of the engine are returned as *Error, a container that
+	// ran and exited with a non-zero code is not an error.
+	Run(options *RunOptions) (*RunResult, error)
+}
+
+// Provider returns the runtime of a container engine
+type Provider interface {
+	// Runtime returns the runtime of an engine given by name or path, AutoEngine picks the first engine installed
+	Runtime(engine string) (Runtime, error)
+}
@@ -0,0 +1,78 @@
+package mock
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
+)
+
+// ContainerRuntimeMock emulates a container engine: it records the containers run, writes Files, keyed by their
+// path in the container, to the mounted directories, calls OnRun and exits with ExitCode
+type ContainerRuntimeMock struct {
+	ExitCode int
+	Output   string
+	Files    map[string]string
+	OnRun    func(options *container.RunOptions) error
+	RunErr   error
+	PullErr  error
+	Runs     []container.RunOptions
+}
+
+func (r *ContainerRuntimeMock) Name() string {
+	return container.DockerEngine
+}
+
+func (r *ContainerRuntimeMock) EnsureImage(string, io.Writer) error {
+	return r.PullErr
+}
+
+func (r *ContainerRuntimeMock) Run(options *container.RunOptions) (*container.RunResult, error) {
+	r.Runs = append(r.Runs, *options)
+	if r.RunErr != nil {
+		return &container.RunResult{ExitCode: -1}, r.RunErr
+	}
+	for path, content := range r.Files {
+		for _, mount := range options.Mounts {
+			target := strings.TrimSuffix(mount.Target, "/") + "/"
+			if strings.HasPrefix(path, target) {
+				hostPath := filepath.Join(mount.Source, filepath.FromSlash(strings.TrimPrefix(path, target)))
+				if err := os.WriteFile(hostPath, []byte(content), 0600); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if r.OnRun != nil {
+		if err := r.OnRun(options); err != nil {
+			return nil, err
+		}
+	}
+	return &container.RunResult{ExitCode: r.ExitCode, Output: []byte(r.Output)}, nil
+}
+
+// ContainerProviderMock returns RuntimeMock for the supported engines and reports any other engine as not installed
+type ContainerProviderMock struct {
+	RuntimeMock *ContainerRuntimeMock
+}
+
+func (p *ContainerProviderMock) Runtime(engine string) (container.Runtime, error) {
+	if engine != container.AutoEngine && !contains(container.Engines, engine) {
+		return nil, &container.Error{Kind: container.ErrEngineNotInstalled, Engine: engine}
+	}
+	if p.RuntimeMock == nil {
+		p.RuntimeMock = &ContainerRuntimeMock{}
+	}
+	return p.RuntimeMock, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package mock
+
+import (
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+)
+
+type NpmRegistryMockWrapper struct {
+	Packuments map[string]*wrappers.NpmPackument
+	Requests   []string
+}
+
+func (n *NpmRegistryMockWrapper) GetPackument(name string) (*wrappers.NpmPackument, error) {
+	n.Requests = append(n.Requests, name)
+	packument, found := n.Packuments[name]
+	if !found {
+		return nil, errors.Errorf("Package %s not found in the npm registry", name)
+	}
+	return packument, nil
+}
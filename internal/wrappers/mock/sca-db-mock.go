@@ -0,0 +1,28 @@
+package mock
+
+import (
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+)
+
+type ScaDBMockWrapper struct {
+	Manifests map[string]*wrappers.ScaDBManifest
+	Snapshots map[string][]byte
+	Downloads int
+}
+
+func (s *ScaDBMockWrapper) GetManifest(version string) (*wrappers.ScaDBManifest, error) {
+	if version == "" {
+		version = "latest"
+	}
+	manifest, found := s.Manifests[version]
+	if !found {
+		return nil, errors.Errorf("Version %s of the SCA vulnerability database was not found", version)
+	}
+	return manifest, nil
+}
+
+func (s *ScaDBMockWrapper) DownloadSnapshot(manifest *wrappers.ScaDBManifest) ([]byte, error) {
+	s.Downloads++
+	return s.Snapshots[manifest.URL], nil
+}
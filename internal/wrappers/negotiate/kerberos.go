@@ -0,0 +1,149 @@
+package negotiate
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultKrb5Conf    = "/etc/krb5.conf"
+	krb5ConfigEnv      = "KRB5_CONFIG"
+	krb5CCacheEnv      = "KRB5CCNAME"
+	ccacheFilePrefix   = "FILE:"
+	defaultCCachePath  = "/tmp/krb5cc_%s"
+	principalSeparator = "@"
+	servicePrincipal   = "HTTP/%s"
+)
+
+// KerberosSettings locates the Kerberos configuration and credentials. Empty values fall back to
+// KRB5_CONFIG / /etc/krb5.conf and KRB5CCNAME / /tmp/krb5cc_<uid>. A keytab requires a principal.
+type KerberosSettings struct {
+	Krb5Conf  string
+	Keytab    string
+	Principal string
+	CCache    string
+}
+
+// KerberosTokenProvider creates SPNEGO tokens for the HTTP/<proxy host> service principal
+type KerberosTokenProvider struct {
+	settings KerberosSettings
+}
+
+var (
+	kerberosClients     = map[KerberosSettings]*client.Client{}
+	kerberosClientsLock sync.Mutex
+)
+
+func NewKerberosTokenProvider(settings KerberosSettings) *KerberosTokenProvider {
+	return &KerberosTokenProvider{settings: settings}
+}
+
+func (p *KerberosTokenProvider) Token(proxyHost string) ([]byte, error) {
+	cl, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+	spnegoClient := spnego.SPNEGOClient(cl, fmt.Sprintf(servicePrincipal, proxyHost))
+	if err = spnegoClient.AcquireCred(); err != nil {
+		return nil, errors.Wrap(err, "could not acquire Kerberos credentials")
+	}
+	contextToken, err := spnegoClient.InitSecContext()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get a service ticket for %s", fmt.Sprintf(servicePrincipal, proxyHost))
+	}
+	return contextToken.Marshal()
+}
+
+// client logs in once per settings and reuses the ticket cache of the Kerberos client for later connections
+func (p *KerberosTokenProvider) client() (*client.Client, error) {
+	kerberosClientsLock.Lock()
+	defer kerberosClientsLock.Unlock()
+	if cl, ok := kerberosClients[p.settings]; ok {
+		return cl, nil
+	}
+	krb5Conf, err := config.Load(p.krb5ConfPath())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load Kerberos configuration %s", p.krb5ConfPath())
+	}
+	var cl *client.Client
+	if p.settings.Keytab != "" {
+		cl, err = p.keytabClient(krb5Conf)
+	} else {
+		cl, err = p.ccacheClient(krb5Conf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	kerberosClients[p.settings] = cl
+	return cl, nil
+}
+
+func (p *KerberosTokenProvider) keytabClient(krb5Conf *config.Config) (*client.Client, error) {
+	kt, err := keytab.Load(p.settings.Keytab)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load keytab %s", p.settings.Keytab)
+	}
+	username, realm, found := strings.Cut(p.settings.Principal, principalSeparator)
+	if !found {
+		realm = krb5Conf.LibDefaults.DefaultRealm
+	}
+	if username == "" || realm == "" {
+		return nil, errors.New("a Kerberos principal (user@REALM) is required when using a keytab")
+	}
+	cl := client.NewWithKeytab(username, realm, kt, krb5Conf, client.DisablePAFXFAST(true))
+	if err = cl.Login(); err != nil {
+		return nil, errors.Wrapf(err, "could not log in to Kerberos as %s@%s", username, realm)
+	}
+	return cl, nil
+}
+
+func (p *KerberosTokenProvider) ccacheClient(krb5Conf *config.Config) (*client.Client, error) {
+	ccachePath, err := p.ccachePath()
+	if err != nil {
+		return nil, err
+	}
+	ccache, err := credentials.LoadCCache(ccachePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load Kerberos credential cache %s, run kinit or provide a keytab", ccachePath)
+	}
+	cl, err := client.NewFromCCache(ccache, krb5Conf, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not use Kerberos credential cache %s", ccachePath)
+	}
+	return cl, nil
+}
+
+func (p *KerberosTokenProvider) krb5ConfPath() string {
+	if p.settings.Krb5Conf != "" {
+		return p.settings.Krb5Conf
+	}
+	if env := os.Getenv(krb5ConfigEnv); env != "" {
+		return env
+	}
+	return defaultKrb5Conf
+}
+
+func (p *KerberosTokenProvider) ccachePath() (string, error) {
+	ccachePath := p.settings.CCache
+	if ccachePath == "" {
+		ccachePath = os.Getenv(krb5CCacheEnv)
+	}
+	if ccachePath == "" {
+		usr, err := user.Current()
+		if err != nil {
+			return "", errors.Wrap(err, "could not find the Kerberos credential cache")
+		}
+		ccachePath = fmt.Sprintf(defaultCCachePath, usr.Uid)
+	}
+	return strings.TrimPrefix(ccachePath, ccacheFilePrefix), nil
+}
@@ -0,0 +1,156 @@
+package negotiate
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/wrappers/ntlm"
+	"github.com/pkg/errors"
+)
+
+const (
+	negotiateScheme         = "Negotiate"
+	ntlmScheme              = "NTLM"
+	proxyAuthenticateHeader = "Proxy-Authenticate"
+	proxyAuthorization      = "Proxy-Authorization"
+)
+
+// TokenProvider returns the SPNEGO token sent to the proxy in a "Proxy-Authorization: Negotiate" header
+type TokenProvider interface {
+	Token(proxyHost string) ([]byte, error)
+}
+
+// NewNegotiateProxyDialContext provides a DialContext function that tunnels through an HTTP proxy with
+// Negotiate (Kerberos/SPNEGO) authentication. When the proxy only offers NTLM in its Proxy-Authenticate
+// challenge, or no Kerberos credentials are available but NTLM is offered, ntlmFallback is used instead.
+func NewNegotiateProxyDialContext(dialer *net.Dialer, proxyURL *url.URL, tokenProvider TokenProvider,
+	ntlmFallback ntlm.DialContext, tlsConfig *tls.Config) ntlm.DialContext {
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialProxy := func() (net.Conn, error) {
+			if proxyURL.Scheme == "https" {
+				return tls.DialWithDialer(dialer, "tcp", proxyURL.Host, tlsConfig)
+			}
+			return dialer.DialContext(ctx, network, proxyURL.Host)
+		}
+		conn, schemes, err := connectAndNegotiate(addr, proxyURL.Hostname(), tokenProvider, dialProxy)
+		if err == nil || !offers(schemes, ntlmScheme) || ntlmFallback == nil {
+			return conn, err
+		}
+		log.Printf("Negotiate authentication with proxy failed, falling back to NTLM: %s", err)
+		return ntlmFallback(ctx, network, addr)
+	}
+}
+
+// connectAndNegotiate sends an unauthenticated CONNECT to learn the offered schemes and answers a Negotiate
+// challenge. The offered schemes are returned so the caller can decide about the NTLM fallback.
+func connectAndNegotiate(addr, proxyHost string, tokenProvider TokenProvider, baseDial func() (net.Conn, error)) (
+	net.Conn, []string, error) {
+	conn, err := baseDial()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not dial proxy")
+	}
+	br := bufio.NewReader(conn)
+	resp, err := sendConnect(conn, br, addr, "")
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		// The proxy does not require authentication
+		return conn, nil, nil
+	}
+	if resp.StatusCode != http.StatusProxyAuthRequired {
+		_ = conn.Close()
+		return nil, nil, errors.Errorf("unexpected proxy response to CONNECT: %s", resp.Status)
+	}
+	schemes := authenticationSchemes(resp.Header)
+	if !offers(schemes, negotiateScheme) {
+		_ = conn.Close()
+		return nil, schemes, errors.Errorf("proxy does not offer Negotiate authentication, offered: %s", strings.Join(schemes, ", "))
+	}
+	token, err := tokenProvider.Token(proxyHost)
+	if err != nil {
+		_ = conn.Close()
+		return nil, schemes, errors.Wrap(err, "could not create Kerberos token for proxy")
+	}
+	if resp.Close {
+		// The proxy closed the connection after the challenge, authenticate on a new one
+		_ = conn.Close()
+		if conn, err = baseDial(); err != nil {
+			return nil, schemes, errors.Wrap(err, "could not dial proxy")
+		}
+		br = bufio.NewReader(conn)
+	}
+	resp, err = sendConnect(conn, br, addr, fmt.Sprintf("%s %s", negotiateScheme, base64.StdEncoding.EncodeToString(token)))
+	if err != nil {
+		_ = conn.Close()
+		return nil, schemes, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, schemes, errors.Errorf("proxy rejected Negotiate authentication: %s", resp.Status)
+	}
+	return conn, schemes, nil
+}
+
+func sendConnect(conn net.Conn, br *bufio.Reader, addr, authorization string) (*http.Response, error) {
+	header := make(http.Header)
+	header.Set("Proxy-Connection", "Keep-Alive")
+	if authorization != "" {
+		header.Set(proxyAuthorization, authorization)
+	}
+	connect := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: header,
+	}
+	if err := connect.Write(conn); err != nil {
+		return nil, errors.Wrap(err, "could not write CONNECT request to proxy")
+	}
+	resp, err := http.ReadResponse(br, connect)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read response from proxy")
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Drain the body so the connection can be reused for the next round
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	return resp, nil
+}
+
+// authenticationSchemes lists the schemes of all Proxy-Authenticate challenges, e.g. ["Negotiate", "NTLM"]
+func authenticationSchemes(header http.Header) []string {
+	var schemes []string
+	for _, challenge := range header.Values(proxyAuthenticateHeader) {
+		for _, part := range strings.Split(challenge, ",") {
+			fields := strings.Fields(part)
+			if len(fields) > 0 && !strings.Contains(fields[0], "=") {
+				schemes = append(schemes, fields[0])
+			}
+		}
+	}
+	return schemes
+}
+
+func offers(schemes []string, scheme string) bool {
+	for _, s := range schemes {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package negotiate
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const targetAddr = "ast.checkmarx.net:443"
+
+// scriptedResponse is what the fake proxy answers to the next CONNECT request it receives
+type scriptedResponse struct {
+	status            int
+	proxyAuthenticate []string
+	closeConnection   bool
+}
+
+// fakeProxy replays scripted challenges and records the Proxy-Authorization header of every CONNECT
+type fakeProxy struct {
+	listener       net.Listener
+	mu             sync.Mutex
+	script         []scriptedResponse
+	authorizations []string
+	connections    int
+}
+
+func newFakeProxy(t *testing.T, script ...scriptedResponse) *fakeProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	proxy := &fakeProxy{listener: listener, script: script}
+	go proxy.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	return proxy
+}
+
+func (p *fakeProxy) url() *url.URL {
+	return &url.URL{Scheme: "http", Host: p.listener.Addr().String()}
+}
+
+func (p *fakeProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.mu.Lock()
+		p.connections++
+		p.mu.Unlock()
+		go p.handle(conn)
+	}
+}
+
+func (p *fakeProxy) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	br := bufio.NewReader(conn)
+	for {
+		req, err := http.ReadRequest(br)
+		if err != nil || req.Method != http.MethodConnect || req.Host != targetAddr {
+			return
+		}
+		p.mu.Lock()
+		p.authorizations = append(p.authorizations, req.Header.Get(proxyAuthorization))
+		if len(p.script) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		next := p.script[0]
+		p.script = p.script[1:]
+		p.mu.Unlock()
+
+		resp := &http.Response{StatusCode: next.status, ProtoMajor: 1, ProtoMinor: 1, Header: http.Header{}, Close: next.closeConnection}
+		for _, challenge := range next.proxyAuthenticate {
+			resp.Header.Add(proxyAuthenticateHeader, challenge)
+		}
+		if next.status != http.StatusOK {
+			resp.Body = io.NopCloser(strings.NewReader("Proxy Authentication Required"))
+			resp.ContentLength = int64(len("Proxy Authentication Required"))
+		}
+		if err = resp.Write(conn); err != nil || next.closeConnection {
+			return
+		}
+		if next.status == http.StatusOK {
+			// Tunnel established, echo everything back
+			_, _ = io.Copy(conn, br)
+			return
+		}
+	}
+}
+
+func (p *fakeProxy) recordedAuthorizations() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.authorizations...)
+}
+
+type fakeTokenProvider struct {
+	token []byte
+	err   error
+	hosts []string
+}
+
+func (f *fakeTokenProvider) Token(proxyHost string) ([]byte, error) {
+	f.hosts = append(f.hosts, proxyHost)
+	return f.token, f.err
+}
+
+type fakeNTLMFallback struct {
+	calls int
+}
+
+func (f *fakeNTLMFallback) dial(_ context.Context, _, _ string) (net.Conn, error) {
+	f.calls++
+	return nil, errors.New("ntlm fallback used")
+}
+
+func dialThrough(proxy *fakeProxy, tokenProvider TokenProvider, fallback *fakeNTLMFallback) (net.Conn, error) {
+	dialContext := NewNegotiateProxyDialContext(nil, proxy.url(), tokenProvider, fallback.dial, nil)
+	return dialContext(context.Background(), "tcp", targetAddr)
+}
+
+func assertTunnel(t *testing.T, conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err := conn.Write([]byte("ping"))
+	assert.NilError(t, err)
+	reply := make([]byte, len("ping"))
+	_, err = io.ReadFull(conn, reply)
+	assert.NilError(t, err)
+	assert.Equal(t, string(reply), "ping")
+}
+
+func TestNegotiateProxyAuthentication(t *testing.T) {
+	proxy := newFakeProxy(t,
+		scriptedResponse{status: http.StatusProxyAuthRequired, proxyAuthenticate: []string{"Negotiate", "NTLM"}},
+		scriptedResponse{status: http.StatusOK},
+	)
+	tokenProvider := &fakeTokenProvider{token: []byte("kerberos-token")}
+	fallback := &fakeNTLMFallback{}
+
+	conn, err := dialThrough(proxy, tokenProvider, fallback)
+	assert.NilError(t, err)
+	assertTunnel(t, conn)
+
+	assert.DeepEqual(t, proxy.recordedAuthorizations(), []string{"", "Negotiate a2VyYmVyb3MtdG9rZW4="})
+	assert.DeepEqual(t, tokenProvider.hosts, []string{"127.0.0.1"})
+	assert.Equal(t, fallback.calls, 0)
+}
+
+func TestNegotiateProxyAuthenticationOnNewConnection(t *testing.T) {
+	proxy := newFakeProxy(t,
+		scriptedResponse{status: http.StatusProxyAuthRequired, proxyAuthenticate: []string{"Negotiate"}, closeConnection: true},
+		scriptedResponse{status: http.StatusOK},
+	)
+	conn, err := dialThrough(proxy, &fakeTokenProvider{token: []byte("token")}, &fakeNTLMFallback{})
+	assert.NilError(t, err)
+	assertTunnel(t, conn)
+	proxy.mu.Lock()
+	defer proxy.mu.Unlock()
+	assert.Equal(t, proxy.connections, 2)
+}
+
+func TestNegotiateProxyWithoutAuthentication(t *testing.T) {
+	proxy := newFakeProxy(t, scriptedResponse{status: http.StatusOK})
+	tokenProvider := &fakeTokenProvider{token: []byte("token")}
+
+	conn, err := dialThrough(proxy, tokenProvider, &fakeNTLMFallback{})
+	assert.NilError(t, err)
+	assertTunnel(t, conn)
+	assert.Equal(t, len(tokenProvider.hosts), 0, "no token should be requested")
+}
+
+func TestNegotiateProxyFallsBackToNTLM(t *testing.T) {
+	proxy := newFakeProxy(t, scriptedResponse{status: http.StatusProxyAuthRequired, proxyAuthenticate: []string{"NTLM", "Basic realm=\"corp\""}})
+	tokenProvider := &fakeTokenProvider{token: []byte("token")}
+	fallback := &fakeNTLMFallback{}
+
+	_, err := dialThrough(proxy, tokenProvider, fallback)
+	assert.Error(t, err, "ntlm fallback used")
+	assert.Equal(t, fallback.calls, 1)
+	assert.Equal(t, len(tokenProvider.hosts), 0, "no token should be requested")
+}
+
+func TestNegotiateProxyFallsBackToNTLMWithoutKerberosCredentials(t *testing.T) {
+	proxy := newFakeProxy(t, scriptedResponse{status: http.StatusProxyAuthRequired, proxyAuthenticate: []string{"Negotiate, NTLM"}})
+	fallback := &fakeNTLMFallback{}
+
+	_, err := dialThrough(proxy, &fakeTokenProvider{err: errors.New("no credential cache")}, fallback)
+	assert.Error(t, err, "ntlm fallback used")
+	assert.Equal(t, fallback.calls, 1)
+}
+
+func TestNegotiateProxyRejected(t *testing.T) {
+	proxy := newFakeProxy(t,
+		scriptedResponse{status: http.StatusProxyAuthRequired, proxyAuthenticate: []string{"Negotiate"}},
+		scriptedResponse{status: http.StatusProxyAuthRequired, proxyAuthenticate: []string{"Negotiate"}},
+	)
+	fallback := &fakeNTLMFallback{}
+
+	_, err := dialThrough(proxy, &fakeTokenProvider{token: []byte("token")}, fallback)
+	assert.ErrorContains(t, err, "proxy rejected Negotiate authentication")
+	assert.Equal(t, fallback.calls, 0, "NTLM was not offered")
+}
+
+func TestNegotiateProxyOnlyBasic(t *testing.T) {
+	proxy := newFakeProxy(t, scriptedResponse{status: http.StatusProxyAuthRequired, proxyAuthenticate: []string{"Basic realm=\"corp\""}})
+
+	_, err := dialThrough(proxy, &fakeTokenProvider{token: []byte("token")}, &fakeNTLMFallback{})
+	assert.ErrorContains(t, err, "proxy does not offer Negotiate authentication, offered: Basic")
+}
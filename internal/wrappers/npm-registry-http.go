@@ -0,0 +1,63 @@
+package wrappers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	// npmAbbreviatedMetadata asks the registry for the install metadata only, which is much smaller than the
+	// full packument
+	npmAbbreviatedMetadata = "application/vnd.npm.install-v1+json"
+	failedGettingPackument = "Failed to get the npm package %s"
+)
+
+type NpmRegistryHTTPWrapper struct {
+	client *http.Client
+}
+
+func NewNpmRegistryHTTPWrapper() NpmRegistryWrapper {
+	return &NpmRegistryHTTPWrapper{
+		client: GetClient(viper.GetUint(commonParams.ClientTimeoutKey)),
+	}
+}
+
+func (n *NpmRegistryHTTPWrapper) GetPackument(name string) (*NpmPackument, error) {
+	registry := strings.TrimSuffix(viper.GetString(commonParams.NpmRegistryKey), "/")
+	// scoped packages keep their @ but the slash is escaped
+	packageURL := registry + "/" + strings.Replace(url.PathEscape(name), "%40", "@", 1)
+	req, err := http.NewRequest(http.MethodGet, packageURL, http.NoBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, failedGettingPackument, name)
+	}
+	req.Header.Set(acceptHeader, npmAbbreviatedMetadata)
+	setAgentName(req)
+	logger.PrintRequest(req)
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, failedGettingPackument, name)
+	}
+	defer resp.Body.Close()
+	logger.PrintResponse(resp, false)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		packument := &NpmPackument{}
+		err = json.NewDecoder(resp.Body).Decode(packument)
+		if err != nil {
+			return nil, errors.Wrapf(err, failedGettingPackument, name)
+		}
+		return packument, nil
+	case http.StatusNotFound:
+		return nil, errors.Errorf("Package %s not found in the npm registry %s", name, registry)
+	default:
+		return nil, errors.Errorf("%s: %s", fmt.Sprintf(failedGettingPackument, name), resp.Status)
+	}
+}
@@ -0,0 +1,18 @@
+package wrappers
+
+// NpmPackument is the metadata of a package published in an npm registry
+type NpmPackument struct {
+	Name     string                       `json:"name"`
+	Versions map[string]NpmPackageVersion `json:"versions"`
+}
+
+// NpmPackageVersion is the metadata of a version of an npm package
+type NpmPackageVersion struct {
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+}
+
+type NpmRegistryWrapper interface {
+	GetPackument(name string) (*NpmPackument, error)
+}
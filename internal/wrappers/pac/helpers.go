@@ -0,0 +1,300 @@
+package pac
+
+import (
+	"encoding/binary"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+const gmtArgument = "GMT"
+
+var (
+	weekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	months   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+)
+
+// helpers implements the functions every PAC script expects to find in its global scope
+type helpers struct {
+	lookup func(host string) ([]net.IP, error)
+	now    func() time.Time
+}
+
+func registerHelpers(vm *goja.Runtime, h *helpers) error {
+	if h.now == nil {
+		h.now = time.Now
+	}
+	functions := map[string]interface{}{
+		"isPlainHostName":     isPlainHostName,
+		"dnsDomainIs":         dnsDomainIs,
+		"localHostOrDomainIs": localHostOrDomainIs,
+		"dnsDomainLevels":     dnsDomainLevels,
+		"shExpMatch":          shExpMatch,
+		"isResolvable":        h.isResolvable,
+		"isInNet":             h.isInNet,
+		"dnsResolve":          func(host string) goja.Value { return stringOrNull(vm, h.dnsResolve(host)) },
+		"myIpAddress":         myIPAddress,
+		"convert_addr":        convertAddr,
+		"weekdayRange":        func(call goja.FunctionCall) goja.Value { return vm.ToValue(h.weekdayRange(exportArgs(call))) },
+		"dateRange":           func(call goja.FunctionCall) goja.Value { return vm.ToValue(h.dateRange(exportArgs(call))) },
+		"timeRange":           func(call goja.FunctionCall) goja.Value { return vm.ToValue(h.timeRange(exportArgs(call))) },
+		"alert":               func(string) {},
+	}
+	for name, fn := range functions {
+		if err := vm.Set(name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stringOrNull(vm *goja.Runtime, value string) goja.Value {
+	if value == "" {
+		return goja.Null()
+	}
+	return vm.ToValue(value)
+}
+
+func exportArgs(call goja.FunctionCall) []string {
+	args := make([]string, 0, len(call.Arguments))
+	for _, arg := range call.Arguments {
+		args = append(args, strings.ToUpper(arg.String()))
+	}
+	return args
+}
+
+func isPlainHostName(host string) bool {
+	return !strings.Contains(host, ".")
+}
+
+func dnsDomainIs(host, domain string) bool {
+	return strings.HasSuffix(strings.ToLower(host), strings.ToLower(domain))
+}
+
+func localHostOrDomainIs(host, hostDomain string) bool {
+	host = strings.ToLower(host)
+	hostDomain = strings.ToLower(hostDomain)
+	if host == hostDomain {
+		return true
+	}
+	return isPlainHostName(host) && strings.HasPrefix(hostDomain, host+".")
+}
+
+func dnsDomainLevels(host string) int {
+	return strings.Count(host, ".")
+}
+
+func shExpMatch(str, pattern string) bool {
+	var expression strings.Builder
+	expression.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expression.WriteString(".*")
+		case '?':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString("$")
+	matched, err := regexp.MatchString(expression.String(), str)
+	return err == nil && matched
+}
+
+func (h *helpers) resolveIPv4(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.To4()
+	}
+	ips, err := h.lookup(host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range ips {
+		if ipv4 := ip.To4(); ipv4 != nil {
+			return ipv4
+		}
+	}
+	return nil
+}
+
+func (h *helpers) isResolvable(host string) bool {
+	return h.resolveIPv4(host) != nil
+}
+
+func (h *helpers) dnsResolve(host string) string {
+	ip := h.resolveIPv4(host)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+func (h *helpers) isInNet(host, pattern, mask string) bool {
+	ip := h.resolveIPv4(host)
+	patternIP := net.ParseIP(pattern).To4()
+	maskIP := net.ParseIP(mask).To4()
+	if ip == nil || patternIP == nil || maskIP == nil {
+		return false
+	}
+	ipMask := net.IPMask(maskIP)
+	return ip.Mask(ipMask).Equal(patternIP.Mask(ipMask))
+}
+
+func myIPAddress() string {
+	addresses, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, address := range addresses {
+			if ipNet, ok := address.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+				return ipNet.IP.String()
+			}
+		}
+	}
+	return "127.0.0.1"
+}
+
+func convertAddr(ip string) uint32 {
+	ipv4 := net.ParseIP(ip).To4()
+	if ipv4 == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(ipv4)
+}
+
+// currentTime strips the optional trailing "GMT" argument and returns the time to compare with
+func (h *helpers) currentTime(args []string) (time.Time, []string) {
+	now := h.now()
+	if len(args) > 0 && args[len(args)-1] == gmtArgument {
+		return now.UTC(), args[:len(args)-1]
+	}
+	return now.Local(), args
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func inRange(value, low, high int) bool {
+	if low <= high {
+		return value >= low && value <= high
+	}
+	// Ranges wrap around, e.g. weekdayRange("FRI", "MON")
+	return value >= low || value <= high
+}
+
+func (h *helpers) weekdayRange(args []string) bool {
+	now, args := h.currentTime(args)
+	if len(args) == 0 {
+		return false
+	}
+	first := indexOf(weekdays, args[0])
+	last := first
+	if len(args) > 1 {
+		last = indexOf(weekdays, args[1])
+	}
+	if first < 0 || last < 0 {
+		return false
+	}
+	return inRange(int(now.Weekday()), first, last)
+}
+
+func (h *helpers) timeRange(args []string) bool {
+	now, args := h.currentTime(args)
+	values := make([]int, 0, len(args))
+	for _, arg := range args {
+		value, err := strconv.Atoi(arg)
+		if err != nil {
+			return false
+		}
+		values = append(values, value)
+	}
+	seconds := now.Hour()*3600 + now.Minute()*60 + now.Second()
+	switch len(values) {
+	case 1:
+		return now.Hour() == values[0]
+	case 2:
+		return now.Hour() >= values[0] && now.Hour() < values[1]
+	case 4:
+		return inRange(seconds, values[0]*3600+values[1]*60, values[2]*3600+values[3]*60)
+	case 6:
+		return inRange(seconds, values[0]*3600+values[1]*60+values[2], values[3]*3600+values[4]*60+values[5])
+	default:
+		return false
+	}
+}
+
+// dateComponent is one parsed dateRange argument: a day of month, a month name or a year
+type dateComponent struct {
+	day, month, year int
+}
+
+func parseDateComponent(arg string) (dateComponent, bool) {
+	if month := indexOf(months, arg); month >= 0 {
+		return dateComponent{month: month + 1}, true
+	}
+	value, err := strconv.Atoi(arg)
+	if err != nil {
+		return dateComponent{}, false
+	}
+	if value <= 31 {
+		return dateComponent{day: value}, true
+	}
+	return dateComponent{year: value}, true
+}
+
+func mergeDateComponents(args []string) (dateComponent, bool) {
+	var merged dateComponent
+	for _, arg := range args {
+		component, ok := parseDateComponent(arg)
+		if !ok {
+			return dateComponent{}, false
+		}
+		merged.day += component.day
+		merged.month += component.month
+		merged.year += component.year
+	}
+	return merged, true
+}
+
+// ordinal projects a date onto the components that were given, so that
+// "JAN 2024" and "MAR 2024" compare by month and year only
+func (d dateComponent) ordinal(bounds dateComponent) int {
+	value := 0
+	if bounds.year > 0 {
+		value += d.year * 10000
+	}
+	if bounds.month > 0 {
+		value += d.month * 100
+	}
+	if bounds.day > 0 {
+		value += d.day
+	}
+	return value
+}
+
+func (h *helpers) dateRange(args []string) bool {
+	now, args := h.currentTime(args)
+	today := dateComponent{day: now.Day(), month: int(now.Month()), year: now.Year()}
+	if len(args) == 1 {
+		single, ok := mergeDateComponents(args)
+		return ok && today.ordinal(single) == single.ordinal(single)
+	}
+	if len(args) == 0 || len(args)%2 != 0 {
+		return false
+	}
+	low, okLow := mergeDateComponents(args[:len(args)/2])
+	high, okHigh := mergeDateComponents(args[len(args)/2:])
+	if !okLow || !okHigh {
+		return false
+	}
+	return inRange(today.ordinal(low), low.ordinal(low), high.ordinal(low))
+}
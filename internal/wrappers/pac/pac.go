@@ -0,0 +1,132 @@
+package pac
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+)
+
+const (
+	findProxyFunction = "FindProxyForURL"
+	directType        = "DIRECT"
+	proxyType         = "PROXY"
+	httpType          = "HTTP"
+	httpsType         = "HTTPS"
+	socksType         = "SOCKS"
+	socks4Type        = "SOCKS4"
+	socks5Type        = "SOCKS5"
+)
+
+// Entry is a single element of a PAC result such as "PROXY proxy.corp:8080"
+type Entry struct {
+	Type string
+	Host string
+}
+
+// IsDirect reports whether the entry asks for a direct connection
+func (e Entry) IsDirect() bool {
+	return e.Type == directType
+}
+
+// URL returns the proxy URL for the entry, or an empty string for DIRECT
+func (e Entry) URL() string {
+	switch e.Type {
+	case proxyType, httpType:
+		return "http://" + e.Host
+	case httpsType:
+		return "https://" + e.Host
+	case socksType, socks5Type:
+		return "socks5://" + e.Host
+	default:
+		return ""
+	}
+}
+
+// Script is a compiled proxy auto-config script. The embedded interpreter is
+// not safe for concurrent use, so evaluations are serialized.
+type Script struct {
+	mu        sync.Mutex
+	vm        *goja.Runtime
+	findProxy goja.Callable
+}
+
+// Parse compiles a PAC script and binds the standard PAC helper functions
+func Parse(source string) (*Script, error) {
+	return parseWithResolver(source, net.LookupIP)
+}
+
+func parseWithResolver(source string, lookup func(host string) ([]net.IP, error)) (*Script, error) {
+	return parseWithHelpers(source, &helpers{lookup: lookup})
+}
+
+func parseWithHelpers(source string, h *helpers) (*Script, error) {
+	vm := goja.New()
+	if err := registerHelpers(vm, h); err != nil {
+		return nil, err
+	}
+	if _, err := vm.RunString(source); err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate PAC script")
+	}
+	findProxy, ok := goja.AssertFunction(vm.Get(findProxyFunction))
+	if !ok {
+		return nil, errors.Errorf("PAC script does not define %s(url, host)", findProxyFunction)
+	}
+	return &Script{vm: vm, findProxy: findProxy}, nil
+}
+
+// FindProxyForURL runs the script for the given URL and returns its raw result
+func (s *Script) FindProxyForURL(rawURL, host string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, err := s.findProxy(goja.Undefined(), s.vm.ToValue(rawURL), s.vm.ToValue(host))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to run %s", findProxyFunction)
+	}
+	if goja.IsUndefined(result) || goja.IsNull(result) {
+		return "", nil
+	}
+	return result.String(), nil
+}
+
+// ParseResult splits a PAC result string into its entries. An empty result
+// is treated as DIRECT, as browsers do.
+func ParseResult(result string) ([]Entry, error) {
+	var entries []Entry
+	for _, part := range strings.Split(result, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		entryType := strings.ToUpper(fields[0])
+		if entryType == directType {
+			entries = append(entries, Entry{Type: directType})
+			continue
+		}
+		// net/http has no SOCKS4 support, such proxies are bypassed
+		if entryType == socks4Type && len(fields) == 2 {
+			logger.Printf("Warning: SOCKS4 proxies are not supported, connecting directly instead of through '%s'", strings.TrimSpace(part))
+			entries = append(entries, Entry{Type: directType})
+			continue
+		}
+		if len(fields) != 2 || (Entry{Type: entryType}).URL() == "" {
+			return nil, errors.Errorf("invalid PAC result entry '%s'", strings.TrimSpace(part))
+		}
+		entries = append(entries, Entry{Type: entryType, Host: fields[1]})
+	}
+	if len(entries) == 0 {
+		entries = append(entries, Entry{Type: directType})
+	}
+	return entries, nil
+}
+
+func (e Entry) String() string {
+	if e.IsDirect() {
+		return directType
+	}
+	return fmt.Sprintf("%s %s", e.Type, e.Host)
+}
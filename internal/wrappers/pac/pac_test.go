@@ -0,0 +1,189 @@
+package pac
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+const samplePac = `
+function FindProxyForURL(url, host) {
+	if (isPlainHostName(host) || dnsDomainIs(host, ".intranet.corp")) {
+		return "DIRECT";
+	}
+	if (shExpMatch(url, "http://*") && isInNet(host, "10.0.0.0", "255.0.0.0")) {
+		return "PROXY internal-proxy.corp:3128";
+	}
+	if (host === "undefined.corp") {
+		return undefined;
+	}
+	return "PROXY proxy.corp:8080; SOCKS5 socks.corp:1080; DIRECT";
+}
+`
+
+// fakeLookup resolves the hosts of the tests without DNS
+func fakeLookup(host string) ([]net.IP, error) {
+	switch host {
+	case "build.corp":
+		return []net.IP{net.ParseIP("fe80::1"), net.ParseIP("10.1.2.3")}, nil
+	case "public.example.com":
+		return []net.IP{net.ParseIP("93.184.216.34")}, nil
+	default:
+		return nil, errors.New("no such host")
+	}
+}
+
+func TestFindProxyForURL(t *testing.T) {
+	script, err := parseWithResolver(samplePac, fakeLookup)
+	assert.NilError(t, err)
+	for _, tc := range []struct {
+		url, host, expected string
+	}{
+		{"https://wiki/", "wiki", "DIRECT"},
+		{"https://git.intranet.corp/repo", "git.intranet.corp", "DIRECT"},
+		{"http://build.corp/job", "build.corp", "PROXY internal-proxy.corp:3128"},
+		{"https://build.corp/job", "build.corp", "PROXY proxy.corp:8080; SOCKS5 socks.corp:1080; DIRECT"},
+		{"http://public.example.com/", "public.example.com", "PROXY proxy.corp:8080; SOCKS5 socks.corp:1080; DIRECT"},
+		{"https://undefined.corp/", "undefined.corp", ""},
+	} {
+		result, err := script.FindProxyForURL(tc.url, tc.host)
+		assert.NilError(t, err)
+		assert.Equal(t, result, tc.expected, tc.url)
+	}
+}
+
+func TestParseInvalidScript(t *testing.T) {
+	_, err := parseWithResolver("function other() { return 'DIRECT'; }", fakeLookup)
+	assert.ErrorContains(t, err, "does not define FindProxyForURL")
+	_, err = parseWithResolver("function FindProxyForURL(url, host) {", fakeLookup)
+	assert.ErrorContains(t, err, "failed to evaluate PAC script")
+
+	script, err := parseWithResolver("function FindProxyForURL(url, host) { throw new Error('boom'); }", fakeLookup)
+	assert.NilError(t, err)
+	_, err = script.FindProxyForURL("https://host/", "host")
+	assert.ErrorContains(t, err, "failed to run FindProxyForURL")
+}
+
+func TestParseResult(t *testing.T) {
+	entries, err := ParseResult("PROXY proxy.corp:8080; https secure.corp:443;SOCKS socks.corp:1080; SOCKS4 old.corp:1080; DIRECT")
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 5)
+	assert.Equal(t, entries[0].URL(), "http://proxy.corp:8080")
+	assert.Equal(t, entries[1].URL(), "https://secure.corp:443")
+	assert.Equal(t, entries[2].URL(), "socks5://socks.corp:1080")
+	// SOCKS4 is not supported by net/http, it is replaced by a direct connection
+	assert.Assert(t, entries[3].IsDirect())
+	assert.Assert(t, entries[4].IsDirect())
+	assert.Equal(t, entries[4].URL(), "")
+	assert.Equal(t, entries[0].String(), "PROXY proxy.corp:8080")
+	assert.Equal(t, entries[4].String(), "DIRECT")
+}
+
+func TestParseResultEmpty(t *testing.T) {
+	for _, result := range []string{"", "   ", ";;"} {
+		entries, err := ParseResult(result)
+		assert.NilError(t, err)
+		assert.Equal(t, len(entries), 1)
+		assert.Assert(t, entries[0].IsDirect())
+	}
+}
+
+func TestParseResultInvalidEntry(t *testing.T) {
+	for _, result := range []string{"PROXY", "PROXY a:1 b:2", "FTP ftp.corp:21", "DIRECT; TUNNEL tunnel.corp:1", "SOCKS4"} {
+		_, err := ParseResult(result)
+		assert.ErrorContains(t, err, "invalid PAC result entry", result)
+	}
+}
+
+func TestDNSHelpers(t *testing.T) {
+	h := &helpers{lookup: fakeLookup}
+	// the IPv4 address is picked even when an IPv6 one comes first
+	assert.Equal(t, h.dnsResolve("build.corp"), "10.1.2.3")
+	assert.Equal(t, h.dnsResolve("192.168.1.5"), "192.168.1.5")
+	assert.Equal(t, h.dnsResolve("unknown.corp"), "")
+	assert.Assert(t, h.isResolvable("public.example.com"))
+	assert.Assert(t, !h.isResolvable("unknown.corp"))
+
+	assert.Assert(t, h.isInNet("build.corp", "10.0.0.0", "255.0.0.0"))
+	assert.Assert(t, h.isInNet("build.corp", "10.1.2.0", "255.255.255.0"))
+	assert.Assert(t, !h.isInNet("build.corp", "10.1.3.0", "255.255.255.0"))
+	assert.Assert(t, !h.isInNet("public.example.com", "10.0.0.0", "255.0.0.0"))
+	assert.Assert(t, !h.isInNet("unknown.corp", "10.0.0.0", "255.0.0.0"))
+	assert.Assert(t, !h.isInNet("build.corp", "not-an-ip", "255.0.0.0"))
+}
+
+// fixedTimeHelpers runs the time helpers at Friday 2024-03-15 22:30 GMT, which is Saturday 2024-03-16 03:30 in
+// the local zone of the tests
+func fixedTimeHelpers(t *testing.T) *helpers {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+	return &helpers{
+		lookup: fakeLookup,
+		now:    func() time.Time { return time.Date(2024, time.March, 15, 22, 30, 0, 0, time.UTC) },
+	}
+}
+
+func TestWeekdayRange(t *testing.T) {
+	h := fixedTimeHelpers(t)
+	assert.Assert(t, h.weekdayRange([]string{"SAT"}))
+	assert.Assert(t, h.weekdayRange([]string{"FRI", "GMT"}))
+	assert.Assert(t, !h.weekdayRange([]string{"FRI"}))
+	assert.Assert(t, h.weekdayRange([]string{"MON", "FRI", "GMT"}))
+	assert.Assert(t, !h.weekdayRange([]string{"MON", "FRI"}))
+	// ranges wrap around the end of the week
+	assert.Assert(t, h.weekdayRange([]string{"FRI", "MON"}))
+	assert.Assert(t, !h.weekdayRange([]string{"SUN", "THU", "GMT"}))
+	assert.Assert(t, !h.weekdayRange([]string{"SOMEDAY"}))
+	assert.Assert(t, !h.weekdayRange([]string{}))
+}
+
+func TestTimeRange(t *testing.T) {
+	h := fixedTimeHelpers(t)
+	assert.Assert(t, h.timeRange([]string{"3"}))
+	assert.Assert(t, h.timeRange([]string{"22", "GMT"}))
+	assert.Assert(t, h.timeRange([]string{"22", "23", "GMT"}))
+	assert.Assert(t, !h.timeRange([]string{"22", "23"}))
+	assert.Assert(t, h.timeRange([]string{"3", "15", "3", "45"}))
+	assert.Assert(t, h.timeRange([]string{"3", "30", "0", "3", "30", "59"}))
+	// ranges wrap around midnight
+	assert.Assert(t, h.timeRange([]string{"22", "0", "2", "0", "GMT"}))
+	assert.Assert(t, !h.timeRange([]string{"22", "0", "2", "0"}))
+	assert.Assert(t, !h.timeRange([]string{"1", "2", "3"}))
+	assert.Assert(t, !h.timeRange([]string{"noon"}))
+}
+
+func TestDateRange(t *testing.T) {
+	h := fixedTimeHelpers(t)
+	assert.Assert(t, h.dateRange([]string{"16"}))
+	assert.Assert(t, h.dateRange([]string{"15", "GMT"}))
+	assert.Assert(t, !h.dateRange([]string{"15"}))
+	assert.Assert(t, h.dateRange([]string{"MAR"}))
+	assert.Assert(t, h.dateRange([]string{"2024"}))
+	assert.Assert(t, !h.dateRange([]string{"2023"}))
+	assert.Assert(t, h.dateRange([]string{"1", "MAR", "2024", "31", "MAR", "2024"}))
+	assert.Assert(t, h.dateRange([]string{"FEB", "2024", "APR", "2024"}))
+	assert.Assert(t, !h.dateRange([]string{"16", "MAR", "2024", "31", "MAR", "2024", "GMT"}))
+	// ranges wrap around the end of the year
+	assert.Assert(t, h.dateRange([]string{"NOV", "MAR"}))
+	assert.Assert(t, !h.dateRange([]string{"DEC", "FEB"}))
+	assert.Assert(t, !h.dateRange([]string{"1", "MAR", "APR"}))
+	assert.Assert(t, !h.dateRange([]string{"SOMEDAY"}))
+}
+
+func TestTimeHelpersFromScript(t *testing.T) {
+	// the arguments are case insensitive, as in browsers
+	script, err := parseWithHelpers(`function FindProxyForURL(url, host) {
+		if (weekdayRange("fri", "GMT") && timeRange(22, 23, "gmt") && dateRange("mar", "GMT")) {
+			return "DIRECT";
+		}
+		return "PROXY proxy.corp:8080";
+	}`, fixedTimeHelpers(t))
+	assert.NilError(t, err)
+	result, err := script.FindProxyForURL("https://host/", "host")
+	assert.NilError(t, err)
+	assert.Equal(t, result, "DIRECT")
+}
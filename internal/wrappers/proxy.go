@@ -0,0 +1,287 @@
+package wrappers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers/pac"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	directProxyRoute        = "DIRECT"
+	noProxyWildcard         = "*"
+	proxyHostsSeparator     = ","
+	proxyHostsPairSeparator = "="
+	fileScheme              = "file://"
+	defaultHTTPPort         = "80"
+	defaultHTTPSPort        = "443"
+	pacDownloadTimeout      = 30
+)
+
+// ProxyRoute describes how requests to a given URL leave the machine. A nil Proxy means a direct connection.
+type ProxyRoute struct {
+	Proxy  *url.URL
+	Reason string
+}
+
+func (r *ProxyRoute) String() string {
+	if r.Proxy == nil {
+		return directProxyRoute
+	}
+	return r.Proxy.Redacted()
+}
+
+var (
+	pacScripts     = map[string]*pac.Script{}
+	pacScriptsLock sync.Mutex
+)
+
+// ResolveProxyRoute selects the proxy for a target URL. The first matching rule wins:
+// --ignore-proxy, NO_PROXY, the per-host proxy map (cx_proxy_hosts), the PAC file and finally --proxy.
+func ResolveProxyRoute(target *url.URL) (*ProxyRoute, error) {
+	if viper.GetBool(commonParams.IgnoreProxyKey) {
+		return &ProxyRoute{Reason: "--ignore-proxy is set"}, nil
+	}
+	host := strings.ToLower(target.Hostname())
+	if entry, ok := matchNoProxy(viper.GetString(commonParams.NoProxyKey), host, targetPort(target)); ok {
+		return &ProxyRoute{Reason: fmt.Sprintf("host matches NO_PROXY entry '%s'", entry)}, nil
+	}
+	proxyHosts, err := getProxyHosts()
+	if err != nil {
+		return nil, err
+	}
+	if pattern, ok := matchProxyHosts(proxyHosts, host); ok {
+		reason := fmt.Sprintf("host matches proxy host map entry '%s'", pattern)
+		if strings.EqualFold(proxyHosts[pattern], directProxyRoute) {
+			return &ProxyRoute{Reason: reason}, nil
+		}
+		proxyURL, parseErr := parseProxyURL(proxyHosts[pattern])
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		return &ProxyRoute{Proxy: withProxyFlagCredentials(proxyURL), Reason: reason}, nil
+	}
+	if pacLocation := viper.GetString(commonParams.ProxyPacKey); pacLocation != "" {
+		return resolvePacRoute(pacLocation, target)
+	}
+	if proxyStr := viper.GetString(commonParams.ProxyKey); proxyStr != "" {
+		proxyURL, parseErr := parseProxyURL(proxyStr)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		return &ProxyRoute{Proxy: proxyURL, Reason: "proxy configured with --proxy or HTTP_PROXY"}, nil
+	}
+	return &ProxyRoute{Reason: "no proxy configured"}, nil
+}
+
+func proxyForRequest(req *http.Request) (*url.URL, error) {
+	route, err := ResolveProxyRoute(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	logger.PrintIfVerbose(fmt.Sprintf("Proxy route for %s: %s (%s)", req.URL.Host, route, route.Reason))
+	return route.Proxy, nil
+}
+
+func targetPort(target *url.URL) string {
+	if port := target.Port(); port != "" {
+		return port
+	}
+	if target.Scheme == "http" {
+		return defaultHTTPPort
+	}
+	return defaultHTTPSPort
+}
+
+func parseProxyURL(proxyStr string) (*url.URL, error) {
+	if !strings.Contains(proxyStr, "://") {
+		proxyStr = "http://" + proxyStr
+	}
+	proxyURL, err := url.Parse(proxyStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy '%s'", proxyStr)
+	}
+	return proxyURL, nil
+}
+
+// withProxyFlagCredentials reuses the credentials of --proxy for proxies selected by the host map or the PAC
+// file, which usually point at the same corporate proxy without credentials.
+func withProxyFlagCredentials(proxyURL *url.URL) *url.URL {
+	if proxyURL.User != nil {
+		return proxyURL
+	}
+	flagProxy, err := url.Parse(viper.GetString(commonParams.ProxyKey))
+	if err != nil || flagProxy.User == nil {
+		return proxyURL
+	}
+	proxyURL.User = flagProxy.User
+	return proxyURL
+}
+
+// matchNoProxy follows the usual NO_PROXY conventions: comma separated hosts, domains (with or without
+// a leading dot, matching subdomains), IP addresses, CIDR ranges, optional ports and '*' for everything.
+func matchNoProxy(noProxy, host, port string) (string, bool) {
+	hostIP := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == noProxyWildcard {
+			return entry, true
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			if hostIP != nil && ipNet.Contains(hostIP) {
+				return entry, true
+			}
+			continue
+		}
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if hostIP != nil && entryIP.Equal(hostIP) {
+				return entry, true
+			}
+			continue
+		}
+		domain := strings.TrimPrefix(strings.TrimPrefix(entryHost, noProxyWildcard), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return entry, true
+		}
+	}
+	return "", false
+}
+
+// getProxyHosts reads the per-host proxy map. It is a map in the configuration file, or a
+// "host=proxy,host=DIRECT" list when given through CX_PROXY_HOSTS or 'cx configure set'.
+func getProxyHosts() (map[string]string, error) {
+	proxyHosts := map[string]string{}
+	switch value := viper.Get(commonParams.ProxyHostsKey).(type) {
+	case map[string]interface{}:
+		for pattern, proxy := range value {
+			proxyHosts[strings.ToLower(pattern)] = fmt.Sprint(proxy)
+		}
+	case string:
+		for _, pair := range strings.Split(value, proxyHostsSeparator) {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			keyValue := strings.SplitN(pair, proxyHostsPairSeparator, commonParams.KeyValuePairSize)
+			if len(keyValue) != commonParams.KeyValuePairSize {
+				return nil, errors.Errorf("invalid proxy host map entry '%s', expected <host>=<proxy|DIRECT>", pair)
+			}
+			proxyHosts[strings.ToLower(strings.TrimSpace(keyValue[0]))] = strings.TrimSpace(keyValue[1])
+		}
+	}
+	return proxyHosts, nil
+}
+
+// matchProxyHosts returns the longest host pattern matching the host. Patterns are exact host names,
+// shell globs such as '*.corp.com' or domains with a leading dot.
+func matchProxyHosts(proxyHosts map[string]string, host string) (string, bool) {
+	best := ""
+	for pattern := range proxyHosts {
+		if !matchProxyHostPattern(pattern, host) {
+			continue
+		}
+		if len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best = pattern
+		}
+	}
+	return best, best != ""
+}
+
+func matchProxyHostPattern(pattern, host string) bool {
+	if strings.HasPrefix(pattern, ".") && (host == pattern[1:] || strings.HasSuffix(host, pattern)) {
+		return true
+	}
+	matched, err := path.Match(pattern, host)
+	return err == nil && matched
+}
+
+func resolvePacRoute(location string, target *url.URL) (*ProxyRoute, error) {
+	script, err := loadPacScript(location)
+	if err != nil {
+		return nil, err
+	}
+	result, err := script.FindProxyForURL(target.String(), target.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	entries, err := pac.ParseResult(result)
+	if err != nil {
+		return nil, err
+	}
+	// Only the first entry is used, the remaining ones are fail-over alternatives
+	reason := fmt.Sprintf("PAC file '%s' returned '%s'", location, result)
+	if entries[0].IsDirect() {
+		return &ProxyRoute{Reason: reason}, nil
+	}
+	proxyURL, err := url.Parse(entries[0].URL())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy returned by PAC file '%s'", location)
+	}
+	return &ProxyRoute{Proxy: withProxyFlagCredentials(proxyURL), Reason: reason}, nil
+}
+
+func loadPacScript(location string) (*pac.Script, error) {
+	pacScriptsLock.Lock()
+	defer pacScriptsLock.Unlock()
+	if script, ok := pacScripts[location]; ok {
+		return script, nil
+	}
+	source, err := readPacFile(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load PAC file '%s'", location)
+	}
+	script, err := pac.Parse(source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load PAC file '%s'", location)
+	}
+	pacScripts[location] = script
+	return script, nil
+}
+
+func readPacFile(location string) (string, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		// PAC files are served from the internal network, so they are always fetched without a proxy
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: viper.GetBool(commonParams.InsecureFlag)},
+			},
+			Timeout: pacDownloadTimeout * time.Second,
+		}
+		resp, err := client.Get(location)
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode != http.StatusOK {
+			return "", errors.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		content, err := io.ReadAll(resp.Body)
+		return string(content), err
+	}
+	content, err := os.ReadFile(strings.TrimPrefix(location, fileScheme))
+	return string(content), err
+}
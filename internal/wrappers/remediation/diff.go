@@ -0,0 +1,183 @@
+package remediation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffContextLines is the number of unchanged lines around each hunk, the default of diff -u and git diff
+const DiffContextLines = 3
+
+const noNewlineMarker = "\\ No newline at end of file\n"
+
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+// edit is a line of the diff, a and b are the positions of the line in the old and new content
+type edit struct {
+	kind editKind
+	a    int
+	b    int
+}
+
+// UnifiedDiff returns the unified diff between two versions of a file, or an empty string when they are equal.
+// The output can be applied with git apply or patch.
+func UnifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	a := splitLines(from)
+	b := splitLines(to)
+	edits := diffLines(a, b)
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range groupHunks(edits) {
+		writeHunk(&out, a, b, edits[hunk[0]:hunk[1]])
+	}
+	return out.String()
+}
+
+// splitLines splits content in lines that keep their line terminator, so a missing final newline is a change
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script from a to b, using the Myers algorithm
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+	var reversed []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, edit{kind: editEqual, a: x, b: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, edit{kind: editInsert, a: x, b: prevY})
+			} else {
+				reversed = append(reversed, edit{kind: editDelete, a: prevX, b: y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	edits := make([]edit, len(reversed))
+	for i := range reversed {
+		edits[i] = reversed[len(reversed)-1-i]
+	}
+	return edits
+}
+
+// groupHunks returns the [start, end) ranges of edits shown in each hunk, changes closer than twice the context
+// share a hunk
+func groupHunks(edits []edit) [][2]int {
+	var hunks [][2]int
+	for i, e := range edits {
+		if e.kind == editEqual {
+			continue
+		}
+		start := max(0, i-DiffContextLines)
+		end := min(len(edits), i+DiffContextLines+1)
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1][1] {
+			hunks[len(hunks)-1][1] = end
+			continue
+		}
+		hunks = append(hunks, [2]int{start, end})
+	}
+	return hunks
+}
+
+func writeHunk(out *strings.Builder, a, b []string, edits []edit) {
+	aStart, bStart := edits[0].a, edits[0].b
+	aLen, bLen := 0, 0
+	for _, e := range edits {
+		if e.kind != editInsert {
+			aLen++
+		}
+		if e.kind != editDelete {
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+	for _, e := range edits {
+		switch e.kind {
+		case editEqual:
+			writeLine(out, ' ', a[e.a])
+		case editDelete:
+			writeLine(out, '-', a[e.a])
+		case editInsert:
+			writeLine(out, '+', b[e.b])
+		}
+	}
+}
+
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, length)
+	}
+}
+
+func writeLine(out *strings.Builder, prefix byte, line string) {
+	out.WriteByte(prefix)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n" + noNewlineMarker)
+	}
+}
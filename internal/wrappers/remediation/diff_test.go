@@ -0,0 +1,47 @@
+package remediation
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "resource \"aws_lb\" \"test\" {\n  name = \"test\"\n  internal = false\n  load_balancer_type = \"application\"\n}\n" +
+		"\n\n\n\nresource \"aws_lb_listener\" \"listener\" {\n  protocol = \"HTTP\"\n}\n"
+	to := "resource \"aws_lb\" \"test\" {\n  name = \"test\"\n  internal = false\n  load_balancer_type = \"application\"\n" +
+		"  drop_invalid_header_fields = true\n}\n\n\n\n\nresource \"aws_lb_listener\" \"listener\" {\n  protocol = \"HTTPS\"\n}\n"
+	expected := `--- a/main.tf
++++ b/main.tf
+@@ -2,11 +2,12 @@
+   name = "test"
+   internal = false
+   load_balancer_type = "application"
++  drop_invalid_header_fields = true
+ }
+ 
+ 
+ 
+ 
+ resource "aws_lb_listener" "listener" {
+-  protocol = "HTTP"
++  protocol = "HTTPS"
+ }
+`
+	assert.Equal(t, UnifiedDiff("a/main.tf", "b/main.tf", from, to), expected)
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	expected := "--- a/f\n+++ b/f\n@@ -1,5 +1,5 @@\n a\n-b\n+X\n c\n d\n e\n@@ -7,5 +7,5 @@\n g\n h\n i\n-j\n+Y\n k\n"
+	assert.Equal(t, UnifiedDiff("a/f", "b/f", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n", "a\nX\nc\nd\ne\nf\ng\nh\ni\nY\nk\n"), expected)
+}
+
+func TestUnifiedDiffNoChanges(t *testing.T) {
+	assert.Equal(t, UnifiedDiff("a/main.tf", "b/main.tf", "a\nb\n", "a\nb\n"), "")
+}
+
+func TestUnifiedDiffMissingNewline(t *testing.T) {
+	expected := "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"
+	assert.Equal(t, UnifiedDiff("a/f", "b/f", "a\nb", "a\nb\n"), expected)
+	assert.Equal(t, UnifiedDiff("a/f", "b/f", "", "a\n"), "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+a\n")
+}
@@ -0,0 +1,560 @@
+package remediation
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Lockfiles of the npm package managers, in the order they are looked for next to a package.json
+const (
+	NpmShrinkwrapFile = "npm-shrinkwrap.json"
+	NpmLockFile       = "package-lock.json"
+	YarnLockFile      = "yarn.lock"
+	PnpmLockFile      = "pnpm-lock.yaml"
+)
+
+// Package managers that read a lockfile
+const (
+	NpmManager  = "npm"
+	YarnManager = "yarn"
+	PnpmManager = "pnpm"
+)
+
+// NpmLockfiles lists the supported lockfiles in the order they are looked for
+var NpmLockfiles = []string{NpmShrinkwrapFile, NpmLockFile, YarnLockFile, PnpmLockFile}
+
+// npmDependencySections are the sections of a package.json whose dependencies are installed
+var npmDependencySections = []string{"dependencies", "devDependencies", "optionalDependencies"}
+
+// LockPackage is a package installed by a lockfile
+type LockPackage struct {
+	Name    string
+	Version string
+	// Requires are the version ranges of the dependencies, as declared by the package, when the lockfile
+	// records them
+	Requires     map[string]string
+	Dependencies []*LockPackage
+}
+
+// ID returns name@version
+func (p *LockPackage) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+// Lockfile is the dependency graph of a project resolved by a lockfile
+type Lockfile struct {
+	Manager string
+	// Direct are the installed dependencies of the package.json of the project
+	Direct []*LockPackage
+}
+
+// ParseLockfile parses an npm, yarn or pnpm lockfile. The package.json of the project tells which of the locked
+// packages are its direct dependencies, older lockfiles do not record it.
+func ParseLockfile(fileName, content, packageJSON string) (*Lockfile, error) {
+	direct, err := packageJSONDependencies(packageJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse the package.json of %s", fileName)
+	}
+	var lockfile *Lockfile
+	switch strings.ToLower(filepath.Base(fileName)) {
+	case NpmLockFile, NpmShrinkwrapFile:
+		lockfile, err = parseNpmLockfile(content, direct)
+	case YarnLockFile:
+		lockfile, err = parseYarnLockfile(content, direct)
+	case PnpmLockFile:
+		lockfile, err = parsePnpmLockfile(content, direct)
+	default:
+		return nil, errors.Errorf("Unsupported lockfile: %s", fileName)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse lockfile %s", fileName)
+	}
+	sort.Slice(lockfile.Direct, func(i, j int) bool {
+		return lockfile.Direct[i].Name < lockfile.Direct[j].Name
+	})
+	return lockfile, nil
+}
+
+// packageJSONDependencies returns the ranges of the dependencies declared by a package.json
+func packageJSONDependencies(packageJSON string) (map[string]string, error) {
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(packageJSON), &manifest); err != nil {
+		return nil, err
+	}
+	dependencies := map[string]string{}
+	for _, section := range npmDependencySections {
+		var ranges map[string]string
+		if raw, found := manifest[section]; found {
+			if err := json.Unmarshal(raw, &ranges); err != nil {
+				return nil, errors.Wrapf(err, "Invalid %s", section)
+			}
+		}
+		for name, versionRange := range ranges {
+			dependencies[name] = versionRange
+		}
+	}
+	return dependencies, nil
+}
+
+// Paths returns the dependency paths from a direct dependency to the installed versions of a package, version
+// empty matches any version. Each path ends with the package, paths only go through a package once. All the
+// paths are returned, as remediating the package requires each direct dependency installing it, callers
+// displaying them limit their number.
+func (l *Lockfile) Paths(name, version string) [][]*LockPackage {
+	matches := func(p *LockPackage) bool {
+		return p.Name == name && (version == "" || p.Version == version)
+	}
+	reaches := l.reaching(matches)
+	var paths [][]*LockPackage
+	var path []*LockPackage
+	onPath := map[*LockPackage]bool{}
+	var walk func(p *LockPackage)
+	walk = func(p *LockPackage) {
+		if onPath[p] || !reaches[p] {
+			return
+		}
+		path = append(path, p)
+		onPath[p] = true
+		if matches(p) {
+			paths = append(paths, append([]*LockPackage(nil), path...))
+		} else {
+			for _, dependency := range p.Dependencies {
+				walk(dependency)
+			}
+		}
+		onPath[p] = false
+		path = path[:len(path)-1]
+	}
+	for _, direct := range l.Direct {
+		walk(direct)
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i]) < len(paths[j])
+	})
+	return paths
+}
+
+// Packages returns the packages installed from the direct dependencies, sorted by name and version
+func (l *Lockfile) Packages() []*LockPackage {
+	visited := map[*LockPackage]bool{}
+	var packages []*LockPackage
+	stack := append([]*LockPackage(nil), l.Direct...)
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[p] {
+			continue
+		}
+		visited[p] = true
+		packages = append(packages, p)
+		stack = append(stack, p.Dependencies...)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].ID() < packages[j].ID()
+	})
+	return packages
+}
+
+// reaching returns the packages of the graph from which a package matched by matches can be reached
+func (l *Lockfile) reaching(matches func(p *LockPackage) bool) map[*LockPackage]bool {
+	dependents := map[*LockPackage][]*LockPackage{}
+	visited := map[*LockPackage]bool{}
+	var queue []*LockPackage
+	stack := append([]*LockPackage(nil), l.Direct...)
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[p] {
+			continue
+		}
+		visited[p] = true
+		if matches(p) {
+			queue = append(queue, p)
+		}
+		for _, dependency := range p.Dependencies {
+			dependents[dependency] = append(dependents[dependency], p)
+			stack = append(stack, dependency)
+		}
+	}
+	reaches := map[*LockPackage]bool{}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if reaches[p] {
+			continue
+		}
+		reaches[p] = true
+		queue = append(queue, dependents[p]...)
+	}
+	return reaches
+}
+
+// FormatDependencyPath returns the path as a > b@1.0.0 > c@2.0.0
+func FormatDependencyPath(path []*LockPackage) string {
+	ids := make([]string, 0, len(path))
+	for _, p := range path {
+		ids = append(ids, p.ID())
+	}
+	return strings.Join(ids, " > ")
+}
+
+type npmLockfile struct {
+	LockfileVersion int                         `json:"lockfileVersion"`
+	Packages        map[string]npmLockPackage   `json:"packages"`
+	Dependencies    map[string]npmLockPackageV1 `json:"dependencies"`
+}
+
+type npmLockPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Link                 bool              `json:"link"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type npmLockPackageV1 struct {
+	Version      string                      `json:"version"`
+	Requires     map[string]string           `json:"requires"`
+	Dependencies map[string]npmLockPackageV1 `json:"dependencies"`
+}
+
+const nodeModules = "node_modules/"
+
+func parseNpmLockfile(content string, direct map[string]string) (*Lockfile, error) {
+	lock := npmLockfile{}
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+	if len(lock.Packages) > 0 {
+		return parseNpmLockfilePackages(lock.Packages, direct), nil
+	}
+	return parseNpmLockfileDependencies(lock.Dependencies, direct), nil
+}
+
+// parseNpmLockfilePackages reads the packages of lockfile versions 2 and 3, keyed by their location in
+// node_modules. Dependencies are resolved as node does, from the nearest node_modules up to the root.
+func parseNpmLockfilePackages(packages map[string]npmLockPackage, direct map[string]string) *Lockfile {
+	nodes := map[string]*LockPackage{}
+	for location, p := range packages {
+		index := strings.LastIndex(location, nodeModules)
+		if index < 0 || p.Link {
+			continue
+		}
+		name := location[index+len(nodeModules):]
+		if p.Name != "" {
+			name = p.Name
+		}
+		requires := map[string]string{}
+		for _, dependencies := range []map[string]string{p.PeerDependencies, p.OptionalDependencies, p.Dependencies} {
+			for dependency, versionRange := range dependencies {
+				requires[dependency] = versionRange
+			}
+		}
+		nodes[location] = &LockPackage{Name: name, Version: p.Version, Requires: requires}
+	}
+	resolve := func(location, dependency string) *LockPackage {
+		for {
+			prefix := location
+			if prefix != "" {
+				prefix += "/"
+			}
+			if node, found := nodes[prefix+nodeModules+dependency]; found {
+				return node
+			}
+			if location == "" {
+				return nil
+			}
+			index := strings.LastIndex(location, "/"+nodeModules)
+			if index < 0 {
+				location = ""
+			} else {
+				location = location[:index]
+			}
+		}
+	}
+	for location, node := range nodes {
+		for _, dependency := range sortedKeys(node.Requires) {
+			if resolved := resolve(location, dependency); resolved != nil {
+				node.Dependencies = append(node.Dependencies, resolved)
+			}
+		}
+	}
+	lockfile := &Lockfile{Manager: NpmManager}
+	for name := range direct {
+		if node := resolve("", name); node != nil {
+			lockfile.Direct = append(lockfile.Direct, node)
+		}
+	}
+	return lockfile
+}
+
+// parseNpmLockfileDependencies reads the nested dependencies of lockfile version 1
+func parseNpmLockfileDependencies(dependencies map[string]npmLockPackageV1, direct map[string]string) *Lockfile {
+	type scope struct {
+		parent   *scope
+		packages map[string]*LockPackage
+	}
+	var build func(dependencies map[string]npmLockPackageV1, parent *scope) *scope
+	var pending []func()
+	build = func(dependencies map[string]npmLockPackageV1, parent *scope) *scope {
+		s := &scope{parent: parent, packages: map[string]*LockPackage{}}
+		for name, p := range dependencies {
+			s.packages[name] = &LockPackage{Name: name, Version: p.Version, Requires: p.Requires}
+		}
+		for name, p := range dependencies {
+			node := s.packages[name]
+			nested := s
+			if len(p.Dependencies) > 0 {
+				nested = build(p.Dependencies, s)
+			}
+			pending = append(pending, func() {
+				for _, dependency := range sortedKeys(node.Requires) {
+					for lookup := nested; lookup != nil; lookup = lookup.parent {
+						if resolved, found := lookup.packages[dependency]; found {
+							node.Dependencies = append(node.Dependencies, resolved)
+							break
+						}
+					}
+				}
+			})
+		}
+		return s
+	}
+	root := build(dependencies, nil)
+	for _, link := range pending {
+		link()
+	}
+	lockfile := &Lockfile{Manager: NpmManager}
+	for name := range direct {
+		if node, found := root.packages[name]; found {
+			lockfile.Direct = append(lockfile.Direct, node)
+		}
+	}
+	return lockfile
+}
+
+var (
+	yarnEntryRegex      = regexp.MustCompile(`^(\S.*):\s*$`)
+	yarnFieldRegex      = regexp.MustCompile(`^  (\S+?):?\s+"?([^"]*)"?\s*$`)
+	yarnSectionRegex    = regexp.MustCompile(`^  (\w+):\s*$`)
+	yarnDependencyRegex = regexp.MustCompile(`^    "?([^"\s:]+)"?:?\s+"?([^"]*)"?\s*$`)
+)
+
+// parseYarnLockfile reads yarn.lock files of yarn classic and of yarn berry, whose entries are keyed by the
+// name@range they resolve
+func parseYarnLockfile(content string, direct map[string]string) (*Lockfile, error) {
+	nodes := map[string]*LockPackage{}
+	var current *LockPackage
+	section := ""
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if match := yarnEntryRegex.FindStringSubmatch(line); match != nil {
+			current = &LockPackage{Requires: map[string]string{}}
+			section = ""
+			for _, specifier := range strings.Split(match[1], ",") {
+				specifier = strings.Trim(strings.TrimSpace(specifier), `"`)
+				name, _ := splitNpmSpecifier(specifier)
+				if name == "" {
+					continue
+				}
+				current.Name = name
+				nodes[specifier] = current
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if match := yarnSectionRegex.FindStringSubmatch(line); match != nil {
+			section = match[1]
+			continue
+		}
+		if match := yarnFieldRegex.FindStringSubmatch(line); match != nil {
+			section = ""
+			if match[1] == "version" {
+				current.Version = match[2]
+			}
+			continue
+		}
+		if match := yarnDependencyRegex.FindStringSubmatch(line); match != nil &&
+			(section == "dependencies" || section == "optionalDependencies") {
+			current.Requires[match[1]] = match[2]
+		}
+	}
+	resolve := func(name, versionRange string) *LockPackage {
+		for _, specifier := range []string{name + "@" + versionRange, name + "@npm:" + versionRange} {
+			if node, found := nodes[specifier]; found {
+				return node
+			}
+		}
+		return nil
+	}
+	linked := map[*LockPackage]bool{}
+	for _, node := range nodes {
+		if linked[node] {
+			continue
+		}
+		linked[node] = true
+		for _, dependency := range sortedKeys(node.Requires) {
+			if resolved := resolve(dependency, node.Requires[dependency]); resolved != nil {
+				node.Dependencies = append(node.Dependencies, resolved)
+			}
+		}
+	}
+	lockfile := &Lockfile{Manager: YarnManager}
+	for name, versionRange := range direct {
+		if node := resolve(name, versionRange); node != nil {
+			lockfile.Direct = append(lockfile.Direct, node)
+		}
+	}
+	return lockfile, nil
+}
+
+// splitNpmSpecifier splits name@range, the name of scoped packages starts with @
+func splitNpmSpecifier(specifier string) (name, versionRange string) {
+	at := strings.Index(strings.TrimPrefix(specifier, "@"), "@")
+	if at < 0 {
+		return specifier, ""
+	}
+	if strings.HasPrefix(specifier, "@") {
+		at++
+	}
+	return specifier[:at], specifier[at+1:]
+}
+
+type pnpmLockfile struct {
+	Dependencies         map[string]yaml.Node       `yaml:"dependencies"`
+	DevDependencies      map[string]yaml.Node       `yaml:"devDependencies"`
+	OptionalDependencies map[string]yaml.Node       `yaml:"optionalDependencies"`
+	Importers            map[string]pnpmImporter    `yaml:"importers"`
+	Packages             map[string]pnpmLockPackage `yaml:"packages"`
+	Snapshots            map[string]pnpmLockPackage `yaml:"snapshots"`
+}
+
+type pnpmImporter struct {
+	Dependencies         map[string]yaml.Node `yaml:"dependencies"`
+	DevDependencies      map[string]yaml.Node `yaml:"devDependencies"`
+	OptionalDependencies map[string]yaml.Node `yaml:"optionalDependencies"`
+}
+
+type pnpmLockPackage struct {
+	Name                 string            `yaml:"name"`
+	Version              string            `yaml:"version"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// the peer dependencies suffix of a resolved version, 1.0.0(react@18.0.0) or 1.0.0_react@18.0.0 in version 5
+var (
+	pnpmPeersRegex   = regexp.MustCompile(`\(.*$`)
+	pnpmV5PeersRegex = regexp.MustCompile(`_.*$`)
+)
+
+// parsePnpmLockfile reads pnpm-lock.yaml files of lockfile versions 5 to 9. The packages are keyed by
+// /name/version (5), /name@version (6) or name@version (9), the resolved versions of the dependencies may carry
+// the versions of their peer dependencies.
+func parsePnpmLockfile(content string, direct map[string]string) (*Lockfile, error) {
+	lock := pnpmLockfile{}
+	if err := yaml.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+	packages := lock.Packages
+	if len(lock.Snapshots) > 0 {
+		packages = lock.Snapshots
+	}
+	nodes := map[string]*LockPackage{}
+	node := func(name, version string) *LockPackage {
+		version = pnpmPeersRegex.ReplaceAllString(version, "")
+		if strings.HasPrefix(version, "link:") || strings.HasPrefix(version, "file:") {
+			return nil
+		}
+		if strings.HasPrefix(version, "/") || strings.Contains(version, "@") {
+			// the dependency is an alias, its version is the key of the package
+			name, version = pnpmPackageKey(version)
+		} else {
+			version = pnpmV5PeersRegex.ReplaceAllString(version, "")
+		}
+		id := name + "@" + version
+		if n, found := nodes[id]; found {
+			return n
+		}
+		n := &LockPackage{Name: name, Version: version, Requires: map[string]string{}}
+		nodes[id] = n
+		return n
+	}
+	for key, p := range packages {
+		name, version := pnpmPackageKey(key)
+		if p.Name != "" {
+			name = p.Name
+		}
+		if p.Version != "" {
+			version = p.Version
+		}
+		parent := node(name, version)
+		if parent == nil {
+			continue
+		}
+		for _, dependencies := range []map[string]string{p.OptionalDependencies, p.Dependencies} {
+			for _, dependency := range sortedKeys(dependencies) {
+				if child := node(dependency, dependencies[dependency]); child != nil {
+					parent.Dependencies = append(parent.Dependencies, child)
+				}
+			}
+		}
+	}
+	rootDependencies := []map[string]yaml.Node{lock.Dependencies, lock.DevDependencies, lock.OptionalDependencies}
+	if root, found := lock.Importers["."]; found {
+		rootDependencies = []map[string]yaml.Node{root.Dependencies, root.DevDependencies, root.OptionalDependencies}
+	}
+	lockfile := &Lockfile{Manager: PnpmManager}
+	for _, dependencies := range rootDependencies {
+		for name, value := range dependencies {
+			if _, declared := direct[name]; !declared {
+				continue
+			}
+			// version 5 maps the name to the version, later versions to a specifier and a version
+			version := value.Value
+			if value.Kind == yaml.MappingNode {
+				resolved := struct {
+					Version string `yaml:"version"`
+				}{}
+				if err := value.Decode(&resolved); err != nil {
+					return nil, err
+				}
+				version = resolved.Version
+			}
+			if n := node(name, version); n != nil {
+				lockfile.Direct = append(lockfile.Direct, n)
+			}
+		}
+	}
+	return lockfile, nil
+}
+
+// pnpmPackageKey returns the name and version of a package key, /name/version, /name@version or name@version
+func pnpmPackageKey(key string) (name, version string) {
+	key = pnpmPeersRegex.ReplaceAllString(strings.TrimPrefix(key, "/"), "")
+	// version 5 keys end with /version, later versions with @version
+	if index := strings.LastIndex(key, "/"); index > 0 && index+1 < len(key) && key[index+1] >= '0' && key[index+1] <= '9' {
+		return key[:index], pnpmV5PeersRegex.ReplaceAllString(key[index+1:], "")
+	}
+	return splitNpmSpecifier(key)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
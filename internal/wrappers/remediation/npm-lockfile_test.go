@@ -0,0 +1,222 @@
+package remediation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const lockfilePackageJSON = `{"name": "app", "dependencies": {"express": "^4.17.1"}}`
+
+var lockfiles = map[string]string{
+	"package-lock.json v3": `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "^4.17.1"}},
+    "node_modules/body-parser": {"version": "1.19.0", "dependencies": {"qs": "6.7.0"}},
+    "node_modules/express": {"version": "4.17.1", "dependencies": {"body-parser": "1.19.0", "qs": "6.7.0"}},
+    "node_modules/qs": {"version": "6.7.0"}
+  }
+}`,
+	"package-lock.json v1": `{
+  "name": "app",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "body-parser": {"version": "1.19.0", "requires": {"qs": "6.7.0"}},
+    "express": {"version": "4.17.1", "requires": {"body-parser": "1.19.0", "qs": "6.7.0"}},
+    "qs": {"version": "6.7.0"}
+  }
+}`,
+	"yarn.lock classic": `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+body-parser@1.19.0:
+  version "1.19.0"
+  resolved "https://registry.yarnpkg.com/body-parser/-/body-parser-1.19.0.tgz"
+  dependencies:
+    qs "6.7.0"
+
+express@^4.17.1:
+  version "4.17.1"
+  dependencies:
+    body-parser "1.19.0"
+    qs "6.7.0"
+
+qs@6.7.0:
+  version "6.7.0"
+`,
+	"yarn.lock berry": `__metadata:
+  version: 6
+  cacheKey: 8
+
+"body-parser@npm:1.19.0":
+  version: 1.19.0
+  resolution: "body-parser@npm:1.19.0"
+  dependencies:
+    qs: 6.7.0
+  languageName: node
+  linkType: hard
+
+"express@npm:^4.17.1":
+  version: 4.17.1
+  dependencies:
+    body-parser: 1.19.0
+    qs: 6.7.0
+
+"qs@npm:6.7.0":
+  version: 6.7.0
+`,
+	"pnpm-lock.yaml v5": `lockfileVersion: 5.4
+
+specifiers:
+  express: ^4.17.1
+
+dependencies:
+  express: 4.17.1
+
+packages:
+
+  /body-parser/1.19.0:
+    resolution: {integrity: sha512-x}
+    dependencies:
+      qs: 6.7.0
+    dev: false
+
+  /express/4.17.1:
+    resolution: {integrity: sha512-x}
+    dependencies:
+      body-parser: 1.19.0
+      qs: 6.7.0
+    dev: false
+
+  /qs/6.7.0:
+    resolution: {integrity: sha512-x}
+    dev: false
+`,
+	"pnpm-lock.yaml v6": `lockfileVersion: '6.0'
+
+dependencies:
+  express:
+    specifier: ^4.17.1
+    version: 4.17.1
+
+packages:
+
+  /body-parser@1.19.0:
+    resolution: {integrity: sha512-x}
+    dependencies:
+      qs: 6.7.0
+
+  /express@4.17.1:
+    resolution: {integrity: sha512-x}
+    dependencies:
+      body-parser: 1.19.0
+      qs: 6.7.0
+
+  /qs@6.7.0:
+    resolution: {integrity: sha512-x}
+`,
+	"pnpm-lock.yaml v9": `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      express:
+        specifier: ^4.17.1
+        version: 4.17.1
+
+packages:
+
+  body-parser@1.19.0:
+    resolution: {integrity: sha512-x}
+
+  express@4.17.1:
+    resolution: {integrity: sha512-x}
+
+  qs@6.7.0:
+    resolution: {integrity: sha512-x}
+
+snapshots:
+
+  body-parser@1.19.0:
+    dependencies:
+      qs: 6.7.0
+
+  express@4.17.1:
+    dependencies:
+      body-parser: 1.19.0
+      qs: 6.7.0
+
+  qs@6.7.0: {}
+`,
+}
+
+func TestParseLockfile(t *testing.T) {
+	managers := map[string]string{"package-lock.json": NpmManager, "yarn.lock": YarnManager, "pnpm-lock.yaml": PnpmManager}
+	for name, content := range lockfiles {
+		fileName := strings.Fields(name)[0]
+		lockfile, err := ParseLockfile(fileName, content, lockfilePackageJSON)
+		assert.NilError(t, err, name)
+		assert.Equal(t, lockfile.Manager, managers[fileName], name)
+		var paths []string
+		for _, path := range lockfile.Paths("qs", "6.7.0") {
+			paths = append(paths, FormatDependencyPath(path))
+		}
+		assert.DeepEqual(t, paths, []string{"express@4.17.1 > qs@6.7.0", "express@4.17.1 > body-parser@1.19.0 > qs@6.7.0"})
+		assert.Equal(t, len(lockfile.Direct), 1, name)
+		var packages []string
+		for _, p := range lockfile.Packages() {
+			packages = append(packages, p.ID())
+		}
+		assert.DeepEqual(t, packages, []string{"body-parser@1.19.0", "express@4.17.1", "qs@6.7.0"})
+		assert.Equal(t, len(lockfile.Paths("qs", "6.11.0")), 0, name)
+	}
+}
+
+func TestParseLockfileNestedNodeModules(t *testing.T) {
+	lock := `{
+  "lockfileVersion": 2,
+  "packages": {
+    "": {"dependencies": {"express": "^4.17.1", "qs": "^6.11.0"}},
+    "node_modules/express": {"version": "4.17.1", "dependencies": {"qs": "6.7.0"}},
+    "node_modules/express/node_modules/qs": {"version": "6.7.0"},
+    "node_modules/qs": {"version": "6.11.0"}
+  }
+}`
+	lockfile, err := ParseLockfile("package-lock.json", lock, `{"dependencies": {"express": "^4.17.1", "qs": "^6.11.0"}}`)
+	assert.NilError(t, err)
+	paths := lockfile.Paths("qs", "")
+	assert.Equal(t, len(paths), 2)
+	assert.Equal(t, FormatDependencyPath(paths[0]), "qs@6.11.0")
+	assert.Equal(t, FormatDependencyPath(paths[1]), "express@4.17.1 > qs@6.7.0")
+}
+
+func TestLockfilePathsOfManyDirectDependencies(t *testing.T) {
+	const directs = 60
+	var packages, dependencies []string
+	for i := 0; i < directs; i++ {
+		name := fmt.Sprintf("dep%02d", i)
+		dependencies = append(dependencies, fmt.Sprintf(`"%s": "1.0.0"`, name))
+		packages = append(packages, fmt.Sprintf(`"node_modules/%s": {"version": "1.0.0", "dependencies": {"qs": "6.7.0"}}`, name))
+	}
+	packageJSON := `{"dependencies": {` + strings.Join(dependencies, ", ") + `}}`
+	lock := `{"lockfileVersion": 3, "packages": {"": ` + packageJSON + `, ` + strings.Join(packages, ", ") +
+		`, "node_modules/qs": {"version": "6.7.0"}}}`
+	lockfile, err := ParseLockfile("package-lock.json", lock, packageJSON)
+	assert.NilError(t, err)
+	paths := lockfile.Paths("qs", "6.7.0")
+	assert.Equal(t, len(paths), directs, "every direct dependency installing the package is needed to remediate it")
+	assert.Equal(t, FormatDependencyPath(paths[directs-1]), "dep59@1.0.0 > qs@6.7.0")
+}
+
+func TestParseLockfileUnsupported(t *testing.T) {
+	_, err := ParseLockfile("Gemfile.lock", "", lockfilePackageJSON)
+	assert.ErrorContains(t, err, "Unsupported lockfile")
+	_, err = ParseLockfile("package-lock.json", "{", lockfilePackageJSON)
+	assert.ErrorContains(t, err, "Failed to parse lockfile")
+}
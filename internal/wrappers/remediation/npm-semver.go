@@ -0,0 +1,335 @@
+package remediation
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semver is a version as defined by semver.org, the build metadata is ignored
+type semver struct {
+	major      int
+	minor      int
+	patch      int
+	prerelease []string
+}
+
+// partialVersion is a version of a range, of which the minor and patch may be missing or wildcards (-1)
+type partialVersion struct {
+	major      int
+	minor      int
+	patch      int
+	prerelease []string
+}
+
+// comparator is a single constraint of a range, such as >=1.2.0
+type comparator struct {
+	operator string
+	version  semver
+}
+
+var (
+	semverRegex         = regexp.MustCompile(`^\s*[v=]*\s*(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?\s*$`)
+	partialVersionRegex = regexp.MustCompile(`^[v=]*(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+	hyphenRangeRegex    = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+	comparatorRegex     = regexp.MustCompile(`^(<=|>=|<|>|=|\^|~>|~)?\s*(\S*)$`)
+	operatorSpaceRegex  = regexp.MustCompile(`(<=|>=|<|>|=|\^|~>|~)\s+`)
+)
+
+func parseSemver(version string) (semver, bool) {
+	match := semverRegex.FindStringSubmatch(version)
+	if match == nil {
+		return semver{}, false
+	}
+	v := semver{}
+	v.major, _ = strconv.Atoi(match[1])
+	v.minor, _ = strconv.Atoi(match[2])
+	v.patch, _ = strconv.Atoi(match[3])
+	if match[4] != "" {
+		v.prerelease = strings.Split(match[4], ".")
+	}
+	return v, true
+}
+
+// CompareSemver compares two semantic versions, pre-releases included. It reports false when one of them is not a
+// semantic version.
+func CompareSemver(a, b string) (int, bool) {
+	versionA, okA := parseSemver(a)
+	versionB, okB := parseSemver(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	return versionA.compare(versionB), true
+}
+
+func (v semver) compare(other semver) int {
+	if c := compareInts(v.major, other.major); c != 0 {
+		return c
+	}
+	if c := compareInts(v.minor, other.minor); c != 0 {
+		return c
+	}
+	if c := compareInts(v.patch, other.patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := comparePrereleaseIdentifiers(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.prerelease), len(other.prerelease))
+}
+
+// comparePrereleaseIdentifiers compares numeric identifiers numerically, and below alphanumeric ones
+func comparePrereleaseIdentifiers(a, b string) int {
+	numberA, errA := strconv.Atoi(a)
+	numberB, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInts(numberA, numberB)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func (v semver) String() string {
+	version := strconv.Itoa(v.major) + "." + strconv.Itoa(v.minor) + "." + strconv.Itoa(v.patch)
+	if len(v.prerelease) > 0 {
+		version += "-" + strings.Join(v.prerelease, ".")
+	}
+	return version
+}
+
+func (v semver) sameRelease(other semver) bool {
+	return v.major == other.major && v.minor == other.minor && v.patch == other.patch
+}
+
+func parsePartialVersion(version string) (partialVersion, bool) {
+	if version == "" {
+		return partialVersion{major: -1, minor: -1, patch: -1}, true
+	}
+	match := partialVersionRegex.FindStringSubmatch(version)
+	if match == nil {
+		return partialVersion{}, false
+	}
+	number := func(s string) int {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+	p := partialVersion{major: number(match[1]), minor: number(match[2]), patch: number(match[3])}
+	// 1.x.3 is 1.x
+	if p.major < 0 {
+		p.minor = -1
+	}
+	if p.minor < 0 {
+		p.patch = -1
+	}
+	if match[4] != "" && p.patch >= 0 {
+		p.prerelease = strings.Split(match[4], ".")
+	}
+	return p, true
+}
+
+func (p partialVersion) floor() semver {
+	return semver{major: max(p.major, 0), minor: max(p.minor, 0), patch: max(p.patch, 0), prerelease: p.prerelease}
+}
+
+// lowest returns the lowest version of a release, below its pre-releases: 1.2.0-0
+func lowest(major, minor, patch int) semver {
+	return semver{major: major, minor: minor, patch: patch, prerelease: []string{"0"}}
+}
+
+// nextRelease returns the lowest version after the wildcard of the partial version, 1.2 gives 1.3.0-0
+func (p partialVersion) nextRelease() semver {
+	if p.minor < 0 {
+		return lowest(p.major+1, 0, 0)
+	}
+	return lowest(p.major, p.minor+1, 0)
+}
+
+// SatisfiesNpmRange reports whether a version satisfies an npm version range, as npm and node-semver do. Ranges
+// that are not versions, such as tags, URLs or paths, are not satisfied by any version.
+func SatisfiesNpmRange(version, versionRange string) bool {
+	v, ok := parseSemver(version)
+	if !ok {
+		return false
+	}
+	versionRange = strings.TrimSpace(versionRange)
+	if alias := strings.TrimPrefix(versionRange, "npm:"); alias != versionRange {
+		// npm:name@range
+		if at := strings.LastIndex(alias, "@"); at > 0 {
+			versionRange = alias[at+1:]
+		}
+	}
+	for _, set := range strings.Split(versionRange, "||") {
+		comparators, ok := parseComparatorSet(set)
+		if ok && satisfiesAll(v, comparators) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxSatisfyingNpmRange returns the highest version that satisfies the range, or an empty string
+func MaxSatisfyingNpmRange(versions []string, versionRange string) string {
+	best := ""
+	var bestVersion semver
+	for _, version := range versions {
+		if !SatisfiesNpmRange(version, versionRange) {
+			continue
+		}
+		v, _ := parseSemver(version)
+		if best == "" || v.compare(bestVersion) > 0 {
+			best, bestVersion = version, v
+		}
+	}
+	return best
+}
+
+func satisfiesAll(v semver, comparators []comparator) bool {
+	for _, c := range comparators {
+		if !c.test(v) {
+			return false
+		}
+	}
+	if len(v.prerelease) == 0 {
+		return true
+	}
+	// a pre-release only satisfies a range that mentions a pre-release of the same release
+	for _, c := range comparators {
+		if len(c.version.prerelease) > 0 && c.version.sameRelease(v) && c.version.prerelease[0] != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c comparator) test(v semver) bool {
+	comparison := v.compare(c.version)
+	switch c.operator {
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	}
+	return comparison == 0
+}
+
+func parseComparatorSet(set string) ([]comparator, bool) {
+	set = strings.TrimSpace(set)
+	if match := hyphenRangeRegex.FindStringSubmatch(set); match != nil {
+		return hyphenRange(match[1], match[2])
+	}
+	var comparators []comparator
+	for _, field := range strings.Fields(operatorSpaceRegex.ReplaceAllString(set, "$1")) {
+		desugared, ok := desugarComparator(field)
+		if !ok {
+			return nil, false
+		}
+		comparators = append(comparators, desugared...)
+	}
+	return comparators, true
+}
+
+// hyphenRange desugars 1.2 - 2.3.4 to >=1.2.0 <=2.3.4
+func hyphenRange(from, to string) ([]comparator, bool) {
+	low, okLow := parsePartialVersion(from)
+	high, okHigh := parsePartialVersion(to)
+	if !okLow || !okHigh {
+		return nil, false
+	}
+	var comparators []comparator
+	if low.major >= 0 {
+		comparators = append(comparators, comparator{">=", low.floor()})
+	}
+	switch {
+	case high.major < 0:
+	case high.patch < 0:
+		comparators = append(comparators, comparator{"<", high.nextRelease()})
+	default:
+		comparators = append(comparators, comparator{"<=", high.floor()})
+	}
+	return comparators, true
+}
+
+// desugarComparator turns a comparator with a partial version, a caret or a tilde into plain comparators
+func desugarComparator(field string) ([]comparator, bool) {
+	match := comparatorRegex.FindStringSubmatch(field)
+	if match == nil {
+		return nil, false
+	}
+	operator := match[1]
+	p, ok := parsePartialVersion(match[2])
+	if !ok {
+		return nil, false
+	}
+	if p.major < 0 {
+		if operator == "<" || operator == ">" {
+			// <* and >* match nothing
+			return []comparator{{"<", lowest(0, 0, 0)}}, true
+		}
+		return nil, true
+	}
+	floor := p.floor()
+	switch operator {
+	case "^":
+		return []comparator{{">=", floor}, {"<", caretCeiling(p)}}, true
+	case "~", "~>":
+		ceiling := lowest(p.major, p.minor+1, 0)
+		if p.minor < 0 {
+			ceiling = lowest(p.major+1, 0, 0)
+		}
+		return []comparator{{">=", floor}, {"<", ceiling}}, true
+	case ">":
+		if p.patch < 0 {
+			return []comparator{{">=", p.nextRelease()}}, true
+		}
+		return []comparator{{">", floor}}, true
+	case ">=":
+		return []comparator{{">=", floor}}, true
+	case "<":
+		if p.patch < 0 {
+			return []comparator{{"<", lowest(floor.major, floor.minor, 0)}}, true
+		}
+		return []comparator{{"<", floor}}, true
+	case "<=":
+		if p.patch < 0 {
+			return []comparator{{"<", p.nextRelease()}}, true
+		}
+		return []comparator{{"<=", floor}}, true
+	}
+	if p.patch < 0 {
+		return []comparator{{">=", floor}, {"<", p.nextRelease()}}, true
+	}
+	return []comparator{{"=", floor}}, true
+}
+
+// caretCeiling returns the upper bound of a caret range, which allows changes that do not modify the left-most
+// non-zero part of the version
+func caretCeiling(p partialVersion) semver {
+	switch {
+	case p.major > 0 || p.minor < 0:
+		return lowest(p.major+1, 0, 0)
+	case p.minor > 0 || p.patch < 0:
+		return lowest(0, p.minor+1, 0)
+	}
+	return lowest(0, 0, p.patch+1)
+}
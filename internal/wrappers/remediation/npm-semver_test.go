@@ -0,0 +1,51 @@
+package remediation
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSatisfiesNpmRange(t *testing.T) {
+	cases := []struct {
+		version, versionRange string
+		expected              bool
+	}{
+		{"4.17.21", "^4.17.11", true},
+		{"5.0.0", "^4.17.11", false},
+		{"0.2.5", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.4", "^0.0.3", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.9.0", "~1", true},
+		{"1.2.0", "1.2.x", true},
+		{"1.3.0", "1.2.x", false},
+		{"3.1.0", "*", true},
+		{"3.1.0", "", true},
+		{"1.0.0", ">=1.0.0 <2.0.0", true},
+		{"2.0.0", ">=1.0.0 <2.0.0", false},
+		{"1.5.0", "1.2 - 1.4", false},
+		{"1.4.9", "1.2 - 1.4", true},
+		{"2.0.0", "^1.0.0 || ^2.0.0", true},
+		{"1.0.0", ">= 1.0.0", true},
+		{"2.0.0-beta.1", "^2.0.0-alpha", true},
+		{"2.0.0-beta.1", "^1.0.0", false},
+		{"2.1.0-beta.1", "^2.0.0-alpha", false},
+		{"2.0.0", "<2", false},
+		{"1.9.9", "<=1", true},
+		{"4.17.21", "npm:lodash@^4.17.0", true},
+		{"1.0.0", "latest", false},
+		{"1.0.0", "git+https://github.com/a/b.git", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, SatisfiesNpmRange(c.version, c.versionRange), c.expected, "%s in %s", c.version, c.versionRange)
+	}
+}
+
+func TestMaxSatisfyingNpmRange(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "1.10.0", "2.0.0", "2.1.0-rc.1"}
+	assert.Equal(t, MaxSatisfyingNpmRange(versions, "^1.0.0"), "1.10.0")
+	assert.Equal(t, MaxSatisfyingNpmRange(versions, ">=2"), "2.0.0")
+	assert.Equal(t, MaxSatisfyingNpmRange(versions, "^3.0.0"), "")
+}
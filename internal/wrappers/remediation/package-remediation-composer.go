@@ -0,0 +1,126 @@
+package remediation
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
+)
+
+// PackageContentComposer remediates the require and require-dev sections of a composer.json
+type PackageContentComposer PackageContentJSON
+
+var composerSections = []string{"require", "require-dev"}
+
+func (r PackageContentComposer) Parser() (string, error) {
+	ranges, err := jsonObjectStringValues(r.FileContent, composerSections, func(key string) bool {
+		return strings.EqualFold(key, r.PackageIdentifier)
+	})
+	if err != nil {
+		return "", err
+	}
+	var replacements []replacement
+	for _, valueRange := range ranges {
+		constraint := r.FileContent[valueRange[0]:valueRange[1]]
+		logger.PrintIfVerbose("Found package " + r.PackageIdentifier + " with version " + constraint + ", replacing it with " + r.PackageVersion + ".")
+		replacements = append(replacements, replacement{start: valueRange[0], end: valueRange[1], value: bumpConstraint(constraint, r.PackageVersion)})
+	}
+	if len(replacements) == 0 {
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
+	}
+	return applyReplacements(r.FileContent, replacements), nil
+}
+
+// jsonObjectStringValues returns the offsets of the string values, without their quotes, of the keys matched by
+// match in the given objects of the top level object of a JSON document
+func jsonObjectStringValues(content string, objects []string, match func(key string) bool) ([][2]int, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("Failed to parse the file, it is not a JSON object")
+	}
+	var ranges [][2]int
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if !containsString(objects, key.(string)) {
+			if err = skipJSONValue(decoder); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			if err == nil {
+				err = skipJSONValueFrom(decoder, token)
+			}
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for decoder.More() {
+			dependency, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			keyEnd := int(decoder.InputOffset())
+			value, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			if _, isString := value.(string); !isString || !match(dependency.(string)) {
+				if err = skipJSONValueFrom(decoder, value); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			valueEnd := int(decoder.InputOffset())
+			valueStart := keyEnd + strings.IndexByte(content[keyEnd:valueEnd], '"') + 1
+			ranges = append(ranges, [2]int{valueStart, valueEnd - 1})
+		}
+		// closing brace of the object
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return ranges, nil
+}
+
+func skipJSONValue(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	return skipJSONValueFrom(decoder, token)
+}
+
+// skipJSONValueFrom skips the rest of a value of which token was the first token
+func skipJSONValueFrom(decoder *json.Decoder, token json.Token) error {
+	if token != json.Delim('{') && token != json.Delim('[') {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
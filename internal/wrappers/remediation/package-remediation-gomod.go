@@ -0,0 +1,65 @@
+package remediation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+)
+
+// PackageContentGoMod remediates the require directives of a go.mod, and the replace directives that replace the
+// module by another version of itself
+type PackageContentGoMod PackageContentJSON
+
+var (
+	goModRequireRegex = regexp.MustCompile(`^(\s*(?:require\s+)?)(\S+)(\s+)(v\S+)`)
+	goModReplaceRegex = regexp.MustCompile(`^\s*(?:replace\s+)?(\S+)(?:\s+v\S+)?\s*=>\s*(\S+)(\s+)(v\S+)`)
+	goModBlockRegex   = regexp.MustCompile(`^\s*(require|replace|exclude|retract)\s*\(\s*$`)
+)
+
+func (r PackageContentGoMod) Parser() (string, error) {
+	version := r.PackageVersion
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	masked := maskComments(r.FileContent, []string{"//"}, false)
+	var replacements []replacement
+	block := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(masked, "\n") {
+		start := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if match := goModBlockRegex.FindStringSubmatch(line); match != nil {
+			block = match[1]
+			continue
+		}
+		if trimmed == ")" {
+			block = ""
+			continue
+		}
+		directive := block
+		if block == "" {
+			directive = strings.SplitN(trimmed, " ", 2)[0]
+		}
+		switch directive {
+		case "require":
+			match := goModRequireRegex.FindStringSubmatchIndex(line)
+			if match == nil || line[match[4]:match[5]] != r.PackageIdentifier {
+				continue
+			}
+			logger.PrintIfVerbose("Found module " + r.PackageIdentifier + " with version " + line[match[8]:match[9]] + ", replacing it with " + version + ".")
+			replacements = append(replacements, replacement{start: start + match[8], end: start + match[9], value: version})
+		case "replace":
+			match := goModReplaceRegex.FindStringSubmatchIndex(line)
+			if match == nil || line[match[2]:match[3]] != r.PackageIdentifier || line[match[4]:match[5]] != r.PackageIdentifier {
+				continue
+			}
+			replacements = append(replacements, replacement{start: start + match[8], end: start + match[9], value: version})
+		}
+	}
+	if len(replacements) == 0 {
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
+	}
+	return applyReplacements(r.FileContent, replacements), nil
+}
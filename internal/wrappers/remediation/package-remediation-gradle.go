@@ -0,0 +1,137 @@
+package remediation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
+)
+
+// PackageContentGradle remediates a build.gradle or build.gradle.kts, the package identifier is group:name
+type PackageContentGradle PackageContentJSON
+
+const gradleExtBlock = "ext"
+
+var (
+	gradleVariableRefRegex = regexp.MustCompile(`^\$\{?([A-Za-z_][\w.]*)}?$`)
+	gradleMapVersionRegex  = regexp.MustCompile(`\bversion\s*[:=]\s*(['"])([^'"\n]*)['"]`)
+)
+
+func (r PackageContentGradle) Parser() (string, error) {
+	groupID, artifactID := splitMavenIdentifier(r.PackageIdentifier)
+	if groupID == "" || artifactID == "" {
+		return "", errors.Errorf("Package identifier %s of a Gradle file must be group:name", r.PackageIdentifier)
+	}
+	masked := maskComments(r.FileContent, []string{"//"}, true)
+	var replacements []replacement
+	// string notation: 'group:name:version', optionally followed by a classifier or an extension
+	coordinates := regexp.MustCompile(`(['"])` + regexp.QuoteMeta(groupID) + `:` + regexp.QuoteMeta(artifactID) + `:([^'":@\n]+)`)
+	for _, match := range coordinates.FindAllStringSubmatchIndex(masked, -1) {
+		versionReplacement, err := r.versionReplacement(masked, match[4], match[5])
+		if err != nil {
+			return "", err
+		}
+		replacements = append(replacements, versionReplacement)
+	}
+	// map notation: group: 'group', name: 'name', version: 'version' or group = "group", name = "name", version = "version"
+	name := regexp.MustCompile(`\bname\s*[:=]\s*['"]` + regexp.QuoteMeta(artifactID) + `['"]`)
+	group := regexp.MustCompile(`\bgroup\s*[:=]\s*['"]` + regexp.QuoteMeta(groupID) + `['"]`)
+	for _, match := range name.FindAllStringIndex(masked, -1) {
+		lineStart := strings.LastIndexByte(masked[:match[0]], '\n') + 1
+		lineEnd := len(masked)
+		if end := strings.IndexByte(masked[match[1]:], '\n'); end >= 0 {
+			lineEnd = match[1] + end
+		}
+		line := masked[lineStart:lineEnd]
+		if !group.MatchString(line) {
+			continue
+		}
+		version := gradleMapVersionRegex.FindStringSubmatchIndex(line)
+		if version == nil {
+			continue
+		}
+		versionReplacement, err := r.versionReplacement(masked, lineStart+version[4], lineStart+version[5])
+		if err != nil {
+			return "", err
+		}
+		replacements = append(replacements, versionReplacement)
+	}
+	if len(replacements) == 0 {
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
+	}
+	return applyReplacements(r.FileContent, replacements), nil
+}
+
+// versionReplacement replaces a version literal, or the definition of the variable the version refers to
+func (r PackageContentGradle) versionReplacement(masked string, start, end int) (replacement, error) {
+	version := masked[start:end]
+	ref := gradleVariableRefRegex.FindStringSubmatch(version)
+	if ref == nil {
+		logger.PrintIfVerbose("Found package " + r.PackageIdentifier + " with version " + version + ", replacing it with " + r.PackageVersion + ".")
+		return replacement{start: start, end: end, value: r.PackageVersion}, nil
+	}
+	// ${project.ext.name} and ${rootProject.name} refer to the extra property name
+	variable := ref[1][strings.LastIndex(ref[1], ".")+1:]
+	start, end, found := gradleVersionDefinition(masked, variable)
+	if !found {
+		return replacement{}, errors.Errorf(
+			"Version variable %s of package %s is not defined in this file, it may be defined in gradle.properties or a version catalog",
+			variable,
+			r.PackageIdentifier,
+		)
+	}
+	logger.PrintIfVerbose("Found package " + r.PackageIdentifier + " with version variable " + variable + ", replacing it with " + r.PackageVersion + ".")
+	return replacement{start: start, end: end, value: r.PackageVersion}, nil
+}
+
+// gradleVersionDefinition returns the offsets of the value of an extra property or script variable: ext.name = '1.0',
+// ext['name'] = '1.0', extra["name"] = "1.0", val name by extra("1.0"), an assignment in an ext block, or a def or
+// val of the script itself. Assignments of the same name in other blocks set unrelated properties.
+func gradleVersionDefinition(masked, variable string) (start, end int, found bool) {
+	name := regexp.QuoteMeta(variable)
+	extra := regexp.MustCompile(
+		`(?:(?:\b(?:project|rootProject)\.)?\bext\.` + name + `|\b(?:ext|extra)\[['"]` + name + `['"]\])\s*=\s*(['"])([^'"\n]*)['"]` +
+			`|\bval\s+` + name + `\s+by\s+extra\(\s*(")([^"\n]*)"\s*\)`,
+	)
+	if match := extra.FindStringSubmatchIndex(masked); match != nil {
+		if match[4] >= 0 {
+			return match[4], match[5], true
+		}
+		return match[8], match[9], true
+	}
+	assignment := regexp.MustCompile(`(?m)(?:^|[^\w.$])((?:def|val|var)\s+)?` + name + `\s*=\s*(['"])([^'"\n]*)['"]`)
+	for _, match := range assignment.FindAllStringSubmatchIndex(masked, -1) {
+		block, depth := gradleEnclosingBlock(masked, match[0])
+		declared := match[2] >= 0
+		if block == gradleExtBlock || strings.HasSuffix(block, "."+gradleExtBlock) || depth == 0 && declared {
+			return match[6], match[7], true
+		}
+	}
+	return 0, 0, false
+}
+
+// gradleEnclosingBlock returns the name of the innermost block around an offset, like ext for ext { ... }, and the
+// number of blocks around it, zero at the top level of the script
+func gradleEnclosingBlock(masked string, offset int) (name string, depth int) {
+	var blocks []string
+	for i := 0; i < offset; i++ {
+		switch masked[i] {
+		case '{':
+			before := strings.TrimRight(masked[:i], " \t\r\n")
+			nameStart := strings.LastIndexFunc(before, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.'
+			})
+			blocks = append(blocks, before[nameStart+1:])
+		case '}':
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+		}
+	}
+	if len(blocks) == 0 {
+		return "", 0
+	}
+	return blocks[len(blocks)-1], len(blocks)
+}
@@ -0,0 +1,96 @@
+package remediation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
+)
+
+// PackageContentMaven remediates a pom.xml, the package identifier is groupId:artifactId
+type PackageContentMaven PackageContentJSON
+
+const maxPropertyIndirections = 5
+
+var (
+	mavenDependencyRegex  = regexp.MustCompile(`(?s)<dependency>(.*?)</dependency>`)
+	mavenExclusionsRegex  = regexp.MustCompile(`(?s)<exclusions>.*?</exclusions>`)
+	mavenPropertiesRegex  = regexp.MustCompile(`(?s)<properties>(.*?)</properties>`)
+	mavenPropertyRefRegex = regexp.MustCompile(`^\$\{([^}]+)}$`)
+)
+
+func (r PackageContentMaven) Parser() (string, error) {
+	groupID, artifactID := splitMavenIdentifier(r.PackageIdentifier)
+	masked := maskXMLComments(r.FileContent)
+	var replacements []replacement
+	for _, dependency := range mavenDependencyRegex.FindAllStringSubmatchIndex(masked, -1) {
+		offset := dependency[2]
+		// exclusions have their own groupId and artifactId
+		block := mavenExclusionsRegex.ReplaceAllStringFunc(masked[dependency[2]:dependency[3]], blank)
+		if text, _, _ := xmlElement(block, "artifactId"); text != artifactID {
+			continue
+		}
+		if text, _, _ := xmlElement(block, "groupId"); groupID != "" && text != groupID {
+			continue
+		}
+		version, start, end := xmlElement(block, "version")
+		if start < 0 {
+			// the version is managed by dependencyManagement or the parent
+			continue
+		}
+		if ref := mavenPropertyRefRegex.FindStringSubmatch(version); ref != nil {
+			property, err := mavenPropertyReplacement(masked, ref[1], r.PackageVersion, 0)
+			if err != nil {
+				return "", err
+			}
+			logger.PrintIfVerbose("Found package " + r.PackageIdentifier + " with version property " + ref[1] + ", replacing it with " + r.PackageVersion + ".")
+			replacements = append(replacements, property)
+			continue
+		}
+		logger.PrintIfVerbose("Found package " + r.PackageIdentifier + " with version " + version + ", replacing it with " + r.PackageVersion + ".")
+		replacements = append(replacements, replacement{start: offset + start, end: offset + end, value: r.PackageVersion})
+	}
+	if len(replacements) == 0 {
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
+	}
+	return applyReplacements(r.FileContent, replacements), nil
+}
+
+func splitMavenIdentifier(identifier string) (groupID, artifactID string) {
+	parts := strings.Split(identifier, ":")
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return parts[0], parts[1]
+}
+
+// xmlElement returns the trimmed text of the first element with the given name and its offsets, start is -1
+// when there is no such element
+func xmlElement(content, name string) (text string, start, end int) {
+	elementRegex := regexp.MustCompile(`<` + regexp.QuoteMeta(name) + `>\s*(.*?)\s*</` + regexp.QuoteMeta(name) + `>`)
+	match := elementRegex.FindStringSubmatchIndex(content)
+	if match == nil {
+		return "", -1, -1
+	}
+	return content[match[2]:match[3]], match[2], match[3]
+}
+
+// mavenPropertyReplacement replaces the value of a property of the pom, following properties defined by other
+// properties
+func mavenPropertyReplacement(masked, name, version string, depth int) (replacement, error) {
+	if depth > maxPropertyIndirections {
+		return replacement{}, errors.Errorf("Too many indirections resolving property %s", name)
+	}
+	for _, properties := range mavenPropertiesRegex.FindAllStringSubmatchIndex(masked, -1) {
+		value, start, end := xmlElement(masked[properties[2]:properties[3]], name)
+		if start < 0 {
+			continue
+		}
+		if ref := mavenPropertyRefRegex.FindStringSubmatch(value); ref != nil {
+			return mavenPropertyReplacement(masked, ref[1], version, depth+1)
+		}
+		return replacement{start: properties[2] + start, end: properties[2] + end, value: version}, nil
+	}
+	return replacement{}, errors.Errorf("Property %s is not defined in this pom.xml, it may be defined by a parent pom", name)
+}
@@ -0,0 +1,319 @@
+package remediation
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NpmVersions returns the published versions of an npm package with the version ranges of their dependencies
+type NpmVersions func(name string) (map[string]map[string]string, error)
+
+// npmOverrideFields are the fields of a package.json that pin a package in the whole dependency tree
+var npmOverrideFields = map[string][]string{
+	NpmManager:  {"overrides"},
+	YarnManager: {"resolutions"},
+	PnpmManager: {"pnpm", "overrides"},
+}
+
+// NpmOverrideField returns the package.json field that pins packages for a package manager, such as pnpm.overrides
+func NpmOverrideField(manager string) string {
+	return strings.Join(npmOverrideFields[manager], ".")
+}
+
+// AddNpmOverride pins a package to a version in the whole dependency tree of a package.json, with the overrides
+// of npm, the resolutions of yarn or the pnpm.overrides of pnpm. The rest of the file is left untouched.
+func AddNpmOverride(packageJSON, manager, name, version string) (string, error) {
+	field, found := npmOverrideFields[manager]
+	if !found {
+		return "", errors.Errorf("Unsupported package manager: %s", manager)
+	}
+	return jsonSetString(packageJSON, append(append([]string(nil), field...), name), version)
+}
+
+// FindNpmDirectUpgrade returns the lowest version of the direct dependency that starts the paths with which all
+// of them install fixVersion or later of the package that ends them, or no longer install it. The dependencies
+// are resolved to the highest version satisfying their ranges, as a fresh install does. An empty version means
+// no published version fixes every path.
+func FindNpmDirectUpgrade(paths [][]*LockPackage, fixVersion string, versions NpmVersions) (string, error) {
+	if len(paths) == 0 || len(paths[0]) < 2 {
+		return "", nil
+	}
+	direct := paths[0][0]
+	published, err := versions(direct.Name)
+	if err != nil {
+		return "", err
+	}
+	current, _ := parseSemver(direct.Version)
+	var candidates []semver
+	for version := range published {
+		v, ok := parseSemver(version)
+		if !ok || len(v.prerelease) > 0 || v.compare(current) <= 0 {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].compare(candidates[j]) < 0
+	})
+	for i := range candidates {
+		candidate := candidates[i].String()
+		fixed := true
+		for _, path := range paths {
+			fixed, err = npmPathFixed(path, candidate, fixVersion, versions)
+			if err != nil {
+				return "", err
+			}
+			if !fixed {
+				break
+			}
+		}
+		if fixed {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// npmPathFixed resolves a dependency path from a version of its direct dependency
+func npmPathFixed(path []*LockPackage, directVersion, fixVersion string, versions NpmVersions) (bool, error) {
+	name, version := path[0].Name, directVersion
+	for _, next := range path[1:] {
+		published, err := versions(name)
+		if err != nil {
+			return false, err
+		}
+		versionRange, found := published[version][next.Name]
+		if !found {
+			// the path no longer exists
+			return true, nil
+		}
+		nextVersions, err := versions(next.Name)
+		if err != nil {
+			return false, err
+		}
+		resolved := MaxSatisfyingNpmRange(mapKeys(nextVersions), versionRange)
+		if resolved == "" {
+			return false, nil
+		}
+		name, version = next.Name, resolved
+	}
+	return CompareVersions(version, fixVersion) >= 0, nil
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonMember is a member of a JSON object, with the offsets of its key and value
+type jsonMember struct {
+	key        string
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+// jsonSetString sets a string value in nested objects of a JSON document, creating the missing objects. The
+// formatting of the document is kept, new members are indented as their siblings.
+func jsonSetString(content string, path []string, value string) (string, error) {
+	open := skipJSONSpace(content, 0)
+	if open >= len(content) || content[open] != '{' {
+		return "", errors.New("Failed to parse the file, it is not a JSON object")
+	}
+	for depth := 0; depth < len(path); depth++ {
+		key := path[depth]
+		members, closing, err := jsonObjectMembers(content, open)
+		if err != nil {
+			return "", err
+		}
+		var member *jsonMember
+		for i := range members {
+			if members[i].key == key {
+				member = &members[i]
+			}
+		}
+		if member == nil {
+			return insertJSONMember(content, open, closing, members, path[depth:], value), nil
+		}
+		last := depth == len(path)-1
+		switch {
+		case last && content[member.valueStart] == '"':
+			return content[:member.valueStart] + jsonString(value) + content[member.valueEnd:], nil
+		case last && content[member.valueStart] == '{':
+			// npm nested overrides, the version of the package itself is the "." key
+			path = append(path, ".")
+			open = member.valueStart
+		case content[member.valueStart] == '{':
+			open = member.valueStart
+		default:
+			return "", errors.Errorf("Failed to set %s, %s is not an object", strings.Join(path, "."), key)
+		}
+	}
+	return content, nil
+}
+
+// insertJSONMember adds the member path[0], with path[1:] as nested objects, at the end of an object
+func insertJSONMember(content string, open, closing int, members []jsonMember, path []string, value string) string {
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+	parentIndent := lineIndent(content, open)
+	unit := jsonIndentUnit(content)
+	indent := parentIndent + unit
+	inline := false
+	if len(members) > 0 {
+		between := content[open+1 : members[0].keyStart]
+		inline = !strings.Contains(between, "\n")
+		indent = lineIndent(content, members[0].keyStart)
+	}
+	var member string
+	if inline {
+		member = jsonString(path[0]) + ": " + inlineJSONObjects(path[1:], value)
+	} else {
+		member = jsonString(path[0]) + ": " + nestedJSONObjects(path[1:], value, indent, unit, newline)
+	}
+	switch {
+	case len(members) > 0 && inline:
+		end := members[len(members)-1].valueEnd
+		return content[:end] + ", " + member + content[end:]
+	case len(members) > 0:
+		end := members[len(members)-1].valueEnd
+		return content[:end] + "," + newline + indent + member + content[end:]
+	}
+	return content[:open+1] + newline + indent + member + newline + parentIndent + content[closing:]
+}
+
+func nestedJSONObjects(path []string, value, indent, unit, newline string) string {
+	if len(path) == 0 {
+		return jsonString(value)
+	}
+	inner := indent + unit
+	return "{" + newline + inner + jsonString(path[0]) + ": " + nestedJSONObjects(path[1:], value, inner, unit, newline) +
+		newline + indent + "}"
+}
+
+func inlineJSONObjects(path []string, value string) string {
+	if len(path) == 0 {
+		return jsonString(value)
+	}
+	return "{" + jsonString(path[0]) + ": " + inlineJSONObjects(path[1:], value) + "}"
+}
+
+// jsonObjectMembers returns the members of the object opened at open and the offset of its closing brace
+func jsonObjectMembers(content string, open int) ([]jsonMember, int, error) {
+	var members []jsonMember
+	i := skipJSONSpace(content, open+1)
+	for i < len(content) && content[i] != '}' {
+		if content[i] == ',' {
+			i = skipJSONSpace(content, i+1)
+			continue
+		}
+		keyEnd, err := skipJSONValueAt(content, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		var key string
+		if err = json.Unmarshal([]byte(content[i:keyEnd]), &key); err != nil {
+			return nil, 0, errors.Wrap(err, "Failed to parse the file")
+		}
+		colon := skipJSONSpace(content, keyEnd)
+		if colon >= len(content) || content[colon] != ':' {
+			return nil, 0, errors.New("Failed to parse the file, missing colon")
+		}
+		valueStart := skipJSONSpace(content, colon+1)
+		valueEnd, err := skipJSONValueAt(content, valueStart)
+		if err != nil {
+			return nil, 0, err
+		}
+		members = append(members, jsonMember{key: key, keyStart: i, valueStart: valueStart, valueEnd: valueEnd})
+		i = skipJSONSpace(content, valueEnd)
+	}
+	if i >= len(content) {
+		return nil, 0, errors.New("Failed to parse the file, unterminated object")
+	}
+	return members, i, nil
+}
+
+// skipJSONValueAt returns the offset following the value that starts at start
+func skipJSONValueAt(content string, start int) (int, error) {
+	if start >= len(content) {
+		return 0, errors.New("Failed to parse the file, unexpected end")
+	}
+	depth := 0
+	for i := start; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '"':
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+			if depth == 0 {
+				return i + 1, nil
+			}
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+			if depth < 0 {
+				return i, nil
+			}
+		case depth == 0 && (c == ',' || c == ' ' || c == '\t' || c == '\r' || c == '\n'):
+			return i, nil
+		}
+	}
+	if depth > 0 {
+		return 0, errors.New("Failed to parse the file, unexpected end")
+	}
+	return len(content), nil
+}
+
+func skipJSONSpace(content string, i int) int {
+	for i < len(content) && strings.IndexByte(" \t\r\n", content[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// lineIndent returns the white space that starts the line of an offset
+func lineIndent(content string, offset int) string {
+	start := strings.LastIndexByte(content[:offset], '\n') + 1
+	end := start
+	for end < len(content) && (content[end] == ' ' || content[end] == '\t') {
+		end++
+	}
+	return content[start:end]
+}
+
+// jsonIndentUnit returns the indentation of the first member of the document, two spaces when it has none
+func jsonIndentUnit(content string) string {
+	open := skipJSONSpace(content, 0)
+	first := skipJSONSpace(content, open+1)
+	if first < len(content) && strings.Contains(content[open:first], "\n") {
+		if indent := lineIndent(content, first); indent != "" {
+			return indent
+		}
+	}
+	return "  "
+}
+
+// jsonString quotes a string without escaping the HTML characters of version ranges such as >=1.0.0
+func jsonString(s string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
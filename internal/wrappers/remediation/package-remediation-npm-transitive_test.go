@@ -0,0 +1,109 @@
+package remediation
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func TestAddNpmOverride(t *testing.T) {
+	cases := []struct {
+		name, manager, content, expected string
+	}{
+		{
+			"npm new field", NpmManager,
+			"{\n  \"name\": \"app\",\n  \"dependencies\": {\n    \"express\": \"^4.17.1\"\n  }\n}\n",
+			"{\n  \"name\": \"app\",\n  \"dependencies\": {\n    \"express\": \"^4.17.1\"\n  },\n  \"overrides\": {\n    \"qs\": \"6.10.3\"\n  }\n}\n",
+		},
+		{
+			"npm existing override", NpmManager,
+			"{\n  \"overrides\": {\n    \"qs\": \"6.7.0\",\n    \"ms\": \"2.1.3\"\n  }\n}",
+			"{\n  \"overrides\": {\n    \"qs\": \"6.10.3\",\n    \"ms\": \"2.1.3\"\n  }\n}",
+		},
+		{
+			"npm nested override", NpmManager,
+			"{\n  \"overrides\": {\n    \"qs\": {\n      \"side-channel\": \"1.0.4\"\n    }\n  }\n}",
+			"{\n  \"overrides\": {\n    \"qs\": {\n      \"side-channel\": \"1.0.4\",\n      \".\": \"6.10.3\"\n    }\n  }\n}",
+		},
+		{
+			"yarn resolutions", YarnManager,
+			"{\n\t\"resolutions\": {\n\t\t\"ms\": \"2.1.3\"\n\t}\n}",
+			"{\n\t\"resolutions\": {\n\t\t\"ms\": \"2.1.3\",\n\t\t\"qs\": \"6.10.3\"\n\t}\n}",
+		},
+		{
+			"pnpm empty object", PnpmManager,
+			"{\n    \"name\": \"app\",\n    \"pnpm\": {}\n}",
+			"{\n    \"name\": \"app\",\n    \"pnpm\": {\n        \"overrides\": {\n            \"qs\": \"6.10.3\"\n        }\n    }\n}",
+		},
+		{
+			"inline object", PnpmManager,
+			`{"name": "app", "pnpm": {"neverBuiltDependencies": []}}`,
+			`{"name": "app", "pnpm": {"neverBuiltDependencies": [], "overrides": {"qs": "6.10.3"}}}`,
+		},
+		{
+			"windows line endings", NpmManager,
+			"{\r\n  \"name\": \"app\"\r\n}\r\n",
+			"{\r\n  \"name\": \"app\",\r\n  \"overrides\": {\r\n    \"qs\": \"6.10.3\"\r\n  }\r\n}\r\n",
+		},
+	}
+	for _, c := range cases {
+		actual, err := AddNpmOverride(c.content, c.manager, "qs", "6.10.3")
+		assert.NilError(t, err, c.name)
+		assert.Equal(t, actual, c.expected, c.name)
+	}
+}
+
+func TestAddNpmOverrideErrors(t *testing.T) {
+	_, err := AddNpmOverride(`{"overrides": ["qs"]}`, NpmManager, "qs", "6.10.3")
+	assert.ErrorContains(t, err, "is not an object")
+	_, err = AddNpmOverride(`[]`, NpmManager, "qs", "6.10.3")
+	assert.ErrorContains(t, err, "not a JSON object")
+	_, err = AddNpmOverride(`{}`, "bower", "qs", "6.10.3")
+	assert.ErrorContains(t, err, "Unsupported package manager")
+	assert.Equal(t, NpmOverrideField(PnpmManager), "pnpm.overrides")
+}
+
+var registryVersions = map[string]map[string]map[string]string{
+	"express": {
+		"4.17.1":       {"body-parser": "1.19.0", "qs": "6.7.0"},
+		"4.17.2":       {"body-parser": "1.19.1", "qs": "6.9.6"},
+		"4.18.0":       {"body-parser": "1.20.0", "qs": "6.10.3"},
+		"5.0.0-beta.1": {"body-parser": "^2.0.0", "qs": "^6.11.0"},
+	},
+	"body-parser": {
+		"1.19.0": {"qs": "6.7.0"},
+		"1.19.1": {"qs": "6.9.6"},
+		"1.20.0": {"qs": "6.10.3"},
+	},
+	"qs": {"6.7.0": {}, "6.9.6": {}, "6.10.3": {}},
+}
+
+func lookupRegistry(name string) (map[string]map[string]string, error) {
+	versions, found := registryVersions[name]
+	if !found {
+		return nil, errors.Errorf("Package %s not found", name)
+	}
+	return versions, nil
+}
+
+func TestFindNpmDirectUpgrade(t *testing.T) {
+	lockfile, err := ParseLockfile("package-lock.json", lockfiles["package-lock.json v3"], lockfilePackageJSON)
+	assert.NilError(t, err)
+	paths := lockfile.Paths("qs", "6.7.0")
+
+	version, err := FindNpmDirectUpgrade(paths, "6.9.6", lookupRegistry)
+	assert.NilError(t, err)
+	assert.Equal(t, version, "4.17.2")
+	version, err = FindNpmDirectUpgrade(paths, "6.10.0", lookupRegistry)
+	assert.NilError(t, err)
+	assert.Equal(t, version, "4.18.0")
+	version, err = FindNpmDirectUpgrade(paths, "6.11.0", lookupRegistry)
+	assert.NilError(t, err)
+	assert.Equal(t, version, "", "pre-releases are not proposed")
+
+	_, err = FindNpmDirectUpgrade(paths, "6.10.0", func(name string) (map[string]map[string]string, error) {
+		return nil, errors.New("offline")
+	})
+	assert.ErrorContains(t, err, "offline")
+}
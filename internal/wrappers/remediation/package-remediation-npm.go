@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 
 	"github.com/checkmarx/ast-cli/internal/logger"
-	"github.com/pkg/errors"
 )
 
 func (r PackageContentJSON) Parser() (string, error) {
@@ -31,7 +30,7 @@ func (r PackageContentJSON) Parser() (string, error) {
 		return "", err
 	}
 	if !(found || foundInDev) {
-		return "", errors.Errorf("Package " + r.PackageIdentifier + " not found")
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
 	}
 	return string(outString), nil
 }
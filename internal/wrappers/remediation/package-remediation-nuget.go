@@ -0,0 +1,60 @@
+package remediation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
+)
+
+// PackageContentNuget remediates the PackageReference and PackageVersion items of a .csproj and the packages of a
+// packages.config. NuGet package ids are case insensitive.
+type PackageContentNuget PackageContentJSON
+
+var (
+	nugetItemRegex        = regexp.MustCompile(`(?s)<(PackageReference|PackageVersion|package)\b([^>]*?)(/>|>(.*?)</(?:PackageReference|PackageVersion|package)>)`)
+	nugetIDAttributeRegex = regexp.MustCompile(`\b(?:Include|Update|id)\s*=\s*"([^"]*)"`)
+	nugetVersionAttrRegex = regexp.MustCompile(`\b(?:Version|version)\s*=\s*"([^"]*)"`)
+	nugetVersionElemRegex = regexp.MustCompile(`<Version>\s*(.*?)\s*</Version>`)
+	msbuildPropertyRegex  = regexp.MustCompile(`^\$\(([^)]+)\)$`)
+)
+
+func (r PackageContentNuget) Parser() (string, error) {
+	masked := maskXMLComments(r.FileContent)
+	var replacements []replacement
+	for _, item := range nugetItemRegex.FindAllStringSubmatchIndex(masked, -1) {
+		attributes := masked[item[4]:item[5]]
+		id := nugetIDAttributeRegex.FindStringSubmatch(attributes)
+		if id == nil || !strings.EqualFold(id[1], r.PackageIdentifier) {
+			continue
+		}
+		var start, end int
+		if version := nugetVersionAttrRegex.FindStringSubmatchIndex(attributes); version != nil {
+			start, end = item[4]+version[2], item[4]+version[3]
+		} else if item[8] >= 0 {
+			version := nugetVersionElemRegex.FindStringSubmatchIndex(masked[item[8]:item[9]])
+			if version == nil {
+				continue
+			}
+			start, end = item[8]+version[2], item[8]+version[3]
+		} else {
+			// the version is set centrally, by a PackageVersion item
+			continue
+		}
+		value := masked[start:end]
+		if property := msbuildPropertyRegex.FindStringSubmatch(value); property != nil {
+			_, propertyStart, propertyEnd := xmlElement(masked, property[1])
+			if propertyStart < 0 {
+				return "", errors.Errorf("Property %s is not defined in this project, it may be defined by Directory.Build.props", property[1])
+			}
+			start, end = propertyStart, propertyEnd
+		}
+		logger.PrintIfVerbose("Found package " + r.PackageIdentifier + " with version " + value + ", replacing it with " + r.PackageVersion + ".")
+		replacements = append(replacements, replacement{start: start, end: end, value: r.PackageVersion})
+	}
+	if len(replacements) == 0 {
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
+	}
+	return applyReplacements(r.FileContent, replacements), nil
+}
@@ -0,0 +1,222 @@
+package remediation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
+)
+
+// PackageContentRequirements remediates a pip requirements.txt
+type PackageContentRequirements PackageContentJSON
+
+// PackageContentPyproject remediates the PEP 621 and poetry dependencies of a pyproject.toml
+type PackageContentPyproject PackageContentJSON
+
+var (
+	// name, extras, version specifiers and environment markers of a PEP 508 requirement
+	requirementRegex          = regexp.MustCompile(`^(\s*)([A-Za-z0-9][A-Za-z0-9._-]*)(\s*\[[^\]]*])?(\s*)([^;@]*?)(\s*(?:;.*)?)$`)
+	requirementSpecifierRegex = regexp.MustCompile(`^(===|==|~=|>=)\s*[^,<>=!~\s]+$`)
+	requirementOptionRegex    = regexp.MustCompile(`\s+--[A-Za-z]`)
+	requirementHashRegex      = regexp.MustCompile(`(?:^|\s)--hash[=\s]`)
+	pythonNameSeparatorsRegex = regexp.MustCompile(`[-_.]+`)
+	tomlTableRegex            = regexp.MustCompile(`(?m)^[ \t]*\[\[?\s*([^\]]+?)\s*]]?[ \t]*$`)
+	tomlKeyValueRegex         = regexp.MustCompile(`(?m)^[ \t]*["']?([A-Za-z0-9._-]+)["']?[ \t]*=[ \t]*`)
+	tomlStringRegex           = regexp.MustCompile(`"([^"\n]*)"|'([^'\n]*)'`)
+	tomlVersionKeyRegex       = regexp.MustCompile(`\bversion\s*=\s*(?:"([^"\n]*)"|'([^'\n]*)')`)
+	poetryDependencyTable     = regexp.MustCompile(`^tool\.poetry\.(?:dev-dependencies|dependencies|group\.[^.]+\.dependencies)$`)
+)
+
+// normalizePythonName normalizes a package name as PEP 503 does
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparatorsRegex.ReplaceAllString(name, "-"))
+}
+
+// bumpRequirement returns the requirement with the version specifiers replaced, ok is false when the requirement
+// is not of the package
+func bumpRequirement(requirement, name, version string) (bumped string, ok bool) {
+	match := requirementRegex.FindStringSubmatch(requirement)
+	if match == nil || normalizePythonName(match[2]) != normalizePythonName(name) {
+		return "", false
+	}
+	specifier := "==" + version
+	if operator := requirementSpecifierRegex.FindStringSubmatch(strings.TrimSpace(match[5])); operator != nil {
+		specifier = operator[1] + version
+	}
+	return match[1] + match[2] + match[3] + match[4] + specifier + match[6], true
+}
+
+func (r PackageContentRequirements) Parser() (string, error) {
+	masked := maskComments(r.FileContent, []string{"#"}, false)
+	lines := strings.SplitAfter(masked, "\n")
+	var replacements []replacement
+	offset := 0
+	for i := 0; i < len(lines); i++ {
+		start := offset
+		offset += len(lines[i])
+		requirement := strings.TrimRight(lines[i], " \t\r\n")
+		// a trailing backslash continues the requirement on the next line, usually with its --hash options
+		logical := requirement
+		for strings.HasSuffix(logical, `\`) && i+1 < len(lines) {
+			i++
+			offset += len(lines[i])
+			logical = strings.TrimSuffix(logical, `\`) + " " + strings.TrimRight(lines[i], " \t\r\n")
+		}
+		if strings.HasPrefix(strings.TrimSpace(requirement), "-") {
+			// options such as -r, -c or -e
+			continue
+		}
+		end := len(strings.TrimRight(strings.TrimSuffix(requirement, `\`), " \t"))
+		if option := requirementOptionRegex.FindStringIndex(requirement[:end]); option != nil {
+			end = option[0]
+		}
+		bumped, ok := bumpRequirement(requirement[:end], r.PackageIdentifier, r.PackageVersion)
+		if !ok {
+			continue
+		}
+		if requirementHashRegex.MatchString(logical) {
+			return "", errors.Errorf(
+				"Requirement %s is pinned with --hash, regenerate its hashes for version %s instead, e.g. with pip-compile --generate-hashes",
+				strings.TrimSpace(requirement[:end]), r.PackageVersion)
+		}
+		logger.PrintIfVerbose("Found package " + r.PackageIdentifier + " with requirement " + strings.TrimSpace(requirement[:end]) + ", replacing it with " + r.PackageVersion + ".")
+		replacements = append(replacements, replacement{start: start, end: start + end, value: bumped})
+	}
+	if len(replacements) == 0 {
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
+	}
+	return applyReplacements(r.FileContent, replacements), nil
+}
+
+func (r PackageContentPyproject) Parser() (string, error) {
+	masked := maskComments(r.FileContent, []string{"#"}, false)
+	var replacements []replacement
+	tables := tomlTableRegex.FindAllStringSubmatchIndex(masked, -1)
+	for i, table := range tables {
+		name := strings.ReplaceAll(masked[table[2]:table[3]], " ", "")
+		start := table[1]
+		end := len(masked)
+		if i+1 < len(tables) {
+			end = tables[i+1][0]
+		}
+		body := masked[start:end]
+		var found []replacement
+		switch {
+		case name == "project":
+			found = pep621Replacements(body, r.PackageIdentifier, r.PackageVersion, "dependencies")
+		case name == "project.optional-dependencies" || name == "dependency-groups":
+			found = pep621Replacements(body, r.PackageIdentifier, r.PackageVersion, "")
+		case poetryDependencyTable.MatchString(name):
+			found = poetryReplacements(body, r.PackageIdentifier, r.PackageVersion)
+		case poetryDependencyTable.MatchString(name[:max(0, strings.LastIndex(name, "."))]) &&
+			normalizePythonName(name[strings.LastIndex(name, ".")+1:]) == normalizePythonName(r.PackageIdentifier):
+			// [tool.poetry.dependencies.name] table
+			found = tomlVersionReplacements(body, r.PackageVersion)
+		}
+		for _, f := range found {
+			replacements = append(replacements, replacement{start: start + f.start, end: start + f.end, value: f.value})
+		}
+	}
+	if len(replacements) == 0 {
+		return "", &PackageNotFoundError{Package: r.PackageIdentifier}
+	}
+	logger.PrintIfVerbose("Found package " + r.PackageIdentifier + ", replacing its version with " + r.PackageVersion + ".")
+	return applyReplacements(r.FileContent, replacements), nil
+}
+
+// pep621Replacements bumps the requirements of the arrays of a table, or of the array of the given key only
+func pep621Replacements(body, name, version, key string) []replacement {
+	var replacements []replacement
+	for _, keyValue := range tomlKeyValueRegex.FindAllStringSubmatchIndex(body, -1) {
+		if key != "" && body[keyValue[2]:keyValue[3]] != key {
+			continue
+		}
+		if !strings.HasPrefix(body[keyValue[1]:], "[") {
+			continue
+		}
+		arrayEnd := tomlArrayEnd(body, keyValue[1])
+		for _, str := range tomlStringRegex.FindAllStringSubmatchIndex(body[keyValue[1]:arrayEnd], -1) {
+			group := 2
+			if str[2] < 0 {
+				group = 4
+			}
+			start, end := keyValue[1]+str[group], keyValue[1]+str[group+1]
+			if bumped, ok := bumpRequirement(body[start:end], name, version); ok {
+				replacements = append(replacements, replacement{start: start, end: end, value: bumped})
+			}
+		}
+	}
+	return replacements
+}
+
+// tomlArrayEnd returns the offset of the bracket closing the array opened at start
+func tomlArrayEnd(body string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(body)
+}
+
+// poetryReplacements bumps name = "constraint" and name = { version = "constraint" } dependencies
+func poetryReplacements(body, name, version string) []replacement {
+	var replacements []replacement
+	for _, keyValue := range tomlKeyValueRegex.FindAllStringSubmatchIndex(body, -1) {
+		if normalizePythonName(body[keyValue[2]:keyValue[3]]) != normalizePythonName(name) {
+			continue
+		}
+		lineEnd := len(body)
+		if end := strings.IndexByte(body[keyValue[1]:], '\n'); end >= 0 {
+			lineEnd = keyValue[1] + end
+		}
+		value := body[keyValue[1]:lineEnd]
+		if str := tomlStringRegex.FindStringSubmatchIndex(value); str != nil && str[0] == 0 {
+			group := 2
+			if str[2] < 0 {
+				group = 4
+			}
+			start, end := keyValue[1]+str[group], keyValue[1]+str[group+1]
+			replacements = append(replacements, replacement{start: start, end: end, value: bumpConstraint(body[start:end], version)})
+			continue
+		}
+		if strings.HasPrefix(value, "{") {
+			for _, f := range tomlVersionReplacements(value, version) {
+				replacements = append(replacements, replacement{start: keyValue[1] + f.start, end: keyValue[1] + f.end, value: f.value})
+			}
+		}
+	}
+	return replacements
+}
+
+// tomlVersionReplacements bumps the first version key of a table or inline table
+func tomlVersionReplacements(body, version string) []replacement {
+	match := tomlVersionKeyRegex.FindStringSubmatchIndex(body)
+	if match == nil {
+		return nil
+	}
+	group := 2
+	if match[2] < 0 {
+		group = 4
+	}
+	start, end := match[group], match[group+1]
+	return []replacement{{start: start, end: end, value: bumpConstraint(body[start:end], version)}}
+}
@@ -1,5 +1,15 @@
 package remediation
 
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 type Package interface {
 	Parser() (string, error)
 }
@@ -9,3 +19,162 @@ type PackageContentJSON struct {
 	PackageIdentifier string
 	PackageVersion    string
 }
+
+// Package manager files supported by the remediation
+const (
+	NpmPackageFile      = "package.json"
+	MavenPackageFile    = "pom.xml"
+	GradlePackageFile   = "build.gradle"
+	GradleKtsFile       = "build.gradle.kts"
+	PipRequirementsFile = "requirements.txt"
+	PyprojectFile       = "pyproject.toml"
+	GoModFile           = "go.mod"
+	CsprojExtension     = ".csproj"
+	NugetPackagesFile   = "packages.config"
+	ComposerPackageFile = "composer.json"
+)
+
+// PackageNotFoundError is returned by the parsers when the file does not declare the package
+type PackageNotFoundError struct {
+	Package string
+}
+
+func (e *PackageNotFoundError) Error() string {
+	return fmt.Sprintf("Package %s not found", e.Package)
+}
+
+// NewPackage returns the parser of a package manager file, chosen by the name of the file
+func NewPackage(fileName, fileContent, packageIdentifier, packageVersion string) (Package, error) {
+	content := PackageContentJSON{
+		FileContent:       fileContent,
+		PackageIdentifier: packageIdentifier,
+		PackageVersion:    packageVersion,
+	}
+	base := strings.ToLower(filepath.Base(fileName))
+	switch {
+	case base == NpmPackageFile:
+		return content, nil
+	case base == MavenPackageFile:
+		return PackageContentMaven(content), nil
+	case base == GradlePackageFile || base == GradleKtsFile:
+		return PackageContentGradle(content), nil
+	case base == PipRequirementsFile:
+		return PackageContentRequirements(content), nil
+	case base == PyprojectFile:
+		return PackageContentPyproject(content), nil
+	case base == GoModFile:
+		return PackageContentGoMod(content), nil
+	case strings.HasSuffix(base, CsprojExtension) || base == NugetPackagesFile:
+		return PackageContentNuget(content), nil
+	case base == ComposerPackageFile:
+		return PackageContentComposer(content), nil
+	}
+	return nil, errors.Errorf("Unsupported package manager file: %s", fileName)
+}
+
+// IsPackageFileSupported reports whether the remediation supports a package manager file
+func IsPackageFileSupported(fileName string) bool {
+	_, err := NewPackage(fileName, "", "", "")
+	return err == nil
+}
+
+// replacement replaces content[start:end] with value
+type replacement struct {
+	start int
+	end   int
+	value string
+}
+
+// applyReplacements applies non overlapping replacements, leaving the rest of the content untouched
+func applyReplacements(content string, replacements []replacement) string {
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start > replacements[j].start
+	})
+	lastStart := len(content) + 1
+	for _, r := range replacements {
+		if r.end > lastStart {
+			continue
+		}
+		content = content[:r.start] + r.value + content[r.end:]
+		lastStart = r.start
+	}
+	return content
+}
+
+var (
+	xmlCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+	notNewlineRegex = regexp.MustCompile(`[^\n]`)
+)
+
+// maskXMLComments blanks the comments of an XML file keeping the offsets, so commented out elements are not matched
+func maskXMLComments(content string) string {
+	return xmlCommentRegex.ReplaceAllStringFunc(content, blank)
+}
+
+// maskComments blanks the comments of a file keeping the offsets. Comments start with one of lineComments and
+// end with the line, or are /* */ blocks when blockComments is set. Comment markers in quoted strings are ignored.
+func maskComments(content string, lineComments []string, blockComments bool) string {
+	masked := []byte(content)
+	var quote byte
+	for i := 0; i < len(masked); i++ {
+		c := masked[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote || c == '\n' {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			continue
+		}
+		if blockComments && strings.HasPrefix(content[i:], "/*") {
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 2
+			}
+			blankRange(masked, i, i+end+4)
+			i += end + 3
+			continue
+		}
+		for _, marker := range lineComments {
+			if strings.HasPrefix(content[i:], marker) {
+				end := strings.IndexByte(content[i:], '\n')
+				if end < 0 {
+					end = len(content) - i
+				}
+				blankRange(masked, i, i+end)
+				i += end
+				break
+			}
+		}
+	}
+	return string(masked)
+}
+
+func blankRange(content []byte, start, end int) {
+	for i := start; i < end && i < len(content); i++ {
+		if content[i] != '\n' {
+			content[i] = ' '
+		}
+	}
+}
+
+func blank(s string) string {
+	return notNewlineRegex.ReplaceAllString(s, " ")
+}
+
+var constraintOperatorRegex = regexp.MustCompile(`^(\^|~>|~=|~|>=|===|==|=|v)?\s*[0-9A-Za-z][0-9A-Za-z.\-+_]*$`)
+
+// bumpConstraint replaces the version of a constraint, keeping its operator when it is a single constraint such
+// as ^1.2.0, ~=1.2 or >=1.2, any other constraint becomes the version itself
+func bumpConstraint(constraint, version string) string {
+	trimmed := strings.TrimSpace(constraint)
+	match := constraintOperatorRegex.FindStringSubmatch(trimmed)
+	if match == nil || match[1] == "" || match[1] == "v" {
+		return version
+	}
+	return match[1] + version
+}
@@ -0,0 +1,308 @@
+package remediation
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func parse(t *testing.T, fileName, content, identifier, version string) string {
+	p, err := NewPackage(fileName, content, identifier, version)
+	assert.NilError(t, err)
+	output, err := p.Parser()
+	assert.NilError(t, err)
+	return output
+}
+
+func TestMavenRemediation(t *testing.T) {
+	pom := `<project>
+  <properties>
+    <jackson.version>2.9.8</jackson.version>
+    <jackson.databind.version>${jackson.version}</jackson.databind.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.apache.logging.log4j</groupId>
+        <artifactId>log4j-core</artifactId>
+        <version>2.14.1</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <!-- <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.12</version></dependency> -->
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-core</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.databind.version}</version>
+      <exclusions>
+        <exclusion>
+          <groupId>junit</groupId>
+          <artifactId>junit</artifactId>
+        </exclusion>
+      </exclusions>
+    </dependency>
+  </dependencies>
+</project>
+`
+	output := parse(t, "pom.xml", pom, "org.apache.logging.log4j:log4j-core", "2.17.1")
+	assert.Equal(t, output, strings.Replace(pom, "<version>2.14.1</version>", "<version>2.17.1</version>", 1))
+
+	output = parse(t, "module/pom.xml", pom, "com.fasterxml.jackson.core:jackson-databind", "2.12.7")
+	assert.Equal(t, output, strings.Replace(pom, "<jackson.version>2.9.8<", "<jackson.version>2.12.7<", 1))
+
+	p, _ := NewPackage("pom.xml", pom, "junit:junit", "4.13.2")
+	_, err := p.Parser()
+	assert.Error(t, err, "Package junit:junit not found")
+}
+
+func TestGradleRemediation(t *testing.T) {
+	gradle := `ext {
+    springVersion = '5.3.9'
+}
+dependencies {
+    // implementation 'org.yaml:snakeyaml:1.26'
+    implementation 'org.yaml:snakeyaml:1.29'
+    implementation "org.springframework:spring-web:$springVersion"
+    runtimeOnly group: 'com.h2database', name: 'h2', version: '1.4.200'
+    testImplementation 'org.yaml:snakeyaml:1.29:tests@jar'
+}
+`
+	output := parse(t, "build.gradle", gradle, "org.yaml:snakeyaml", "2.0")
+	assert.Equal(t, output, strings.ReplaceAll(gradle, "snakeyaml:1.29", "snakeyaml:2.0"))
+
+	output = parse(t, "build.gradle", gradle, "org.springframework:spring-web", "5.3.33")
+	assert.Equal(t, output, strings.Replace(gradle, "'5.3.9'", "'5.3.33'", 1))
+
+	output = parse(t, "build.gradle", gradle, "com.h2database:h2", "2.2.220")
+	assert.Equal(t, output, strings.Replace(gradle, "'1.4.200'", "'2.2.220'", 1))
+
+	kts := `val jacksonVersion = "2.9.8"
+dependencies {
+    implementation("com.fasterxml.jackson.core:jackson-databind:${jacksonVersion}") /* pinned */
+    implementation(group = "com.h2database", name = "h2", version = "1.4.200")
+    implementation("io.netty:netty-all:${libs.versions.netty}")
+}
+`
+	output = parse(t, "build.gradle.kts", kts, "com.fasterxml.jackson.core:jackson-databind", "2.12.7")
+	assert.Equal(t, output, strings.Replace(kts, `"2.9.8"`, `"2.12.7"`, 1))
+	output = parse(t, "build.gradle.kts", kts, "com.h2database:h2", "2.2.220")
+	assert.Equal(t, output, strings.Replace(kts, `"1.4.200"`, `"2.2.220"`, 1))
+
+	p, _ := NewPackage("build.gradle.kts", kts, "io.netty:netty-all", "4.1.100.Final")
+	_, err := p.Parser()
+	assert.ErrorContains(t, err, "Version variable netty of package io.netty:netty-all is not defined in this file")
+}
+
+func TestGradleRemediationWithoutGroup(t *testing.T) {
+	gradle := `dependencies {
+    implementation 'org.yaml:snakeyaml:1.29'
+    implementation group: '', name: 'snakeyaml', version: '1.29'
+}
+`
+	for _, identifier := range []string{"snakeyaml", ":snakeyaml", "org.yaml:"} {
+		p, _ := NewPackage("build.gradle", gradle, identifier, "2.0")
+		_, err := p.Parser()
+		assert.Error(t, err, "Package identifier "+identifier+" of a Gradle file must be group:name")
+	}
+}
+
+func TestGradleRemediationVersionVariableScope(t *testing.T) {
+	// the assignments in the task and in the publication set other properties, only the ext block defines the version
+	gradle := `tasks.register('report') {
+    springVersion = '0.0.1'
+}
+ext {
+    springVersion = '5.3.9'
+}
+publishing.springVersion = '0.0.2'
+dependencies {
+    implementation "org.springframework:spring-web:$springVersion"
+}
+`
+	output := parse(t, "build.gradle", gradle, "org.springframework:spring-web", "5.3.33")
+	assert.Equal(t, output, strings.Replace(gradle, "'5.3.9'", "'5.3.33'", 1))
+
+	for definition, version := range map[string]string{
+		"ext.springVersion = '5.3.9'":                                         "'5.3.9'",
+		"project.ext['springVersion'] = '5.3.9'":                              "'5.3.9'",
+		"allprojects {\n    ext {\n        springVersion = '5.3.9'\n    }\n}": "'5.3.9'",
+		"def springVersion = '5.3.9'":                                         "'5.3.9'",
+		`extra["springVersion"] = "5.3.9"`:                                    `"5.3.9"`,
+		`val springVersion by extra("5.3.9")`:                                 `"5.3.9"`,
+	} {
+		content := definition + "\ndependencies {\n    implementation \"org.springframework:spring-web:${springVersion}\"\n}\n"
+		output = parse(t, "build.gradle", content, "org.springframework:spring-web", "5.3.33")
+		assert.Equal(t, output, strings.Replace(content, version, strings.ReplaceAll(version, "5.3.9", "5.3.33"), 1), definition)
+	}
+
+	for _, definition := range []string{
+		"springVersion = '5.3.9'",
+		"tasks.register('report') {\n    def springVersion = '5.3.9'\n}",
+		"subprojects {\n    springVersion = '5.3.9'\n}",
+		"other.springVersion = '5.3.9'",
+	} {
+		content := definition + "\ndependencies {\n    implementation \"org.springframework:spring-web:$springVersion\"\n}\n"
+		p, _ := NewPackage("build.gradle", content, "org.springframework:spring-web", "5.3.33")
+		_, err := p.Parser()
+		assert.ErrorContains(t, err, "it may be defined in gradle.properties", definition)
+	}
+}
+
+func TestRequirementsRemediation(t *testing.T) {
+	requirements := `# pinned dependencies
+-r base.txt
+Django==3.2.0  # LTS
+requests[security] >= 2.20 ; python_version >= "3.6"
+PyYAML
+urllib3>=1.25,<2
+django-cors-headers==3.7.0
+`
+	output := parse(t, "requirements.txt", requirements, "django", "3.2.25")
+	assert.Equal(t, output, strings.Replace(requirements, "Django==3.2.0", "Django==3.2.25", 1))
+	output = parse(t, "requirements.txt", requirements, "requests", "2.31.0")
+	assert.Equal(t, output, strings.Replace(requirements, ">= 2.20 ;", ">=2.31.0 ;", 1))
+	output = parse(t, "requirements.txt", requirements, "pyyaml", "6.0.1")
+	assert.Equal(t, output, strings.Replace(requirements, "PyYAML\n", "PyYAML==6.0.1\n", 1))
+	output = parse(t, "requirements.txt", requirements, "urllib3", "1.26.18")
+	assert.Equal(t, output, strings.Replace(requirements, "urllib3>=1.25,<2", "urllib3==1.26.18", 1))
+	output = parse(t, "requirements.txt", requirements, "Django_Cors.Headers", "3.14.0")
+	assert.Equal(t, output, strings.Replace(requirements, "cors-headers==3.7.0", "cors-headers==3.14.0", 1))
+}
+
+func TestRequirementsRemediationHashes(t *testing.T) {
+	requirements := `--require-hashes
+Django==3.2.0 \
+    --hash=sha256:aaaaaaaa \
+    --hash=sha256:bbbbbbbb
+pytest==6.0.0 --hash=sha256:cccccccc
+requests==2.20.0 \
+    ; python_version >= "3.6"
+`
+	for _, name := range []string{"django", "pytest"} {
+		p, _ := NewPackage("requirements.txt", requirements, name, "9.9.9")
+		_, err := p.Parser()
+		assert.ErrorContains(t, err, "is pinned with --hash, regenerate its hashes for version 9.9.9", name)
+	}
+	output := parse(t, "requirements.txt", requirements, "requests", "2.31.0")
+	assert.Equal(t, output, strings.Replace(requirements, "requests==2.20.0 \\\n", "requests==2.31.0 \\\n", 1))
+}
+
+func TestPyprojectRemediation(t *testing.T) {
+	pyproject := `[project]
+name = "demo"
+dependencies = [
+    "requests>=2.20",  # http
+    'Jinja2 == 2.11.3',
+]
+
+[project.optional-dependencies]
+dev = ["pytest~=6.0"]
+
+[tool.poetry.dependencies]
+python = "^3.8"
+flask = "^1.1.2"
+sqlalchemy = { version = "~1.3.0", extras = ["postgresql"] }
+
+[tool.poetry.group.test.dependencies.pytest]
+version = "6.2.0"
+`
+	output := parse(t, "pyproject.toml", pyproject, "requests", "2.31.0")
+	assert.Equal(t, output, strings.Replace(pyproject, "requests>=2.20", "requests>=2.31.0", 1))
+	output = parse(t, "pyproject.toml", pyproject, "jinja2", "3.1.3")
+	assert.Equal(t, output, strings.Replace(pyproject, "Jinja2 == 2.11.3", "Jinja2 ==3.1.3", 1))
+	output = parse(t, "pyproject.toml", pyproject, "flask", "2.2.5")
+	assert.Equal(t, output, strings.Replace(pyproject, `"^1.1.2"`, `"^2.2.5"`, 1))
+	output = parse(t, "pyproject.toml", pyproject, "SQLAlchemy", "1.3.24")
+	assert.Equal(t, output, strings.Replace(pyproject, `"~1.3.0"`, `"~1.3.24"`, 1))
+	output = parse(t, "pyproject.toml", pyproject, "pytest", "7.4.4")
+	assert.Equal(t, output, strings.Replace(strings.Replace(pyproject, "pytest~=6.0", "pytest~=7.4.4", 1), `"6.2.0"`, `"7.4.4"`, 1))
+}
+
+func TestGoModRemediation(t *testing.T) {
+	goMod := `module example.com/demo
+
+go 1.21
+
+require golang.org/x/net v0.7.0
+
+require (
+	github.com/gin-gonic/gin v1.7.0 // pinned
+	golang.org/x/text v0.3.7 // indirect
+	// github.com/pkg/errors v0.8.0
+)
+
+replace golang.org/x/text => golang.org/x/text v0.3.6
+`
+	output := parse(t, "go.mod", goMod, "golang.org/x/net", "0.17.0")
+	assert.Equal(t, output, strings.Replace(goMod, "v0.7.0", "v0.17.0", 1))
+	output = parse(t, "go.mod", goMod, "github.com/gin-gonic/gin", "v1.9.1")
+	assert.Equal(t, output, strings.Replace(goMod, "v1.7.0 // pinned", "v1.9.1 // pinned", 1))
+	output = parse(t, "go.mod", goMod, "golang.org/x/text", "v0.3.8")
+	assert.Equal(t, output, strings.ReplaceAll(strings.ReplaceAll(goMod, "v0.3.7", "v0.3.8"), "v0.3.6", "v0.3.8"))
+
+	p, _ := NewPackage("go.mod", goMod, "github.com/pkg/errors", "v0.9.1")
+	_, err := p.Parser()
+	assert.Error(t, err, "Package github.com/pkg/errors not found")
+}
+
+func TestNugetRemediation(t *testing.T) {
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <NewtonsoftVersion>12.0.1</NewtonsoftVersion>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="$(NewtonsoftVersion)" />
+    <PackageReference Include="System.Text.Encodings.Web">
+      <Version>4.5.0</Version>
+    </PackageReference>
+    <!-- <PackageReference Include="log4net" Version="2.0.8" /> -->
+  </ItemGroup>
+</Project>
+`
+	output := parse(t, "src/App.csproj", csproj, "newtonsoft.json", "13.0.1")
+	assert.Equal(t, output, strings.Replace(csproj, ">12.0.1<", ">13.0.1<", 1))
+	output = parse(t, "src/App.csproj", csproj, "System.Text.Encodings.Web", "4.5.1")
+	assert.Equal(t, output, strings.Replace(csproj, "4.5.0", "4.5.1", 1))
+
+	packagesConfig := `<?xml version="1.0" encoding="utf-8"?>
+<packages>
+  <package id="log4net" version="2.0.8" targetFramework="net48" />
+</packages>
+`
+	output = parse(t, "packages.config", packagesConfig, "log4net", "2.0.10")
+	assert.Equal(t, output, strings.Replace(packagesConfig, `version="2.0.8"`, `version="2.0.10"`, 1))
+}
+
+func TestComposerRemediation(t *testing.T) {
+	composer := `{
+    "name": "demo/app",
+    "require": {
+        "php": ">=7.4",
+        "guzzlehttp/guzzle": "^6.5",
+        "monolog/monolog": "2.0.0"
+    },
+    "require-dev": {"phpunit/phpunit": "~9.5"},
+    "extra": {"guzzlehttp/guzzle": "not a dependency"}
+}
+`
+	output := parse(t, "composer.json", composer, "guzzlehttp/guzzle", "7.4.5")
+	assert.Equal(t, output, strings.Replace(composer, `"^6.5"`, `"^7.4.5"`, 1))
+	output = parse(t, "composer.json", composer, "monolog/monolog", "2.9.2")
+	assert.Equal(t, output, strings.Replace(composer, `"2.0.0"`, `"2.9.2"`, 1))
+	output = parse(t, "composer.json", composer, "phpunit/phpunit", "9.6.0")
+	assert.Equal(t, output, strings.Replace(composer, `"~9.5"`, `"~9.6.0"`, 1))
+}
+
+func TestUnsupportedPackageFile(t *testing.T) {
+	assert.Assert(t, IsPackageFileSupported("web/package.json"))
+	assert.Assert(t, IsPackageFileSupported("Api.csproj"))
+	assert.Assert(t, !IsPackageFileSupported("Gemfile"))
+	_, err := NewPackage("Gemfile", "", "rails", "7.0.0")
+	assert.Error(t, err, "Unsupported package manager file: Gemfile")
+}
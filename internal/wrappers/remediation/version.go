@@ -0,0 +1,112 @@
+package remediation
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// manifestsByManager lists the package manager files of each SCA package manager, as named in the package
+// identifiers of the SCA results
+var manifestsByManager = map[string][]string{
+	"npm":       {NpmPackageFile},
+	"maven":     {MavenPackageFile, GradlePackageFile, GradleKtsFile},
+	"python":    {PipRequirementsFile, PyprojectFile},
+	"pypi":      {PipRequirementsFile, PyprojectFile},
+	"go":        {GoModFile},
+	"nuget":     {CsprojExtension, NugetPackagesFile},
+	"php":       {ComposerPackageFile},
+	"packagist": {ComposerPackageFile},
+}
+
+// IsManifestOf reports whether a file is a package manager file of an SCA package manager
+func IsManifestOf(packageManager, fileName string) bool {
+	base := strings.ToLower(filepath.Base(fileName))
+	for _, manifest := range manifestsByManager[strings.ToLower(packageManager)] {
+		if base == manifest || (strings.HasPrefix(manifest, ".") && strings.HasSuffix(base, manifest)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareVersions compares two package versions segment by segment, numbers numerically and qualifiers
+// alphabetically. A qualifier after the last common segment marks a pre-release: 1.0.0-rc1 < 1.0.0 < 1.0.0.1
+func CompareVersions(a, b string) int {
+	segmentsA := versionSegments(a)
+	segmentsB := versionSegments(b)
+	for i := 0; i < len(segmentsA) && i < len(segmentsB); i++ {
+		if c := compareSegments(segmentsA[i], segmentsB[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(segmentsA) > len(segmentsB):
+		return remainderSign(segmentsA[len(segmentsB)])
+	case len(segmentsA) < len(segmentsB):
+		return -remainderSign(segmentsB[len(segmentsA)])
+	}
+	return 0
+}
+
+// versionSegments splits a version in numeric and alphabetic segments, v1.2.3-beta.1 gives 1 2 3 beta 1
+func versionSegments(version string) []string {
+	version = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(version)), "v")
+	var segments []string
+	var current strings.Builder
+	digits := false
+	for _, r := range version {
+		isDigit := unicode.IsDigit(r)
+		isSeparator := !isDigit && !unicode.IsLetter(r)
+		if current.Len() > 0 && (isSeparator || isDigit != digits) {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+		if !isSeparator {
+			current.WriteRune(r)
+			digits = isDigit
+		}
+	}
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	}
+	return segments
+}
+
+func compareSegments(a, b string) int {
+	numberA, errA := strconv.Atoi(a)
+	numberB, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInts(numberA, numberB)
+	case errA == nil:
+		// a release segment is greater than a qualifier
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// remainderSign tells whether extra segments make a version greater (1.0.1 > 1.0) or smaller (1.0-rc1 < 1.0)
+func remainderSign(segment string) int {
+	if _, err := strconv.Atoi(segment); err == nil {
+		return 1
+	}
+	switch segment {
+	case "final", "ga", "release", "sp":
+		return 1
+	}
+	return -1
+}
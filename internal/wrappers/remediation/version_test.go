@@ -0,0 +1,34 @@
+package remediation
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.4.20", "1.4.5", 1},
+		{"v0.17.0", "0.7.0", 1},
+		{"2.17.1", "2.17.1", 0},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0", "1.0.0.1", -1},
+		{"5.3.33.Final", "5.3.33", 1},
+		{"9.4.41.v20210516", "9.4.41.v20210413", 1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+	}
+	for _, c := range cases {
+		assert.Equal(t, CompareVersions(c.a, c.b), c.expected, "%s <=> %s", c.a, c.b)
+	}
+}
+
+func TestIsManifestOf(t *testing.T) {
+	assert.Assert(t, IsManifestOf("Maven", "app/build.gradle.kts"))
+	assert.Assert(t, IsManifestOf("Nuget", "src/Api.csproj"))
+	assert.Assert(t, IsManifestOf("Python", "requirements.txt"))
+	assert.Assert(t, !IsManifestOf("Npm", "pom.xml"))
+	assert.Assert(t, !IsManifestOf("Ruby", "Gemfile"))
+}
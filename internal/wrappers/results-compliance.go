@@ -0,0 +1,182 @@
+package wrappers
+
+// ComplianceMarkdownTemplate lists the categories of each framework, linking to the findings listed at the end
+const ComplianceMarkdownTemplate = `# Checkmarx One Compliance Report
+
+{{if .ProjectName}}**Project:** {{.ProjectName}} {{end}}{{if .BranchName}}**Branch:** {{.BranchName}} {{end}}**Scan:** {{.ScanID}}{{if .CreatedAt}} **Created:** {{.CreatedAt}}{{end}}
+
+**Findings:** {{len .Findings}}
+{{range .Frameworks}}
+## {{.Name}}
+{{if .Categories}}
+| Category | Total |{{range $.Severities}} {{.}} |{{end}} States | Findings |
+|---|---|{{range $.Severities}}---|{{end}}---|---|
+{{range $category := .Categories}}| {{.Name}} | {{.Total}} |{{range $.Severities}} {{index $category.Severities .}} |{{end}} {{range $state, $count := .States}}{{$state}}: {{$count}} {{end}}| {{range $i, $finding := .Findings}}{{if $i}}, {{end}}[{{$finding}}](#finding-{{$finding}}){{end}} |
+{{end}}{{else}}
+No findings
+{{end}}{{end}}
+## Findings
+{{range .Findings}}
+### <a id="finding-{{.Number}}"></a>{{.Number}}. {{.Title}}
+
+- **Severity:** {{.Severity}}
+- **Engine:** {{.Engine}}
+{{if .State}}- **State:** {{.State}}
+{{end}}{{if .CWE}}- **CWE:** {{.CWE}}
+{{end}}{{if .Locations}}- **Locations:** {{range $i, $location := .Locations}}{{if $i}}, {{end}}{{$location}}{{end}}
+{{end}}{{if .Compliances}}- **Compliances:** {{range $i, $compliance := .Compliances}}{{if $i}}, {{end}}{{$compliance}}{{end}}
+{{end}}{{else}}
+No findings
+{{end}}`
+
+// ComplianceHTMLTemplate is the HTML version of ComplianceMarkdownTemplate, a single file without external resources
+const ComplianceHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+
+<head>
+    <meta http-equiv="Content-type" content="text/html; charset=utf-8">
+    <meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Checkmarx One Compliance Report</title>
+    <style type="text/css">
+        * {
+            box-sizing: border-box;
+            margin: 0;
+            padding: 0;
+        }
+
+        body {
+            background-color: #f7f7f8;
+            color: #565360;
+            font-family: Arial, Helvetica, sans-serif;
+            font-size: 14px;
+            padding: 24px;
+        }
+
+        h1 {
+            font-size: 22px;
+            margin-bottom: 8px;
+        }
+
+        h2 {
+            font-size: 18px;
+            margin: 24px 0 8px;
+        }
+
+        .scan-info span {
+            margin-right: 20px;
+        }
+
+        table {
+            background: #fff;
+            border: 1px solid #dad8dc;
+            border-collapse: collapse;
+            width: 100%;
+        }
+
+        th,
+        td {
+            border-bottom: 1px solid #dad8dc;
+            padding: 6px 8px;
+            text-align: left;
+            vertical-align: top;
+        }
+
+        th {
+            background-color: #ececef;
+            font-size: 12px;
+            text-transform: uppercase;
+        }
+
+        td.count {
+            text-align: right;
+        }
+
+        tr.empty td {
+            color: #95939b;
+        }
+
+        .location {
+            font-family: monospace;
+            word-break: break-all;
+        }
+
+        :target {
+            background-color: #fff6e5;
+        }
+    </style>
+</head>
+
+<body>
+    <h1>Checkmarx One Compliance Report</h1>
+    <div class="scan-info">
+        {{if .ProjectName}}<span>Project: {{.ProjectName}}</span>{{end}}
+        {{if .BranchName}}<span>Branch: {{.BranchName}}</span>{{end}}
+        <span>Scan: {{.ScanID}}</span>
+        {{if .CreatedAt}}<span>Created: {{.CreatedAt}}</span>{{end}}
+        <span>Findings: {{len .Findings}}</span>
+    </div>
+    {{range .Frameworks}}
+    <h2>{{.Name}} ({{.Total}})</h2>
+    {{if .Categories}}
+    <table>
+        <thead>
+            <tr>
+                <th>Category</th>
+                <th>Total</th>
+                {{range $.Severities}}<th>{{.}}</th>{{end}}
+                <th>States</th>
+                <th>Findings</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range $category := .Categories}}
+            <tr{{if not .Total}} class="empty"{{end}}>
+                <td>{{.Name}}</td>
+                <td class="count">{{.Total}}</td>
+                {{range $.Severities}}<td class="count">{{index $category.Severities .}}</td>{{end}}
+                <td>{{range $state, $count := .States}}<div>{{$state}}: {{$count}}</div>{{end}}</td>
+                <td>{{range .Findings}}<a href="#finding-{{.}}">{{.}}</a> {{end}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{else}}
+    <p>No findings</p>
+    {{end}}
+    {{end}}
+    <h2>Findings</h2>
+    <table>
+        <thead>
+            <tr>
+                <th>#</th>
+                <th>Severity</th>
+                <th>Engine</th>
+                <th>Finding</th>
+                <th>CWE</th>
+                <th>Location</th>
+                <th>State</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Findings}}
+            <tr id="finding-{{.Number}}">
+                <td>{{.Number}}</td>
+                <td>{{.Severity}}</td>
+                <td>{{.Engine}}</td>
+                <td>{{.Title}}{{if .Compliances}}<div>{{range $i, $compliance := .Compliances}}{{if $i}}, {{end}}{{$compliance}}{{end}}</div>{{end}}</td>
+                <td>{{.CWE}}</td>
+                <td class="location">{{range .Locations}}<div>{{.}}</div>{{end}}</td>
+                <td>{{.State}}</td>
+            </tr>
+            {{else}}
+            <tr class="empty">
+                <td colspan="7">No findings</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</body>
+
+</html>
+`
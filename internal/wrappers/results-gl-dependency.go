@@ -0,0 +1,77 @@
+package wrappers
+
+const (
+	GlDependencyScanningType = "dependency_scanning"
+	GlContainerScanningType  = "container_scanning"
+	GlDependencyAnalyzerID   = AnalyzerName + "-SCA"
+	GlContainerAnalyzerID    = AnalyzerName + "-Containers"
+)
+
+// GlDependencyResultsCollection is a GitLab dependency scanning or container scanning report. Dependency files
+// are only part of dependency scanning reports.
+type GlDependencyResultsCollection struct {
+	Scan            ScanGlReport                `json:"scan"`
+	Schema          string                      `json:"schema"`
+	Version         string                      `json:"version"`
+	Vulnerabilities []GlDependencyVulnerability `json:"vulnerabilities"`
+	DependencyFiles *[]GlDependencyFile         `json:"dependency_files,omitempty"`
+	Remediations    []GlRemediation             `json:"remediations"`
+}
+
+type GlDependencyVulnerability struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Severity    string               `json:"severity"`
+	Solution    string               `json:"solution,omitempty"`
+	Identifiers []Identifier         `json:"identifiers"`
+	Links       []GlLink             `json:"links,omitempty"`
+	Location    GlDependencyLocation `json:"location"`
+}
+
+type GlLink struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+}
+
+// GlDependencyLocation is the file of a dependency for dependency scanning, the image and operating system for
+// container scanning
+type GlDependencyLocation struct {
+	File            string       `json:"file,omitempty"`
+	Image           string       `json:"image,omitempty"`
+	OperatingSystem string       `json:"operating_system,omitempty"`
+	Dependency      GlDependency `json:"dependency"`
+}
+
+type GlDependency struct {
+	Package        GlPackage          `json:"package"`
+	Version        string             `json:"version"`
+	IID            int                `json:"iid,omitempty"`
+	Direct         bool               `json:"direct,omitempty"`
+	DependencyPath []GlDependencyPath `json:"dependency_path,omitempty"`
+}
+
+type GlPackage struct {
+	Name string `json:"name"`
+}
+
+// GlDependencyPath refers to the dependency with the same iid in the dependency file
+type GlDependencyPath struct {
+	IID int `json:"iid"`
+}
+
+type GlDependencyFile struct {
+	Path           string         `json:"path"`
+	PackageManager string         `json:"package_manager"`
+	Dependencies   []GlDependency `json:"dependencies"`
+}
+
+type GlRemediation struct {
+	Fixes   []GlRemediationFix `json:"fixes"`
+	Summary string             `json:"summary"`
+	Diff    string             `json:"diff"`
+}
+
+type GlRemediationFix struct {
+	ID string `json:"id"`
+}
@@ -0,0 +1,319 @@
+package wrappers
+
+// ResultsHTMLTemplate is the full results report, a single file without external resources: the styles and the
+// script filtering the findings are inlined and the content security policy blocks anything else
+const ResultsHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+
+<head>
+    <meta http-equiv="Content-type" content="text/html; charset=utf-8">
+    <meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'; script-src 'unsafe-inline'">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Checkmarx Scan Results</title>
+    <style type="text/css">
+        * {
+            box-sizing: border-box;
+            margin: 0;
+            padding: 0;
+        }
+
+        body {
+            background-color: #f7f7f8;
+            color: #565360;
+            font-family: Arial, Helvetica, sans-serif;
+            font-size: 14px;
+            padding: 24px;
+        }
+
+        h1 {
+            font-size: 22px;
+            margin-bottom: 8px;
+        }
+
+        .scan-info {
+            display: flex;
+            flex-wrap: wrap;
+            font-size: 13px;
+            margin-bottom: 16px;
+        }
+
+        .scan-info span {
+            margin-right: 20px;
+        }
+
+        .counts {
+            display: flex;
+            flex-wrap: wrap;
+            margin-bottom: 16px;
+        }
+
+        .count {
+            background: #fff;
+            border: 1px solid #dad8dc;
+            border-radius: 4px;
+            margin: 0 8px 8px 0;
+            min-width: 110px;
+            padding: 8px 12px;
+        }
+
+        .count .value {
+            display: block;
+            font-size: 22px;
+            font-weight: 700;
+        }
+
+        .filters {
+            align-items: flex-end;
+            background: #fff;
+            border: 1px solid #dad8dc;
+            border-radius: 4px;
+            display: flex;
+            flex-wrap: wrap;
+            margin-bottom: 16px;
+            padding: 12px;
+        }
+
+        .filters label {
+            display: flex;
+            flex-direction: column;
+            font-size: 12px;
+            margin-right: 12px;
+        }
+
+        .filters select,
+        .filters input {
+            border: 1px solid #dad8dc;
+            border-radius: 4px;
+            margin-top: 4px;
+            min-width: 140px;
+            padding: 4px;
+        }
+
+        .filters .visible {
+            font-size: 13px;
+            margin-left: auto;
+        }
+
+        table {
+            background: #fff;
+            border: 1px solid #dad8dc;
+            border-collapse: collapse;
+            width: 100%;
+        }
+
+        th,
+        td {
+            border-bottom: 1px solid #dad8dc;
+            padding: 8px;
+            text-align: left;
+            vertical-align: top;
+        }
+
+        th {
+            background-color: #ececef;
+            font-size: 12px;
+            text-transform: uppercase;
+        }
+
+        .severity {
+            border-radius: 4px;
+            color: #fcfdff;
+            display: inline-block;
+            font-size: 11px;
+            font-weight: 700;
+            padding: 2px 6px;
+        }
+
+        .severity.CRITICAL {
+            background-color: #a4001e;
+        }
+
+        .severity.HIGH {
+            background-color: #f1605d;
+        }
+
+        .severity.MEDIUM {
+            background-color: #f9ae4d;
+        }
+
+        .severity.LOW {
+            background-color: #bdbdbd;
+        }
+
+        .severity.INFO {
+            background-color: #8c8a92;
+        }
+
+        .location {
+            font-family: monospace;
+            word-break: break-all;
+        }
+
+        details summary {
+            cursor: pointer;
+            margin-top: 6px;
+        }
+
+        .details {
+            border-left: 3px solid #dad8dc;
+            font-size: 13px;
+            margin-top: 6px;
+            padding-left: 8px;
+        }
+
+        .details dt {
+            font-weight: 700;
+        }
+
+        .details dd {
+            margin-bottom: 4px;
+        }
+
+        .flow {
+            font-family: monospace;
+            font-size: 12px;
+            list-style: decimal inside;
+        }
+
+        .flow li {
+            padding: 2px 0;
+        }
+
+        .empty {
+            padding: 16px;
+            text-align: center;
+        }
+    </style>
+</head>
+
+<body>
+    <h1>Checkmarx Scan Results</h1>
+    <div class="scan-info">
+        {{with .Summary}}
+        {{if .ProjectName}}<span>Project: {{.ProjectName}}</span>{{end}}
+        {{if .BranchName}}<span>Branch: {{.BranchName}}</span>{{end}}
+        <span>Scan: {{.ScanID}}</span>
+        <span>Created: {{.CreatedAt}}</span>
+        <span>Status: {{.Status}}</span>
+        {{end}}
+        <span>Generated: {{.GeneratedAt}}</span>
+    </div>
+    <div class="counts">
+        <div class="count"><span class="value">{{len .Findings}}</span>Findings</div>
+        {{range .SeverityCounts}}<div class="count"><span class="value">{{.Count}}</span>{{.Name}}</div>{{end}}
+    </div>
+    <form class="filters" id="filters">
+        <label>Engine
+            <select data-filter="engine">
+                <option value="">All</option>
+                {{range .Engines}}<option value="{{.}}">{{.}}</option>{{end}}
+            </select>
+        </label>
+        <label>Severity
+            <select data-filter="severity">
+                <option value="">All</option>
+                {{range .Severities}}<option value="{{.}}">{{.}}</option>{{end}}
+            </select>
+        </label>
+        <label>State
+            <select data-filter="state">
+                <option value="">All</option>
+                {{range .States}}<option value="{{.}}">{{.}}</option>{{end}}
+            </select>
+        </label>
+        <label>File
+            <input type="search" data-filter="file" data-match="contains" placeholder="Path contains">
+        </label>
+        <label>Query
+            <input type="search" data-filter="query" data-match="contains" placeholder="Query, package or CVE">
+        </label>
+        <span class="visible"><span id="visible-count">{{len .Findings}}</span> of {{len .Findings}} findings</span>
+    </form>
+    <table>
+        <thead>
+            <tr>
+                <th>Severity</th>
+                <th>Engine</th>
+                <th>Finding</th>
+                <th>Location</th>
+                <th>State</th>
+            </tr>
+        </thead>
+        <tbody id="findings">
+            {{range .Findings}}
+            <tr data-engine="{{.Engine}}" data-severity="{{.Severity}}" data-state="{{.State}}" data-file="{{.File}}" data-query="{{.Query}}">
+                <td><span class="severity {{.Severity}}">{{.Severity}}</span></td>
+                <td>{{.Engine}}</td>
+                <td>
+                    <strong>{{.Query}}</strong>
+                    <details>
+                        <summary>Details</summary>
+                        <dl class="details">
+                            {{if .Description}}<dt>Description</dt><dd>{{.Description}}</dd>{{end}}
+                            {{with .Package}}
+                            <dt>Package</dt><dd>{{.Name}} {{.Version}} ({{.TypeOfDependency}})</dd>
+                            {{if .CVE}}<dt>CVE</dt><dd>{{.CVE}}{{if .CvssScore}}, CVSS {{.CvssScore}}{{end}}</dd>{{end}}
+                            {{if .CWE}}<dt>CWE</dt><dd>{{.CWE}}</dd>{{end}}
+                            {{if .RecommendedVersion}}<dt>Recommended version</dt><dd>{{.RecommendedVersion}}</dd>{{end}}
+                            {{if .FixLink}}<dt>Advisory</dt><dd><a href="{{.FixLink}}" rel="noopener noreferrer">{{.FixLink}}</a></dd>{{end}}
+                            {{end}}
+                            {{if .ExpectedValue}}<dt>Value</dt><dd>{{.Value}}</dd><dt>Expected value</dt><dd>{{.ExpectedValue}}</dd>{{end}}
+                        </dl>
+                    </details>
+                    {{if .Nodes}}
+                    <details>
+                        <summary>Data flow ({{len .Nodes}} nodes)</summary>
+                        <ol class="flow">
+                            {{range .Nodes}}<li>{{.Name}} <span class="location">{{.FileName}}:{{.Line}}:{{.Column}}</span>{{if .Method}} in {{.Method}}{{end}}</li>{{end}}
+                        </ol>
+                    </details>
+                    {{end}}
+                </td>
+                <td class="location">{{range .Locations}}<div>{{.}}</div>{{end}}</td>
+                <td>{{.State}}</td>
+            </tr>
+            {{end}}
+            <tr id="no-findings" class="empty"{{if .Findings}} hidden{{end}}>
+                <td colspan="5">No findings match the filters</td>
+            </tr>
+        </tbody>
+    </table>
+    <script>
+        (function () {
+            var filters = document.querySelectorAll("#filters [data-filter]");
+            var rows = document.querySelectorAll("#findings tr[data-engine]");
+            function apply() {
+                var visible = 0;
+                rows.forEach(function (row) {
+                    var shown = true;
+                    filters.forEach(function (filter) {
+                        var value = filter.value.trim().toLowerCase();
+                        var field = (row.getAttribute("data-" + filter.getAttribute("data-filter")) || "").toLowerCase();
+                        if (value === "") {
+                            return;
+                        }
+                        if (filter.getAttribute("data-match") === "contains" ? field.indexOf(value) < 0 : field !== value) {
+                            shown = false;
+                        }
+                    });
+                    row.hidden = !shown;
+                    if (shown) {
+                        visible++;
+                    }
+                });
+                document.getElementById("visible-count").textContent = visible;
+                document.getElementById("no-findings").hidden = visible > 0;
+            }
+            filters.forEach(function (filter) {
+                filter.addEventListener("input", apply);
+                filter.addEventListener("change", apply);
+            });
+            document.getElementById("filters").addEventListener("submit", function (event) {
+                event.preventDefault();
+            });
+        })();
+    </script>
+</body>
+
+</html>
+`
@@ -101,6 +101,9 @@ type ScanResultData struct {
 	PackageIdentifier    string                   `json:"packageIdentifier,omitempty"`
 	ScaPackageCollection *ScaPackageCollection    `json:"scaPackageData,omitempty"`
 	RecommendedVersion   interface{}              `json:"recommendedVersion,omitempty"`
+	// Added to support SCA container results
+	ImageName string `json:"imageName,omitempty"`
+	ImageTag  string `json:"imageTag,omitempty"`
 	// Added to support kics results
 	Line          uint   `json:"line,omitempty"`
 	Platform      string `json:"platform,omitempty"`
@@ -0,0 +1,177 @@
+package wrappers
+
+// PortfolioMarkdownTemplate lists the projects of the portfolio ranked by risk
+const PortfolioMarkdownTemplate = `# Checkmarx One Portfolio Summary
+
+Generated: {{.GeneratedAt}}
+
+{{with .Totals}}| Projects | Scanned | Policy violations | Total | High | Medium | Low | Info | SAST | SCA | IaC Security |
+|---|---|---|---|---|---|---|---|---|---|---|
+| {{.Projects}} | {{.ScannedProjects}} | {{.PolicyViolations}} | {{.TotalIssues}} | {{.HighIssues}} | {{.MediumIssues}} | {{.LowIssues}} | {{.InfoIssues}} | {{.SastIssues}} | {{.ScaIssues}} | {{.KicsIssues}} |
+{{end}}
+## Projects
+{{if .Projects}}
+| Rank | Project | Branch | Risk | Total | High | Medium | Low | Info | Policy | Scan |
+|---|---|---|---|---|---|---|---|---|---|---|
+{{range .Projects}}| {{.Rank}} | {{.ProjectName}} | {{.Branch}} | {{if .Error}}Error: {{.Error}}{{else}}{{.RiskMsg}}{{end}} | {{.TotalIssues}} | {{.HighIssues}} | {{.MediumIssues}} | {{.LowIssues}} | {{.InfoIssues}} | {{.PolicyStatus}}{{if .ViolatedPolicies}}: {{range $i, $policy := .ViolatedPolicies}}{{if $i}}, {{end}}{{$policy}}{{end}}{{if .BreakBuild}} (break build){{end}}{{end}} | {{if .ScanURL}}[{{.ScanCreatedAt}}]({{.ScanURL}}){{else}}{{.ScanCreatedAt}}{{end}} |
+{{end}}{{else}}
+No projects found
+{{end}}`
+
+// PortfolioHTMLTemplate is the HTML version of PortfolioMarkdownTemplate, a single file without external resources
+const PortfolioHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+
+<head>
+    <meta http-equiv="Content-type" content="text/html; charset=utf-8">
+    <meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Checkmarx One Portfolio Summary</title>
+    <style type="text/css">
+        * {
+            box-sizing: border-box;
+            margin: 0;
+            padding: 0;
+        }
+
+        body {
+            background-color: #f7f7f8;
+            color: #565360;
+            font-family: Arial, Helvetica, sans-serif;
+            font-size: 14px;
+            padding: 24px;
+        }
+
+        h1 {
+            font-size: 22px;
+            margin-bottom: 8px;
+        }
+
+        h2 {
+            font-size: 18px;
+            margin: 24px 0 8px;
+        }
+
+        .counts {
+            display: flex;
+            flex-wrap: wrap;
+            margin-top: 16px;
+        }
+
+        .count {
+            background: #fff;
+            border: 1px solid #dad8dc;
+            border-radius: 4px;
+            margin: 0 8px 8px 0;
+            min-width: 110px;
+            padding: 8px 12px;
+        }
+
+        .count .value {
+            display: block;
+            font-size: 22px;
+            font-weight: 700;
+        }
+
+        table {
+            background: #fff;
+            border: 1px solid #dad8dc;
+            border-collapse: collapse;
+            width: 100%;
+        }
+
+        th,
+        td {
+            border-bottom: 1px solid #dad8dc;
+            padding: 6px 8px;
+            text-align: left;
+            vertical-align: top;
+        }
+
+        th {
+            background-color: #ececef;
+            font-size: 12px;
+            text-transform: uppercase;
+        }
+
+        td.count-cell {
+            text-align: right;
+        }
+
+        .high {
+            color: #f1605d;
+            font-weight: 700;
+        }
+
+        .error {
+            color: #a4001e;
+        }
+
+        .break-build {
+            color: #a4001e;
+            font-weight: 700;
+        }
+    </style>
+</head>
+
+<body>
+    <h1>Checkmarx One Portfolio Summary</h1>
+    <div>Generated: {{.GeneratedAt}}</div>
+    {{with .Totals}}
+    <div class="counts">
+        <div class="count"><span class="value">{{.Projects}}</span>Projects</div>
+        <div class="count"><span class="value">{{.ScannedProjects}}</span>Scanned</div>
+        <div class="count"><span class="value">{{.PolicyViolations}}</span>Policy violations</div>
+        <div class="count"><span class="value">{{.TotalIssues}}</span>Total</div>
+        <div class="count"><span class="value">{{.HighIssues}}</span>High</div>
+        <div class="count"><span class="value">{{.MediumIssues}}</span>Medium</div>
+        <div class="count"><span class="value">{{.LowIssues}}</span>Low</div>
+        <div class="count"><span class="value">{{.InfoIssues}}</span>Info</div>
+        <div class="count"><span class="value">{{.SastIssues}}</span>SAST</div>
+        <div class="count"><span class="value">{{.ScaIssues}}</span>SCA</div>
+        <div class="count"><span class="value">{{.KicsIssues}}</span>IaC Security</div>
+    </div>
+    {{end}}
+    <h2>Projects</h2>
+    <table>
+        <thead>
+            <tr>
+                <th>Rank</th>
+                <th>Project</th>
+                <th>Branch</th>
+                <th>Risk</th>
+                <th>Total</th>
+                <th>High</th>
+                <th>Medium</th>
+                <th>Low</th>
+                <th>Info</th>
+                <th>Policy</th>
+                <th>Scan</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Projects}}
+            <tr>
+                <td>{{.Rank}}</td>
+                <td>{{.ProjectName}}</td>
+                <td>{{.Branch}}</td>
+                <td>{{if .Error}}<span class="error">{{.Error}}</span>{{else}}{{.RiskMsg}}{{end}}</td>
+                <td class="count-cell">{{.TotalIssues}}</td>
+                <td class="count-cell{{if .HighIssues}} high{{end}}">{{.HighIssues}}</td>
+                <td class="count-cell">{{.MediumIssues}}</td>
+                <td class="count-cell">{{.LowIssues}}</td>
+                <td class="count-cell">{{.InfoIssues}}</td>
+                <td>{{.PolicyStatus}}{{range .ViolatedPolicies}}<div>{{.}}</div>{{end}}{{if .BreakBuild}}<div class="break-build">Break build</div>{{end}}</td>
+                <td>{{if .ScanURL}}<a href="{{.ScanURL}}" rel="noopener noreferrer">{{.ScanCreatedAt}}</a>{{else}}{{.ScanCreatedAt}}{{end}}</td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="11">No projects found</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</body>
+
+</html>
+`
@@ -13,8 +13,14 @@ type SarifResultsCollection struct {
 }
 
 type SarifRun struct {
-	Tool    SarifTool         `json:"tool"`
-	Results []SarifScanResult `json:"results"`
+	Tool              SarifTool               `json:"tool"`
+	AutomationDetails *SarifAutomationDetails `json:"automationDetails,omitempty"`
+	Results           []SarifScanResult       `json:"results"`
+}
+
+// SarifAutomationDetails identifies the run, the id is "category/instance"
+type SarifAutomationDetails struct {
+	ID string `json:"id"`
 }
 
 type SarifTool struct {
@@ -57,12 +63,35 @@ type SarifScanResult struct {
 	RuleID              string                  `json:"ruleId"`
 	Level               string                  `json:"level"`
 	Message             SarifMessage            `json:"message"`
+	Fingerprints        map[string]string       `json:"fingerprints,omitempty"`
 	PartialFingerprints *SarifResultFingerprint `json:"partialFingerprints,omitempty"`
+	BaselineState       string                  `json:"baselineState,omitempty"`
 	Locations           []SarifLocation         `json:"locations,omitempty"`
+	CodeFlows           []SarifCodeFlow         `json:"codeFlows,omitempty"`
+	Properties          *SarifResultProperties  `json:"properties,omitempty"`
+}
+
+// SarifResultProperties keeps the result fields SARIF has no place for, so reports can be imported back
+type SarifResultProperties struct {
+	SimilarityID string `json:"similarityId,omitempty"`
+	State        string `json:"state,omitempty"`
 }
 
 type SarifLocation struct {
 	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+	Message          *SarifMessage         `json:"message,omitempty"`
+}
+
+type SarifCodeFlow struct {
+	ThreadFlows []SarifThreadFlow `json:"threadFlows"`
+}
+
+type SarifThreadFlow struct {
+	Locations []SarifThreadFlowLocation `json:"locations"`
+}
+
+type SarifThreadFlowLocation struct {
+	Location SarifLocation `json:"location"`
 }
 
 type SarifPhysicalLocation struct {
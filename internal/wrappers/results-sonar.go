@@ -26,3 +26,24 @@ type SonarTextRange struct {
 	StartColumn uint `json:"startColumn,omitempty"`
 	EndColumn   uint `json:"endColumn,omitempty"`
 }
+
+// ScanResultsSonarV10 is the generic issue format of SonarQube 10.3 and later, the rules hold the impacts that
+// replace the severity and type of the issues
+type ScanResultsSonarV10 struct {
+	Rules  []SonarRule   `json:"rules"`
+	Issues []SonarIssues `json:"issues"`
+}
+
+type SonarRule struct {
+	ID                 string        `json:"id"`
+	Name               string        `json:"name"`
+	Description        string        `json:"description"`
+	EngineID           string        `json:"engineId"`
+	CleanCodeAttribute string        `json:"cleanCodeAttribute"`
+	Impacts            []SonarImpact `json:"impacts"`
+}
+
+type SonarImpact struct {
+	SoftwareQuality string `json:"softwareQuality"`
+	Severity        string `json:"severity"`
+}
@@ -0,0 +1,288 @@
+package wrappers
+
+// TrendsMarkdownTemplate lists the new, fixed, triaged and open results of each scan of a project branch
+const TrendsMarkdownTemplate = `# Checkmarx One Results Trends
+
+Project: {{.ProjectName}} ({{.ProjectID}})
+
+Branch: {{.Branch}}
+
+Scans since: {{.From}} ({{.Since}})
+
+Generated: {{.GeneratedAt}}
+
+## Scans
+{{if .Scans}}
+| Scan | Created | Open | New | Fixed | Triaged |{{range .Severities}} {{.}} |{{end}}{{range .Engines}} {{.}} |{{end}}
+|---|---|---|---|---|---|{{range .Severities}}---|{{end}}{{range .Engines}}---|{{end}}
+{{range .Scans}}{{$scan := .}}| {{.ScanID}} | {{.CreatedAt}} | {{.Open.Total}} | {{.New.Total}} | {{.Fixed.Total}} | {{.Triaged.Total}} |{{range $.Severities}} {{index $scan.Open.BySeverity .}} |{{end}}{{range $.Engines}} {{index $scan.Open.ByEngine .}} |{{end}}
+{{end}}
+The severity and engine columns count the open results. Triaged results were triaged as not exploitable or ignored,
+they are not counted as fixed.
+{{else}}
+No completed scans found
+{{end}}
+## Time to remediate
+{{with .Remediation}}{{if .Fixed}}
+| Severity | Fixed | Mean (days) | Median (days) |
+|---|---|---|---|
+| all | {{.Fixed}} | {{.MeanDays}} | {{.MedianDays}} |
+{{range .BySeverity}}| {{.Severity}} | {{.Fixed}} | {{.MeanDays}} | {{.MedianDays}} |
+{{end}}{{else}}
+No fixed results
+{{end}}{{end}}
+## Oldest open results
+{{if .OldestOpen}}
+| Severity | Engine | Result | Location | First found | Age (days) |
+|---|---|---|---|---|---|
+{{range .OldestOpen}}| {{.Severity}} | {{.Engine}} | {{.Finding}} | {{.Location}} | {{.FirstFoundAt}} | {{.AgeDays}} |
+{{end}}{{else}}
+No open results
+{{end}}`
+
+// TrendsHTMLTemplate is the HTML version of TrendsMarkdownTemplate with a chart of the open results, a single file
+// without external resources
+const TrendsHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+
+<head>
+    <meta http-equiv="Content-type" content="text/html; charset=utf-8">
+    <meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Checkmarx One Results Trends</title>
+    <style type="text/css">
+        * {
+            box-sizing: border-box;
+            margin: 0;
+            padding: 0;
+        }
+
+        body {
+            background-color: #f7f7f8;
+            color: #565360;
+            font-family: Arial, Helvetica, sans-serif;
+            font-size: 14px;
+            padding: 24px;
+        }
+
+        h1 {
+            font-size: 22px;
+            margin-bottom: 8px;
+        }
+
+        h2 {
+            font-size: 18px;
+            margin: 24px 0 8px;
+        }
+
+        .chart {
+            background: #fff;
+            border: 1px solid #dad8dc;
+            padding: 8px;
+        }
+
+        .chart text {
+            fill: #565360;
+            font-size: 11px;
+        }
+
+        .chart .axis {
+            stroke: #dad8dc;
+        }
+
+        .legend span {
+            display: inline-block;
+            margin-right: 12px;
+        }
+
+        .legend i {
+            display: inline-block;
+            height: 10px;
+            margin-right: 4px;
+            width: 10px;
+        }
+
+        rect,
+        .legend i {
+            background-color: #9e9e9e;
+            fill: #9e9e9e;
+        }
+
+        .critical {
+            background-color: #a4001e;
+            fill: #a4001e;
+        }
+
+        .high {
+            background-color: #f1605d;
+            fill: #f1605d;
+        }
+
+        .medium {
+            background-color: #ffa500;
+            fill: #ffa500;
+        }
+
+        .low {
+            background-color: #fadb5d;
+            fill: #fadb5d;
+        }
+
+        .info {
+            background-color: #87bed1;
+            fill: #87bed1;
+        }
+
+        table {
+            background: #fff;
+            border: 1px solid #dad8dc;
+            border-collapse: collapse;
+            width: 100%;
+        }
+
+        th,
+        td {
+            border-bottom: 1px solid #dad8dc;
+            padding: 6px 8px;
+            text-align: left;
+            vertical-align: top;
+        }
+
+        th {
+            background-color: #ececef;
+            font-size: 12px;
+            text-transform: uppercase;
+        }
+
+        td.count-cell {
+            text-align: right;
+        }
+    </style>
+</head>
+
+<body>
+    <h1>Checkmarx One Results Trends</h1>
+    <div>Project: {{.ProjectName}} ({{.ProjectID}})</div>
+    <div>Branch: {{.Branch}}</div>
+    <div>Scans since: {{.From}} ({{.Since}})</div>
+    <div>Generated: {{.GeneratedAt}}</div>
+
+    <h2>Open results</h2>
+    {{if .Scans}}
+    <div class="chart">
+        {{with .Chart}}
+        <svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="Open results by scan">
+            <line class="axis" x1="{{.Left}}" y1="{{.Bottom}}" x2="{{.Right}}" y2="{{.Bottom}}"></line>
+            <text x="{{.Left}}" y="{{.Top}}" dx="-6" dy="4" text-anchor="end">{{.MaxOpen}}</text>
+            <text x="{{.Left}}" y="{{.Bottom}}" dx="-6" text-anchor="end">0</text>
+            {{range .Bars}}{{$bar := .}}
+            <g>
+                <title>{{.Title}}</title>
+                {{range .Segments}}<rect class="{{.Severity}}" x="{{$bar.X}}" y="{{.Y}}" width="{{$bar.Width}}" height="{{.Height}}"></rect>{{end}}
+            </g>
+            {{end}}
+            <text x="{{.Left}}" y="{{.Bottom}}" dy="16">{{.FirstDate}}</text>
+            <text x="{{.Right}}" y="{{.Bottom}}" dy="16" text-anchor="end">{{.LastDate}}</text>
+        </svg>
+        {{end}}
+        <div class="legend">{{range .Severities}}<span><i class="{{.}}"></i>{{.}}</span>{{end}}</div>
+    </div>
+
+    <h2>Scans</h2>
+    <table>
+        <thead>
+            <tr>
+                <th>Scan</th>
+                <th>Created</th>
+                <th>Open</th>
+                <th>New</th>
+                <th>Fixed</th>
+                <th>Triaged</th>
+                {{range .Severities}}<th>{{.}}</th>{{end}}
+                {{range .Engines}}<th>{{.}}</th>{{end}}
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Scans}}{{$scan := .}}
+            <tr>
+                <td>{{.ScanID}}</td>
+                <td>{{.CreatedAt}}</td>
+                <td class="count-cell">{{.Open.Total}}</td>
+                <td class="count-cell">{{.New.Total}}</td>
+                <td class="count-cell">{{.Fixed.Total}}</td>
+                <td class="count-cell">{{.Triaged.Total}}</td>
+                {{range $.Severities}}<td class="count-cell">{{index $scan.Open.BySeverity .}}</td>{{end}}
+                {{range $.Engines}}<td class="count-cell">{{index $scan.Open.ByEngine .}}</td>{{end}}
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{else}}
+    <div>No completed scans found</div>
+    {{end}}
+
+    <h2>Time to remediate</h2>
+    {{with .Remediation}}{{if .Fixed}}
+    <table>
+        <thead>
+            <tr>
+                <th>Severity</th>
+                <th>Fixed</th>
+                <th>Mean (days)</th>
+                <th>Median (days)</th>
+            </tr>
+        </thead>
+        <tbody>
+            <tr>
+                <td>all</td>
+                <td class="count-cell">{{.Fixed}}</td>
+                <td class="count-cell">{{.MeanDays}}</td>
+                <td class="count-cell">{{.MedianDays}}</td>
+            </tr>
+            {{range .BySeverity}}
+            <tr>
+                <td>{{.Severity}}</td>
+                <td class="count-cell">{{.Fixed}}</td>
+                <td class="count-cell">{{.MeanDays}}</td>
+                <td class="count-cell">{{.MedianDays}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{else}}
+    <div>No fixed results</div>
+    {{end}}{{end}}
+
+    <h2>Oldest open results</h2>
+    {{if .OldestOpen}}
+    <table>
+        <thead>
+            <tr>
+                <th>Severity</th>
+                <th>Engine</th>
+                <th>Result</th>
+                <th>Location</th>
+                <th>First found</th>
+                <th>Age (days)</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .OldestOpen}}
+            <tr>
+                <td>{{.Severity}}</td>
+                <td>{{.Engine}}</td>
+                <td>{{.Finding}}</td>
+                <td>{{.Location}}</td>
+                <td>{{.FirstFoundAt}}</td>
+                <td class="count-cell">{{.AgeDays}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{else}}
+    <div>No open results</div>
+    {{end}}
+</body>
+
+</html>
+`
@@ -0,0 +1,98 @@
+package wrappers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	commonParams "github.com/checkmarx/ast-cli/internal/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	scaDBLatestVersion     = "latest"
+	scaDBManifestFile      = "manifest.json"
+	failedGettingScaDB     = "Failed to get the SCA vulnerability database manifest"
+	failedDownloadingScaDB = "Failed to download the SCA vulnerability database"
+)
+
+type ScaDBHTTPWrapper struct {
+	client *http.Client
+}
+
+func NewScaDBHTTPWrapper() ScaDBWrapper {
+	return &ScaDBHTTPWrapper{
+		client: GetClient(viper.GetUint(commonParams.ClientTimeoutKey)),
+	}
+}
+
+func (s *ScaDBHTTPWrapper) GetManifest(version string) (*ScaDBManifest, error) {
+	if version == "" {
+		version = scaDBLatestVersion
+	}
+	manifestURL := strings.TrimSuffix(viper.GetString(commonParams.ScaDBURLKey), "/") + "/" +
+		url.PathEscape(version) + "/" + scaDBManifestFile
+	resp, err := s.get(manifestURL)
+	if err != nil {
+		return nil, errors.Wrap(err, failedGettingScaDB)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		manifest := &ScaDBManifest{}
+		err = json.NewDecoder(resp.Body).Decode(manifest)
+		if err != nil {
+			return nil, errors.Wrap(err, failedGettingScaDB)
+		}
+		// the snapshot URL may be relative to the manifest
+		snapshotURL, err := url.Parse(manifestURL)
+		if err != nil {
+			return nil, errors.Wrap(err, failedGettingScaDB)
+		}
+		reference, err := url.Parse(manifest.URL)
+		if err != nil {
+			return nil, errors.Wrap(err, failedGettingScaDB)
+		}
+		manifest.URL = snapshotURL.ResolveReference(reference).String()
+		return manifest, nil
+	case http.StatusNotFound, http.StatusForbidden:
+		return nil, errors.Errorf("Version %s of the SCA vulnerability database was not found", version)
+	default:
+		return nil, errors.Errorf("%s: %s", failedGettingScaDB, resp.Status)
+	}
+}
+
+func (s *ScaDBHTTPWrapper) DownloadSnapshot(manifest *ScaDBManifest) ([]byte, error) {
+	resp, err := s.get(manifest.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, failedDownloadingScaDB)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("%s: %s", failedDownloadingScaDB, resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, failedDownloadingScaDB)
+	}
+	return content, nil
+}
+
+func (s *ScaDBHTTPWrapper) get(requestURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	setAgentName(req)
+	logger.PrintRequest(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, requestURL)
+	}
+	logger.PrintResponse(resp, false)
+	return resp, nil
+}
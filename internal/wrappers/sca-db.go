@@ -0,0 +1,19 @@
+package wrappers
+
+import "time"
+
+// ScaDBManifest describes a version of the offline SCA vulnerability database. The snapshot is signed with
+// ed25519, the signature and the SHA-256 digest are of the downloaded snapshot file.
+type ScaDBManifest struct {
+	Version   string    `json:"version"`
+	Published time.Time `json:"published"`
+	URL       string    `json:"url"`
+	SHA256    string    `json:"sha256"`
+	Signature string    `json:"signature"`
+}
+
+type ScaDBWrapper interface {
+	// GetManifest returns the manifest of a version of the database, of the latest one when the version is empty
+	GetManifest(version string) (*ScaDBManifest, error)
+	DownloadSnapshot(manifest *ScaDBManifest) ([]byte, error)
+}
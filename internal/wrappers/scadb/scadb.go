@@ -0,0 +1,245 @@
+package scadb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultDirName   = ".checkmarx/sca-db"
+	manifestFileName = "manifest.json"
+	snapshotFileName = "snapshot.json.gz"
+	dirPermissions   = 0700
+	filePermissions  = 0600
+	notInstalledMsg  = "The SCA vulnerability database is not installed, run 'cx utils sca-db sync' while connected"
+)
+
+var pythonNameRegex = regexp.MustCompile(`[-_.]+`)
+
+// Snapshot is a version of the vulnerability database
+type Snapshot struct {
+	Version   string    `json:"version"`
+	Published time.Time `json:"published"`
+	Packages  []Package `json:"packages"`
+}
+
+// Package lists the vulnerabilities of a package
+type Package struct {
+	PackageManager  string     `json:"packageManager"`
+	PackageName     string     `json:"packageName"`
+	Vulnerabilities []Advisory `json:"vulnerabilities"`
+}
+
+// Advisory is a vulnerability with the version ranges of the package it affects, in the notation of the package
+// manager
+type Advisory struct {
+	wrappers.Vulnerability
+	AffectedVersions []string `json:"affectedVersions"`
+}
+
+// Database is an installed snapshot. It answers the SCA dependency requests as the SCA vulnerabilities API does.
+type Database struct {
+	Manifest wrappers.ScaDBManifest
+	packages map[string]*Package
+}
+
+// Dir returns the directory of the database, from the configuration or in the home directory
+func Dir() (string, error) {
+	if dir := viper.GetString(params.ScaDBDirKey); dir != "" {
+		return dir, nil
+	}
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, defaultDirName), nil
+}
+
+// InstalledManifest returns the manifest of the installed database, nil when none is installed
+func InstalledManifest(dir string) (*wrappers.ScaDBManifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest := &wrappers.ScaDBManifest{}
+	if err = json.Unmarshal(content, manifest); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the manifest of the SCA vulnerability database")
+	}
+	return manifest, nil
+}
+
+// Verify checks that a downloaded snapshot is the one of the manifest and that it is signed by the key configured
+// in CX_SCA_DB_PUBLIC_KEY, a base64 ed25519 public key
+func Verify(manifest *wrappers.ScaDBManifest, snapshot []byte) error {
+	if err := verifyDigest(manifest, snapshot); err != nil {
+		return err
+	}
+	encodedKey := viper.GetString(params.ScaDBPublicKeyKey)
+	if encodedKey == "" {
+		return errors.Errorf("The public key that signs the SCA vulnerability database is not configured, set %s",
+			params.ScaDBPublicKeyEnv)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errors.Errorf("Invalid %s, expected a base64 ed25519 public key", params.ScaDBPublicKeyEnv)
+	}
+	signature, err := base64.StdEncoding.DecodeString(manifest.Signature)
+	if err != nil || !ed25519.Verify(publicKey, snapshot, signature) {
+		return errors.Errorf("The signature of version %s of the SCA vulnerability database is invalid", manifest.Version)
+	}
+	return nil
+}
+
+func verifyDigest(manifest *wrappers.ScaDBManifest, snapshot []byte) error {
+	digest := sha256.Sum256(snapshot)
+	if !strings.EqualFold(hex.EncodeToString(digest[:]), manifest.SHA256) {
+		return errors.Errorf("The SHA-256 digest of version %s of the SCA vulnerability database does not match its manifest",
+			manifest.Version)
+	}
+	return nil
+}
+
+// Install replaces the installed database by a verified snapshot
+func Install(dir string, manifest *wrappers.ScaDBManifest, snapshot []byte) error {
+	if _, err := decodeSnapshot(snapshot); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the snapshot is replaced before the manifest that describes it, an interrupted install fails the digest check
+	if err = writeFileAtomically(filepath.Join(dir, snapshotFileName), snapshot); err != nil {
+		return err
+	}
+	return writeFileAtomically(filepath.Join(dir, manifestFileName), content)
+}
+
+func writeFileAtomically(path string, content []byte) error {
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, content, filePermissions); err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+// Open loads the installed database
+func Open(dir string) (*Database, error) {
+	manifest, err := InstalledManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, errors.New(notInstalledMsg)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil, errors.New(notInstalledMsg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = verifyDigest(manifest, content); err != nil {
+		return nil, err
+	}
+	snapshot, err := decodeSnapshot(content)
+	if err != nil {
+		return nil, err
+	}
+	db := &Database{Manifest: *manifest, packages: map[string]*Package{}}
+	for i := range snapshot.Packages {
+		p := &snapshot.Packages[i]
+		db.packages[packageKey(p.PackageManager, p.PackageName)] = p
+	}
+	return db, nil
+}
+
+// decodeSnapshot decodes a snapshot, gzip compressed or not
+func decodeSnapshot(content []byte) (*Snapshot, error) {
+	var reader io.Reader = bytes.NewReader(content)
+	if len(content) > 1 && content[0] == 0x1f && content[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to read the SCA vulnerability database")
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	snapshot := &Snapshot{}
+	if err := json.NewDecoder(reader).Decode(snapshot); err != nil {
+		return nil, errors.Wrap(err, "Failed to read the SCA vulnerability database")
+	}
+	return snapshot, nil
+}
+
+// packageKey identifies a package, with its name normalized as its package manager compares names
+func packageKey(packageManager, name string) string {
+	packageManager = strings.ToLower(packageManager)
+	switch packageManager {
+	case pythonPackageManager:
+		name = strings.ToLower(pythonNameRegex.ReplaceAllString(name, "-"))
+	case nugetPackageManager, "php":
+		name = strings.ToLower(name)
+	}
+	return packageManager + ":" + name
+}
+
+// Vulnerabilities returns the vulnerabilities that affect a version of a package
+func (db *Database) Vulnerabilities(packageManager, name, version string) []*wrappers.Vulnerability {
+	vulnerabilities := []*wrappers.Vulnerability{}
+	p, found := db.packages[packageKey(packageManager, name)]
+	if !found {
+		return vulnerabilities
+	}
+	for i := range p.Vulnerabilities {
+		advisory := &p.Vulnerabilities[i]
+		for _, versionRange := range advisory.AffectedVersions {
+			if Affected(packageManager, version, versionRange) {
+				vulnerability := advisory.Vulnerability
+				vulnerabilities = append(vulnerabilities, &vulnerability)
+				break
+			}
+		}
+	}
+	return vulnerabilities
+}
+
+// GetScaVulnerabilitiesPackages matches dependencies against the database, the response is the one of the SCA
+// vulnerabilities API
+func (db *Database) GetScaVulnerabilitiesPackages(scaRequest []wrappers.ScaDependencyBodyRequest) (
+	[]wrappers.ScaVulnerabilitiesResponseModel, *wrappers.WebError, error,
+) {
+	models := make([]wrappers.ScaVulnerabilitiesResponseModel, 0, len(scaRequest))
+	for _, dependency := range scaRequest {
+		models = append(models, wrappers.ScaVulnerabilitiesResponseModel{
+			PackageName:     dependency.PackageName,
+			PackageManager:  dependency.PackageManager,
+			Version:         dependency.Version,
+			Vulnerabilities: db.Vulnerabilities(dependency.PackageManager, dependency.PackageName, dependency.Version),
+		})
+	}
+	return models, nil, nil
+}
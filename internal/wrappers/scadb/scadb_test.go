@@ -0,0 +1,115 @@
+package scadb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/spf13/viper"
+	"gotest.tools/assert"
+)
+
+func testSnapshot() *Snapshot {
+	advisory := func(cve string, affected ...string) Advisory {
+		a := Advisory{AffectedVersions: affected}
+		a.Cve = cve
+		a.Severity = "High"
+		return a
+	}
+	return &Snapshot{
+		Version:   "2024.05.01",
+		Published: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Packages: []Package{
+			{PackageManager: "Npm", PackageName: "lodash", Vulnerabilities: []Advisory{advisory("CVE-2021-23337", "<4.17.21")}},
+			{PackageManager: "Python", PackageName: "Django", Vulnerabilities: []Advisory{
+				advisory("CVE-2023-36053", ">=4.2,<4.2.3", ">=3.2,<3.2.20"),
+				advisory("CVE-2024-24680", ">=5.0,<5.0.2"),
+			}},
+		},
+	}
+}
+
+// signedSnapshot returns a gzip compressed snapshot with its manifest, signed by a key configured as the public key
+func signedSnapshot(t *testing.T, snapshot *Snapshot) (*wrappers.ScaDBManifest, []byte) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NilError(t, err)
+	viper.Set(params.ScaDBPublicKeyKey, base64.StdEncoding.EncodeToString(publicKey))
+	t.Cleanup(func() { viper.Set(params.ScaDBPublicKeyKey, "") })
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	assert.NilError(t, json.NewEncoder(writer).Encode(snapshot))
+	assert.NilError(t, writer.Close())
+	content := buffer.Bytes()
+	digest := sha256.Sum256(content)
+	return &wrappers.ScaDBManifest{
+		Version:   snapshot.Version,
+		Published: snapshot.Published,
+		URL:       "snapshot.json.gz",
+		SHA256:    hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, content)),
+	}, content
+}
+
+func TestVerify(t *testing.T) {
+	manifest, content := signedSnapshot(t, testSnapshot())
+	assert.NilError(t, Verify(manifest, content))
+
+	tampered := append([]byte(nil), content...)
+	tampered[len(tampered)-1] ^= 1
+	assert.ErrorContains(t, Verify(manifest, tampered), "SHA-256 digest")
+
+	forged := *manifest
+	forged.Signature = base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))
+	assert.ErrorContains(t, Verify(&forged, content), "signature of version 2024.05.01")
+
+	viper.Set(params.ScaDBPublicKeyKey, "")
+	assert.ErrorContains(t, Verify(manifest, content), params.ScaDBPublicKeyEnv)
+}
+
+func TestInstallAndOpen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sca-db")
+	_, err := Open(dir)
+	assert.Error(t, err, notInstalledMsg)
+
+	manifest, content := signedSnapshot(t, testSnapshot())
+	assert.NilError(t, Install(dir, manifest, content))
+	installed, err := InstalledManifest(dir)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, installed, manifest)
+
+	db, err := Open(dir)
+	assert.NilError(t, err)
+	models, webError, err := db.GetScaVulnerabilitiesPackages([]wrappers.ScaDependencyBodyRequest{
+		{PackageName: "lodash", Version: "4.17.20", PackageManager: "Npm"},
+		{PackageName: "lodash", Version: "4.17.21", PackageManager: "Npm"},
+		{PackageName: "django", Version: "4.2.1", PackageManager: "Python"},
+		{PackageName: "express", Version: "4.17.1", PackageManager: "Npm"},
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, webError == nil)
+	var found []string
+	for _, model := range models {
+		for _, vulnerability := range model.Vulnerabilities {
+			found = append(found, model.PackageName+"@"+model.Version+" "+vulnerability.Cve)
+		}
+	}
+	assert.DeepEqual(t, found, []string{"lodash@4.17.20 CVE-2021-23337", "django@4.2.1 CVE-2023-36053"})
+	assert.Equal(t, len(models), 4)
+	assert.Assert(t, models[3].Vulnerabilities != nil)
+
+	// a snapshot modified after the install is rejected
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, snapshotFileName), []byte("{}"), filePermissions))
+	_, err = Open(dir)
+	assert.ErrorContains(t, err, "SHA-256 digest")
+}
@@ -0,0 +1,443 @@
+package scadb
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/checkmarx/ast-cli/internal/wrappers/remediation"
+)
+
+// Package managers of the SCA dependency requests whose ranges have their own notation
+const (
+	npmPackageManager    = "npm"
+	mavenPackageManager  = "maven"
+	nugetPackageManager  = "nuget"
+	pythonPackageManager = "python"
+	goPackageManager     = "go"
+)
+
+var (
+	pep440Regex = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+		`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d*))?` +
+		`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+		`(?:[-_.]?(dev)[-_.]?(\d*))?(?:\+[a-z0-9._-]*)?$`)
+	pythonSpecifierRegex = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*(\S+)$`)
+	comparatorRegex      = regexp.MustCompile(`^(==|=|!=|<=|>=|<|>)?\s*(\S+)$`)
+	comparatorSpaceRegex = regexp.MustCompile(`(==|!=|<=|>=|<|>|=)\s+`)
+)
+
+// Affected reports whether a version of a package is in an affected range of an advisory. Ranges are written in
+// the notation of the package manager:
+//   - npm: node-semver ranges, such as ^1.2.0 || >=2.0.0 <2.1.3
+//   - Maven and NuGet: interval notation, such as [1.0,2.0) or (,1.2.3],[1.5]
+//   - Python: PEP 440 specifiers, such as >=1.0,<1.4.2
+//   - others: comparators separated by spaces or commas, such as >=1.0.0 <1.2.3, alternatives separated by ||
+func Affected(packageManager, version, versionRange string) bool {
+	switch strings.ToLower(packageManager) {
+	case npmPackageManager:
+		return remediation.SatisfiesNpmRange(version, versionRange)
+	case mavenPackageManager:
+		return inIntervals(version, versionRange, CompareMavenVersions)
+	case nugetPackageManager:
+		return inIntervals(version, versionRange, CompareNugetVersions)
+	case pythonPackageManager:
+		return satisfiesPythonSpecifiers(version, versionRange)
+	case goPackageManager:
+		return satisfiesComparators(version, versionRange, compareSemverVersions)
+	}
+	return satisfiesComparators(version, versionRange, remediation.CompareVersions)
+}
+
+// compareSemverVersions orders semantic versions as semver does, other versions segment by segment
+func compareSemverVersions(a, b string) int {
+	if c, ok := remediation.CompareSemver(a, b); ok {
+		return c
+	}
+	return remediation.CompareVersions(a, b)
+}
+
+// inIntervals tests a version against the interval notation of Maven and NuGet. A version alone is the exact
+// version, as vulnerability ranges have no soft requirements.
+func inIntervals(version, versionRange string, compare func(a, b string) int) bool {
+	versionRange = strings.ReplaceAll(versionRange, " ", "")
+	for len(versionRange) > 0 {
+		if versionRange[0] != '[' && versionRange[0] != '(' {
+			return compare(version, versionRange) == 0
+		}
+		end := strings.IndexAny(versionRange, "])")
+		if end < 0 {
+			return false
+		}
+		if inInterval(version, versionRange[:end+1], compare) {
+			return true
+		}
+		versionRange = strings.TrimPrefix(versionRange[end+1:], ",")
+	}
+	return false
+}
+
+func inInterval(version, interval string, compare func(a, b string) int) bool {
+	lowInclusive, highInclusive := interval[0] == '[', interval[len(interval)-1] == ']'
+	bounds := strings.Split(interval[1:len(interval)-1], ",")
+	if len(bounds) == 1 {
+		return lowInclusive && highInclusive && compare(version, bounds[0]) == 0
+	}
+	low, high := bounds[0], bounds[1]
+	if low != "" {
+		c := compare(version, low)
+		if c < 0 || (c == 0 && !lowInclusive) {
+			return false
+		}
+	}
+	if high != "" {
+		c := compare(version, high)
+		if c > 0 || (c == 0 && !highInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// satisfiesComparators tests a version against comparators such as >=1.0.0 <1.2.3, with || between alternatives
+func satisfiesComparators(version, versionRange string, compare func(a, b string) int) bool {
+	for _, set := range strings.Split(versionRange, "||") {
+		set = comparatorSpaceRegex.ReplaceAllString(strings.ReplaceAll(set, ",", " "), "$1")
+		fields := strings.Fields(set)
+		satisfied := len(fields) > 0
+		for _, field := range fields {
+			match := comparatorRegex.FindStringSubmatch(field)
+			if match == nil || !compareWith(match[1], compare(version, match[2])) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// compareWith tells whether a comparison result satisfies an operator, no operator is an exact version
+func compareWith(operator string, comparison int) bool {
+	switch operator {
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	case "!=":
+		return comparison != 0
+	}
+	return comparison == 0
+}
+
+// Qualifiers of Maven versions, in their order. Unknown qualifiers sort after them, alphabetically.
+var mavenQualifiers = map[string]int{
+	"alpha":     0,
+	"a":         0,
+	"beta":      1,
+	"b":         1,
+	"milestone": 2,
+	"m":         2,
+	"rc":        3,
+	"cr":        3,
+	"snapshot":  4,
+	"":          5,
+	"ga":        5,
+	"final":     5,
+	"release":   5,
+	"sp":        6,
+}
+
+const unknownMavenQualifier = 7
+
+// mavenToken is a numeric or qualifier part of a Maven version
+type mavenToken struct {
+	numeric bool
+	number  int
+	rank    int
+	text    string
+}
+
+// CompareMavenVersions orders Maven versions as Maven does: 1.0-alpha1 < 1.0-rc1 < 1.0 = 1.0.0 < 1.0-sp1 < 1.0.1
+func CompareMavenVersions(a, b string) int {
+	tokensA, tokensB := mavenTokens(a), mavenTokens(b)
+	for i := 0; i < len(tokensA) || i < len(tokensB); i++ {
+		x, y := mavenTokenAt(tokensA, i, tokensB), mavenTokenAt(tokensB, i, tokensA)
+		if c := compareMavenTokens(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// mavenTokenAt returns a token of a version, padded with the zero or release qualifier that matches the token of
+// the other version
+func mavenTokenAt(tokens []mavenToken, i int, other []mavenToken) mavenToken {
+	if i < len(tokens) {
+		return tokens[i]
+	}
+	if other[i].numeric {
+		return mavenToken{numeric: true}
+	}
+	return mavenToken{rank: mavenQualifiers[""]}
+}
+
+func compareMavenTokens(x, y mavenToken) int {
+	switch {
+	case x.numeric && y.numeric:
+		return compareInts(x.number, y.number)
+	case x.numeric:
+		return 1
+	case y.numeric:
+		return -1
+	case x.rank != y.rank:
+		return compareInts(x.rank, y.rank)
+	}
+	return strings.Compare(x.text, y.text)
+}
+
+func mavenTokens(version string) []mavenToken {
+	var tokens []mavenToken
+	var current strings.Builder
+	digits := false
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		text := current.String()
+		current.Reset()
+		if number, err := strconv.Atoi(text); err == nil {
+			tokens = append(tokens, mavenToken{numeric: true, number: number})
+			return
+		}
+		rank, known := mavenQualifiers[text]
+		if !known {
+			rank = unknownMavenQualifier
+		}
+		tokens = append(tokens, mavenToken{rank: rank, text: text})
+	}
+	for _, r := range strings.ToLower(strings.TrimSpace(version)) {
+		isDigit := unicode.IsDigit(r)
+		if r == '.' || r == '-' || r == '_' || (current.Len() > 0 && isDigit != digits) {
+			flush()
+		}
+		if r != '.' && r != '-' && r != '_' {
+			current.WriteRune(r)
+			digits = isDigit
+		}
+	}
+	flush()
+	// trailing zeros and release qualifiers do not change a version: 1.0.0 = 1 = 1-final
+	for len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		if (last.numeric && last.number != 0) || (!last.numeric && last.rank != mavenQualifiers[""]) {
+			break
+		}
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// CompareNugetVersions orders NuGet versions, which have up to four numbers and compare their pre-release labels
+// case-insensitively: 1.0 = 1.0.0.0 and 1.0.0-beta < 1.0.0
+func CompareNugetVersions(a, b string) int {
+	releaseA, prereleaseA := splitNugetVersion(a)
+	releaseB, prereleaseB := splitNugetVersion(b)
+	for i := 0; i < len(releaseA) || i < len(releaseB); i++ {
+		if c := compareInts(numberAt(releaseA, i), numberAt(releaseB, i)); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case prereleaseA == prereleaseB:
+		return 0
+	case prereleaseA == "":
+		return 1
+	case prereleaseB == "":
+		return -1
+	}
+	if c, ok := remediation.CompareSemver("0.0.0-"+prereleaseA, "0.0.0-"+prereleaseB); ok {
+		return c
+	}
+	return strings.Compare(prereleaseA, prereleaseB)
+}
+
+func splitNugetVersion(version string) (release []int, prerelease string) {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if plus := strings.Index(version, "+"); plus >= 0 {
+		version = version[:plus]
+	}
+	if dash := strings.Index(version, "-"); dash >= 0 {
+		version, prerelease = version[:dash], version[dash+1:]
+	}
+	for _, part := range strings.Split(version, ".") {
+		number, _ := strconv.Atoi(part)
+		release = append(release, number)
+	}
+	return release, prerelease
+}
+
+func numberAt(numbers []int, i int) int {
+	if i < len(numbers) {
+		return numbers[i]
+	}
+	return 0
+}
+
+// pep440Version is a Python version, as defined by PEP 440
+type pep440Version struct {
+	epoch   int
+	release []int
+	// pre, post and dev are -1 when missing
+	preLabel int
+	pre      int
+	post     int
+	dev      int
+}
+
+var pep440PreLabels = map[string]int{"a": 0, "alpha": 0, "b": 1, "beta": 1, "c": 2, "rc": 2, "pre": 2, "preview": 2}
+
+func parsePep440(version string) (pep440Version, bool) {
+	match := pep440Regex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(version)))
+	if match == nil {
+		return pep440Version{}, false
+	}
+	number := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	v := pep440Version{epoch: number(match[1]), preLabel: -1, pre: -1, post: -1, dev: -1}
+	for _, part := range strings.Split(match[2], ".") {
+		v.release = append(v.release, number(part))
+	}
+	if match[3] != "" {
+		v.preLabel, v.pre = pep440PreLabels[match[3]], number(match[4])
+	}
+	switch {
+	case match[5] != "":
+		v.post = number(match[5])
+	case match[6] != "":
+		v.post = number(match[7])
+	}
+	if match[8] != "" {
+		v.dev = number(match[9])
+	}
+	return v, true
+}
+
+// sortKey returns the parts of a version that follow the release, in the order PEP 440 compares them. A
+// development release sorts before the pre-releases of its release, a release after them.
+func (v pep440Version) sortKey() []int {
+	preLabel, pre := v.preLabel, v.pre
+	switch {
+	case preLabel < 0 && v.post < 0 && v.dev >= 0:
+		preLabel = -1
+	case preLabel < 0:
+		preLabel = len(pep440PreLabels)
+	}
+	dev := v.dev
+	if dev < 0 {
+		dev = int(^uint(0) >> 1)
+	}
+	return []int{preLabel, pre, v.post, dev}
+}
+
+func comparePep440(a, b pep440Version) int {
+	if c := compareInts(a.epoch, b.epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(a.release) || i < len(b.release); i++ {
+		if c := compareInts(numberAt(a.release, i), numberAt(b.release, i)); c != 0 {
+			return c
+		}
+	}
+	keyA, keyB := a.sortKey(), b.sortKey()
+	for i := range keyA {
+		if c := compareInts(keyA[i], keyB[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// satisfiesPythonSpecifiers tests a version against comma separated PEP 440 specifiers. Pre-releases are
+// matched as any other version, an affected pre-release is affected.
+func satisfiesPythonSpecifiers(version, specifiers string) bool {
+	v, ok := parsePep440(version)
+	if !ok {
+		return satisfiesComparators(version, specifiers, remediation.CompareVersions)
+	}
+	satisfied := false
+	for _, specifier := range strings.Split(specifiers, ",") {
+		specifier = strings.TrimSpace(specifier)
+		if specifier == "" {
+			continue
+		}
+		match := pythonSpecifierRegex.FindStringSubmatch(specifier)
+		if match == nil || !satisfiesPythonSpecifier(version, v, match[1], match[2]) {
+			return false
+		}
+		satisfied = true
+	}
+	return satisfied
+}
+
+func satisfiesPythonSpecifier(version string, v pep440Version, operator, specified string) bool {
+	switch operator {
+	case "===":
+		return strings.EqualFold(strings.TrimSpace(version), specified)
+	case "==", "!=":
+		var matches bool
+		if prefix := strings.TrimSuffix(specified, ".*"); prefix != specified {
+			matches = pep440HasPrefix(v, prefix)
+		} else {
+			s, ok := parsePep440(specified)
+			matches = ok && comparePep440(v, s) == 0
+		}
+		return matches == (operator == "==")
+	case "~=":
+		// ~=1.4.5 is >=1.4.5 and ==1.4.*
+		s, ok := parsePep440(specified)
+		if !ok || len(s.release) < 2 || comparePep440(v, s) < 0 {
+			return false
+		}
+		prefix := make([]string, 0, len(s.release)-1)
+		for _, n := range s.release[:len(s.release)-1] {
+			prefix = append(prefix, strconv.Itoa(n))
+		}
+		return pep440HasPrefix(v, strings.Join(prefix, "."))
+	}
+	s, ok := parsePep440(specified)
+	return ok && compareWith(operator, comparePep440(v, s))
+}
+
+// pep440HasPrefix tells whether the release of a version starts with the numbers of a prefix, as ==1.4.* does
+func pep440HasPrefix(v pep440Version, prefix string) bool {
+	p, ok := parsePep440(prefix)
+	if !ok || p.epoch != v.epoch {
+		return false
+	}
+	for i, n := range p.release {
+		if numberAt(v.release, i) != n {
+			return false
+		}
+	}
+	return true
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package scadb
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestAffected(t *testing.T) {
+	cases := []struct {
+		packageManager, version, versionRange string
+		expected                              bool
+	}{
+		{"Npm", "4.17.20", "<4.17.21", true},
+		{"Npm", "4.17.21", "<4.17.21", false},
+		{"Npm", "2.0.5", "^1.2.0 || >=2.0.0 <2.1.3", true},
+		{"Npm", "3.0.0-beta.1", ">=2.0.0 <3.0.0", false},
+		{"Maven", "2.14.1", "[2.0,2.15.0)", true},
+		{"Maven", "2.15.0", "[2.0,2.15.0)", false},
+		{"Maven", "2.15.0-rc1", "[2.0,2.15.0)", true},
+		{"Maven", "1.2.3", "(,1.0],[1.2,1.3)", true},
+		{"Maven", "1.1", "(,1.0],[1.2,1.3)", false},
+		{"Maven", "1.0.0", "[1.0]", true},
+		{"Maven", "5.3.18.RELEASE", "[5.3.0,5.3.18]", true},
+		{"Nuget", "13.0.1", "[13.0.1]", true},
+		{"Nuget", "13.0", "[13.0.0.0]", true},
+		{"Nuget", "12.0.3", "(,13.0.1)", true},
+		{"Nuget", "13.0.1-beta1", "[13.0.0, 13.0.1)", true},
+		{"Python", "2.31.0", ">=2.0,<2.32.0", true},
+		{"Python", "2.32.0", ">=2.0,<2.32.0", false},
+		{"Python", "2.32.0rc1", "<2.32.0", true},
+		{"Python", "1.4.7", "~=1.4.5", true},
+		{"Python", "1.5.0", "~=1.4.5", false},
+		{"Python", "1.4.2.post1", "==1.4.*,!=1.4.3", true},
+		{"Python", "1.4.3", "==1.4.*,!=1.4.3", false},
+		{"Go", "v0.0.0-20220722155237-a158d28d115b", "<v0.0.0-20220906165146-f3363e06e74c", true},
+		{"Go", "v0.14.0", ">=v0.0.0 <v0.3.8", false},
+		{"Go", "v1.2.3+incompatible", ">=1.0.0, <1.3.0", true},
+		{"Php", "5.4.1", ">=5.0.0,<5.4.2 || >=6.0.0,<6.0.1", true},
+		{"Ruby", "6.1.7", "6.1.7", true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, Affected(c.packageManager, c.version, c.versionRange), c.expected,
+			"%s %s in %s", c.packageManager, c.version, c.versionRange)
+	}
+}
+
+func TestCompareMavenVersions(t *testing.T) {
+	ordered := []string{"1.0-alpha1", "1.0-beta", "1.0-m2", "1.0-rc1", "1.0-SNAPSHOT", "1.0", "1.0-sp1", "1.0.1", "1.1"}
+	for i := 1; i < len(ordered); i++ {
+		assert.Equal(t, CompareMavenVersions(ordered[i-1], ordered[i]), -1, "%s < %s", ordered[i-1], ordered[i])
+	}
+	assert.Equal(t, CompareMavenVersions("1.0.0", "1"), 0)
+	assert.Equal(t, CompareMavenVersions("1.0-final", "1.0"), 0)
+}
+
+func TestComparePep440(t *testing.T) {
+	ordered := []string{"1.0.dev1", "1.0a1.dev1", "1.0a1", "1.0b2", "1.0rc1", "1.0", "1.0.post1.dev1", "1.0.post1", "1.1", "1!0.1"}
+	for i := 1; i < len(ordered); i++ {
+		a, okA := parsePep440(ordered[i-1])
+		b, okB := parsePep440(ordered[i])
+		assert.Assert(t, okA && okB)
+		assert.Equal(t, comparePep440(a, b), -1, "%s < %s", ordered[i-1], ordered[i])
+	}
+}
@@ -43,3 +43,33 @@ type KicsRemediationSummary struct {
 	AvailableRemediation int `json:"available_remediation_count"`
 	AppliedRemediation   int `json:"applied_remediation_count"`
 }
+
+// KicsExitStatus describes an exit code of the kics container
+type KicsExitStatus struct {
+	Description string
+	// Completed is set when kics ran to the end and wrote its results
+	Completed bool
+}
+
+// kicsExitCodes decodes the exit codes of kics, see https://docs.kics.io/latest/results/#exit_status_code.
+// Scans exit with the highest severity found, remediation exits with 70 when some results were not remediated.
+// The kics engine error, 126, is not listed: container engines exit with 126 when the command cannot be invoked,
+// so the container runtime reports it as a run failure.
+var kicsExitCodes = map[int]KicsExitStatus{
+	0:   {Description: "no results were found", Completed: true},
+	20:  {Description: "INFO results were found", Completed: true},
+	30:  {Description: "LOW results were found", Completed: true},
+	40:  {Description: "MEDIUM results were found", Completed: true},
+	50:  {Description: "HIGH results were found", Completed: true},
+	60:  {Description: "CRITICAL results were found", Completed: true},
+	70:  {Description: "some results could not be remediated", Completed: true},
+	130: {Description: "kics was interrupted"},
+}
+
+// DecodeKicsExitCode returns the meaning of a kics exit code
+func DecodeKicsExitCode(code int) KicsExitStatus {
+	if status, ok := kicsExitCodes[code]; ok {
+		return status
+	}
+	return KicsExitStatus{Description: "unknown kics exit code"}
+}
@@ -0,0 +1,157 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	encryptedFileVersion     = 1
+	encryptedFileName        = ".checkmarx/secrets.enc"
+	encryptedFilePermissions = 0600
+	encryptedDirPermissions  = 0700
+	saltLength               = 16
+	keyLength                = 32
+	scryptN                  = 32768
+	scryptR                  = 8
+	scryptP                  = 1
+)
+
+// encryptedFile is the on-disk format: the secrets map as JSON, sealed with AES-256-GCM
+// under a key derived from the passphrase with scrypt
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+type encryptedFileStore struct {
+	path       string
+	passphrase string
+}
+
+func newEncryptedFileStore() (*encryptedFileStore, error) {
+	passphrase := os.Getenv(params.SecretsPassphraseEnv)
+	if passphrase == "" {
+		return nil, errors.Errorf("the encrypted secret backend requires a passphrase in %s", params.SecretsPassphraseEnv)
+	}
+	path := viper.GetString(params.SecretsFileKey)
+	if path == "" {
+		usr, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(usr.HomeDir, encryptedFileName)
+	}
+	return &encryptedFileStore{path: path, passphrase: passphrase}, nil
+}
+
+func (s *encryptedFileStore) deriveKey(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *encryptedFileStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	content, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file encryptedFile
+	if err = json.Unmarshal(content, &file); err != nil || file.Version != encryptedFileVersion {
+		return nil, errors.Errorf("%s is not a valid secrets file", s.path)
+	}
+	aead, err := s.deriveKey(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	// GCM panics on a nonce of the wrong size, a damaged file must fail like any other invalid one
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, errors.Errorf("%s is not a valid secrets file", s.path)
+	}
+	plain, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, errors.Errorf("could not decrypt %s, check the passphrase in %s", s.path, params.SecretsPassphraseEnv)
+	}
+	err = json.Unmarshal(plain, &secrets)
+	return secrets, err
+}
+
+func (s *encryptedFileStore) save(secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	file := encryptedFile{Version: encryptedFileVersion, Salt: make([]byte, saltLength)}
+	if _, err = rand.Read(file.Salt); err != nil {
+		return err
+	}
+	aead, err := s.deriveKey(file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, plain, nil)
+	content, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), encryptedDirPermissions); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, content, encryptedFilePermissions)
+}
+
+func (s *encryptedFileStore) Get(path string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[path]
+	if !ok {
+		return "", errors.Errorf("secret '%s' not found in %s", path, s.path)
+	}
+	return secret, nil
+}
+
+func (s *encryptedFileStore) Set(path, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[path] = value
+	return s.save(secrets)
+}
+
+func (s *encryptedFileStore) Delete(path string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(secrets, path)
+	return s.save(secrets)
+}
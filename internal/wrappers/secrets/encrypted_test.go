@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/checkmarx/ast-cli/internal/params"
+	"github.com/spf13/viper"
+	"gotest.tools/assert"
+)
+
+// encryptedTestFile points the encrypted backend to a file of the test with the given passphrase
+func encryptedTestFile(t *testing.T, passphrase string) string {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	viper.Set(params.SecretsFileKey, path)
+	t.Cleanup(func() { viper.Set(params.SecretsFileKey, "") })
+	t.Setenv(params.SecretsPassphraseEnv, passphrase)
+	return path
+}
+
+func TestEncryptedRoundTrip(t *testing.T) {
+	path := encryptedTestFile(t, "correct horse")
+	reference, err := Save(EncryptedBackend, "cx/roundtrip/apikey", "my-api-key")
+	assert.NilError(t, err)
+	assert.Equal(t, reference, "encrypted://cx/roundtrip/apikey")
+
+	content, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(content), "my-api-key"))
+	info, err := os.Stat(path)
+	assert.NilError(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(encryptedFilePermissions))
+
+	secret, err := Resolve(reference)
+	assert.NilError(t, err)
+	assert.Equal(t, secret, "my-api-key")
+
+	assert.NilError(t, Delete(reference))
+	_, err = Resolve(reference)
+	assert.ErrorContains(t, err, "secret 'cx/roundtrip/apikey' not found")
+}
+
+func TestEncryptedWrongPassphrase(t *testing.T) {
+	path := encryptedTestFile(t, "correct horse")
+	_, err := Save(EncryptedBackend, "cx/wrong/apikey", "my-api-key")
+	assert.NilError(t, err)
+
+	t.Setenv(params.SecretsPassphraseEnv, "battery staple")
+	_, err = Resolve("encrypted://cx/wrong/apikey")
+	assert.ErrorContains(t, err, "could not decrypt "+path+", check the passphrase in "+params.SecretsPassphraseEnv)
+	_, err = Save(EncryptedBackend, "cx/wrong/other", "other-key")
+	assert.ErrorContains(t, err, "could not decrypt")
+}
+
+func TestEncryptedCorruptFile(t *testing.T) {
+	path := encryptedTestFile(t, "correct horse")
+	store, err := newEncryptedFileStore()
+	assert.NilError(t, err)
+	assert.NilError(t, store.Set("cx/corrupt/apikey", "my-api-key"))
+	content, err := os.ReadFile(path)
+	assert.NilError(t, err)
+
+	for name, corrupt := range map[string]string{
+		"truncated":     string(content[:len(content)/2]),
+		"garbage":       "not a secrets file",
+		"empty":         "",
+		"version":       strings.Replace(string(content), `"version":1`, `"version":2`, 1),
+		"short nonce":   `{"version":1,"salt":"c2FsdA==","nonce":"AAE=","data":"AAE="}`,
+		"missing nonce": `{"version":1,"salt":"c2FsdA==","data":"AAE="}`,
+	} {
+		assert.NilError(t, os.WriteFile(path, []byte(corrupt), encryptedFilePermissions))
+		_, err = store.Get("cx/corrupt/apikey")
+		assert.ErrorContains(t, err, "is not a valid secrets file", name)
+	}
+
+	// a file cut inside the sealed data is still valid JSON, GCM authentication rejects it
+	var sealed encryptedFile
+	assert.NilError(t, store.save(map[string]string{"cx/corrupt/apikey": "my-api-key"}))
+	file, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.NilError(t, json.Unmarshal(file, &sealed))
+	sealed.Data = sealed.Data[:len(sealed.Data)-1]
+	truncated, err := json.Marshal(sealed)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(path, truncated, encryptedFilePermissions))
+	_, err = store.Get("cx/corrupt/apikey")
+	assert.ErrorContains(t, err, "could not decrypt")
+}
+
+func TestEncryptedMissingPassphrase(t *testing.T) {
+	path := encryptedTestFile(t, "")
+	_, err := Save(EncryptedBackend, "cx/missing/apikey", "my-api-key")
+	assert.ErrorContains(t, err, "the encrypted secret backend requires a passphrase in "+params.SecretsPassphraseEnv)
+	_, err = Resolve("encrypted://cx/missing/apikey")
+	assert.ErrorContains(t, err, "requires a passphrase")
+	_, err = os.Stat(path)
+	assert.Assert(t, os.IsNotExist(err))
+}
@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/pkg/errors"
+)
+
+// execStore runs the command of an exec:// reference, e.g. exec://vault kv get -field=apikey secret/cx/prod,
+// and uses its standard output as the secret
+type execStore struct{}
+
+func (*execStore) Get(path string) (string, error) {
+	args, err := shlex.Split(path)
+	if err != nil || len(args) == 0 {
+		return "", errors.Errorf("invalid exec reference '%s'", path)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "command '%s' failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+func (*execStore) Set(_, _ string) error {
+	return errors.New("the exec backend is read only, set the property to an exec:// reference instead")
+}
+
+func (*execStore) Delete(_ string) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/zalando/go-keyring"
+)
+
+const pathSeparator = "/"
+
+// keyringStore uses the OS keyring: Secret Service over D-Bus on Linux, Keychain on macOS and
+// the Credential Manager on Windows. The first path segment is the service, the rest the account.
+type keyringStore struct{}
+
+func splitKeyringPath(path string) (service, account string, err error) {
+	service, account, found := strings.Cut(path, pathSeparator)
+	if !found || service == "" || account == "" {
+		return "", "", errors.Errorf("invalid keyring reference '%s', expected keyring://<service>/<account>", path)
+	}
+	return service, account, nil
+}
+
+func (*keyringStore) Get(path string) (string, error) {
+	service, account, err := splitKeyringPath(path)
+	if err != nil {
+		return "", err
+	}
+	return keyring.Get(service, account)
+}
+
+func (*keyringStore) Set(path, value string) error {
+	service, account, err := splitKeyringPath(path)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(service, account, value)
+}
+
+func (*keyringStore) Delete(path string) error {
+	service, account, err := splitKeyringPath(path)
+	if err != nil {
+		return err
+	}
+	err = keyring.Delete(service, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
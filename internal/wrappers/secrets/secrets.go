@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/checkmarx/ast-cli/internal/logger"
+	"github.com/pkg/errors"
+)
+
+// Backends, each backend except file stores references of the form <backend>://<path> in the configuration
+const (
+	FileBackend      = "file"
+	KeyringBackend   = "keyring"
+	EncryptedBackend = "encrypted"
+	ExecBackend      = "exec"
+	schemeSeparator  = "://"
+)
+
+// Backends lists the supported secret backends
+var Backends = []string{FileBackend, KeyringBackend, EncryptedBackend, ExecBackend}
+
+// Store keeps the secrets referenced from the configuration
+type Store interface {
+	Get(path string) (string, error)
+	Set(path, value string) error
+	Delete(path string) error
+}
+
+var (
+	resolved     = map[string]string{}
+	resolvedLock sync.Mutex
+)
+
+func newStore(backend string) (Store, error) {
+	switch backend {
+	case KeyringBackend:
+		return &keyringStore{}, nil
+	case EncryptedBackend:
+		return newEncryptedFileStore()
+	case ExecBackend:
+		return &execStore{}, nil
+	default:
+		return nil, errors.Errorf("unknown secret backend '%s', use one of %v", backend, Backends)
+	}
+}
+
+// IsReference reports whether a configuration value points to a secret backend instead of holding the secret
+func IsReference(value string) bool {
+	backend, _, found := strings.Cut(value, schemeSeparator)
+	return found && (backend == KeyringBackend || backend == EncryptedBackend || backend == ExecBackend)
+}
+
+// Reference builds the configuration value pointing to a secret of a backend
+func Reference(backend, path string) string {
+	return backend + schemeSeparator + path
+}
+
+// Resolve returns the secret a configuration value refers to. Values that are not references are returned
+// as they are. Resolved secrets are cached for the lifetime of the process and hidden from the logs.
+func Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	resolvedLock.Lock()
+	defer resolvedLock.Unlock()
+	if secret, ok := resolved[value]; ok {
+		return secret, nil
+	}
+	backend, path, _ := strings.Cut(value, schemeSeparator)
+	store, err := newStore(backend)
+	if err != nil {
+		return "", err
+	}
+	secret, err := store.Get(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve secret %s", value)
+	}
+	logger.SanitizeValue(secret)
+	resolved[value] = secret
+	return secret, nil
+}
+
+// Save stores a secret in a backend and returns the value to write to the configuration: the secret itself for
+// the file backend, a reference otherwise. The exec backend is read only, its references are set directly.
+func Save(backend, path, secret string) (string, error) {
+	if backend == "" || backend == FileBackend {
+		return secret, nil
+	}
+	store, err := newStore(backend)
+	if err != nil {
+		return "", err
+	}
+	if err = store.Set(path, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to store secret in %s backend", backend)
+	}
+	return Reference(backend, path), nil
+}
+
+// Delete removes the secret a reference points to, values that are not references are ignored
+func Delete(value string) error {
+	if !IsReference(value) {
+		return nil
+	}
+	backend, path, _ := strings.Cut(value, schemeSeparator)
+	store, err := newStore(backend)
+	if err != nil {
+		return err
+	}
+	resolvedLock.Lock()
+	delete(resolved, value)
+	resolvedLock.Unlock()
+	return store.Delete(path)
+}
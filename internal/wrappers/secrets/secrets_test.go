@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestIsReference(t *testing.T) {
+	assert.Assert(t, IsReference("keyring://cx/default/apikey"))
+	assert.Assert(t, IsReference("encrypted://cx/default/apikey"))
+	assert.Assert(t, IsReference("exec://pass show cx"))
+	assert.Assert(t, !IsReference("my-api-key"))
+	assert.Assert(t, !IsReference("https://ast.checkmarx.net"))
+}
+
+func TestResolveExec(t *testing.T) {
+	secret, err := Resolve("exec://echo exec-secret")
+	assert.NilError(t, err)
+	assert.Equal(t, secret, "exec-secret")
+
+	_, err = Resolve("exec://false")
+	assert.ErrorContains(t, err, "failed to resolve secret exec://false")
+
+	value, err := Resolve("plain-secret")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "plain-secret")
+}
+
+func TestSaveFileBackend(t *testing.T) {
+	value, err := Save(FileBackend, "cx/default/apikey", "my-api-key")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "my-api-key")
+
+	_, err = Save(ExecBackend, "cx/default/apikey", "my-api-key")
+	assert.ErrorContains(t, err, "read only")
+}
+
+func TestSplitKeyringPath(t *testing.T) {
+	service, account, err := splitKeyringPath("cx/default/apikey")
+	assert.NilError(t, err)
+	assert.Equal(t, service, "cx")
+	assert.Equal(t, account, "default/apikey")
+
+	_, _, err = splitKeyringPath("cx")
+	assert.ErrorContains(t, err, "invalid keyring reference")
+}
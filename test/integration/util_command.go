@@ -14,6 +14,7 @@ import (
 	"github.com/checkmarx/ast-cli/internal/commands"
 	"github.com/checkmarx/ast-cli/internal/params"
 	"github.com/checkmarx/ast-cli/internal/wrappers"
+	"github.com/checkmarx/ast-cli/internal/wrappers/container"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"gotest.tools/assert"
@@ -50,6 +51,11 @@ func bindProxy(t *testing.T) {
 	if err != nil {
 		assert.NilError(t, err)
 	}
+	err = viper.BindEnv(params.NoProxyKey, params.CxNoProxyEnv, params.NoProxyEnv, params.NoProxyLowerCaseEnv)
+	if err != nil {
+		assert.NilError(t, err)
+	}
+	viper.SetDefault(params.NoProxyKey, "")
 }
 
 // Create a command to execute in tests
@@ -108,6 +114,8 @@ func createASTIntegrationTestCommand(t *testing.T) *cobra.Command {
 	featureFlagsWrapper := wrappers.NewFeatureFlagsHTTPWrapper(featureFlagsPath)
 	policyWrapper := wrappers.NewHTTPPolicyWrapper(policyEvaluationPath)
 	sastMetadataWrapper := wrappers.NewSastIncrementalHTTPWrapper(sastIncrementalPath)
+	npmRegistryWrapper := wrappers.NewNpmRegistryHTTPWrapper()
+	scaDBWrapper := wrappers.NewScaDBHTTPWrapper()
 
 	astCli := commands.NewAstCLI(
 		scansWrapper,
@@ -137,6 +145,9 @@ func createASTIntegrationTestCommand(t *testing.T) *cobra.Command {
 		featureFlagsWrapper,
 		policyWrapper,
 		sastMetadataWrapper,
+		npmRegistryWrapper,
+		scaDBWrapper,
+		container.NewProvider(),
 	)
 	return astCli
 }